@@ -98,6 +98,10 @@ func Encode(e *Endpoint) map[string]string {
 	set("path", strings.Join(e.Path, ","))
 	set("host", strings.Join(e.Host, ","))
 
+	if e.Stream {
+		set("stream", "true")
+	}
+
 	return ep
 }
 
@@ -114,6 +118,7 @@ func Decode(e map[string]string) *Endpoint {
 		Path:        slice(e["path"]),
 		Host:        slice(e["host"]),
 		Handler:     e["handler"],
+		Stream:      e["stream"] == "true",
 	}
 }
 
@@ -1,7 +1,6 @@
 package api
 
 import (
-	"strings"
 	"testing"
 )
 
@@ -16,6 +15,12 @@ func TestEncoding(t *testing.T) {
 			Method:      []string{"GET"},
 			Path:        []string{"/test"},
 		},
+		{
+			Name:    "Foo.Stream",
+			Handler: "rpc",
+			Path:    []string{"/stream"},
+			Stream:  true,
+		},
 	}
 
 	compare := func(expect, got []string) bool {
@@ -67,9 +72,9 @@ func TestEncoding(t *testing.T) {
 		// check encoded map
 		name := e["endpoint"]
 		desc := e["description"]
-		method := strings.Split(e["method"], ",")
-		path := strings.Split(e["path"], ",")
-		host := strings.Split(e["host"], ",")
+		method := slice(e["method"])
+		path := slice(e["path"])
+		host := slice(e["host"])
 		handler := e["handler"]
 
 		if name != d.Name {
@@ -109,6 +114,9 @@ func TestEncoding(t *testing.T) {
 		if ok := compare(d.Host, de.Host); !ok {
 			t.Fatalf("expected %v got %v", d.Host, de.Host)
 		}
+		if de.Stream != d.Stream {
+			t.Fatalf("expected %v got %v", d.Stream, de.Stream)
+		}
 	}
 }
 
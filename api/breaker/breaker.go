@@ -0,0 +1,223 @@
+// Package breaker provides circuit breaking for the api handlers
+//
+// Errors are tracked per service and per node. Nodes with an open circuit are
+// ejected from selection and once a service circuit opens requests fail fast
+// with a 503 rather than waiting on a backend which is down.
+package breaker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/selector"
+)
+
+type state int
+
+const (
+	closed state = iota
+	open
+	halfOpen
+)
+
+type circuit struct {
+	state    state
+	total    int
+	failures int
+	start    time.Time
+	opened   time.Time
+	// when the trial request was let through
+	probed time.Time
+}
+
+// Breaker tracks the error rate of backends
+type Breaker struct {
+	opts Options
+
+	sync.Mutex
+	circuits map[string]*circuit
+}
+
+// New returns a new breaker
+func New(opts ...Option) *Breaker {
+	return &Breaker{
+		opts:     NewOptions(opts...),
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// Allow returns whether a request to the key should be made, when the
+// circuit is open it also returns how long until it will be retried
+func (b *Breaker) Allow(key string) (time.Duration, bool) {
+	b.Lock()
+	defer b.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		return 0, true
+	}
+
+	switch c.state {
+	case open:
+		wait := b.opts.Cooldown - b.since(c.opened)
+		if wait > 0 {
+			return wait, false
+		}
+		// let a single trial request through
+		c.state = halfOpen
+		c.probed = b.opts.Now()
+		return 0, true
+	case halfOpen:
+		// the result of the trial request was never marked
+		if b.since(c.probed) > b.opts.Cooldown {
+			c.probed = b.opts.Now()
+			return 0, true
+		}
+		// a trial request is in flight
+		return b.opts.Cooldown - b.since(c.probed), false
+	}
+
+	return 0, true
+}
+
+// Mark records the result of a request to the key
+func (b *Breaker) Mark(key string, err error) {
+	failed := IsFailure(err)
+
+	b.Lock()
+	defer b.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{start: b.opts.Now()}
+		b.circuits[key] = c
+	}
+
+	switch c.state {
+	case halfOpen:
+		if failed {
+			c.state = open
+			c.opened = b.opts.Now()
+			return
+		}
+		*c = circuit{start: b.opts.Now()}
+		return
+	case open:
+		// results of requests made before the circuit opened
+		return
+	}
+
+	// start a new window
+	if b.since(c.start) > b.opts.Window {
+		*c = circuit{start: b.opts.Now()}
+	}
+
+	c.total++
+	if failed {
+		c.failures++
+	}
+
+	if c.total >= b.opts.MinRequests && float64(c.failures)/float64(c.total) >= b.opts.Threshold {
+		c.state = open
+		c.opened = b.opts.Now()
+	}
+}
+
+// Filter returns a select filter which ejects nodes with an open circuit,
+// if every node would be ejected the nodes are returned unchanged
+func (b *Breaker) Filter() selector.Filter {
+	return func(old []*registry.Service) []*registry.Service {
+		var services []*registry.Service
+
+		for _, service := range old {
+			var nodes []*registry.Node
+
+			for _, node := range service.Nodes {
+				if b.ejected(node.Id) {
+					continue
+				}
+				nodes = append(nodes, node)
+			}
+
+			if len(nodes) == 0 {
+				continue
+			}
+
+			serv := new(registry.Service)
+			*serv = *service
+			serv.Nodes = nodes
+			services = append(services, serv)
+		}
+
+		if len(services) == 0 {
+			return old
+		}
+
+		return services
+	}
+}
+
+func (b *Breaker) ejected(key string) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok || c.state == closed {
+		return false
+	}
+
+	// due a trial request
+	if c.state == open && b.since(c.opened) > b.opts.Cooldown {
+		c.state = halfOpen
+		c.probed = b.opts.Now()
+		return false
+	}
+
+	// the node wasn't selected for the trial request
+	if c.state == halfOpen && b.since(c.probed) > b.opts.Cooldown {
+		c.probed = b.opts.Now()
+		return false
+	}
+
+	return true
+}
+
+// CallWrapper returns a call wrapper which records the result of calls per node
+func (b *Breaker) CallWrapper() client.CallWrapper {
+	return func(fn client.CallFunc) client.CallFunc {
+		return func(ctx context.Context, node *registry.Node, req client.Request, rsp interface{}, opts client.CallOptions) error {
+			err := fn(ctx, node, req, rsp, opts)
+			b.Mark(node.Id, err)
+			return err
+		}
+	}
+}
+
+func (b *Breaker) since(t time.Time) time.Duration {
+	return b.opts.Now().Sub(t)
+}
+
+// IsFailure returns true for errors which indicate the backend is unhealthy,
+// client errors such as bad requests are not counted
+func IsFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	e := errors.FromError(err)
+
+	switch {
+	case e.Code == 0:
+		return true
+	case e.Code == 408:
+		return true
+	case e.Code >= 500:
+		return true
+	}
+
+	return false
+}
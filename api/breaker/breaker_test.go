@@ -0,0 +1,120 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/registry"
+)
+
+// clock is moved on by tests rather than sleeping
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestBreaker(t *testing.T) {
+	clk := &clock{now: time.Now()}
+	b := New(MinRequests(4), Threshold(0.5), Cooldown(time.Second*30), Now(clk.Now))
+
+	// client errors don't count
+	for i := 0; i < 10; i++ {
+		b.Mark("foo", errors.New(`{"code":400}`))
+	}
+	if _, ok := b.Allow("foo"); !ok {
+		t.Fatal("Expected circuit to be closed")
+	}
+
+	b.Mark("bar", nil)
+	for i := 0; i < 3; i++ {
+		b.Mark("bar", errors.New("connection refused"))
+	}
+
+	wait, ok := b.Allow("bar")
+	if ok || wait <= 0 {
+		t.Fatal("Expected circuit to be open")
+	}
+
+	clk.Add(time.Second * 31)
+
+	// trial request
+	if _, ok := b.Allow("bar"); !ok {
+		t.Fatal("Expected trial request to be allowed")
+	}
+	if _, ok := b.Allow("bar"); ok {
+		t.Fatal("Expected only one trial request")
+	}
+
+	b.Mark("bar", nil)
+
+	if _, ok := b.Allow("bar"); !ok {
+		t.Fatal("Expected circuit to be closed after successful trial")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	b := New(MinRequests(1))
+
+	services := []*registry.Service{{
+		Name: "bar",
+		Nodes: []*registry.Node{
+			{Id: "foo-1"},
+			{Id: "foo-2"},
+		},
+	}}
+
+	b.Mark("foo-1", errors.New("timeout"))
+
+	filtered := b.Filter()(services)
+	if len(filtered) != 1 || len(filtered[0].Nodes) != 1 || filtered[0].Nodes[0].Id != "foo-2" {
+		t.Fatalf("Expected foo-1 to be ejected got %+v", filtered)
+	}
+
+	b.Mark("foo-2", errors.New("timeout"))
+
+	// never eject every node
+	filtered = b.Filter()(services)
+	if len(filtered[0].Nodes) != 2 {
+		t.Fatalf("Expected all nodes when all are ejected got %+v", filtered)
+	}
+}
+
+func TestFilterTrial(t *testing.T) {
+	clk := &clock{now: time.Now()}
+	b := New(MinRequests(1), Cooldown(time.Second*30), Now(clk.Now))
+
+	services := []*registry.Service{{
+		Name: "bar",
+		Nodes: []*registry.Node{
+			{Id: "foo-1"},
+			{Id: "foo-2"},
+		},
+	}}
+
+	b.Mark("foo-1", errors.New("timeout"))
+
+	clk.Add(time.Second * 31)
+
+	// the node is due a trial request
+	if filtered := b.Filter()(services); len(filtered[0].Nodes) != 2 {
+		t.Fatalf("Expected foo-1 to be included for a trial got %+v", filtered)
+	}
+	if filtered := b.Filter()(services); len(filtered[0].Nodes) != 1 {
+		t.Fatalf("Expected foo-1 to be ejected during the trial got %+v", filtered)
+	}
+
+	clk.Add(time.Second * 31)
+
+	// the node wasn't selected for the trial so it's included again
+	if filtered := b.Filter()(services); len(filtered[0].Nodes) != 2 {
+		t.Fatalf("Expected foo-1 to be included for another trial got %+v", filtered)
+	}
+}
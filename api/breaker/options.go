@@ -0,0 +1,73 @@
+package breaker
+
+import (
+	"time"
+)
+
+type Options struct {
+	// Threshold is the error ratio at which the circuit opens
+	Threshold float64
+	// MinRequests is the number of requests in a window before the ratio is considered
+	MinRequests int
+	// Window is the period errors are counted over
+	Window time.Duration
+	// Cooldown is how long a circuit stays open before a trial request is let through
+	// and how long the result of a trial is waited for before another is let through
+	Cooldown time.Duration
+	// Now returns the current time, it's time.Now by default
+	Now func() time.Time
+}
+
+type Option func(o *Options)
+
+// NewOptions fills in the blanks
+func NewOptions(opts ...Option) Options {
+	options := Options{
+		Threshold:   0.5,
+		MinRequests: 20,
+		Window:      time.Second * 10,
+		Cooldown:    time.Second * 30,
+		Now:         time.Now,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return options
+}
+
+// Threshold sets the error ratio at which the circuit opens
+func Threshold(f float64) Option {
+	return func(o *Options) {
+		o.Threshold = f
+	}
+}
+
+// MinRequests sets the requests required in a window before a circuit can open
+func MinRequests(n int) Option {
+	return func(o *Options) {
+		o.MinRequests = n
+	}
+}
+
+// Window sets the period errors are counted over
+func Window(d time.Duration) Option {
+	return func(o *Options) {
+		o.Window = d
+	}
+}
+
+// Cooldown sets how long a circuit stays open
+func Cooldown(d time.Duration) Option {
+	return func(o *Options) {
+		o.Cooldown = d
+	}
+}
+
+// Now sets the clock circuits are timed with
+func Now(fn func() time.Time) Option {
+	return func(o *Options) {
+		o.Now = fn
+	}
+}
@@ -7,6 +7,7 @@ import (
 	goapi "github.com/asim/go-micro/v3/api"
 	"github.com/asim/go-micro/v3/api/handler"
 	api "github.com/asim/go-micro/v3/api/proto"
+	"github.com/asim/go-micro/v3/api/server/access"
 	"github.com/asim/go-micro/v3/client"
 	"github.com/asim/go-micro/v3/errors"
 	"github.com/asim/go-micro/v3/selector"
@@ -64,6 +65,8 @@ func (a *apiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	access.SetRoute(r.Context(), service.Name, access.Endpoint(service))
+
 	// create request and response
 	c := a.opts.Client
 	req := c.NewRequest(service.Name, service.Endpoint.Name, request)
@@ -28,6 +28,13 @@ type event struct {
 var (
 	Handler   = "event"
 	versionRe = regexp.MustCompilePOSIX("^v[0-9]+$")
+
+	// credentials of the caller aren't passed on to subscribers
+	dropHeaders = map[string]bool{
+		"Authorization":       true,
+		"Cookie":              true,
+		"Proxy-Authorization": true,
+	}
 )
 
 func eventName(parts []string) string {
@@ -92,6 +99,9 @@ func (e *event) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// set headers
 	for key, vals := range r.Header {
+		if dropHeaders[key] {
+			continue
+		}
 		header, ok := ev.Header[key]
 		if !ok {
 			header = &proto.Pair{
@@ -128,6 +138,13 @@ func (e *event) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), 500)
 		return
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"id":    ev.Id,
+		"topic": topic,
+	})
 }
 
 func (e *event) String() string {
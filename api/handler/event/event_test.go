@@ -0,0 +1,97 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/asim/go-micro/v3/api/handler"
+	proto "github.com/asim/go-micro/v3/api/proto"
+	"github.com/asim/go-micro/v3/client"
+)
+
+// testClient records published messages rather than sending them
+type testClient struct {
+	client.Client
+	published []client.Message
+}
+
+func (c *testClient) Publish(ctx context.Context, msg client.Message, opts ...client.PublishOption) error {
+	c.published = append(c.published, msg)
+	return nil
+}
+
+func TestEvRoute(t *testing.T) {
+	testData := []struct {
+		path   string
+		topic  string
+		action string
+	}{
+		{"/", "go.micro.api", "event"},
+		{"/foo", "go.micro.api.foo", ""},
+		{"/foo/bar", "go.micro.api.foo", "bar"},
+		{"/v1/foo/bar", "go.micro.api.v1.foo", "foo.bar"},
+	}
+
+	for _, d := range testData {
+		topic, action := evRoute("go.micro.api", d.path)
+		if topic != d.topic || action != d.action {
+			t.Fatalf("Expected %s %s for %s got %s %s", d.topic, d.action, d.path, topic, action)
+		}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	c := &testClient{Client: client.NewClient()}
+	h := NewHandler(handler.WithClient(c), handler.WithNamespace("go.micro.api"))
+
+	r := httptest.NewRequest("POST", "/webhooks/github", strings.NewReader(`{"action":"opened"}`))
+	r.Header.Set("X-Github-Event", "issues")
+	r.Header.Set("Authorization", "Bearer secret")
+	r.Header.Set("Cookie", "session=secret")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202 got %d", w.Code)
+	}
+	if len(c.published) != 1 {
+		t.Fatalf("Expected 1 message got %d", len(c.published))
+	}
+
+	msg := c.published[0]
+	if msg.Topic() != "go.micro.api.webhooks" {
+		t.Fatalf("Unexpected topic %s", msg.Topic())
+	}
+
+	ev, ok := msg.Payload().(*proto.Event)
+	if !ok {
+		t.Fatalf("Unexpected payload %T", msg.Payload())
+	}
+	if ev.Name != "github" {
+		t.Fatalf("Unexpected event name %s", ev.Name)
+	}
+	if ev.Data != `{"action":"opened"}` {
+		t.Fatalf("Unexpected event data %s", ev.Data)
+	}
+	if p := ev.Header["X-Github-Event"]; p == nil || p.Values[0] != "issues" {
+		t.Fatalf("Expected X-Github-Event header got %+v", ev.Header)
+	}
+	for _, k := range []string{"Authorization", "Cookie"} {
+		if _, ok := ev.Header[k]; ok {
+			t.Fatalf("Expected %s header to be dropped", k)
+		}
+	}
+
+	var rsp map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &rsp); err != nil {
+		t.Fatal(err)
+	}
+	if rsp["id"] != ev.Id || rsp["topic"] != msg.Topic() {
+		t.Fatalf("Unexpected response %+v", rsp)
+	}
+}
@@ -0,0 +1,338 @@
+// Package graphql provides a handler which serves graphql queries resolved by rpc services
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/api/handler"
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/util/ctx"
+)
+
+const (
+	Handler = "graphql"
+)
+
+var (
+	// MaxConcurrency is the max number of fields of a query resolved at once
+	MaxConcurrency = 10
+	// ResolverTTL is how long the fields derived from the registry are cached
+	ResolverTTL = time.Second * 30
+)
+
+// Resolver maps a top level graphql field to a service endpoint
+type Resolver struct {
+	Service  string `json:"service"`
+	Endpoint string `json:"endpoint"`
+}
+
+// Request is a graphql request
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Response is a graphql response
+type Response struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []*Error               `json:"errors,omitempty"`
+}
+
+// Error is a graphql error
+type Error struct {
+	Message string   `json:"message"`
+	Path    []string `json:"path,omitempty"`
+}
+
+type graphqlHandler struct {
+	opts handler.Options
+
+	// explicit mapping, if nil fields are derived from the registry
+	resolvers map[string]Resolver
+
+	// the fields derived from the registry
+	sync.Mutex
+	cached  map[string]Resolver
+	updated time.Time
+}
+
+func (g *graphqlHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bsize := handler.DefaultMaxRecvSize
+	if g.opts.MaxRecvSize > 0 {
+		bsize = g.opts.MaxRecvSize
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, bsize)
+	defer r.Body.Close()
+
+	var req Request
+
+	switch r.Method {
+	case "GET":
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if v := r.URL.Query().Get("variables"); len(v) > 0 {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				writeResponse(w, http.StatusBadRequest, &Response{Errors: []*Error{{Message: err.Error()}}})
+				return
+			}
+		}
+	case "POST":
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(w, http.StatusBadRequest, &Response{Errors: []*Error{{Message: err.Error()}}})
+			return
+		}
+	default:
+		writeResponse(w, http.StatusMethodNotAllowed, &Response{Errors: []*Error{{Message: "method not allowed"}}})
+		return
+	}
+
+	op, err := Parse(req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		writeResponse(w, http.StatusBadRequest, &Response{Errors: []*Error{{Message: err.Error()}}})
+		return
+	}
+
+	// GET requests can be made cross site so they must not change state
+	if op.Type == "mutation" && r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		writeResponse(w, http.StatusMethodNotAllowed, &Response{Errors: []*Error{{Message: "mutations require POST"}}})
+		return
+	}
+
+	resolvers, err := g.getResolvers()
+	if err != nil {
+		writeResponse(w, http.StatusInternalServerError, &Response{Errors: []*Error{{Message: err.Error()}}})
+		return
+	}
+
+	rsp := &Response{Data: make(map[string]interface{})}
+
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+
+	limit := MaxConcurrency
+	if limit < 1 {
+		limit = 1
+	}
+	sem := make(chan bool, limit)
+
+	// queries are resolved in parallel up to the limit, mutations in order
+	for _, field := range op.Selections {
+		wg.Add(1)
+
+		fn := func(f *Field) {
+			defer wg.Done()
+
+			v, err := g.resolve(r, resolvers, f)
+
+			mtx.Lock()
+			defer mtx.Unlock()
+
+			rsp.Data[f.Key()] = v
+			if err != nil {
+				rsp.Errors = append(rsp.Errors, &Error{
+					Message: errors.Parse(err.Error()).Detail,
+					Path:    []string{f.Key()},
+				})
+			}
+		}
+
+		if op.Type == "mutation" {
+			fn(field)
+			continue
+		}
+
+		sem <- true
+		go func(f *Field) {
+			defer func() { <-sem }()
+			fn(f)
+		}(field)
+	}
+
+	wg.Wait()
+
+	writeResponse(w, http.StatusOK, rsp)
+}
+
+// resolve calls the endpoint mapped to the field and selects the requested fields
+func (g *graphqlHandler) resolve(r *http.Request, resolvers map[string]Resolver, f *Field) (interface{}, error) {
+	if f.Name == "__typename" {
+		return "Query", nil
+	}
+
+	res, ok := resolvers[f.Name]
+	if !ok {
+		return nil, errors.BadRequest("go.micro.api", "unknown field %s", f.Name)
+	}
+
+	args := f.Arguments
+	if args == nil {
+		args = make(map[string]interface{})
+	}
+
+	b, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+
+	c := g.opts.Client
+	request := json.RawMessage(b)
+	var response json.RawMessage
+
+	req := c.NewRequest(res.Service, res.Endpoint, &request, client.WithContentType("application/json"))
+	if err := c.Call(ctx.FromRequest(r), req, &response); err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	if len(response) > 0 {
+		if err := json.Unmarshal(response, &v); err != nil {
+			return nil, err
+		}
+	}
+
+	return Select(v, f.Selections), nil
+}
+
+// getResolvers returns the explicit mapping or derives one from the registry,
+// derived mappings are cached for the ResolverTTL
+func (g *graphqlHandler) getResolvers() (map[string]Resolver, error) {
+	if g.resolvers != nil {
+		return g.resolvers, nil
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	if g.cached != nil && time.Since(g.updated) < ResolverTTL {
+		return g.cached, nil
+	}
+
+	resolvers, err := g.listResolvers()
+	if err != nil {
+		if g.cached == nil {
+			return nil, err
+		}
+		// keep serving the fields we know of
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error listing graphql fields: %v", err)
+		}
+		return g.cached, nil
+	}
+
+	g.cached = resolvers
+	g.updated = time.Now()
+
+	return resolvers, nil
+}
+
+// listResolvers derives the mapping from the services in the registry
+func (g *graphqlHandler) listResolvers() (map[string]Resolver, error) {
+	reg := registry.DefaultRegistry
+	if g.opts.Router != nil && g.opts.Router.Options().Registry != nil {
+		reg = g.opts.Router.Options().Registry
+	}
+
+	services, err := reg.ListServices()
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make(map[string]Resolver)
+
+	for _, s := range services {
+		// only services in the namespace are exposed
+		if !strings.HasPrefix(s.Name, g.opts.Namespace+".") {
+			continue
+		}
+
+		srvs, err := reg.GetService(s.Name)
+		if err != nil {
+			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+				logger.Warnf("Skipping the graphql fields of %s: %v", s.Name, err)
+			}
+			continue
+		}
+
+		for _, srv := range srvs {
+			for _, ep := range srv.Endpoints {
+				resolvers[FieldName(g.opts.Namespace, srv.Name, ep.Name)] = Resolver{
+					Service:  srv.Name,
+					Endpoint: ep.Name,
+				}
+			}
+		}
+	}
+
+	return resolvers, nil
+}
+
+func (g *graphqlHandler) String() string {
+	return "graphql"
+}
+
+// FieldName returns the graphql field name derived for a service endpoint
+// e.g go.micro.api.greeter Say.Hello => greeter_Say_Hello
+func FieldName(namespace, service, endpoint string) string {
+	name := strings.TrimPrefix(service, namespace+".") + "_" + endpoint
+	return strings.Map(func(r rune) rune {
+		if isNameChar(r) {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// Select returns only the selected fields of the value
+func Select(v interface{}, fields []*Field) interface{} {
+	if len(fields) == 0 {
+		return v
+	}
+
+	switch val := v.(type) {
+	case []interface{}:
+		list := make([]interface{}, len(val))
+		for i, item := range val {
+			list[i] = Select(item, fields)
+		}
+		return list
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(fields))
+		for _, f := range fields {
+			obj[f.Key()] = Select(val[f.Name], f.Selections)
+		}
+		return obj
+	}
+
+	return v
+}
+
+func writeResponse(w http.ResponseWriter, code int, rsp *Response) {
+	b, _ := json.Marshal(rsp)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(b)
+}
+
+func NewHandler(opts ...handler.Option) handler.Handler {
+	return &graphqlHandler{
+		opts: handler.NewOptions(opts...),
+	}
+}
+
+// WithResolvers returns a handler which resolves fields using the given mapping
+func WithResolvers(r map[string]Resolver, opts ...handler.Option) handler.Handler {
+	return &graphqlHandler{
+		opts:      handler.NewOptions(opts...),
+		resolvers: r,
+	}
+}
@@ -0,0 +1,199 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/api/handler"
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/registry"
+)
+
+// testClient records the calls made rather than sending them
+type testClient struct {
+	client.Client
+
+	sync.Mutex
+	calls  int
+	active int
+	max    int
+}
+
+func (c *testClient) NewRequest(service, endpoint string, req interface{}, opts ...client.RequestOption) client.Request {
+	return client.NewRequest(service, endpoint, req, opts...)
+}
+
+func (c *testClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	c.Lock()
+	c.calls++
+	c.active++
+	if c.active > c.max {
+		c.max = c.active
+	}
+	c.Unlock()
+
+	time.Sleep(time.Millisecond * 10)
+
+	c.Lock()
+	c.active--
+	c.Unlock()
+	return nil
+}
+
+func TestServeHTTPLimits(t *testing.T) {
+	h := WithResolvers(map[string]Resolver{})
+
+	testData := []struct {
+		name  string
+		query string
+	}{
+		{name: "depth", query: strings.Repeat(`{ foo `, MaxDepth+1) + strings.Repeat(`}`, MaxDepth+1)},
+		{name: "fields", query: `{ ` + strings.Repeat(`foo `, MaxFields+1) + `}`},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape(d.query), nil))
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("Expected 400 got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestServeHTTPMutation(t *testing.T) {
+	c := new(testClient)
+	h := WithResolvers(map[string]Resolver{
+		"foo": {Service: "go.micro.srv.foo", Endpoint: "Foo.Bar"},
+	}, handler.WithClient(c))
+
+	testData := []struct {
+		method string
+		code   int
+		calls  int
+	}{
+		{method: "GET", code: http.StatusMethodNotAllowed, calls: 0},
+		{method: "POST", code: http.StatusOK, calls: 1},
+	}
+
+	for _, d := range testData {
+		t.Run(d.method, func(t *testing.T) {
+			c.calls = 0
+
+			var r *http.Request
+			if d.method == "GET" {
+				r = httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape(`mutation { foo }`), nil)
+			} else {
+				r = httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"mutation { foo }"}`))
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != d.code {
+				t.Fatalf("Expected %d got %d", d.code, w.Code)
+			}
+			if c.calls != d.calls {
+				t.Fatalf("Expected %d calls got %d", d.calls, c.calls)
+			}
+		})
+	}
+}
+
+func TestServeHTTPConcurrency(t *testing.T) {
+	c := new(testClient)
+	h := WithResolvers(map[string]Resolver{
+		"foo": {Service: "go.micro.srv.foo", Endpoint: "Foo.Bar"},
+	}, handler.WithClient(c))
+
+	var fields []string
+	for i := 0; i < MaxConcurrency*3; i++ {
+		fields = append(fields, fmt.Sprintf("f%d: foo", i))
+	}
+	query := `{ ` + strings.Join(fields, " ") + ` }`
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape(query), nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 got %d", w.Code)
+	}
+	if c.calls != len(fields) {
+		t.Fatalf("Expected %d calls got %d", len(fields), c.calls)
+	}
+	if c.max > MaxConcurrency {
+		t.Fatalf("Expected at most %d concurrent calls got %d", MaxConcurrency, c.max)
+	}
+}
+
+// testRegistry counts the lookups made
+type testRegistry struct {
+	registry.Registry
+
+	lists int
+	gets  int
+	err   error
+}
+
+func (r *testRegistry) ListServices(opts ...registry.ListOption) ([]*registry.Service, error) {
+	r.lists++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.Registry.ListServices(opts...)
+}
+
+func (r *testRegistry) GetService(name string, opts ...registry.GetOption) ([]*registry.Service, error) {
+	r.gets++
+	return r.Registry.GetService(name, opts...)
+}
+
+func TestGetResolversCached(t *testing.T) {
+	reg := &testRegistry{Registry: registry.NewMemoryRegistry()}
+	reg.Register(&registry.Service{
+		Name:      "go.micro.api.greeter",
+		Version:   "latest",
+		Nodes:     []*registry.Node{{Id: "greeter-1", Address: "127.0.0.1:9090"}},
+		Endpoints: []*registry.Endpoint{{Name: "Say.Hello"}},
+	})
+
+	defer func(r registry.Registry) { registry.DefaultRegistry = r }(registry.DefaultRegistry)
+	registry.DefaultRegistry = reg
+
+	g := NewHandler(handler.WithNamespace("go.micro.api")).(*graphqlHandler)
+
+	for i := 0; i < 3; i++ {
+		resolvers, err := g.getResolvers()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := resolvers["greeter_Say_Hello"]; !ok {
+			t.Fatalf("Expected the greeter_Say_Hello field got %v", resolvers)
+		}
+	}
+
+	if reg.lists != 1 || reg.gets != 1 {
+		t.Fatalf("Expected the registry to be read once got %d lists and %d gets", reg.lists, reg.gets)
+	}
+
+	// the cached fields are served if the registry fails once they expire
+	g.updated = time.Now().Add(-ResolverTTL)
+	reg.err = fmt.Errorf("registry unavailable")
+
+	resolvers, err := g.getResolvers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := resolvers["greeter_Say_Hello"]; !ok || reg.lists != 2 {
+		t.Fatalf("Expected the cached fields after a refresh got %v", resolvers)
+	}
+}
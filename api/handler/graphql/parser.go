@@ -0,0 +1,422 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// MaxDepth is the max nesting of selections and values in a query
+	MaxDepth = 32
+	// MaxFields is the max number of fields selected in a query
+	MaxFields = 1000
+)
+
+// Operation is a parsed query or mutation
+type Operation struct {
+	Type       string
+	Name       string
+	Selections []*Field
+}
+
+// Field is a selected field with its arguments and sub selections
+type Field struct {
+	Alias      string
+	Name       string
+	Arguments  map[string]interface{}
+	Selections []*Field
+}
+
+// Key returns the name the field is returned as
+func (f *Field) Key() string {
+	if len(f.Alias) > 0 {
+		return f.Alias
+	}
+	return f.Name
+}
+
+type token struct {
+	kind  rune // 'n' name, 's' string, 'd' number, 'v' variable or punctuation
+	value string
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	vars   map[string]interface{}
+
+	// nesting and fields parsed so far
+	depth  int
+	fields int
+}
+
+// Parse parses a query document returning the named operation or the
+// only operation if name is blank. Fragments are not supported.
+func Parse(query, name string, vars map[string]interface{}) (*Operation, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, vars: vars}
+
+	var ops []*Operation
+	for !p.eof() {
+		op, err := p.operation()
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("no operation found")
+	}
+
+	if len(name) == 0 {
+		if len(ops) > 1 {
+			return nil, fmt.Errorf("operation name required")
+		}
+		return ops[0], nil
+	}
+
+	for _, op := range ops {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown operation %s", name)
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	if p.eof() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// enter descends into a selection set or value, bounding the recursion
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > MaxDepth {
+		return fmt.Errorf("query exceeds the max depth of %d", MaxDepth)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) expect(kind rune, value string) error {
+	t := p.next()
+	if t.kind != kind || (len(value) > 0 && t.value != value) {
+		return fmt.Errorf("expected %q got %q", value, t.value)
+	}
+	return nil
+}
+
+func (p *parser) operation() (*Operation, error) {
+	op := &Operation{Type: "query"}
+
+	if t := p.peek(); t.kind == 'n' {
+		switch t.value {
+		case "query", "mutation":
+			op.Type = t.value
+		case "fragment", "subscription":
+			return nil, fmt.Errorf("%s is not supported", t.value)
+		default:
+			return nil, fmt.Errorf("unexpected %q", t.value)
+		}
+		p.next()
+
+		if t := p.peek(); t.kind == 'n' {
+			op.Name = p.next().value
+		}
+
+		// variable definitions are not type checked
+		if t := p.peek(); t.kind == 'p' && t.value == "(" {
+			if err := p.skip("(", ")"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sel, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.Selections = sel
+
+	return op, nil
+}
+
+// skip skips over a balanced pair of punctuators
+func (p *parser) skip(open, close string) error {
+	depth := 0
+	for !p.eof() {
+		t := p.next()
+		if t.kind != 'p' {
+			continue
+		}
+		switch t.value {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("expected %q", close)
+}
+
+func (p *parser) selectionSet() ([]*Field, error) {
+	if err := p.expect('p', "{"); err != nil {
+		return nil, err
+	}
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	var fields []*Field
+
+	for {
+		t := p.peek()
+		if t.kind == 'p' && t.value == "}" {
+			p.next()
+			return fields, nil
+		}
+		if t.kind == 'p' && t.value == "..." {
+			return nil, fmt.Errorf("fragments are not supported")
+		}
+		if t.kind != 'n' {
+			return nil, fmt.Errorf("expected field got %q", t.value)
+		}
+
+		f, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+}
+
+func (p *parser) field() (*Field, error) {
+	p.fields++
+	if p.fields > MaxFields {
+		return nil, fmt.Errorf("query exceeds the max of %d fields", MaxFields)
+	}
+
+	f := &Field{Name: p.next().value}
+
+	// alias: name
+	if t := p.peek(); t.kind == 'p' && t.value == ":" {
+		p.next()
+		t := p.next()
+		if t.kind != 'n' {
+			return nil, fmt.Errorf("expected field name got %q", t.value)
+		}
+		f.Alias = f.Name
+		f.Name = t.value
+	}
+
+	if t := p.peek(); t.kind == 'p' && t.value == "(" {
+		args, err := p.arguments()
+		if err != nil {
+			return nil, err
+		}
+		f.Arguments = args
+	}
+
+	if t := p.peek(); t.kind == 'p' && t.value == "{" {
+		sel, err := p.selectionSet()
+		if err != nil {
+			return nil, err
+		}
+		f.Selections = sel
+	}
+
+	return f, nil
+}
+
+func (p *parser) arguments() (map[string]interface{}, error) {
+	p.next()
+
+	args := make(map[string]interface{})
+
+	for {
+		t := p.next()
+		if t.kind == 'p' && t.value == ")" {
+			return args, nil
+		}
+		if t.kind != 'n' {
+			return nil, fmt.Errorf("expected argument got %q", t.value)
+		}
+		if err := p.expect('p', ":"); err != nil {
+			return nil, err
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		args[t.value] = v
+	}
+}
+
+func (p *parser) value() (interface{}, error) {
+	t := p.next()
+
+	switch t.kind {
+	case 's':
+		return t.value, nil
+	case 'd':
+		if strings.ContainsAny(t.value, ".eE") {
+			return strconv.ParseFloat(t.value, 64)
+		}
+		return strconv.ParseInt(t.value, 10, 64)
+	case 'v':
+		return p.vars[t.value], nil
+	case 'n':
+		switch t.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		// enum values are passed as strings
+		return t.value, nil
+	case 'p':
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+
+		switch t.value {
+		case "[":
+			var list []interface{}
+			for {
+				if n := p.peek(); n.kind == 'p' && n.value == "]" {
+					p.next()
+					return list, nil
+				}
+				if p.eof() {
+					return nil, fmt.Errorf("expected \"]\"")
+				}
+				v, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			}
+		case "{":
+			obj := make(map[string]interface{})
+			for {
+				n := p.next()
+				if n.kind == 'p' && n.value == "}" {
+					return obj, nil
+				}
+				if n.kind != 'n' {
+					return nil, fmt.Errorf("expected field got %q", n.value)
+				}
+				if err := p.expect('p', ":"); err != nil {
+					return nil, err
+				}
+				v, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				obj[n.value] = v
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected %q", t.value)
+}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+
+		switch {
+		case c == '#':
+			// comment until the end of the line
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case unicode.IsSpace(c) || c == ',':
+			i++
+		case strings.HasPrefix(s[i:], "..."):
+			tokens = append(tokens, token{'p', "..."})
+			i += 3
+		case strings.ContainsRune("{}()[]:=!@", c):
+			tokens = append(tokens, token{'p', string(c)})
+			i++
+		case c == '$':
+			j := i + 1
+			for j < len(s) && isNameChar(rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, token{'v', s[i+1 : j]})
+			i = j
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			v, err := strconv.Unquote(s[i : j+1])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{'s', v})
+			i = j + 1
+		case c == '-' || unicode.IsDigit(c):
+			j := i + 1
+			for j < len(s) && strings.ContainsRune("0123456789.eE+-", rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, token{'d', s[i:j]})
+			i = j
+		case isNameChar(c):
+			j := i
+			for j < len(s) && isNameChar(rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, token{'n', s[i:j]})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+
+	return tokens, nil
+}
+
+func isNameChar(c rune) bool {
+	return c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
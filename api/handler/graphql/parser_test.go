@@ -0,0 +1,100 @@
+package graphql
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	query := `
+		# fetch a user
+		query GetUser($id: String!) {
+			user: users_Users_Read(id: $id, limit: 10, tags: ["a", "b"]) {
+				name
+				address { city }
+			}
+		}
+	`
+
+	op, err := Parse(query, "", map[string]interface{}{"id": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if op.Type != "query" || op.Name != "GetUser" {
+		t.Fatalf("Unexpected operation %s %s", op.Type, op.Name)
+	}
+
+	if len(op.Selections) != 1 {
+		t.Fatalf("Expected 1 selection got %d", len(op.Selections))
+	}
+
+	f := op.Selections[0]
+	if f.Key() != "user" || f.Name != "users_Users_Read" {
+		t.Fatalf("Unexpected field %s %s", f.Key(), f.Name)
+	}
+
+	b, _ := json.Marshal(f.Arguments)
+	if string(b) != `{"id":"1","limit":10,"tags":["a","b"]}` {
+		t.Fatalf("Unexpected arguments %s", string(b))
+	}
+
+	if len(f.Selections) != 2 || f.Selections[1].Selections[0].Name != "city" {
+		t.Fatalf("Unexpected selections %+v", f.Selections)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testData := []string{
+		``,
+		`{ foo `,
+		`{ ...Foo }`,
+		`subscription { foo }`,
+		`{ foo(a: ) }`,
+		strings.Repeat(`{ foo `, MaxDepth+1) + strings.Repeat(`}`, MaxDepth+1),
+		`{ foo(a: ` + strings.Repeat(`[`, MaxDepth+1) + strings.Repeat(`]`, MaxDepth+1) + `) }`,
+		`{ ` + strings.Repeat(`foo `, MaxFields+1) + `}`,
+	}
+
+	for _, d := range testData {
+		if _, err := Parse(d, "", nil); err == nil {
+			t.Fatalf("Expected error parsing %q", d)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	var v interface{}
+	json.Unmarshal([]byte(`{"name":"john","password":"x","friends":[{"name":"bob","age":1}]}`), &v)
+
+	op, err := Parse(`{ user { name friends { name } } }`, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := json.Marshal(Select(v, op.Selections[0].Selections))
+	if string(b) != `{"friends":[{"name":"bob"}],"name":"john"}` {
+		t.Fatalf("Unexpected selection %s", string(b))
+	}
+}
+
+func TestFieldName(t *testing.T) {
+	if v := FieldName("go.micro.api", "go.micro.api.greeter", "Say.Hello"); v != "greeter_Say_Hello" {
+		t.Fatalf("Expected greeter_Say_Hello got %s", v)
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	// queries at the limits are parsed
+	testData := []string{
+		strings.Repeat(`{ foo `, MaxDepth) + strings.Repeat(`}`, MaxDepth),
+		`{ ` + strings.Repeat(`foo `, MaxFields) + `}`,
+	}
+
+	for _, d := range testData {
+		if _, err := Parse(d, "", nil); err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+// Package grpcweb is a gRPC-Web handler which translates browser requests to rpc calls
+package grpcweb
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/asim/go-micro/v3/api"
+	"github.com/asim/go-micro/v3/api/handler"
+	"github.com/asim/go-micro/v3/api/internal/proto"
+	"github.com/asim/go-micro/v3/api/server/access"
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/selector"
+	"github.com/asim/go-micro/v3/util/ctx"
+)
+
+const (
+	Handler = "grpcweb"
+
+	// frame flags as defined by the gRPC-Web protocol
+	dataFrame    byte = 0x00
+	trailerFrame byte = 0x80
+)
+
+// errTooLarge is returned for messages larger than the max recv size
+var errTooLarge = errors.New("go.micro.api", "message too large", 413)
+
+type grpcwebHandler struct {
+	opts handler.Options
+	s    *api.Service
+}
+
+// frameWriter writes length prefixed frames, base64 encoding them in text mode
+type frameWriter struct {
+	w    io.Writer
+	text bool
+}
+
+func (f *frameWriter) write(flag byte, b []byte) error {
+	buf := encodeFrame(flag, b)
+
+	if f.text {
+		buf = []byte(base64.StdEncoding.EncodeToString(buf))
+	}
+
+	if _, err := f.w.Write(buf); err != nil {
+		return err
+	}
+
+	if fl, ok := f.w.(http.Flusher); ok {
+		fl.Flush()
+	}
+
+	return nil
+}
+
+// strategy is a hack for selection
+func strategy(services []*registry.Service) selector.Strategy {
+	return func(_ []*registry.Service) selector.Next {
+		// ignore input to this function, use services above
+		return selector.Random(services)
+	}
+}
+
+func (h *grpcwebHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	service, err := h.getService(r)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	access.SetRoute(r.Context(), service.Name, access.Endpoint(service))
+
+	// browsers without streaming fetch fall back to websockets
+	if isWebSocket(r) {
+		h.serveWebSocket(w, r, service)
+		return
+	}
+
+	ct := r.Header.Get("Content-Type")
+	if !isGrpcWeb(ct) {
+		http.Error(w, "unsupported content type "+ct, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	bsize := h.maxRecvSize()
+
+	r.Body = http.MaxBytesReader(w, r.Body, bsize)
+	defer r.Body.Close()
+
+	var body io.Reader = r.Body
+	if isText(ct) {
+		body = base64.NewDecoder(base64.StdEncoding, r.Body)
+	}
+
+	// a unary or server streaming request carries a single message
+	_, req, err := readFrame(body, bsize)
+	if err == errTooLarge {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	} else if err != nil && err != io.EOF {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	w.Header().Set("Content-Type", ct)
+	w.Header().Set("Access-Control-Expose-Headers", "grpc-status, grpc-message")
+	w.WriteHeader(200)
+
+	fw := &frameWriter{w: w, text: isText(ct)}
+	err = h.call(requestContext(r), service, ct, req, func(b []byte) error {
+		return fw.write(dataFrame, b)
+	})
+
+	if werr := fw.write(trailerFrame, trailers(err)); werr != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error(werr)
+		}
+	}
+}
+
+// call makes the backend request and invokes fn for every response message
+func (h *grpcwebHandler) call(cx context.Context, service *api.Service, ct string, req []byte, fn func([]byte) error) error {
+	c := h.opts.Client
+	so := selector.WithStrategy(strategy(service.Services))
+	ct = backendContentType(ct)
+
+	var request interface{}
+	if ct == "application/json" {
+		m := json.RawMessage(req)
+		if len(req) == 0 {
+			m = json.RawMessage(`{}`)
+		}
+		request = &m
+	} else {
+		request = proto.NewMessage(req)
+	}
+
+	// unary requests
+	if !isStream(service) {
+		rsp, err := h.unary(cx, service, ct, request, so)
+		if err != nil {
+			return err
+		}
+		return fn(rsp)
+	}
+
+	creq := c.NewRequest(
+		service.Name,
+		service.Endpoint.Name,
+		request,
+		client.WithContentType(ct),
+		client.StreamingRequest(),
+	)
+
+	stream, err := c.Stream(cx, creq, client.WithSelectOption(so))
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	if err := stream.Send(request); err != nil {
+		return err
+	}
+
+	for {
+		var rsp []byte
+
+		if ct == "application/json" {
+			var m json.RawMessage
+			err = stream.Recv(&m)
+			rsp = m
+		} else {
+			m := &proto.Message{}
+			if err = stream.Recv(m); err == nil {
+				rsp, err = m.Marshal()
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err := fn(rsp); err != nil {
+			return err
+		}
+	}
+}
+
+func (h *grpcwebHandler) unary(cx context.Context, service *api.Service, ct string, request interface{}, so selector.SelectOption) ([]byte, error) {
+	c := h.opts.Client
+
+	req := c.NewRequest(
+		service.Name,
+		service.Endpoint.Name,
+		request,
+		client.WithContentType(ct),
+	)
+
+	if ct == "application/json" {
+		var response json.RawMessage
+		if err := c.Call(cx, req, &response, client.WithSelectOption(so)); err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+
+	response := &proto.Message{}
+	if err := c.Call(cx, req, response, client.WithSelectOption(so)); err != nil {
+		return nil, err
+	}
+	return response.Marshal()
+}
+
+// maxRecvSize returns the max size of a request message
+func (h *grpcwebHandler) maxRecvSize() int64 {
+	if h.opts.MaxRecvSize > 0 {
+		return h.opts.MaxRecvSize
+	}
+	return handler.DefaultMaxRecvSize
+}
+
+// getService returns the service for this request from the router
+func (h *grpcwebHandler) getService(r *http.Request) (*api.Service, error) {
+	if h.s != nil {
+		// we were given the service
+		return h.s, nil
+	} else if h.opts.Router != nil {
+		// try get service from router
+		return h.opts.Router.Route(r)
+	}
+	// we have no way of routing the request
+	return nil, errors.InternalServerError("go.micro.api", "no route found")
+}
+
+func (h *grpcwebHandler) String() string {
+	return "grpcweb"
+}
+
+// encodeFrame prefixes b with the frame flag and length
+func encodeFrame(flag byte, b []byte) []byte {
+	buf := make([]byte, 5+len(b))
+	buf[0] = flag
+	binary.BigEndian.PutUint32(buf[1:5], uint32(len(b)))
+	copy(buf[5:], b)
+	return buf
+}
+
+// readFrame reads a single length prefixed frame, frames larger than max are
+// rejected before the length sent by the client is allocated
+func readFrame(r io.Reader, max int64) (byte, []byte, error) {
+	hdr := make([]byte, 5)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return 0, nil, err
+	}
+
+	size := binary.BigEndian.Uint32(hdr[1:])
+	if int64(size) > max {
+		return 0, nil, errTooLarge
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return 0, nil, err
+	}
+
+	return hdr[0], buf, nil
+}
+
+// trailers encodes the grpc status of err as a trailer frame body
+func trailers(err error) []byte {
+	var status uint32
+	var message string
+
+	if err != nil {
+		ce := errors.Parse(err.Error())
+		status = errors.GRPCCode(ce.Code)
+		message = ce.Detail
+		if len(message) == 0 {
+			message = err.Error()
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "grpc-status: %d\r\n", status)
+	fmt.Fprintf(buf, "grpc-message: %s\r\n", encodeMessage(message))
+	return buf.Bytes()
+}
+
+// encodeMessage percent encodes the grpc-message as the grpc spec requires,
+// bytes outside printable ascii and % are encoded as %XX like grpc-go
+func encodeMessage(msg string) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			buf.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&buf, "%%%02X", c)
+	}
+	return buf.String()
+}
+
+// requestContext creates a context with the request headers as metadata
+func requestContext(r *http.Request) context.Context {
+	cx := ctx.FromRequest(r)
+
+	md, ok := metadata.FromContext(r.Context())
+	if !ok {
+		md = make(metadata.Metadata)
+	}
+
+	for k := range r.Header {
+		md[textproto.CanonicalMIMEHeaderKey(k)] = r.Header.Get(k)
+	}
+
+	return metadata.MergeContext(cx, md, true)
+}
+
+// backendContentType returns the content type used to call the backend
+func backendContentType(ct string) string {
+	if strings.Contains(ct, "json") {
+		return "application/json"
+	}
+	return "application/protobuf"
+}
+
+func isGrpcWeb(ct string) bool {
+	return strings.HasPrefix(ct, "application/grpc-web")
+}
+
+func isText(ct string) bool {
+	return strings.HasPrefix(ct, "application/grpc-web-text")
+}
+
+func isStream(srv *api.Service) bool {
+	if srv.Endpoint.Stream {
+		return true
+	}
+	// check if the endpoint supports streaming
+	for _, service := range srv.Services {
+		for _, ep := range service.Endpoints {
+			// skip if it doesn't match the name
+			if ep.Name != srv.Endpoint.Name {
+				continue
+			}
+			// matched if the name
+			if v := ep.Metadata["stream"]; v == "true" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func NewHandler(opts ...handler.Option) handler.Handler {
+	return &grpcwebHandler{
+		opts: handler.NewOptions(opts...),
+	}
+}
+
+func WithService(s *api.Service, opts ...handler.Option) handler.Handler {
+	return &grpcwebHandler{
+		opts: handler.NewOptions(opts...),
+		s:    s,
+	}
+}
@@ -0,0 +1,136 @@
+package grpcweb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/asim/go-micro/v3/api"
+	"github.com/asim/go-micro/v3/api/handler"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+)
+
+func TestFrame(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	fw := &frameWriter{w: buf}
+
+	if err := fw.write(dataFrame, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	flag, b, err := readFrame(buf, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flag != dataFrame {
+		t.Fatalf("Expected flag %x got %x", dataFrame, flag)
+	}
+	if string(b) != "hello" {
+		t.Fatalf("Expected hello got %s", string(b))
+	}
+}
+
+func TestTextFrame(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	fw := &frameWriter{w: buf, text: true}
+
+	if err := fw.write(trailerFrame, trailers(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	flag, b, err := readFrame(base64.NewDecoder(base64.StdEncoding, buf), 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flag != trailerFrame {
+		t.Fatalf("Expected flag %x got %x", trailerFrame, flag)
+	}
+	if string(b) != "grpc-status: 0\r\ngrpc-message: \r\n" {
+		t.Fatalf("Unexpected trailers %q", string(b))
+	}
+}
+
+func TestTrailers(t *testing.T) {
+	testData := []struct {
+		err    error
+		expect string
+	}{
+		{nil, "grpc-status: 0\r\ngrpc-message: \r\n"},
+		{errors.NotFound("go.micro.api", "not found"), "grpc-status: 5\r\ngrpc-message: not found\r\n"},
+		{errors.Unauthorized("go.micro.api", "denied"), "grpc-status: 16\r\ngrpc-message: denied\r\n"},
+		{errors.InternalServerError("go.micro.api", "%s", "100% failed\nretry"), "grpc-status: 13\r\ngrpc-message: 100%25 failed%0Aretry\r\n"},
+		{errors.BadRequest("go.micro.api", "café"), "grpc-status: 3\r\ngrpc-message: caf%C3%A9\r\n"},
+	}
+
+	for _, d := range testData {
+		if v := string(trailers(d.err)); v != d.expect {
+			t.Fatalf("Expected %q got %q", d.expect, v)
+		}
+	}
+}
+
+func TestFrameTooLarge(t *testing.T) {
+	// the length is checked before it's allocated
+	buf := bytes.NewBuffer([]byte{dataFrame, 0xff, 0xff, 0xff, 0xff})
+
+	if _, _, err := readFrame(buf, 1024); err != errTooLarge {
+		t.Fatalf("Expected errTooLarge got %v", err)
+	}
+}
+
+func TestMaxRecvSize(t *testing.T) {
+	h := WithService(&api.Service{
+		Name:     "foo",
+		Endpoint: &api.Endpoint{Name: "Foo.Bar"},
+	}, handler.WithMaxRecvSize(10))
+
+	r := httptest.NewRequest("POST", "/foo/bar", bytes.NewReader(encodeFrame(dataFrame, make([]byte, 100))))
+	r.Header.Set("Content-Type", "application/grpc-web+proto")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected 413 got %d", w.Code)
+	}
+}
+
+func TestWebSocketMessageTooLarge(t *testing.T) {
+	testData := []struct {
+		name     string
+		messages [][]byte
+	}{
+		{
+			name:     "message",
+			messages: [][]byte{append([]byte{wsData}, make([]byte, 100)...)},
+		},
+		{
+			name: "frames",
+			messages: [][]byte{
+				append([]byte{wsData}, encodeFrame(dataFrame, make([]byte, 100))[:10]...),
+				append([]byte{wsData}, make([]byte, 10)...),
+			},
+		},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			for _, m := range d.messages {
+				if err := wsutil.WriteClientMessage(buf, ws.OpBinary, m); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			rw := bufio.NewReadWriter(bufio.NewReader(buf), bufio.NewWriter(bytes.NewBuffer(nil)))
+			if _, err := readWebSocketMessage(rw, 16); err != errTooLarge {
+				t.Fatalf("Expected errTooLarge got %v", err)
+			}
+		})
+	}
+}
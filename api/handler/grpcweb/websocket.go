@@ -0,0 +1,217 @@
+package grpcweb
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/asim/go-micro/v3/api"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+)
+
+const (
+	// websocket sub protocol used by grpc-web clients
+	wsProtocol = "grpc-websockets"
+
+	// message prefixes sent by the client
+	wsData   byte = 0x00
+	wsFinish byte = 0x01
+)
+
+// wsWriter writes each call to Write as a single binary message
+type wsWriter struct {
+	rw *bufio.ReadWriter
+}
+
+func (w *wsWriter) Write(b []byte) (int, error) {
+	if err := wsutil.WriteServerMessage(w.rw, ws.OpBinary, b); err != nil {
+		return 0, err
+	}
+	if err := w.rw.Flush(); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// serveWebSocket serves the grpc-websockets transport used as a browser fallback
+func (h *grpcwebHandler) serveWebSocket(w http.ResponseWriter, r *http.Request, service *api.Service) {
+	upgrader := ws.HTTPUpgrader{
+		Timeout: 5 * time.Second,
+		Protocol: func(proto string) bool {
+			return proto == wsProtocol
+		},
+	}
+
+	conn, rw, _, err := upgrader.Upgrade(r, w)
+	if err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error(err)
+		}
+		return
+	}
+
+	defer func() {
+		if err := conn.Close(); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Error(err)
+			}
+		}
+	}()
+
+	max := h.maxRecvSize()
+
+	// the first message carries the request headers
+	buf, _, err := readClientData(rw, max)
+	if err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error(err)
+		}
+		return
+	}
+
+	req := r.Clone(r.Context())
+	for _, line := range strings.Split(string(buf), "\r\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		req.Header.Set(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+
+	ct := req.Header.Get("Content-Type")
+	if !isGrpcWeb(ct) {
+		ct = "application/grpc-web+proto"
+	}
+
+	msg, err := readWebSocketMessage(rw, max)
+	if err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error(err)
+		}
+		return
+	}
+
+	fw := &frameWriter{w: &wsWriter{rw}}
+
+	// response headers are sent as the first frame
+	if err := fw.write(trailerFrame, []byte("content-type: "+ct+"\r\n")); err != nil {
+		return
+	}
+
+	err = h.call(requestContext(req), service, ct, msg, func(b []byte) error {
+		return fw.write(dataFrame, b)
+	})
+
+	if werr := fw.write(trailerFrame, trailers(err)); werr != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Error(werr)
+		}
+	}
+}
+
+// readClientData reads the next data message of the client, messages larger
+// than max are rejected rather than read into memory
+func readClientData(rw io.ReadWriter, max int64) ([]byte, ws.OpCode, error) {
+	control := wsutil.ControlFrameHandler(rw, ws.StateServerSide)
+	rd := &wsutil.Reader{
+		Source:         rw,
+		State:          ws.StateServerSide,
+		CheckUTF8:      true,
+		OnIntermediate: control,
+	}
+
+	for {
+		hdr, err := rd.NextFrame()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if hdr.OpCode.IsControl() {
+			if err := control(hdr, rd); err != nil {
+				return nil, 0, err
+			}
+			continue
+		}
+
+		buf, err := ioutil.ReadAll(io.LimitReader(rd, max+1))
+		if err != nil {
+			return nil, 0, err
+		}
+		if int64(len(buf)) > max {
+			return nil, 0, errTooLarge
+		}
+
+		return buf, hdr.OpCode, nil
+	}
+}
+
+// readWebSocketMessage reads client frames until a full message has been received
+func readWebSocketMessage(rw *bufio.ReadWriter, max int64) ([]byte, error) {
+	var data []byte
+
+	for {
+		buf, op, err := readClientData(rw, max)
+		if err != nil {
+			return nil, err
+		}
+
+		if op != ws.OpBinary || len(buf) == 0 {
+			continue
+		}
+
+		switch buf[0] {
+		case wsData:
+			// the message and its frame header
+			if int64(len(data)+len(buf)-1) > max+5 {
+				return nil, errTooLarge
+			}
+			data = append(data, buf[1:]...)
+		case wsFinish:
+			if len(data) == 0 {
+				return nil, nil
+			}
+		}
+
+		// wait until we have the complete frame
+		if len(data) < 5 {
+			if buf[0] == wsFinish {
+				return nil, errors.New("incomplete frame")
+			}
+			continue
+		}
+
+		_, msg, err := readFrame(bytes.NewReader(data), max)
+		if err == nil {
+			return msg, nil
+		}
+
+		if err == errTooLarge || buf[0] == wsFinish {
+			return nil, err
+		}
+	}
+}
+
+func isWebSocket(r *http.Request) bool {
+	contains := func(key, val string) bool {
+		vv := strings.Split(r.Header.Get(key), ",")
+		for _, v := range vv {
+			if val == strings.ToLower(strings.TrimSpace(v)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if contains("Connection", "upgrade") && contains("Upgrade", "websocket") {
+		return true
+	}
+
+	return false
+}
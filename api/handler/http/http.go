@@ -7,9 +7,12 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strconv"
 
 	"github.com/asim/go-micro/v3/api"
 	"github.com/asim/go-micro/v3/api/handler"
+	"github.com/asim/go-micro/v3/api/server/access"
+	"github.com/asim/go-micro/v3/registry"
 	"github.com/asim/go-micro/v3/selector"
 )
 
@@ -31,49 +34,101 @@ func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(service) == 0 {
+	if service == nil {
 		w.WriteHeader(404)
 		return
 	}
 
-	rp, err := url.Parse(service)
+	// the endpoint is the url so isn't recorded
+	access.SetRoute(r.Context(), service.Name, "")
+
+	b := h.options.Breaker
+
+	// fail fast if the service is unhealthy
+	if b != nil {
+		if wait, ok := b.Allow(service.Name); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			w.WriteHeader(503)
+			return
+		}
+	}
+
+	node, err := h.getNode(service)
 	if err != nil {
 		w.WriteHeader(500)
 		return
 	}
 
-	httputil.NewSingleHostReverseProxy(rp).ServeHTTP(w, r)
-}
+	if node == nil {
+		w.WriteHeader(404)
+		return
+	}
 
-// getService returns the service for this request from the selector
-func (h *httpHandler) getService(r *http.Request) (string, error) {
-	var service *api.Service
+	rp, err := url.Parse(fmt.Sprintf("http://%s", node.Address))
+	if err != nil {
+		w.WriteHeader(500)
+		return
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(rp)
 
+	if b != nil {
+		mark := func(err error) {
+			b.Mark(service.Name, err)
+			b.Mark(node.Id, err)
+		}
+
+		proxy.ModifyResponse = func(rsp *http.Response) error {
+			var err error
+			if rsp.StatusCode >= 500 {
+				err = errors.New(rsp.Status)
+			}
+			mark(err)
+			return nil
+		}
+
+		proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+			mark(err)
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}
+
+	proxy.ServeHTTP(w, r)
+}
+
+// getService returns the service for this request from the router
+func (h *httpHandler) getService(r *http.Request) (*api.Service, error) {
 	if h.s != nil {
 		// we were given the service
-		service = h.s
+		return h.s, nil
 	} else if h.options.Router != nil {
 		// try get service from router
-		s, err := h.options.Router.Route(r)
-		if err != nil {
-			return "", err
-		}
-		service = s
-	} else {
-		// we have no way of routing the request
-		return "", errors.New("no route found")
+		return h.options.Router.Route(r)
+	}
+
+	// we have no way of routing the request
+	return nil, errors.New("no route found")
+}
+
+// getNode returns a node for the service from the selector
+func (h *httpHandler) getNode(service *api.Service) (*registry.Node, error) {
+	services := service.Services
+
+	// eject unhealthy nodes
+	if h.options.Breaker != nil {
+		services = h.options.Breaker.Filter()(services)
 	}
 
 	// create a random selector
-	next := selector.Random(service.Services)
+	next := selector.Random(services)
 
 	// get the next node
-	s, err := next()
+	node, err := next()
 	if err != nil {
-		return "", nil
+		return nil, nil
 	}
 
-	return fmt.Sprintf("http://%s", s.Address), nil
+	return node, nil
 }
 
 func (h *httpHandler) String() string {
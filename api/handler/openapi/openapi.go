@@ -0,0 +1,384 @@
+// Package openapi provides a handler which serves an OpenAPI spec generated from the registry
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/asim/go-micro/v3/api"
+	"github.com/asim/go-micro/v3/api/handler"
+	"github.com/asim/go-micro/v3/api/router/util"
+	"github.com/asim/go-micro/v3/registry"
+)
+
+const (
+	Handler = "openapi"
+
+	// Version of the OpenAPI specification generated
+	Version = "3.0.3"
+)
+
+var (
+	// SwaggerUI is the template used to render the ui, %s is replaced with the spec url
+	SwaggerUI = `<!DOCTYPE html>
+<html>
+<head>
+  <title>API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@3/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@3/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = function() {
+      SwaggerUIBundle({url: "%s", dom_id: "#swagger-ui"});
+    };
+  </script>
+</body>
+</html>
+`
+)
+
+// Spec is an OpenAPI document
+type Spec struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Operation struct {
+	OperationId string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Content map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type Schema struct {
+	Ref        string             `json:"$ref,omitempty"`
+	Type       string             `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Pattern    string             `json:"pattern,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+}
+
+type openapiHandler struct {
+	opts handler.Options
+}
+
+func (o *openapiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// serve the ui unless the spec is requested
+	if !strings.HasSuffix(r.URL.Path, ".json") {
+		// the spec is requested relative to the ui so the url can't
+		// point elsewhere, the path is escaped for the script
+		url := "openapi.json"
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			url = path.Base(r.URL.Path) + "/openapi.json"
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, SwaggerUI, template.JSEscapeString(url))
+		return
+	}
+
+	spec, err := o.spec()
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	b, err := json.Marshal(spec)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+func (o *openapiHandler) registry() registry.Registry {
+	if o.opts.Router != nil {
+		if r := o.opts.Router.Options().Registry; r != nil {
+			return r
+		}
+	}
+	return registry.DefaultRegistry
+}
+
+// spec generates the OpenAPI document from the services in the registry
+func (o *openapiHandler) spec() (*Spec, error) {
+	reg := o.registry()
+
+	list, err := reg.ListServices()
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &Spec{
+		OpenAPI: Version,
+		Info: Info{
+			Title:   o.opts.Namespace,
+			Version: "latest",
+		},
+		Paths: make(map[string]map[string]Operation),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+		},
+	}
+
+	// sort for a stable document
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	for _, s := range list {
+		services, err := reg.GetService(s.Name)
+		if err != nil {
+			continue
+		}
+
+		for _, srv := range services {
+			for _, ep := range srv.Endpoints {
+				Generate(spec, o.opts.Namespace, srv.Name, ep)
+			}
+		}
+	}
+
+	return spec, nil
+}
+
+func (o *openapiHandler) String() string {
+	return "openapi"
+}
+
+// Generate adds a registry endpoint to the spec
+func Generate(spec *Spec, namespace, service string, ep *registry.Endpoint) {
+	paths := []string{defaultPath(namespace, service, ep.Name)}
+	methods := []string{"POST"}
+
+	// use the api endpoint metadata if it exists
+	if e := api.Decode(ep.Metadata); e != nil && len(e.Path) > 0 {
+		paths = nil
+		for _, p := range e.Path {
+			// regex paths can't be described
+			if strings.HasPrefix(p, "^") {
+				continue
+			}
+			paths = append(paths, p)
+		}
+		if len(e.Method) > 0 {
+			methods = e.Method
+		}
+	}
+
+	op := Operation{
+		OperationId: service + "." + ep.Name,
+		Summary:     ep.Metadata["description"],
+		Tags:        []string{service},
+		Responses: map[string]Response{
+			"200": {Description: "Success"},
+			"default": {
+				Description: "Error",
+				Content: map[string]MediaType{
+					"application/json": {Schema: errorSchema},
+				},
+			},
+		},
+	}
+
+	if ep.Request != nil {
+		op.RequestBody = &RequestBody{
+			Content: map[string]MediaType{
+				"application/json": {Schema: schemaRef(spec, service, ep.Request)},
+			},
+		}
+	}
+
+	if ep.Response != nil {
+		op.Responses["200"] = Response{
+			Description: "Success",
+			Content: map[string]MediaType{
+				"application/json": {Schema: schemaRef(spec, service, ep.Response)},
+			},
+		}
+	}
+
+	for _, p := range paths {
+		p, params, ok := pathParams(p)
+		// catch-alls match many segments which can't be described
+		if !ok {
+			continue
+		}
+
+		pop := op
+		pop.Parameters = params
+
+		ops, ok := spec.Paths[p]
+		if !ok {
+			ops = make(map[string]Operation)
+			spec.Paths[p] = ops
+		}
+		for _, m := range methods {
+			ops[strings.ToLower(m)] = pop
+		}
+	}
+}
+
+// pathParams strips the types of the parameters of a route e.g /users/{id:int}
+// becomes /users/{id}, returning the parameters. It returns false for routes
+// with catch-all segments e.g /files/{path...}
+func pathParams(p string) (string, []Parameter, bool) {
+	parts := strings.Split(p, "/")
+	var params []Parameter
+
+	for i, part := range parts {
+		if part == "**" || strings.HasSuffix(part, "...}") {
+			return "", nil, false
+		}
+		if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+			continue
+		}
+
+		name, expr := part[1:len(part)-1], ""
+		if idx := strings.Index(name, ":"); idx >= 0 {
+			name, expr = name[:idx], name[idx+1:]
+		}
+		// http rule variables e.g {name=messages/*} are left as is
+		if strings.Contains(name, "=") {
+			continue
+		}
+
+		parts[i] = "{" + name + "}"
+		params = append(params, Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   paramSchema(expr),
+		})
+	}
+
+	return strings.Join(parts, "/"), params, true
+}
+
+// paramSchema returns the schema of a path parameter of the type or regexp
+func paramSchema(expr string) *Schema {
+	switch expr {
+	case "":
+		return &Schema{Type: "string"}
+	case "int", "uint":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "float":
+		return &Schema{Type: "number", Format: "double"}
+	case "bool":
+		return &Schema{Type: "boolean"}
+	case "uuid":
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+
+	if v, ok := util.Types[expr]; ok {
+		expr = v
+	}
+	return &Schema{Type: "string", Pattern: "^(?:" + expr + ")$"}
+}
+
+var errorSchema = &Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"id":     {Type: "string"},
+		"code":   {Type: "integer", Format: "int32"},
+		"detail": {Type: "string"},
+		"status": {Type: "string"},
+	},
+}
+
+// defaultPath returns the path the rpc resolver maps to the endpoint
+func defaultPath(namespace, service, endpoint string) string {
+	name := strings.TrimPrefix(service, namespace+".")
+	return "/" + strings.Replace(name, ".", "/", -1) + "/" + strings.Replace(endpoint, ".", "/", -1)
+}
+
+// schemaRef registers a component schema for the value and returns a reference to it,
+// the schema is named by the service since types of different services share names
+func schemaRef(spec *Spec, service string, v *registry.Value) *Schema {
+	if len(v.Values) == 0 || len(v.Type) == 0 {
+		return Value(v)
+	}
+	name := service + "." + v.Type
+	if _, ok := spec.Components.Schemas[name]; !ok {
+		spec.Components.Schemas[name] = Value(v)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// Value converts a registry value to a schema
+func Value(v *registry.Value) *Schema {
+	if strings.HasPrefix(v.Type, "[]") {
+		elem := &registry.Value{Type: strings.TrimPrefix(v.Type, "[]"), Values: v.Values}
+		return &Schema{Type: "array", Items: Value(elem)}
+	}
+
+	switch v.Type {
+	case "string":
+		return &Schema{Type: "string"}
+	case "bool":
+		return &Schema{Type: "boolean"}
+	case "int", "int32", "uint32", "int16", "uint16", "int8", "uint8":
+		return &Schema{Type: "integer", Format: "int32"}
+	case "int64", "uint64", "uint":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "float32":
+		return &Schema{Type: "number", Format: "float"}
+	case "float64":
+		return &Schema{Type: "number", Format: "double"}
+	}
+
+	schema := &Schema{Type: "object"}
+	for _, field := range v.Values {
+		if schema.Properties == nil {
+			schema.Properties = make(map[string]*Schema)
+		}
+		schema.Properties[field.Name] = Value(field)
+	}
+	return schema
+}
+
+func NewHandler(opts ...handler.Option) handler.Handler {
+	return &openapiHandler{
+		opts: handler.NewOptions(opts...),
+	}
+}
@@ -0,0 +1,188 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/asim/go-micro/v3/api"
+	"github.com/asim/go-micro/v3/api/handler"
+	"github.com/asim/go-micro/v3/api/router"
+	regRouter "github.com/asim/go-micro/v3/api/router/registry"
+	"github.com/asim/go-micro/v3/registry"
+)
+
+func TestValue(t *testing.T) {
+	v := &registry.Value{
+		Name: "Request",
+		Type: "Request",
+		Values: []*registry.Value{
+			{Name: "name", Type: "string"},
+			{Name: "count", Type: "int64"},
+			{Name: "tags", Type: "[]string"},
+		},
+	}
+
+	s := Value(v)
+	if s.Type != "object" {
+		t.Fatalf("Expected object got %s", s.Type)
+	}
+	if p := s.Properties["name"]; p == nil || p.Type != "string" {
+		t.Fatalf("Unexpected name property %+v", p)
+	}
+	if p := s.Properties["count"]; p == nil || p.Type != "integer" || p.Format != "int64" {
+		t.Fatalf("Unexpected count property %+v", p)
+	}
+	if p := s.Properties["tags"]; p == nil || p.Type != "array" || p.Items.Type != "string" {
+		t.Fatalf("Unexpected tags property %+v", p)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+
+	err := reg.Register(&registry.Service{
+		Name:    "go.micro.api.greeter",
+		Version: "latest",
+		Endpoints: []*registry.Endpoint{
+			{
+				Name: "Say.Hello",
+				Request: &registry.Value{
+					Name:   "Request",
+					Type:   "Request",
+					Values: []*registry.Value{{Name: "name", Type: "string"}},
+				},
+			},
+			{
+				Name: "Say.Bye",
+				Metadata: api.Encode(&api.Endpoint{
+					Name:   "Say.Bye",
+					Path:   []string{"/bye"},
+					Method: []string{"GET"},
+				}),
+			},
+		},
+		Nodes: []*registry.Node{{Id: "greeter-1", Address: "localhost:9999"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rt := regRouter.NewRouter(router.WithRegistry(reg))
+	defer rt.Close()
+
+	h := NewHandler(handler.WithRouter(rt))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/docs/openapi.json", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 got %d", w.Code)
+	}
+
+	var spec Spec
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := spec.Paths["/greeter/Say/Hello"]["post"]; !ok {
+		t.Fatalf("Expected /greeter/Say/Hello in paths %+v", spec.Paths)
+	}
+	if _, ok := spec.Paths["/bye"]["get"]; !ok {
+		t.Fatalf("Expected /bye in paths %+v", spec.Paths)
+	}
+	if _, ok := spec.Components.Schemas["go.micro.api.greeter.Request"]; !ok {
+		t.Fatalf("Expected go.micro.api.greeter.Request schema %+v", spec.Components.Schemas)
+	}
+}
+
+func TestGeneratePathParams(t *testing.T) {
+	spec := &Spec{Paths: make(map[string]map[string]Operation)}
+
+	Generate(spec, "go.micro.api", "go.micro.api.users", &registry.Endpoint{
+		Name: "Users.Read",
+		Metadata: api.Encode(&api.Endpoint{
+			Name:   "Users.Read",
+			Path:   []string{"/users/{id:int}/files/{name:[a-z]+}", "/files/{path...}"},
+			Method: []string{"GET"},
+		}),
+	})
+
+	op, ok := spec.Paths["/users/{id}/files/{name}"]["get"]
+	if !ok {
+		t.Fatalf("Expected /users/{id}/files/{name} in paths %+v", spec.Paths)
+	}
+
+	// catch-alls can't be described
+	if len(spec.Paths) != 1 {
+		t.Fatalf("Expected a single path got %+v", spec.Paths)
+	}
+
+	testData := []Parameter{
+		{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer", Format: "int64"}},
+		{Name: "name", In: "path", Required: true, Schema: &Schema{Type: "string", Pattern: "^(?:[a-z]+)$"}},
+	}
+
+	if len(op.Parameters) != len(testData) {
+		t.Fatalf("Expected %d parameters got %+v", len(testData), op.Parameters)
+	}
+	for i, d := range testData {
+		p := op.Parameters[i]
+		if p.Name != d.Name || p.In != d.In || p.Required != d.Required || !reflect.DeepEqual(p.Schema, d.Schema) {
+			t.Fatalf("Expected parameter %+v got %+v", d, p)
+		}
+	}
+}
+
+func TestSchemaRef(t *testing.T) {
+	spec := &Spec{Components: Components{Schemas: make(map[string]*Schema)}}
+
+	// types of different services with the same name don't collide
+	foo := &registry.Value{Type: "Request", Values: []*registry.Value{{Name: "foo", Type: "string"}}}
+	bar := &registry.Value{Type: "Request", Values: []*registry.Value{{Name: "bar", Type: "int64"}}}
+
+	if s := schemaRef(spec, "foo", foo); s.Ref != "#/components/schemas/foo.Request" {
+		t.Fatalf("Unexpected ref %s", s.Ref)
+	}
+	if s := schemaRef(spec, "bar", bar); s.Ref != "#/components/schemas/bar.Request" {
+		t.Fatalf("Unexpected ref %s", s.Ref)
+	}
+	if _, ok := spec.Components.Schemas["foo.Request"].Properties["foo"]; !ok {
+		t.Fatal("Expected foo.Request to have foo")
+	}
+	if _, ok := spec.Components.Schemas["bar.Request"].Properties["bar"]; !ok {
+		t.Fatal("Expected bar.Request to have bar")
+	}
+}
+
+func TestSwaggerUI(t *testing.T) {
+	h := NewHandler()
+
+	testData := []struct {
+		path string
+		url  string
+	}{
+		{path: "/docs/", url: `url: "openapi.json"`},
+		{path: "/docs", url: `url: "docs/openapi.json"`},
+		{path: "/docs\"><img src=x onerror=alert(1)>", url: `url: "docs\"\u003E\u003Cimg`},
+	}
+
+	for _, d := range testData {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.URL.Path = d.path
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		body := w.Body.String()
+		if strings.Contains(body, "<img") {
+			t.Fatalf("Expected the path to be escaped got %s", body)
+		}
+		if !strings.Contains(body, d.url) {
+			t.Fatalf("Expected %s in %s", d.url, body)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"github.com/asim/go-micro/v3/api/breaker"
 	"github.com/asim/go-micro/v3/api/router"
 	"github.com/asim/go-micro/v3/client"
 )
@@ -14,6 +15,8 @@ type Options struct {
 	Namespace   string
 	Router      router.Router
 	Client      client.Client
+	// Breaker tracks backend errors, circuit breaking is disabled if nil
+	Breaker *breaker.Breaker
 }
 
 type Option func(o *Options)
@@ -67,3 +70,10 @@ func WithMaxRecvSize(size int64) Option {
 		o.MaxRecvSize = size
 	}
 }
+
+// WithBreaker enables circuit breaking of backends
+func WithBreaker(b *breaker.Breaker) Option {
+	return func(o *Options) {
+		o.Breaker = b
+	}
+}
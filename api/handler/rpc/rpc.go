@@ -12,6 +12,7 @@ import (
 	"github.com/asim/go-micro/v3/api"
 	"github.com/asim/go-micro/v3/api/handler"
 	"github.com/asim/go-micro/v3/api/internal/proto"
+	"github.com/asim/go-micro/v3/api/server/access"
 	"github.com/asim/go-micro/v3/client"
 	"github.com/asim/go-micro/v3/codec"
 	"github.com/asim/go-micro/v3/codec/jsonrpc"
@@ -101,6 +102,8 @@ func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	access.SetRoute(r.Context(), service.Name, access.Endpoint(service))
+
 	ct := r.Header.Get("Content-Type")
 
 	// Strip charset from Content-Type (like `application/json; charset=UTF-8`)
@@ -111,6 +114,9 @@ func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// micro client
 	c := h.opts.Client
 
+	// the context of the client connection, which the merged context drops
+	rctx := r.Context()
+
 	// create context
 	cx := ctx.FromRequest(r)
 	// get context from http handler wrappers
@@ -132,6 +138,21 @@ func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// set merged context to request
 	*r = *r.Clone(cx)
+
+	// fail fast if the service is unhealthy, streams included
+	if b := h.opts.Breaker; b != nil {
+		if wait, ok := b.Allow(service.Name); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			writeError(w, r, errors.New("go.micro.api", "service unavailable", 503))
+			return
+		}
+
+		// eject unhealthy nodes
+		filtered := *service
+		filtered.Services = b.Filter()(service.Services)
+		service = &filtered
+	}
+
 	// if stream we currently only support json
 	if isStream(r, service) {
 		// drop older context as it can have timeouts and create new
@@ -141,8 +162,23 @@ func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// stream back as server-sent events
+	if isEventStream(r) && isStreamEndpoint(service) {
+		// end the stream when the client disconnects
+		md, _ := metadata.FromContext(cx)
+		serveEvents(metadata.NewContext(rctx, md), w, r, service, c)
+		return
+	}
+
+	services := service.Services
+
 	// create strategy
-	so := selector.WithStrategy(strategy(service.Services))
+	so := selector.WithStrategy(strategy(services))
+
+	callOpts := []client.CallOption{client.WithSelectOption(so)}
+	if b := h.opts.Breaker; b != nil {
+		callOpts = append(callOpts, client.WithCallWrapper(b.CallWrapper()))
+	}
 
 	// walk the standard call path
 	// get payload
@@ -174,7 +210,11 @@ func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		)
 
 		// make the call
-		if err := c.Call(cx, req, response, client.WithSelectOption(so)); err != nil {
+		err := c.Call(cx, req, response, callOpts...)
+		if b := h.opts.Breaker; b != nil {
+			b.Mark(service.Name, err)
+		}
+		if err != nil {
 			writeError(w, r, err)
 			return
 		}
@@ -209,7 +249,11 @@ func (h *rpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			client.WithContentType(ct),
 		)
 		// make the call
-		if err := c.Call(cx, req, &response, client.WithSelectOption(so)); err != nil {
+		err := c.Call(cx, req, &response, callOpts...)
+		if b := h.opts.Breaker; b != nil {
+			b.Mark(service.Name, err)
+		}
+		if err != nil {
 			writeError(w, r, err)
 			return
 		}
@@ -310,10 +354,10 @@ func requestPayload(r *http.Request) ([]byte, error) {
 		// filter own keys
 		if strings.HasPrefix(k, "x-api-field-") {
 			matches[strings.TrimPrefix(k, "x-api-field-")] = v
-			delete(md, k)
+			md.Delete(k)
 		} else if k == "x-api-body" {
 			bodydst = v
-			delete(md, k)
+			md.Delete(k)
 		}
 	}
 
@@ -2,11 +2,24 @@ package rpc
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/asim/go-micro/v3/api"
+	"github.com/asim/go-micro/v3/api/breaker"
+	"github.com/asim/go-micro/v3/api/handler"
 	go_api "github.com/asim/go-micro/v3/api/proto"
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/codec"
+	"github.com/asim/go-micro/v3/registry"
 	"github.com/golang/protobuf/proto"
 )
 
@@ -110,3 +123,191 @@ func TestRequestPayloadFromRequest(t *testing.T) {
 		}
 	})
 }
+
+func TestWriteEvent(t *testing.T) {
+	testData := []struct {
+		event  string
+		id     uint64
+		data   string
+		expect string
+	}{
+		{"", 1, `{"msg":"hello"}`, "id: 1\ndata: {\"msg\":\"hello\"}\n\n"},
+		{"", 2, "foo\nbar", "id: 2\ndata: foo\ndata: bar\n\n"},
+		{"error", 0, `{"code":500}`, "event: error\ndata: {\"code\":500}\n\n"},
+	}
+
+	for _, d := range testData {
+		var buf bytes.Buffer
+		if err := writeEvent(&buf, d.event, d.id, []byte(d.data)); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != d.expect {
+			t.Fatalf("Expected %q got %q", d.expect, buf.String())
+		}
+	}
+}
+
+func TestBreakerTrial(t *testing.T) {
+	now := time.Now()
+	b := breaker.New(
+		breaker.MinRequests(1),
+		breaker.Cooldown(time.Second*30),
+		breaker.Now(func() time.Time { return now }),
+	)
+	b.Mark("foo", errors.New("timeout"))
+
+	c := client.NewClient(client.Registry(registry.NewMemoryRegistry()))
+	h := WithService(&api.Service{
+		Name:     "foo",
+		Endpoint: &api.Endpoint{Name: "Foo.Bar"},
+	}, handler.WithBreaker(b), handler.WithClient(c))
+
+	serve := func(ct, body string) int {
+		r := httptest.NewRequest("POST", "/foo/bar", strings.NewReader(body))
+		r.Header.Set("Content-Type", ct)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	now = now.Add(time.Second * 31)
+
+	// the trial request returns before the call is made
+	if code := serve("application/json-rpc", "{"); code == 503 {
+		t.Fatal("Expected the trial request to be let through")
+	}
+	if code := serve("application/json", "{}"); code != 503 {
+		t.Fatalf("Expected 503 while the trial request is in flight got %d", code)
+	}
+
+	now = now.Add(time.Second * 31)
+
+	// the unmarked trial doesn't hold the circuit
+	if code := serve("application/json", "{}"); code == 503 {
+		t.Fatal("Expected another trial request to be let through")
+	}
+}
+
+func TestBreakerStream(t *testing.T) {
+	b := breaker.New(breaker.MinRequests(1))
+	b.Mark("foo", errors.New("timeout"))
+
+	c := client.NewClient(client.Registry(registry.NewMemoryRegistry()))
+	h := WithService(&api.Service{
+		Name:     "foo",
+		Endpoint: &api.Endpoint{Name: "Foo.Bar", Stream: true},
+	}, handler.WithBreaker(b), handler.WithClient(c))
+
+	headers := map[string]map[string]string{
+		"events": {
+			"Accept": "text/event-stream",
+		},
+		"websocket": {
+			"Connection": "Upgrade",
+			"Upgrade":    "websocket",
+		},
+	}
+
+	for name, hdr := range headers {
+		r := httptest.NewRequest("GET", "/foo/bar", nil)
+		for k, v := range hdr {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != 503 {
+			t.Fatalf("Expected 503 for a %s stream while the circuit is open got %d", name, w.Code)
+		}
+		if len(w.Header().Get("Retry-After")) == 0 {
+			t.Fatalf("Expected Retry-After for a %s stream", name)
+		}
+	}
+}
+
+// testStreamClient returns streams which block reading until they're closed
+type testStreamClient struct {
+	client.Client
+	streams chan *testStream
+}
+
+func (c *testStreamClient) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	s := &testStream{closed: make(chan bool)}
+	c.streams <- s
+	return s, nil
+}
+
+type testStream struct {
+	client.Stream
+	closed chan bool
+}
+
+func (s *testStream) Response() client.Response {
+	return s
+}
+
+func (s *testStream) Codec() codec.Reader {
+	return nil
+}
+
+func (s *testStream) Header() map[string]string {
+	return nil
+}
+
+func (s *testStream) Read() ([]byte, error) {
+	<-s.closed
+	return nil, io.ErrUnexpectedEOF
+}
+
+func (s *testStream) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func TestEventsDisconnect(t *testing.T) {
+	c := &testStreamClient{
+		Client:  client.NewClient(client.Registry(registry.NewMemoryRegistry())),
+		streams: make(chan *testStream, 1),
+	}
+	h := WithService(&api.Service{
+		Name:     "foo",
+		Endpoint: &api.Endpoint{Name: "Foo.Bar", Stream: true},
+	}, handler.WithClient(c))
+
+	goroutines := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest("GET", "/foo/bar", nil).WithContext(ctx)
+	r.Header.Set("Accept", "text/event-stream")
+
+	done := make(chan bool)
+	go func() {
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		close(done)
+	}()
+
+	stream := <-c.streams
+
+	// the client disconnects
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the event stream to end when the client disconnects")
+	}
+
+	select {
+	case <-stream.closed:
+	default:
+		t.Fatal("Expected the backend stream to be closed")
+	}
+
+	// the reader of the stream returns
+	for i := 0; runtime.NumGoroutine() > goroutines; i++ {
+		if i == 100 {
+			t.Fatalf("Expected the stream reader to return, %d goroutines running", runtime.NumGoroutine())
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
@@ -0,0 +1,185 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asim/go-micro/v3/api"
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/selector"
+)
+
+var (
+	// HeartbeatInterval is how often a comment is sent on idle event streams
+	// so proxies and load balancers don't close the connection
+	HeartbeatInterval = time.Second * 15
+	// RetryInterval is the reconnection time sent to event stream clients
+	RetryInterval = time.Second * 3
+)
+
+// serveEvents streams rpc responses back as server-sent events assuming json,
+// the stream is closed once the context is done or the backend ends it
+func serveEvents(ctx context.Context, w http.ResponseWriter, r *http.Request, service *api.Service, c client.Client) {
+	// release the reader of the stream when we return
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, errors.InternalServerError("go.micro.api", "streaming not supported"))
+		return
+	}
+
+	payload, err := requestPayload(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// events are numbered from the last one the client saw so it can resume
+	var id uint64
+	if last := r.Header.Get("Last-Event-ID"); len(last) > 0 {
+		id, _ = strconv.ParseUint(last, 10, 64)
+		// pass through so the backend can resume the stream
+		ctx = metadata.Set(ctx, "Last-Event-Id", last)
+	}
+
+	var request interface{}
+	if len(payload) > 0 && !bytes.Equal(payload, []byte(`{}`)) {
+		m := json.RawMessage(payload)
+		request = &m
+	}
+
+	req := c.NewRequest(
+		service.Name,
+		service.Endpoint.Name,
+		request,
+		client.WithContentType("application/json"),
+		client.StreamingRequest(),
+	)
+
+	so := selector.WithStrategy(strategy(service.Services))
+
+	stream, err := c.Stream(ctx, req, client.WithSelectOption(so))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer stream.Close()
+
+	if request != nil {
+		if err := stream.Send(request); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// disable response buffering in nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", RetryInterval.Milliseconds())
+	flusher.Flush()
+
+	type result struct {
+		buf []byte
+		err error
+	}
+
+	results := make(chan result)
+	rsp := stream.Response()
+
+	// read from the backend
+	go func() {
+		for {
+			buf, err := rsp.Read()
+			select {
+			case results <- result{buf, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case res := <-results:
+			if res.err == io.EOF {
+				return
+			}
+
+			if res.err != nil {
+				if strings.Contains(res.err.Error(), "context canceled") {
+					return
+				}
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Error(res.err)
+				}
+				writeEvent(w, "error", 0, []byte(errors.FromError(res.err).Error()))
+				flusher.Flush()
+				return
+			}
+
+			id++
+			if err := writeEvent(w, "", id, res.buf); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes a single event, multi line data is split into data fields
+func writeEvent(w io.Writer, event string, id uint64, data []byte) error {
+	var buf bytes.Buffer
+
+	if id > 0 {
+		fmt.Fprintf(&buf, "id: %d\n", id)
+	}
+
+	if len(event) > 0 {
+		fmt.Fprintf(&buf, "event: %s\n", event)
+	}
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(bytes.TrimSuffix(line, []byte("\r")))
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString("\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// isEventStream returns true if the client accepts server-sent events
+func isEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
@@ -1,6 +1,7 @@
 package rpc
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -148,6 +149,11 @@ func serveWebsocket(ctx context.Context, w http.ResponseWriter, r *http.Request,
 				if strings.Contains(err.Error(), "context canceled") {
 					return
 				}
+				// the backend finished the stream so close the websocket cleanly
+				if err == io.EOF {
+					closeWebsocket(rw, ws.StatusNormalClosure, "")
+					return
+				}
 				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
 					logger.Error(err)
 				}
@@ -219,11 +225,29 @@ func writeLoop(rw io.ReadWriter, stream client.Stream) {
 	}
 }
 
+// closeWebsocket sends a close frame to the client
+func closeWebsocket(rw *bufio.ReadWriter, code ws.StatusCode, reason string) {
+	body := ws.NewCloseFrameBody(code, reason)
+	if err := wsutil.WriteServerMessage(rw, ws.OpClose, body); err != nil {
+		return
+	}
+	rw.Flush()
+}
+
 func isStream(r *http.Request, srv *api.Service) bool {
 	// check if it's a web socket
 	if !isWebSocket(r) {
 		return false
 	}
+	return isStreamEndpoint(srv)
+}
+
+// isStreamEndpoint returns true if the endpoint streams responses
+func isStreamEndpoint(srv *api.Service) bool {
+	// the api endpoint declares itself as a stream
+	if srv.Endpoint != nil && srv.Endpoint.Stream {
+		return true
+	}
 	// check if the endpoint supports streaming
 	for _, service := range srv.Services {
 		for _, ep := range service.Endpoints {
@@ -13,6 +13,7 @@ import (
 
 	"github.com/asim/go-micro/v3/api"
 	"github.com/asim/go-micro/v3/api/handler"
+	"github.com/asim/go-micro/v3/api/server/access"
 	"github.com/asim/go-micro/v3/selector"
 )
 
@@ -70,6 +71,9 @@ func (wh *webHandler) getService(r *http.Request) (string, error) {
 		return "", errors.New("no route found")
 	}
 
+	// the endpoint is the url so isn't recorded
+	access.SetRoute(r.Context(), service.Name, "")
+
 	// create a random selector
 	next := selector.Random(service.Services)
 
@@ -3,6 +3,10 @@ package router
 import (
 	"github.com/asim/go-micro/v3/api/resolver"
 	"github.com/asim/go-micro/v3/api/resolver/vpath"
+	"github.com/asim/go-micro/v3/api/router/policy"
+	"github.com/asim/go-micro/v3/api/router/rules"
+	"github.com/asim/go-micro/v3/config/encoder"
+	"github.com/asim/go-micro/v3/config/source"
 	"github.com/asim/go-micro/v3/registry"
 )
 
@@ -10,6 +14,14 @@ type Options struct {
 	Handler  string
 	Registry registry.Registry
 	Resolver resolver.Resolver
+	// Rules route requests to service versions
+	Rules *rules.Rules
+	// Source static routes are loaded from and watched
+	Source source.Source
+	// Encoder used to decode the source, defaults to json
+	Encoder encoder.Encoder
+	// Policies decide which services are advertised and routed to
+	Policies *policy.Policies
 }
 
 type Option func(o *Options)
@@ -50,3 +62,32 @@ func WithResolver(r resolver.Resolver) Option {
 		o.Resolver = r
 	}
 }
+
+// WithRules sets the version routing rules, the rules can be changed at runtime
+func WithRules(r *rules.Rules) Option {
+	return func(o *Options) {
+		o.Rules = r
+	}
+}
+
+// WithSource sets the source static routes are loaded from e.g a file.
+// Static routes take precedence over the registry and are reloaded on change.
+func WithSource(s source.Source) Option {
+	return func(o *Options) {
+		o.Source = s
+	}
+}
+
+// WithEncoder sets the encoder used to decode the source e.g yaml
+func WithEncoder(e encoder.Encoder) Option {
+	return func(o *Options) {
+		o.Encoder = e
+	}
+}
+
+// WithPolicies sets the policies which allow or deny routing to services
+func WithPolicies(p *policy.Policies) Option {
+	return func(o *Options) {
+		o.Policies = p
+	}
+}
@@ -0,0 +1,194 @@
+// Package policy provides allow and deny policies for api routes
+//
+// Policies are checked in order and the first which matches decides if a
+// service is advertised by the router and if a request may be routed to it.
+// Services no policy matches are allowed. Policies can be changed at runtime.
+//
+// For example to only route to services with metadata visibility=internal
+// from the private network:
+//
+//	p, err := policy.New(
+//		policy.Policy{Action: policy.Allow, Networks: []string{"10.0.0.0/8"}},
+//		policy.Policy{Action: policy.Deny, Metadata: map[string]string{"visibility": "internal"}},
+//	)
+package policy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/asim/go-micro/v3/registry"
+)
+
+// Action taken when a policy matches
+type Action string
+
+const (
+	Allow Action = "allow"
+	Deny  Action = "deny"
+)
+
+// Policy allows or denies services
+type Policy struct {
+	// Action is allow or deny
+	Action Action `json:"action"`
+	// Services the policy applies to, a trailing * matches a prefix e.g go.micro.srv.*
+	Services []string `json:"services,omitempty"`
+	// Networks the request must come from e.g 10.0.0.0/8
+	Networks []string `json:"networks,omitempty"`
+	// Metadata values the service must have
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	nets []*net.IPNet
+}
+
+func (p *Policy) matchService(service *registry.Service) bool {
+	if len(p.Services) > 0 {
+		var ok bool
+		for _, name := range p.Services {
+			if name == service.Name || (strings.HasSuffix(name, "*") && strings.HasPrefix(service.Name, strings.TrimSuffix(name, "*"))) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	for k, v := range p.Metadata {
+		if service.Metadata[k] != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *Policy) matchNetwork(req *http.Request) bool {
+	if len(p.Networks) == 0 {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range p.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Policies is an ordered set of policies
+type Policies struct {
+	sync.RWMutex
+	policies []*Policy
+}
+
+// New returns the policies or an error if an action isn't allow or deny or a
+// network isn't a valid CIDR
+func New(policies ...Policy) (*Policies, error) {
+	p := new(Policies)
+	if err := p.Set(policies...); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Set replaces the policies, they're left unchanged if an action isn't allow
+// or deny or a network isn't a valid CIDR
+func (p *Policies) Set(policies ...Policy) error {
+	var ps []*Policy
+
+	for _, policy := range policies {
+		policy := policy
+		if policy.Action != Allow && policy.Action != Deny {
+			return fmt.Errorf("invalid action %q", policy.Action)
+		}
+		policy.nets = nil
+		for _, n := range policy.Networks {
+			_, ipnet, err := net.ParseCIDR(n)
+			if err != nil {
+				return fmt.Errorf("invalid network %q: %v", n, err)
+			}
+			policy.nets = append(policy.nets, ipnet)
+		}
+		ps = append(ps, &policy)
+	}
+
+	p.Lock()
+	p.policies = ps
+	p.Unlock()
+
+	return nil
+}
+
+// Get returns the policies
+func (p *Policies) Get() []Policy {
+	p.RLock()
+	defer p.RUnlock()
+
+	policies := make([]Policy, 0, len(p.policies))
+	for _, policy := range p.policies {
+		policies = append(policies, *policy)
+	}
+	return policies
+}
+
+// Advertise returns true if the service may be advertised. Network
+// policies don't apply as there's no request.
+func (p *Policies) Advertise(service *registry.Service) bool {
+	p.RLock()
+	defer p.RUnlock()
+
+	for _, policy := range p.policies {
+		if len(policy.Networks) > 0 {
+			continue
+		}
+		if policy.matchService(service) {
+			return policy.Action != Deny
+		}
+	}
+
+	return true
+}
+
+// Lookup returns true if the request may be routed to the service
+func (p *Policies) Lookup(req *http.Request, service *registry.Service) bool {
+	p.RLock()
+	defer p.RUnlock()
+
+	for _, policy := range p.policies {
+		if policy.matchService(service) && policy.matchNetwork(req) {
+			return policy.Action != Deny
+		}
+	}
+
+	return true
+}
+
+// Filter returns the services the request may be routed to
+func (p *Policies) Filter(req *http.Request, services []*registry.Service) []*registry.Service {
+	var allowed []*registry.Service
+
+	for _, service := range services {
+		if p.Lookup(req, service) {
+			allowed = append(allowed, service)
+		}
+	}
+
+	return allowed
+}
@@ -0,0 +1,88 @@
+package policy
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/asim/go-micro/v3/registry"
+)
+
+func TestPolicies(t *testing.T) {
+	internal := &registry.Service{Name: "go.micro.srv.users", Metadata: map[string]string{"visibility": "internal"}}
+	public := &registry.Service{Name: "go.micro.api.users"}
+	admin := &registry.Service{Name: "go.micro.admin.users"}
+
+	p, err := New(
+		Policy{Action: Deny, Services: []string{"go.micro.admin.*"}},
+		Policy{Action: Allow, Networks: []string{"10.0.0.0/8"}},
+		Policy{Action: Deny, Metadata: map[string]string{"visibility": "internal"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// advertisement ignores network policies
+	if !p.Advertise(public) {
+		t.Fatal("expected public service to be advertised")
+	}
+	if p.Advertise(internal) {
+		t.Fatal("expected internal service not to be advertised")
+	}
+	if p.Advertise(admin) {
+		t.Fatal("expected admin service not to be advertised")
+	}
+
+	private := httptest.NewRequest("GET", "/", nil)
+	private.RemoteAddr = "10.1.2.3:1234"
+
+	external := httptest.NewRequest("GET", "/", nil)
+	external.RemoteAddr = "192.0.2.1:1234"
+
+	testData := []struct {
+		req     string
+		service *registry.Service
+		allowed bool
+	}{
+		{"private", internal, true},
+		{"private", public, true},
+		{"private", admin, false},
+		{"external", internal, false},
+		{"external", public, true},
+		{"external", admin, false},
+	}
+
+	for _, d := range testData {
+		req := external
+		if d.req == "private" {
+			req = private
+		}
+		if ok := p.Lookup(req, d.service); ok != d.allowed {
+			t.Fatalf("%s %s: expected %v got %v", d.req, d.service.Name, d.allowed, ok)
+		}
+	}
+
+	if services := p.Filter(external, []*registry.Service{internal, public}); len(services) != 1 || services[0] != public {
+		t.Fatalf("expected only the public service got %v", services)
+	}
+
+	// invalid networks are an error and leave the policies unchanged
+	if err := p.Set(Policy{Action: Allow, Networks: []string{"invalid"}}); err == nil {
+		t.Fatal("expected an error for an invalid network")
+	}
+	if len(p.Get()) != 3 || p.Lookup(external, admin) {
+		t.Fatal("expected the policies to be unchanged")
+	}
+	if _, err := New(Policy{Action: Deny, Networks: []string{"10.0.0.0"}}); err == nil {
+		t.Fatal("expected an error for a network without a mask")
+	}
+
+	// as are actions other than allow or deny
+	for _, action := range []Action{"", "Allow", "block"} {
+		if err := p.Set(Policy{Action: action}); err == nil {
+			t.Fatalf("expected an error for action %q", action)
+		}
+	}
+	if len(p.Get()) != 3 {
+		t.Fatal("expected the policies to be unchanged")
+	}
+}
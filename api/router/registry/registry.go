@@ -24,6 +24,67 @@ type endpoint struct {
 	hostregs []*regexp.Regexp
 	pathregs []util.Pattern
 	pcreregs []*regexp.Regexp
+	routes   []*util.Route
+	// specificity of the path patterns and routes
+	pathspecs  [][]int
+	routespecs [][]int
+}
+
+// match returns the specificity and fields of the most specific path matching
+func (e *endpoint) match(path []string, urlPath string) ([]int, map[string]string, bool) {
+	var (
+		spec    []int
+		fields  map[string]string
+		matched bool
+	)
+
+	// try typed, regex and catch-all routes
+	for i, route := range e.routes {
+		params, ok := route.Match(path)
+		if !ok {
+			continue
+		}
+		if !matched || util.CompareSpecificity(e.routespecs[i], spec) > 0 {
+			spec, fields, matched = e.routespecs[i], params, true
+		}
+	}
+
+	// try path via google.api path matching
+	for i, pathreg := range e.pathregs {
+		params, err := pathreg.Match(path, "")
+		if err != nil {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("api gpath not match %s != %v", path, pathreg)
+			}
+			continue
+		}
+		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+			logger.Debugf("api gpath match %s = %v", path, pathreg)
+		}
+		if !matched || util.CompareSpecificity(e.pathspecs[i], spec) > 0 {
+			spec, fields, matched = e.pathspecs[i], params, true
+		}
+	}
+
+	if matched {
+		return spec, fields, true
+	}
+
+	// try path via pcre path matching, this has the lowest precedence
+	for _, pathreg := range e.pcreregs {
+		if !pathreg.MatchString(urlPath) {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("api pcre path not match %s != %v", path, pathreg)
+			}
+			continue
+		}
+		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+			logger.Debugf("api pcre path match %s != %v", path, pathreg)
+		}
+		return nil, nil, true
+	}
+
+	return nil, nil, false
 }
 
 // router is the default router
@@ -38,6 +99,11 @@ type registryRouter struct {
 	eps map[string]*api.Service
 	// compiled regexp for host and path
 	ceps map[string]*endpoint
+	// static routes loaded from the source
+	static map[string]*api.Service
+	sceps  map[string]*endpoint
+	// versions static routes are pinned to
+	versions map[string]string
 }
 
 func (r *registryRouter) isClosed() bool {
@@ -121,6 +187,11 @@ func (r *registryRouter) store(services []*registry.Service) {
 		// set names we need later
 		names[service.Name] = true
 
+		// don't advertise services denied by policy
+		if r.opts.Policies != nil && !r.opts.Policies.Advertise(service) {
+			continue
+		}
+
 		// map per endpoint
 		for _, sep := range service.Endpoints {
 			// create a key service:endpoint_name
@@ -169,56 +240,76 @@ func (r *registryRouter) store(services []*registry.Service) {
 	// now set the eps we have
 	for name, ep := range eps {
 		r.eps[name] = ep
-		cep := &endpoint{}
+		r.ceps[name] = compile(ep.Endpoint)
+	}
+}
 
-		for _, h := range ep.Endpoint.Host {
-			if h == "" || h == "*" {
-				continue
+// compile the host and path patterns of the endpoint, invalid patterns are skipped
+func compile(ep *api.Endpoint) *endpoint {
+	cep := &endpoint{}
+
+	for _, h := range ep.Host {
+		if h == "" || h == "*" {
+			continue
+		}
+		hostreg, err := regexp.CompilePOSIX(h)
+		if err != nil {
+			if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+				logger.Tracef("endpoint have invalid host regexp: %v", err)
 			}
-			hostreg, err := regexp.CompilePOSIX(h)
+			continue
+		}
+		cep.hostregs = append(cep.hostregs, hostreg)
+	}
+
+	for _, p := range ep.Path {
+		var pcreok bool
+
+		// typed params, regex segments and catch-alls
+		if util.IsRoute(p) {
+			route, err := util.ParseRoute(p)
 			if err != nil {
 				if logger.V(logger.TraceLevel, logger.DefaultLogger) {
-					logger.Tracef("endpoint have invalid host regexp: %v", err)
+					logger.Tracef("endpoint have invalid path route: %v", err)
 				}
 				continue
 			}
-			cep.hostregs = append(cep.hostregs, hostreg)
+			cep.routes = append(cep.routes, route)
+			cep.routespecs = append(cep.routespecs, util.Specificity(p))
+			continue
 		}
 
-		for _, p := range ep.Endpoint.Path {
-			var pcreok bool
-
-			if p[0] == '^' && p[len(p)-1] == '$' {
-				pcrereg, err := regexp.CompilePOSIX(p)
-				if err == nil {
-					cep.pcreregs = append(cep.pcreregs, pcrereg)
-					pcreok = true
-				}
+		if p[0] == '^' && p[len(p)-1] == '$' {
+			pcrereg, err := regexp.CompilePOSIX(p)
+			if err == nil {
+				cep.pcreregs = append(cep.pcreregs, pcrereg)
+				pcreok = true
 			}
+		}
 
-			rule, err := util.Parse(p)
-			if err != nil && !pcreok {
-				if logger.V(logger.TraceLevel, logger.DefaultLogger) {
-					logger.Tracef("endpoint have invalid path pattern: %v", err)
-				}
-				continue
-			} else if err != nil && pcreok {
-				continue
+		rule, err := util.Parse(p)
+		if err != nil && !pcreok {
+			if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+				logger.Tracef("endpoint have invalid path pattern: %v", err)
 			}
+			continue
+		} else if err != nil && pcreok {
+			continue
+		}
 
-			tpl := rule.Compile()
-			pathreg, err := util.NewPattern(tpl.Version, tpl.OpCodes, tpl.Pool, "")
-			if err != nil {
-				if logger.V(logger.TraceLevel, logger.DefaultLogger) {
-					logger.Tracef("endpoint have invalid path pattern: %v", err)
-				}
-				continue
+		tpl := rule.Compile()
+		pathreg, err := util.NewPattern(tpl.Version, tpl.OpCodes, tpl.Pool, "")
+		if err != nil {
+			if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+				logger.Tracef("endpoint have invalid path pattern: %v", err)
 			}
-			cep.pathregs = append(cep.pathregs, pathreg)
+			continue
 		}
-
-		r.ceps[name] = cep
+		cep.pathregs = append(cep.pathregs, pathreg)
+		cep.pathspecs = append(cep.pathspecs, util.Specificity(p))
 	}
+
+	return cep
 }
 
 // watch for endpoint changes
@@ -298,24 +389,70 @@ func (r *registryRouter) Endpoint(req *http.Request) (*api.Service, error) {
 		return nil, errors.New("router closed")
 	}
 
-	r.RLock()
-	defer r.RUnlock()
-
 	var idx int
 	if len(req.URL.Path) > 0 && req.URL.Path != "/" {
 		idx = 1
 	}
 	path := strings.Split(req.URL.Path[idx:], "/")
 
-	// use the first match
-	// TODO: weighted matching
-	for n, e := range r.eps {
-		cep, ok := r.ceps[n]
+	r.RLock()
+	// static routes override the registry
+	var version string
+	static, fields := matchEndpoint(req, path, r.static, r.sceps)
+	match := static
+	if static != nil {
+		version = r.versions[fmt.Sprintf("%s.%s", static.Name, static.Endpoint.Name)]
+	} else {
+		match, fields = matchEndpoint(req, path, r.eps, r.ceps)
+	}
+	r.RUnlock()
+
+	// the services are looked up without holding the lock
+	if static != nil {
+		service, err := r.resolve(static, version)
+		if err != nil {
+			return nil, err
+		}
+		match = service
+	}
+
+	if match != nil {
+		if fields != nil {
+			ctx := req.Context()
+			md, ok := metadata.FromContext(ctx)
+			if !ok {
+				md = make(metadata.Metadata)
+			}
+			for k, v := range fields {
+				md.Set(fmt.Sprintf("x-api-field-%s", k), v)
+			}
+			md.Set("x-api-body", match.Endpoint.Body)
+			*req = *req.Clone(metadata.NewContext(ctx, md))
+		}
+		return match, nil
+	}
+
+	// no match
+	return nil, errors.New("not found")
+}
+
+// matchEndpoint returns the most specific endpoint matching the request and the path fields
+func matchEndpoint(req *http.Request, path []string, eps map[string]*api.Service, ceps map[string]*endpoint) (*api.Service, map[string]string) {
+	var (
+		match     *api.Service
+		matchName string
+		matchSpec []int
+		fields    map[string]string
+	)
+
+	// use the most specific match
+	for n, e := range eps {
+		cep, ok := ceps[n]
 		if !ok {
 			continue
 		}
 		ep := e.Endpoint
-		var mMatch, hMatch, pMatch bool
+		var mMatch, hMatch bool
 		// 1. try method
 		for _, m := range ep.Method {
 			if m == req.Method {
@@ -353,60 +490,23 @@ func (r *registryRouter) Endpoint(req *http.Request) (*api.Service, error) {
 			logger.Debugf("api host match %s", req.URL.Host)
 		}
 
-		// 3. try path via google.api path matching
-		for _, pathreg := range cep.pathregs {
-			matches, err := pathreg.Match(path, "")
-			if err != nil {
-				if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-					logger.Debugf("api gpath not match %s != %v", path, pathreg)
-				}
-				continue
-			}
-			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-				logger.Debugf("api gpath match %s = %v", path, pathreg)
-			}
-			pMatch = true
-			ctx := req.Context()
-			md, ok := metadata.FromContext(ctx)
-			if !ok {
-				md = make(metadata.Metadata)
-			}
-			for k, v := range matches {
-				md[fmt.Sprintf("x-api-field-%s", k)] = v
-			}
-			md["x-api-body"] = ep.Body
-			*req = *req.Clone(metadata.NewContext(ctx, md))
-			break
+		// 3. try path
+		spec, params, ok := cep.match(path, req.URL.Path)
+		if !ok {
+			continue
 		}
 
-		if !pMatch {
-			// 4. try path via pcre path matching
-			for _, pathreg := range cep.pcreregs {
-				if !pathreg.MatchString(req.URL.Path) {
-					if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-						logger.Debugf("api pcre path not match %s != %v", path, pathreg)
-					}
-					continue
-				}
-				if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-					logger.Debugf("api pcre path match %s != %v", path, pathreg)
-				}
-				pMatch = true
-				break
+		// ties are broken by name so routing is deterministic
+		if match != nil {
+			if c := util.CompareSpecificity(spec, matchSpec); c < 0 || (c == 0 && n > matchName) {
+				continue
 			}
 		}
 
-		if !pMatch {
-			continue
-		}
-
-		// TODO: Percentage traffic
-		// we got here, so its a match
-		return e, nil
+		match, matchName, matchSpec, fields = e, n, spec, params
 	}
 
-	// no match
-	return nil, errors.New("not found")
+	return match, fields
 }
 
 func (r *registryRouter) Route(req *http.Request) (*api.Service, error) {
@@ -417,7 +517,7 @@ func (r *registryRouter) Route(req *http.Request) (*api.Service, error) {
 	// try get an endpoint
 	ep, err := r.Endpoint(req)
 	if err == nil {
-		return ep, nil
+		return r.route(req, ep)
 	}
 
 	// error not nil
@@ -451,18 +551,18 @@ func (r *registryRouter) Route(req *http.Request) (*api.Service, error) {
 		}
 
 		// construct api service
-		return &api.Service{
+		return r.route(req, &api.Service{
 			Name: name,
 			Endpoint: &api.Endpoint{
 				Name:    rp.Method,
 				Handler: handler,
 			},
 			Services: services,
-		}, nil
+		})
 	// http handler
 	case "http", "proxy", "web":
 		// construct api service
-		return &api.Service{
+		return r.route(req, &api.Service{
 			Name: name,
 			Endpoint: &api.Endpoint{
 				Name:    req.URL.String(),
@@ -472,12 +572,29 @@ func (r *registryRouter) Route(req *http.Request) (*api.Service, error) {
 				Path:    []string{req.URL.Path},
 			},
 			Services: services,
-		}, nil
+		})
 	}
 
 	return nil, errors.New("unknown handler")
 }
 
+// route applies the policies and version routing rules to the service
+func (r *registryRouter) route(req *http.Request, service *api.Service) (*api.Service, error) {
+	if r.opts.Policies != nil {
+		services := r.opts.Policies.Filter(req, service.Services)
+		if len(services) == 0 {
+			return nil, errors.New("not found")
+		}
+		svc := *service
+		svc.Services = services
+		service = &svc
+	}
+	if r.opts.Rules == nil {
+		return service, nil
+	}
+	return r.opts.Rules.Route(req, service), nil
+}
+
 func newRouter(opts ...router.Option) *registryRouter {
 	options := router.NewOptions(opts...)
 	r := &registryRouter{
@@ -487,6 +604,14 @@ func newRouter(opts ...router.Option) *registryRouter {
 		eps:  make(map[string]*api.Service),
 		ceps: make(map[string]*endpoint),
 	}
+	if options.Source != nil {
+		if err := r.load(); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("unable to load routes: %v", err)
+			}
+		}
+		go r.watchSource()
+	}
 	go r.watch()
 	go r.refresh()
 	return r
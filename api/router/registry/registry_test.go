@@ -1,8 +1,13 @@
 package registry
 
 import (
+	"net/http/httptest"
 	"testing"
 
+	"github.com/asim/go-micro/v3/api/router"
+	"github.com/asim/go-micro/v3/config/source"
+	"github.com/asim/go-micro/v3/config/source/memory"
+	"github.com/asim/go-micro/v3/metadata"
 	"github.com/asim/go-micro/v3/registry"
 	"github.com/stretchr/testify/assert"
 )
@@ -32,3 +37,50 @@ func TestStoreRegex(t *testing.T) {
 
 	assert.Len(t, router.ceps["Foobar.foo"].pcreregs, 1)
 }
+
+func TestStaticRoutes(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	for _, version := range []string{"1.0.0", "1.0.1"} {
+		reg.Register(&registry.Service{
+			Name:    "greeter",
+			Version: version,
+			Nodes:   []*registry.Node{{Id: "greeter-" + version, Address: "127.0.0.1:8080"}},
+		})
+	}
+
+	src := memory.NewSource(memory.WithJSON([]byte(`{
+		"routes": [{
+			"service": "greeter",
+			"endpoint": "Say.Hello",
+			"method": ["POST"],
+			"path": ["/greeter/{name}"],
+			"version": "1.0.1"
+		}]
+	}`)))
+
+	r := newRouter(router.WithRegistry(reg), router.WithSource(src))
+	defer r.Close()
+
+	req := httptest.NewRequest("POST", "/greeter/john", nil)
+	svc, err := r.Endpoint(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "greeter", svc.Name)
+	assert.Equal(t, "Say.Hello", svc.Endpoint.Name)
+	assert.Len(t, svc.Services, 1)
+	assert.Equal(t, "1.0.1", svc.Services[0].Version)
+
+	name, _ := metadata.Get(req.Context(), "x-api-field-name")
+	assert.Equal(t, "john", name)
+
+	// invalid routes keep the current routes
+	err = r.apply(&source.ChangeSet{Data: []byte(`{"routes": [{"service": "greeter"}]}`)})
+	assert.Error(t, err)
+	assert.Len(t, r.static, 1)
+
+	// methods which don't match fall through to the registry
+	_, err = r.Endpoint(httptest.NewRequest("GET", "/greeter/john", nil))
+	assert.Error(t, err)
+}
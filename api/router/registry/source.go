@@ -0,0 +1,201 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/asim/go-micro/v3/api"
+	"github.com/asim/go-micro/v3/config/encoder/json"
+	"github.com/asim/go-micro/v3/config/source"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/registry"
+)
+
+// staticRoute is a route loaded from the source e.g
+//
+//	{
+//		"routes": [{
+//			"service": "greeter",
+//			"endpoint": "Say.Hello",
+//			"method": ["POST"],
+//			"path": ["/greeter/hello"],
+//			"version": "1.0.1"
+//		}]
+//	}
+type staticRoute struct {
+	Service  string   `json:"service"`
+	Endpoint string   `json:"endpoint"`
+	Handler  string   `json:"handler"`
+	Host     []string `json:"host"`
+	Method   []string `json:"method"`
+	Path     []string `json:"path"`
+	Body     string   `json:"body"`
+	Stream   bool     `json:"stream"`
+	// Version pins the route to a version of the service
+	Version string `json:"version"`
+}
+
+type staticRoutes struct {
+	Routes []staticRoute `json:"routes"`
+}
+
+// load reads and replaces the static routes from the source
+func (r *registryRouter) load() error {
+	cs, err := r.opts.Source.Read()
+	if err != nil {
+		return err
+	}
+	return r.apply(cs)
+}
+
+// apply decodes the change set and replaces the static routes, the
+// current routes are kept if any route is invalid
+func (r *registryRouter) apply(cs *source.ChangeSet) error {
+	enc := r.opts.Encoder
+	if enc == nil {
+		enc = json.NewEncoder()
+	}
+
+	var routes staticRoutes
+	if len(cs.Data) > 0 {
+		if err := enc.Decode(cs.Data, &routes); err != nil {
+			return err
+		}
+	}
+
+	eps := make(map[string]*api.Service)
+	ceps := make(map[string]*endpoint)
+	versions := make(map[string]string)
+
+	for i, rt := range routes.Routes {
+		if len(rt.Service) == 0 {
+			return fmt.Errorf("route %d: service required", i)
+		}
+
+		handler := rt.Handler
+		if len(handler) == 0 {
+			handler = "rpc"
+		}
+
+		ep := &api.Endpoint{
+			Name:    rt.Endpoint,
+			Handler: handler,
+			Host:    rt.Host,
+			Method:  rt.Method,
+			Path:    rt.Path,
+			Body:    rt.Body,
+			Stream:  rt.Stream,
+		}
+		if len(ep.Name) == 0 {
+			ep.Name = rt.Service
+		}
+		if len(ep.Method) == 0 {
+			ep.Method = []string{"GET", "POST"}
+		}
+		if len(ep.Path) == 0 {
+			return fmt.Errorf("route %d: path required", i)
+		}
+		if err := api.Validate(ep); err != nil {
+			return fmt.Errorf("route %d: %v", i, err)
+		}
+
+		key := fmt.Sprintf("%s.%s", rt.Service, ep.Name)
+		eps[key] = &api.Service{Name: rt.Service, Endpoint: ep}
+		ceps[key] = compile(ep)
+		versions[key] = rt.Version
+	}
+
+	r.Lock()
+	r.static = eps
+	r.sceps = ceps
+	r.versions = versions
+	r.Unlock()
+
+	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
+		logger.Infof("loaded %d static routes from %s", len(eps), r.opts.Source)
+	}
+
+	return nil
+}
+
+// resolve returns a copy of the static route with the services it routes
+// to, only the given version of the service if set
+func (r *registryRouter) resolve(route *api.Service, version string) (*api.Service, error) {
+	services, err := r.rc.GetService(route.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(version) > 0 {
+		var pinned []*registry.Service
+		for _, service := range services {
+			if service.Version == version {
+				pinned = append(pinned, service)
+			}
+		}
+		if len(pinned) == 0 {
+			return nil, fmt.Errorf("version %s of %s not found", version, route.Name)
+		}
+		services = pinned
+	}
+
+	return &api.Service{
+		Name:     route.Name,
+		Endpoint: route.Endpoint,
+		Services: services,
+	}, nil
+}
+
+// watchSource reloads the static routes when the source changes
+func (r *registryRouter) watchSource() {
+	var attempts int
+
+	for {
+		if r.isClosed() {
+			return
+		}
+
+		w, err := r.opts.Source.Watch()
+		if err != nil {
+			attempts++
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("error watching routes: %v", err)
+			}
+			time.Sleep(time.Duration(attempts) * time.Second)
+			continue
+		}
+
+		ch := make(chan bool)
+
+		go func() {
+			select {
+			case <-ch:
+				w.Stop()
+			case <-r.exit:
+				w.Stop()
+			}
+		}()
+
+		// reset if we get here
+		attempts = 0
+
+		for {
+			cs, err := w.Next()
+			if err != nil {
+				if !errors.Is(err, source.ErrWatcherStopped) {
+					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+						logger.Errorf("error getting next routes: %v", err)
+					}
+				}
+				close(ch)
+				break
+			}
+			if err := r.apply(cs); err != nil {
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Errorf("unable to apply routes: %v", err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Package rules provides version routing rules for canary releases
+//
+// Rules either match requests by header or cookie and send them to a version
+// of the service, or send a weighted percentage of the remaining traffic to a
+// version. Requests not picked by a weighted rule are sent to the versions
+// which have no weighted rule. Rules can be changed at runtime.
+package rules
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+
+	"github.com/asim/go-micro/v3/api"
+	"github.com/asim/go-micro/v3/registry"
+)
+
+// Rule routes requests for a service to a version
+type Rule struct {
+	// Version of the service requests are sent to
+	Version string `json:"version"`
+	// Weight is the percentage of traffic sent to the version
+	Weight int `json:"weight,omitempty"`
+	// Header values the request must have
+	Header map[string]string `json:"header,omitempty"`
+	// Cookie values the request must have
+	Cookie map[string]string `json:"cookie,omitempty"`
+}
+
+// Match returns true if the request has all the headers and cookies of the rule
+func (r Rule) Match(req *http.Request) bool {
+	if len(r.Header) == 0 && len(r.Cookie) == 0 {
+		return false
+	}
+
+	for k, v := range r.Header {
+		if req.Header.Get(k) != v {
+			return false
+		}
+	}
+
+	for k, v := range r.Cookie {
+		c, err := req.Cookie(k)
+		if err != nil || c.Value != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Rules is a set of rules keyed by service name
+type Rules struct {
+	sync.RWMutex
+	rules map[string][]Rule
+}
+
+// New returns an empty rule set
+func New() *Rules {
+	return &Rules{
+		rules: make(map[string][]Rule),
+	}
+}
+
+// Set replaces the rules for a service
+func (r *Rules) Set(service string, rules ...Rule) {
+	r.Lock()
+	defer r.Unlock()
+
+	if len(rules) == 0 {
+		delete(r.rules, service)
+		return
+	}
+
+	r.rules[service] = rules
+}
+
+// Get returns the rules for a service
+func (r *Rules) Get(service string) []Rule {
+	r.RLock()
+	defer r.RUnlock()
+	return r.rules[service]
+}
+
+// Delete removes the rules for a service
+func (r *Rules) Delete(service string) {
+	r.Set(service)
+}
+
+// Select returns the versions of the service the request should be sent to
+func (r *Rules) Select(req *http.Request, name string, services []*registry.Service) []*registry.Service {
+	rules := r.Get(name)
+	if len(rules) == 0 {
+		return services
+	}
+
+	// header and cookie matches take priority
+	for _, rule := range rules {
+		if rule.Match(req) {
+			if srv := filter(services, rule.Version); len(srv) > 0 {
+				return srv
+			}
+		}
+	}
+
+	n := rand.Intn(100)
+	weighted := make(map[string]bool)
+
+	for _, rule := range rules {
+		if rule.Weight <= 0 || len(rule.Header) > 0 || len(rule.Cookie) > 0 {
+			continue
+		}
+
+		weighted[rule.Version] = true
+
+		if n < rule.Weight {
+			if srv := filter(services, rule.Version); len(srv) > 0 {
+				return srv
+			}
+			continue
+		}
+
+		n -= rule.Weight
+	}
+
+	// send the rest to the versions without a weight
+	var srv []*registry.Service
+	for _, s := range services {
+		if !weighted[s.Version] {
+			srv = append(srv, s)
+		}
+	}
+
+	if len(srv) == 0 {
+		return services
+	}
+
+	return srv
+}
+
+// Route returns a copy of the api service limited to the selected versions
+func (r *Rules) Route(req *http.Request, service *api.Service) *api.Service {
+	services := r.Select(req, service.Name, service.Services)
+	if len(services) == len(service.Services) {
+		return service
+	}
+
+	srv := new(api.Service)
+	*srv = *service
+	srv.Services = services
+	return srv
+}
+
+func filter(services []*registry.Service, version string) []*registry.Service {
+	var srv []*registry.Service
+	for _, s := range services {
+		if s.Version == version {
+			srv = append(srv, s)
+		}
+	}
+	return srv
+}
@@ -0,0 +1,59 @@
+package rules
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/asim/go-micro/v3/registry"
+)
+
+func TestSelect(t *testing.T) {
+	services := []*registry.Service{
+		{Name: "foo", Version: "v1"},
+		{Name: "foo", Version: "v2"},
+	}
+
+	r := New()
+
+	req := httptest.NewRequest("GET", "/foo", nil)
+	if srv := r.Select(req, "foo", services); len(srv) != 2 {
+		t.Fatalf("Expected all versions without rules got %d", len(srv))
+	}
+
+	r.Set("foo",
+		Rule{Version: "v2", Header: map[string]string{"X-Canary": "true"}},
+		Rule{Version: "v2", Cookie: map[string]string{"canary": "true"}},
+		Rule{Version: "v2", Weight: 20},
+	)
+
+	req.Header.Set("X-Canary", "true")
+	if srv := r.Select(req, "foo", services); len(srv) != 1 || srv[0].Version != "v2" {
+		t.Fatalf("Expected v2 for header match got %+v", srv)
+	}
+
+	req = httptest.NewRequest("GET", "/foo", nil)
+	req.AddCookie(&http.Cookie{Name: "canary", Value: "true"})
+	if srv := r.Select(req, "foo", services); len(srv) != 1 || srv[0].Version != "v2" {
+		t.Fatalf("Expected v2 for cookie match got %+v", srv)
+	}
+
+	req = httptest.NewRequest("GET", "/foo", nil)
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		srv := r.Select(req, "foo", services)
+		if len(srv) != 1 {
+			t.Fatalf("Expected a single version got %d", len(srv))
+		}
+		counts[srv[0].Version]++
+	}
+
+	if counts["v2"] < 100 || counts["v2"] > 300 {
+		t.Fatalf("Expected roughly 20%% of traffic to v2 got %d", counts["v2"])
+	}
+
+	r.Delete("foo")
+	if srv := r.Select(req, "foo", services); len(srv) != 2 {
+		t.Fatalf("Expected all versions after delete got %d", len(srv))
+	}
+}
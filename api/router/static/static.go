@@ -22,6 +22,64 @@ type endpoint struct {
 	hostregs []*regexp.Regexp
 	pathregs []util.Pattern
 	pcreregs []*regexp.Regexp
+	routes   []*util.Route
+	// specificity of the path patterns and routes
+	pathspecs  [][]int
+	routespecs [][]int
+}
+
+// match returns the specificity and fields of the most specific path matching
+func (e *endpoint) match(path []string, urlPath string) ([]int, map[string]string, bool) {
+	var (
+		spec    []int
+		fields  map[string]string
+		matched bool
+	)
+
+	// try typed, regex and catch-all routes
+	for i, route := range e.routes {
+		params, ok := route.Match(path)
+		if !ok {
+			continue
+		}
+		if !matched || util.CompareSpecificity(e.routespecs[i], spec) > 0 {
+			spec, fields, matched = e.routespecs[i], params, true
+		}
+	}
+
+	// try google.api path
+	for i, pathreg := range e.pathregs {
+		params, err := pathreg.Match(path, "")
+		if err != nil {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("api gpath not match %s != %v", path, pathreg)
+			}
+			continue
+		}
+		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+			logger.Debugf("api gpath match %s = %v", path, pathreg)
+		}
+		if !matched || util.CompareSpecificity(e.pathspecs[i], spec) > 0 {
+			spec, fields, matched = e.pathspecs[i], params, true
+		}
+	}
+
+	if matched {
+		return spec, fields, true
+	}
+
+	// try path via pcre path matching, this has the lowest precedence
+	for _, pathreg := range e.pcreregs {
+		if !pathreg.MatchString(urlPath) {
+			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+				logger.Debugf("api pcre path not match %s != %v", urlPath, pathreg)
+			}
+			continue
+		}
+		return nil, nil, true
+	}
+
+	return nil, nil, false
 }
 
 // router is the default router
@@ -96,6 +154,8 @@ func (r *staticRouter) Register(ep *api.Endpoint) error {
 	var pathregs []util.Pattern
 	var hostregs []*regexp.Regexp
 	var pcreregs []*regexp.Regexp
+	var routes []*util.Route
+	var pathspecs, routespecs [][]int
 
 	for _, h := range ep.Host {
 		if h == "" || h == "*" {
@@ -111,6 +171,17 @@ func (r *staticRouter) Register(ep *api.Endpoint) error {
 	for _, p := range ep.Path {
 		var pcreok bool
 
+		// typed params, regex segments and catch-alls
+		if util.IsRoute(p) {
+			route, err := util.ParseRoute(p)
+			if err != nil {
+				return err
+			}
+			routes = append(routes, route)
+			routespecs = append(routespecs, util.Specificity(p))
+			continue
+		}
+
 		// pcre only when we have start and end markers
 		if p[0] == '^' && p[len(p)-1] == '$' {
 			pcrereg, err := regexp.CompilePOSIX(p)
@@ -133,14 +204,18 @@ func (r *staticRouter) Register(ep *api.Endpoint) error {
 			return err
 		}
 		pathregs = append(pathregs, pathreg)
+		pathspecs = append(pathspecs, util.Specificity(p))
 	}
 
 	r.Lock()
 	r.eps[ep.Name] = &endpoint{
-		apiep:    ep,
-		pcreregs: pcreregs,
-		pathregs: pathregs,
-		hostregs: hostregs,
+		apiep:      ep,
+		pcreregs:   pcreregs,
+		pathregs:   pathregs,
+		hostregs:   hostregs,
+		routes:     routes,
+		pathspecs:  pathspecs,
+		routespecs: routespecs,
 	}
 	r.Unlock()
 	return nil
@@ -233,11 +308,17 @@ func (r *staticRouter) endpoint(req *http.Request) (*endpoint, error) {
 		idx = 1
 	}
 	path := strings.Split(req.URL.Path[idx:], "/")
-	// use the first match
-	// TODO: weighted matching
 
-	for _, ep := range r.eps {
-		var mMatch, hMatch, pMatch bool
+	var (
+		match     *endpoint
+		matchName string
+		matchSpec []int
+		fields    map[string]string
+	)
+
+	// use the most specific match
+	for n, ep := range r.eps {
+		var mMatch, hMatch bool
 
 		// 1. try method
 		for _, m := range ep.apiep.Method {
@@ -276,53 +357,36 @@ func (r *staticRouter) endpoint(req *http.Request) (*endpoint, error) {
 			logger.Debugf("api host match %s", req.URL.Host)
 		}
 
-		// 3. try google.api path
-		for _, pathreg := range ep.pathregs {
-			matches, err := pathreg.Match(path, "")
-			if err != nil {
-				if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-					logger.Debugf("api gpath not match %s != %v", path, pathreg)
-				}
+		// 3. try path
+		spec, params, ok := ep.match(path, req.URL.Path)
+		if !ok {
+			continue
+		}
+
+		// ties are broken by name so routing is deterministic
+		if match != nil {
+			if c := util.CompareSpecificity(spec, matchSpec); c < 0 || (c == 0 && n > matchName) {
 				continue
 			}
-			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-				logger.Debugf("api gpath match %s = %v", path, pathreg)
-			}
-			pMatch = true
+		}
+
+		match, matchName, matchSpec, fields = ep, n, spec, params
+	}
+
+	if match != nil {
+		if fields != nil {
 			ctx := req.Context()
 			md, ok := metadata.FromContext(ctx)
 			if !ok {
 				md = make(metadata.Metadata)
 			}
-			for k, v := range matches {
-				md[fmt.Sprintf("x-api-field-%s", k)] = v
+			for k, v := range fields {
+				md.Set(fmt.Sprintf("x-api-field-%s", k), v)
 			}
-			md["x-api-body"] = ep.apiep.Body
+			md.Set("x-api-body", match.apiep.Body)
 			*req = *req.Clone(metadata.NewContext(ctx, md))
-			break
-		}
-
-		if !pMatch {
-			// 4. try path via pcre path matching
-			for _, pathreg := range ep.pcreregs {
-				if !pathreg.MatchString(req.URL.Path) {
-					if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-						logger.Debugf("api pcre path not match %s != %v", req.URL.Path, pathreg)
-					}
-					continue
-				}
-				pMatch = true
-				break
-			}
-		}
-
-		if !pMatch {
-			continue
 		}
-		// TODO: Percentage traffic
-
-		// we got here, so its a match
-		return ep, nil
+		return match, nil
 	}
 
 	// no match
@@ -340,6 +404,18 @@ func (r *staticRouter) Route(req *http.Request) (*api.Service, error) {
 		return nil, err
 	}
 
+	if r.opts.Policies != nil {
+		services := r.opts.Policies.Filter(req, ep.Services)
+		if len(services) == 0 {
+			return nil, fmt.Errorf("endpoint not found for %v", req.URL)
+		}
+		ep.Services = services
+	}
+
+	if r.opts.Rules != nil {
+		return r.opts.Rules.Route(req, ep), nil
+	}
+
 	return ep, nil
 }
 
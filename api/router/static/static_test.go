@@ -0,0 +1,64 @@
+package static
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/asim/go-micro/v3/api"
+	"github.com/asim/go-micro/v3/api/router"
+	"github.com/asim/go-micro/v3/api/router/rules"
+	"github.com/asim/go-micro/v3/registry"
+)
+
+func TestRouteRules(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+
+	for _, version := range []string{"v1", "v2"} {
+		if err := reg.Register(&registry.Service{
+			Name:    "foo",
+			Version: version,
+			Nodes:   []*registry.Node{{Id: "foo-" + version, Address: "127.0.0.1:8080"}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rs := rules.New()
+	r := NewRouter(router.WithRegistry(reg), router.WithRules(rs))
+	defer r.Close()
+
+	if err := r.Register(&api.Endpoint{
+		Name:    "foo.Bar",
+		Path:    []string{"/foo/bar"},
+		Method:  []string{"GET"},
+		Handler: "rpc",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	route := func(canary bool) []*registry.Service {
+		req := httptest.NewRequest("GET", "/foo/bar", nil)
+		if canary {
+			req.Header.Set("X-Canary", "true")
+		}
+		svc, err := r.Route(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return svc.Services
+	}
+
+	if srv := route(true); len(srv) != 2 {
+		t.Fatalf("Expected all versions without rules got %d", len(srv))
+	}
+
+	// rules set at runtime are applied
+	rs.Set("foo", rules.Rule{Version: "v2", Header: map[string]string{"X-Canary": "true"}})
+
+	if srv := route(true); len(srv) != 1 || srv[0].Version != "v2" {
+		t.Fatalf("Expected v2 for header match got %+v", srv)
+	}
+	if srv := route(false); len(srv) != 2 {
+		t.Fatalf("Expected all versions without a match got %d", len(srv))
+	}
+}
@@ -0,0 +1,255 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// segment kinds in order of precedence
+const (
+	kindCatchAll = iota + 1
+	kindParam
+	kindTyped
+	kindLiteral
+)
+
+var (
+	// Types are the named parameter types e.g /users/{id:int}
+	Types = map[string]string{
+		"int":   `-?[0-9]+`,
+		"uint":  `[0-9]+`,
+		"float": `-?[0-9]+(\.[0-9]+)?`,
+		"bool":  `true|false`,
+		"alpha": `[a-zA-Z]+`,
+		"alnum": `[a-zA-Z0-9]+`,
+		"uuid":  `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+	}
+)
+
+type routeSegment struct {
+	kind    int
+	literal string
+	name    string
+	re      *regexp.Regexp
+}
+
+// Route is a path template supporting typed parameters, regex segments and
+// catch-all wildcards e.g /users/{id:int}, /files/{name:[a-z]+\.txt} and
+// /static/{path...}. A catch-all must be the last segment and matches the
+// rest of the path. Bare * and ** match a segment and the rest of the path.
+type Route struct {
+	tmpl     string
+	segments []routeSegment
+}
+
+// IsRoute returns true if the template uses the route syntax rather
+// than the google.api http rule syntax handled by Parse
+func IsRoute(tmpl string) bool {
+	for _, s := range splitTemplate(tmpl) {
+		if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+			continue
+		}
+		if strings.Contains(s, ":") || strings.HasSuffix(s, "...}") {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseRoute parses a route template
+func ParseRoute(tmpl string) (*Route, error) {
+	if !strings.HasPrefix(tmpl, "/") {
+		return nil, InvalidTemplateError{tmpl: tmpl, msg: "no leading /"}
+	}
+
+	parts := splitTemplate(tmpl)
+	r := &Route{tmpl: tmpl}
+	names := make(map[string]bool)
+
+	for i, p := range parts {
+		var seg routeSegment
+
+		switch {
+		case p == "*":
+			seg.kind = kindParam
+		case p == "**":
+			seg.kind = kindCatchAll
+		case strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}"):
+			name := p[1 : len(p)-1]
+			var expr string
+
+			if idx := strings.Index(name, ":"); idx >= 0 {
+				name, expr = name[:idx], name[idx+1:]
+			}
+
+			switch {
+			case strings.HasSuffix(name, "...") && len(expr) == 0:
+				name = strings.TrimSuffix(name, "...")
+				seg.kind = kindCatchAll
+			case len(expr) == 0:
+				seg.kind = kindParam
+			default:
+				if v, ok := Types[expr]; ok {
+					expr = v
+				}
+				re, err := regexp.Compile("^(?:" + expr + ")$")
+				if err != nil {
+					return nil, InvalidTemplateError{tmpl: tmpl, msg: fmt.Sprintf("invalid regexp %s", expr)}
+				}
+				seg.kind = kindTyped
+				seg.re = re
+			}
+
+			if err := expectIdent(name); err != nil {
+				return nil, InvalidTemplateError{tmpl: tmpl, msg: fmt.Sprintf("invalid parameter %s", name)}
+			}
+			if names[name] {
+				return nil, InvalidTemplateError{tmpl: tmpl, msg: fmt.Sprintf("duplicate parameter %s", name)}
+			}
+			names[name] = true
+			seg.name = name
+		default:
+			if strings.ContainsAny(p, "{}") {
+				return nil, InvalidTemplateError{tmpl: tmpl, msg: fmt.Sprintf("invalid segment %s", p)}
+			}
+			seg.kind = kindLiteral
+			seg.literal = p
+		}
+
+		if seg.kind == kindCatchAll && i != len(parts)-1 {
+			return nil, InvalidTemplateError{tmpl: tmpl, msg: "catch-all must be the last segment"}
+		}
+
+		r.segments = append(r.segments, seg)
+	}
+
+	return r, nil
+}
+
+// Match matches the path components returning the parameters
+func (r *Route) Match(components []string) (map[string]string, bool) {
+	params := make(map[string]string)
+
+	for i, seg := range r.segments {
+		if seg.kind == kindCatchAll {
+			if len(seg.name) > 0 {
+				params[seg.name] = strings.Join(components[i:], "/")
+			}
+			return params, true
+		}
+
+		if i >= len(components) {
+			return nil, false
+		}
+
+		c := components[i]
+
+		switch seg.kind {
+		case kindLiteral:
+			if c != seg.literal {
+				return nil, false
+			}
+			continue
+		case kindTyped:
+			if !seg.re.MatchString(c) {
+				return nil, false
+			}
+		case kindParam:
+			if len(c) == 0 {
+				return nil, false
+			}
+		}
+
+		if len(seg.name) > 0 {
+			params[seg.name] = c
+		}
+	}
+
+	if len(components) != len(r.segments) {
+		return nil, false
+	}
+
+	return params, true
+}
+
+func (r *Route) String() string {
+	return r.tmpl
+}
+
+// Specificity returns the precedence of a path template, literal segments
+// take precedence over typed parameters, then parameters and then catch-alls.
+// Both the route and google.api http rule syntax are understood.
+func Specificity(tmpl string) []int {
+	var spec []int
+
+	for _, p := range splitTemplate(tmpl) {
+		switch {
+		case p == "*":
+			spec = append(spec, kindParam)
+		case p == "**":
+			spec = append(spec, kindCatchAll)
+		case strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}"):
+			name := p[1 : len(p)-1]
+			switch {
+			case strings.HasSuffix(name, "...") || strings.HasSuffix(name, "=**"):
+				spec = append(spec, kindCatchAll)
+			case strings.Contains(name, ":"):
+				spec = append(spec, kindTyped)
+			default:
+				spec = append(spec, kindParam)
+			}
+		default:
+			spec = append(spec, kindLiteral)
+		}
+	}
+
+	return spec
+}
+
+// CompareSpecificity returns 1 if a takes precedence over b, -1 if b
+// takes precedence over a and 0 if they're equal
+func CompareSpecificity(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] > b[i] {
+			return 1
+		}
+		if a[i] < b[i] {
+			return -1
+		}
+	}
+
+	// the longer path is more specific
+	switch {
+	case len(a) > len(b):
+		return 1
+	case len(a) < len(b):
+		return -1
+	}
+
+	return 0
+}
+
+// splitTemplate splits the template into segments ignoring slashes in braces
+func splitTemplate(tmpl string) []string {
+	tmpl = strings.TrimPrefix(tmpl, "/")
+
+	var parts []string
+	var depth, start int
+
+	for i, c := range tmpl {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '/':
+			if depth == 0 {
+				parts = append(parts, tmpl[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, tmpl[start:])
+}
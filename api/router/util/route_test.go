@@ -0,0 +1,87 @@
+package util
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRoute(t *testing.T) {
+	testData := []struct {
+		tmpl   string
+		path   string
+		match  bool
+		params map[string]string
+	}{
+		{"/users/{id:int}", "/users/123", true, map[string]string{"id": "123"}},
+		{"/users/{id:int}", "/users/abc", false, nil},
+		{"/users/{id:uuid}", "/users/4f1b4f2e-5b5c-4c7a-9a4c-0f5d3c8e2a11", true, map[string]string{"id": "4f1b4f2e-5b5c-4c7a-9a4c-0f5d3c8e2a11"}},
+		{"/files/{name:[a-z]+\\.txt}", "/files/notes.txt", true, map[string]string{"name": "notes.txt"}},
+		{"/files/{name:[a-z]+\\.txt}", "/files/notes.png", false, nil},
+		{"/codes/{code:[0-9]{3}}", "/codes/404", true, map[string]string{"code": "404"}},
+		{"/static/{path...}", "/static/css/app.css", true, map[string]string{"path": "css/app.css"}},
+		{"/static/{path...}", "/static", true, map[string]string{"path": ""}},
+		{"/users/{id:int}/posts", "/users/1/posts/2", false, nil},
+		{"/users/*/posts/{id:int}", "/users/john/posts/2", true, map[string]string{"id": "2"}},
+	}
+
+	for _, d := range testData {
+		if !IsRoute(d.tmpl) {
+			t.Fatalf("%s: expected route syntax", d.tmpl)
+		}
+
+		r, err := ParseRoute(d.tmpl)
+		if err != nil {
+			t.Fatalf("%s: %v", d.tmpl, err)
+		}
+
+		params, ok := r.Match(strings.Split(strings.TrimPrefix(d.path, "/"), "/"))
+		if ok != d.match {
+			t.Fatalf("%s: expected match %v for %s", d.tmpl, d.match, d.path)
+		}
+		if ok && !reflect.DeepEqual(params, d.params) {
+			t.Fatalf("%s: expected %v got %v", d.tmpl, d.params, params)
+		}
+	}
+}
+
+func TestParseRouteErrors(t *testing.T) {
+	for _, tmpl := range []string{
+		"users/{id:int}",
+		"/static/{path...}/foo",
+		"/users/{id:int}/{id:int}",
+		"/users/{id:[0-9}",
+		"/users/{1d:int}",
+	} {
+		if _, err := ParseRoute(tmpl); err == nil {
+			t.Fatalf("%s: expected error", tmpl)
+		}
+	}
+}
+
+func TestSpecificity(t *testing.T) {
+	// ordered from most to least specific
+	tmpls := []string{
+		"/users/me",
+		"/users/{id:int}",
+		"/users/{id}",
+		"/users/*",
+		"/users/{path...}",
+		"/users/{path=**}",
+	}
+
+	for i := 0; i < len(tmpls)-1; i++ {
+		a, b := Specificity(tmpls[i]), Specificity(tmpls[i+1])
+		if CompareSpecificity(a, b) < 0 {
+			t.Fatalf("expected %s to take precedence over %s", tmpls[i], tmpls[i+1])
+		}
+	}
+
+	if CompareSpecificity(Specificity("/users/{id}/posts"), Specificity("/users/{id}")) <= 0 {
+		t.Fatal("expected longer path to take precedence")
+	}
+
+	if IsRoute("/users/{id}") || IsRoute("/v1/{name=messages/*}") {
+		t.Fatal("expected google.api http rule syntax")
+	}
+}
@@ -0,0 +1,190 @@
+// Package access provides a http handler which writes structured access logs
+// and reports requests, labelled by the route resolved by the api handlers,
+// to reporters such as prometheus metrics
+package access
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/asim/go-micro/v3/api"
+	"github.com/asim/go-micro/v3/logger"
+)
+
+// Record is a completed request
+type Record struct {
+	Method   string
+	Path     string
+	Remote   string
+	Status   int
+	Size     int64
+	Duration time.Duration
+	// Service and Endpoint are set by the api handler which served the request
+	Service  string
+	Endpoint string
+}
+
+// Reporter is called with every completed request
+type Reporter func(Record)
+
+type Options struct {
+	// Logger access logs are written to, nil disables logging
+	Logger logger.Logger
+	// Reporters are called with every request
+	Reporters []Reporter
+}
+
+type Option func(o *Options)
+
+// WithLogger sets the access logger
+func WithLogger(l logger.Logger) Option {
+	return func(o *Options) {
+		o.Logger = l
+	}
+}
+
+// WithReporter adds a reporter e.g for metrics
+func WithReporter(r Reporter) Option {
+	return func(o *Options) {
+		o.Reporters = append(o.Reporters, r)
+	}
+}
+
+type routeKey struct{}
+
+// Route is the backend a request was routed to
+type Route struct {
+	Service  string
+	Endpoint string
+}
+
+// SetRoute records the route of the request, it's called by the api
+// handlers once they've resolved the backend service
+func SetRoute(ctx context.Context, service, endpoint string) {
+	if r, ok := ctx.Value(routeKey{}).(*Route); ok {
+		r.Service = service
+		r.Endpoint = endpoint
+	}
+}
+
+// Endpoint returns the endpoint the service was routed to if it's declared by
+// the service in the registry, otherwise "unknown". Routers build the endpoint
+// name from the url for undeclared endpoints so it can't be used as a label.
+func Endpoint(service *api.Service) string {
+	if service.Endpoint == nil {
+		return "unknown"
+	}
+
+	for _, s := range service.Services {
+		for _, ep := range s.Endpoints {
+			if ep.Name == service.Endpoint.Name {
+				return ep.Name
+			}
+		}
+	}
+
+	return "unknown"
+}
+
+type accessHandler struct {
+	opts    Options
+	handler http.Handler
+}
+
+func (a *accessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	route := new(Route)
+	rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+
+	a.handler.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), routeKey{}, route)))
+
+	rec := Record{
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		Remote:   r.RemoteAddr,
+		Status:   rw.status,
+		Size:     rw.size,
+		Duration: time.Since(start),
+		Service:  route.Service,
+		Endpoint: route.Endpoint,
+	}
+
+	if a.opts.Logger != nil {
+		a.opts.Logger.Fields(map[string]interface{}{
+			"method":   rec.Method,
+			"path":     rec.Path,
+			"remote":   rec.Remote,
+			"status":   rec.Status,
+			"size":     rec.Size,
+			"duration": rec.Duration.String(),
+			"service":  rec.Service,
+			"endpoint": rec.Endpoint,
+		}).Log(logger.InfoLevel, "access")
+	}
+
+	for _, report := range a.opts.Reporters {
+		report(rec)
+	}
+}
+
+// NewHandler wraps a handler with access logging and reporting
+func NewHandler(h http.Handler, opts ...Option) http.Handler {
+	options := Options{
+		Logger: logger.DefaultLogger,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &accessHandler{
+		opts:    options,
+		handler: h,
+	}
+}
+
+// NewWrapper returns a wrapper which can be used with server.WrapHandler
+func NewWrapper(opts ...Option) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return NewHandler(h, opts...)
+	}
+}
+
+// responseWriter records the status and size of the response
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("hijack not supported")
+	}
+	// upgraded connections e.g websockets
+	w.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
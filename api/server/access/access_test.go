@@ -0,0 +1,62 @@
+package access
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/asim/go-micro/v3/api"
+	"github.com/asim/go-micro/v3/registry"
+)
+
+func TestHandler(t *testing.T) {
+	var rec Record
+
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetRoute(r.Context(), "greeter", "Say.Hello")
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("hello"))
+	}), WithLogger(nil), WithReporter(func(r Record) {
+		rec = r
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/greeter/say/hello", nil))
+
+	if rec.Status != http.StatusTeapot {
+		t.Fatalf("Expected status %d got %d", http.StatusTeapot, rec.Status)
+	}
+	if rec.Size != 5 {
+		t.Fatalf("Expected size 5 got %d", rec.Size)
+	}
+	if rec.Service != "greeter" || rec.Endpoint != "Say.Hello" {
+		t.Fatalf("Expected route greeter Say.Hello got %s %s", rec.Service, rec.Endpoint)
+	}
+	if rec.Method != "POST" || rec.Path != "/greeter/say/hello" {
+		t.Fatalf("Unexpected request %s %s", rec.Method, rec.Path)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	services := []*registry.Service{{
+		Name:      "greeter",
+		Endpoints: []*registry.Endpoint{{Name: "Say.Hello"}},
+	}}
+
+	testData := []struct {
+		endpoint *api.Endpoint
+		expect   string
+	}{
+		{&api.Endpoint{Name: "Say.Hello"}, "Say.Hello"},
+		// built from the url by the router
+		{&api.Endpoint{Name: "Say.Anything"}, "unknown"},
+		{nil, "unknown"},
+	}
+
+	for _, d := range testData {
+		ep := Endpoint(&api.Service{Name: "greeter", Endpoint: d.endpoint, Services: services})
+		if ep != d.expect {
+			t.Fatalf("Expected endpoint %s got %s", d.expect, ep)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Package apikey provides a http handler which authenticates requests with api
+// keys issued by the auth/apikey package
+//
+// The key is read from the X-Api-Key header and removed. The verified key is
+// passed downstream as the X-Api-Key-Id, X-Api-Key-Name and X-Api-Key-Scopes
+// headers and request metadata, any such headers sent by the client are removed.
+package apikey
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/asim/go-micro/v3/auth/apikey"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/metadata"
+)
+
+const (
+	// Header the key is read from
+	Header = "X-Api-Key"
+	// HeaderPrefix is the prefix of headers set from the verified key
+	HeaderPrefix = "X-Api-Key-"
+)
+
+// Verifier verifies keys e.g *apikey.Keys
+type Verifier interface {
+	Verify(token string) (*apikey.Key, error)
+}
+
+type Options struct {
+	// Scopes the key must have
+	Scopes []string
+	// Optional allows requests without a key through
+	Optional bool
+}
+
+type Option func(o *Options)
+
+// WithScopes sets the scopes required to access any route
+func WithScopes(s ...string) Option {
+	return func(o *Options) {
+		o.Scopes = s
+	}
+}
+
+// Optional allows requests without a key, invalid keys are still rejected
+func Optional(b bool) Option {
+	return func(o *Options) {
+		o.Optional = b
+	}
+}
+
+type apikeyHandler struct {
+	opts     Options
+	verifier Verifier
+	handler  http.Handler
+}
+
+func (a *apikeyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// never trust keys sent by the client
+	for k := range r.Header {
+		if strings.HasPrefix(k, HeaderPrefix) {
+			r.Header.Del(k)
+		}
+	}
+
+	token := r.Header.Get(Header)
+	if len(token) == 0 {
+		if a.opts.Optional {
+			a.handler.ServeHTTP(w, r)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// the key is not passed downstream
+	r.Header.Del(Header)
+
+	key, err := a.verifier.Verify(token)
+	switch err {
+	case nil:
+	case apikey.ErrInvalidKey, apikey.ErrExpired:
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	default:
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("error verifying api key: %v", err)
+		}
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if !key.HasScopes(a.opts.Scopes...) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	md := metadata.Metadata{
+		HeaderPrefix + "Id":     key.ID,
+		HeaderPrefix + "Name":   key.Name,
+		HeaderPrefix + "Scopes": strings.Join(key.Scopes, " "),
+	}
+
+	for k, v := range md {
+		r.Header.Set(k, v)
+	}
+
+	ctx := metadata.MergeContext(r.Context(), md, true)
+	a.handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// NewHandler wraps a handler with api key authentication
+func NewHandler(h http.Handler, v Verifier, opts ...Option) http.Handler {
+	var options Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &apikeyHandler{
+		opts:     options,
+		verifier: v,
+		handler:  h,
+	}
+}
+
+// NewWrapper returns a wrapper which can be used with server.WrapHandler
+func NewWrapper(v Verifier, opts ...Option) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return NewHandler(h, v, opts...)
+	}
+}
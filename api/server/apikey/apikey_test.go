@@ -0,0 +1,71 @@
+package apikey
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/asim/go-micro/v3/auth/apikey"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/store"
+)
+
+func TestHandler(t *testing.T) {
+	keys := apikey.NewKeys(apikey.WithStore(store.NewMemoryStore()))
+
+	key, token, err := keys.Issue("test", apikey.WithScopes("read"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var id, raw string
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, _ = metadata.Get(r.Context(), HeaderPrefix+"Id")
+		raw = r.Header.Get(Header)
+	})
+
+	testData := []struct {
+		name   string
+		token  string
+		opts   []Option
+		status int
+	}{
+		{name: "valid", token: token, status: 200},
+		{name: "no key", status: 401},
+		{name: "optional", opts: []Option{Optional(true)}, status: 200},
+		{name: "invalid key", token: key.ID + ".bad", status: 401},
+		{name: "scope", token: token, opts: []Option{WithScopes("read")}, status: 200},
+		{name: "missing scope", token: token, opts: []Option{WithScopes("write")}, status: 403},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			id, raw = "", ""
+
+			req := httptest.NewRequest("GET", "/foo", nil)
+			req.Header.Set(HeaderPrefix+"Id", "spoofed")
+			if len(d.token) > 0 {
+				req.Header.Set(Header, d.token)
+			}
+
+			w := httptest.NewRecorder()
+			NewHandler(ok, keys, d.opts...).ServeHTTP(w, req)
+
+			if w.Code != d.status {
+				t.Fatalf("Expected status %d got %d", d.status, w.Code)
+			}
+			if d.status != 200 {
+				return
+			}
+			if len(raw) > 0 {
+				t.Fatal("Expected the key to be removed")
+			}
+			if len(d.token) > 0 && id != key.ID {
+				t.Fatalf("Expected key id %s got %s", key.ID, id)
+			}
+			if len(d.token) == 0 && len(id) > 0 {
+				t.Fatalf("Expected spoofed header to be removed got %s", id)
+			}
+		})
+	}
+}
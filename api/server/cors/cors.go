@@ -1,7 +1,12 @@
+// Package cors provides handlers which set CORS headers
 package cors
 
 import (
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // CombinedCORSHandler wraps a server and provides CORS headers
@@ -42,3 +47,149 @@ func SetHeaders(w http.ResponseWriter, r *http.Request) {
 	set(w, "Access-Control-Allow-Methods", "POST, PATCH, GET, OPTIONS, PUT, DELETE")
 	set(w, "Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
 }
+
+// Config is the CORS policy applied by a handler
+type Config struct {
+	// AllowOrigins is the list of allowed origins, "*" allows any and
+	// "*.example.com" allows any https subdomain on the default port, the
+	// scheme and port can be given e.g "http://*.example.com:8080"
+	AllowOrigins []string
+	// AllowMethods is the list of methods allowed in a preflight
+	AllowMethods []string
+	// AllowHeaders is the list of request headers allowed in a preflight
+	AllowHeaders []string
+	// ExposeHeaders is the list of response headers exposed to the client
+	ExposeHeaders []string
+	// AllowCredentials allows cookies and auth headers to be sent, it's not
+	// allowed for origins matched by "*"
+	AllowCredentials bool
+	// MaxAge is how long a preflight response can be cached
+	MaxAge time.Duration
+}
+
+// DefaultConfig returns the policy used by CombinedCORSHandler without
+// credentials, which can't be allowed for any origin
+func DefaultConfig() Config {
+	return Config{
+		AllowOrigins: []string{"*"},
+		AllowMethods: []string{"POST", "PATCH", "GET", "OPTIONS", "PUT", "DELETE"},
+		AllowHeaders: []string{"Accept", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization"},
+	}
+}
+
+// NewHandler wraps a handler and applies the CORS policy
+func NewHandler(h http.Handler, c Config) http.Handler {
+	return configHandler{
+		handler: h,
+		config:  c,
+	}
+}
+
+type configHandler struct {
+	handler http.Handler
+	config  Config
+}
+
+func (c configHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+
+	// not a cross origin request
+	if len(origin) == 0 {
+		c.handler.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Add("Vary", "Origin")
+
+	preflight := r.Method == "OPTIONS" && len(r.Header.Get("Access-Control-Request-Method")) > 0
+
+	allowed, wildcard := c.config.allowOrigin(origin)
+	if !allowed {
+		if preflight {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		c.handler.ServeHTTP(w, r)
+		return
+	}
+
+	switch {
+	case wildcard:
+		// never reflect any origin as that would allow credentials from it
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	case c.config.AllowCredentials:
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	default:
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+
+	if !preflight {
+		if len(c.config.ExposeHeaders) > 0 {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.config.ExposeHeaders, ", "))
+		}
+		c.handler.ServeHTTP(w, r)
+		return
+	}
+
+	if len(c.config.AllowMethods) > 0 {
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(c.config.AllowMethods, ", "))
+	}
+
+	if len(c.config.AllowHeaders) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(c.config.AllowHeaders, ", "))
+	}
+
+	if c.config.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.config.MaxAge.Seconds())))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// allowOrigin returns whether the origin is allowed and if it was by "*"
+func (c Config) allowOrigin(origin string) (bool, bool) {
+	var wildcard bool
+
+	for _, o := range c.AllowOrigins {
+		switch {
+		case o == origin:
+			return true, false
+		case o == "*":
+			wildcard = true
+		case strings.Contains(o, "*."):
+			if matchSubdomain(o, origin) {
+				return true, false
+			}
+		}
+	}
+
+	return wildcard, wildcard
+}
+
+// matchSubdomain matches the origin against a pattern such as *.example.com,
+// the scheme defaults to https and the port to that of the scheme
+func matchSubdomain(pattern, origin string) bool {
+	scheme := "https"
+	if i := strings.Index(pattern, "://"); i >= 0 {
+		scheme, pattern = pattern[:i], pattern[i+3:]
+	}
+
+	var port string
+	if i := strings.LastIndex(pattern, ":"); i >= 0 {
+		pattern, port = pattern[:i], pattern[i+1:]
+	}
+
+	if !strings.HasPrefix(pattern, "*.") {
+		return false
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme != scheme || u.Port() != port {
+		return false
+	}
+
+	// a subdomain rather than the domain itself
+	host := u.Hostname()
+	return len(host) > len(pattern)-1 && strings.HasSuffix(host, pattern[1:])
+}
@@ -0,0 +1,116 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConfigHandler(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	h := NewHandler(ok, Config{
+		AllowOrigins: []string{"https://foo.com", "*.bar.com"},
+		AllowMethods: []string{"GET", "POST"},
+		MaxAge:       time.Minute,
+	})
+
+	testData := []struct {
+		origin string
+		allow  bool
+	}{
+		{"https://foo.com", true},
+		{"https://api.bar.com", true},
+		{"https://baz.com", false},
+		{"http://api.bar.com", false},
+		{"https://api.bar.com:8080", false},
+		{"https://bar.com", false},
+		{"https://evilbar.com", false},
+	}
+
+	for _, d := range testData {
+		r := httptest.NewRequest("OPTIONS", "/", nil)
+		r.Header.Set("Origin", d.origin)
+		r.Header.Set("Access-Control-Request-Method", "POST")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if !d.allow {
+			if w.Code != http.StatusForbidden {
+				t.Fatalf("Expected 403 for %s got %d", d.origin, w.Code)
+			}
+			continue
+		}
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected 204 for %s got %d", d.origin, w.Code)
+		}
+		if v := w.Header().Get("Access-Control-Allow-Origin"); v != d.origin {
+			t.Fatalf("Expected origin %s got %s", d.origin, v)
+		}
+		if v := w.Header().Get("Access-Control-Allow-Methods"); v != "GET, POST" {
+			t.Fatalf("Unexpected methods %s", v)
+		}
+		if v := w.Header().Get("Access-Control-Max-Age"); v != "60" {
+			t.Fatalf("Unexpected max age %s", v)
+		}
+	}
+}
+
+func TestConfigHandlerWildcard(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	testData := []struct {
+		name        string
+		config      Config
+		origin      string
+		expect      string
+		credentials bool
+	}{
+		{
+			name:   "default",
+			config: DefaultConfig(),
+			origin: "https://evil.com",
+			expect: "*",
+		},
+		{
+			name:   "any with credentials",
+			config: Config{AllowOrigins: []string{"*"}, AllowCredentials: true},
+			origin: "https://evil.com",
+			expect: "*",
+		},
+		{
+			name:        "listed with credentials",
+			config:      Config{AllowOrigins: []string{"*", "https://foo.com"}, AllowCredentials: true},
+			origin:      "https://foo.com",
+			expect:      "https://foo.com",
+			credentials: true,
+		},
+		{
+			name:        "subdomain with scheme and port",
+			config:      Config{AllowOrigins: []string{"http://*.bar.com:8080"}, AllowCredentials: true},
+			origin:      "http://api.bar.com:8080",
+			expect:      "http://api.bar.com:8080",
+			credentials: true,
+		},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("Origin", d.origin)
+
+			w := httptest.NewRecorder()
+			NewHandler(ok, d.config).ServeHTTP(w, r)
+
+			if v := w.Header().Get("Access-Control-Allow-Origin"); v != d.expect {
+				t.Fatalf("Expected origin %s got %s", d.expect, v)
+			}
+			if v := w.Header().Get("Access-Control-Allow-Credentials") == "true"; v != d.credentials {
+				t.Fatalf("Expected credentials %v got %v", d.credentials, v)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/asim/go-micro/v3/api/server"
 	"github.com/asim/go-micro/v3/api/server/cors"
@@ -23,8 +24,15 @@ type httpServer struct {
 	exit    chan chan error
 }
 
+var (
+	// DefaultReadHeaderTimeout protects against clients which send headers slowly
+	DefaultReadHeaderTimeout = time.Second * 10
+)
+
 func NewServer(address string, opts ...server.Option) server.Server {
-	var options server.Options
+	options := server.Options{
+		ReadHeaderTimeout: DefaultReadHeaderTimeout,
+	}
 	for _, o := range opts {
 		o(&options)
 	}
@@ -58,8 +66,15 @@ func (s *httpServer) Handle(path string, handler http.Handler) {
 		handler = wrapper(handler)
 	}
 
+	// limit the request body
+	if s.opts.MaxBodySize > 0 {
+		handler = maxBodyHandler(handler, s.opts.MaxBodySize)
+	}
+
 	// wrap with cors
-	if s.opts.EnableCORS {
+	if s.opts.EnableCORS && s.opts.CORSConfig != nil {
+		handler = cors.NewHandler(handler, *s.opts.CORSConfig)
+	} else if s.opts.EnableCORS {
 		handler = cors.CombinedCORSHandler(handler)
 	}
 
@@ -94,8 +109,17 @@ func (s *httpServer) Start() error {
 	s.address = l.Addr().String()
 	s.mtx.Unlock()
 
+	srv := &http.Server{
+		Handler:           s.mux,
+		MaxHeaderBytes:    s.opts.MaxHeaderBytes,
+		ReadHeaderTimeout: s.opts.ReadHeaderTimeout,
+		ReadTimeout:       s.opts.ReadTimeout,
+		WriteTimeout:      s.opts.WriteTimeout,
+		IdleTimeout:       s.opts.IdleTimeout,
+	}
+
 	go func() {
-		if err := http.Serve(l, s.mux); err != nil {
+		if err := srv.Serve(l); err != nil {
 			// temporary fix
 			//logger.Fatal(err)
 		}
@@ -115,6 +139,19 @@ func (s *httpServer) Stop() error {
 	return <-ch
 }
 
+// maxBodyHandler rejects requests declaring a body over the limit up front and
+// enforces the limit as the body is read so chunked uploads can't exceed it
+func maxBodyHandler(h http.Handler, n int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > n {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, n)
+		h.ServeHTTP(w, r)
+	})
+}
+
 func (s *httpServer) String() string {
 	return "http"
 }
@@ -2,9 +2,13 @@ package http
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
+
+	"github.com/asim/go-micro/v3/api/server"
 )
 
 func TestHTTPServer(t *testing.T) {
@@ -39,3 +43,44 @@ func TestHTTPServer(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestMaxBodySize(t *testing.T) {
+	s := NewServer("localhost:0", server.MaxBodySize(8))
+
+	s.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := ioutil.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	url := fmt.Sprintf("http://%s/", s.Address())
+
+	testData := []struct {
+		body io.Reader
+		code int
+	}{
+		{strings.NewReader("small"), http.StatusOK},
+		{strings.NewReader("way too large"), http.StatusRequestEntityTooLarge},
+		// unknown length so the limit is enforced while reading
+		{ioutil.NopCloser(strings.NewReader("way too large")), http.StatusRequestEntityTooLarge},
+	}
+
+	for i, d := range testData {
+		rsp, err := http.Post(url, "text/plain", d.body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rsp.Body.Close()
+
+		if rsp.StatusCode != d.code {
+			t.Fatalf("%d: expected %d got %d", i, d.code, rsp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// refreshBackoff is how long to wait before refetching keys which are
+// unknown or failed to be fetched
+const refreshBackoff = time.Minute
+
+var (
+	// ErrKeyNotFound is returned when no key matches the token key id
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrKeySource is returned when the keys couldn't be fetched
+	ErrKeySource = errors.New("error fetching keys")
+)
+
+// JSONWebKey is a single key in a key set
+type JSONWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey is a key of the set and the algorithm it's restricted to, if any
+type publicKey struct {
+	key crypto.PublicKey
+	alg string
+}
+
+// keySet caches the keys fetched from a JWKS url
+type keySet struct {
+	opts Options
+
+	// concurrent refreshes share a single fetch
+	sg singleflight.Group
+
+	sync.RWMutex
+	keys    map[string]*publicKey
+	updated time.Time
+	// last failed refresh and its error
+	failed time.Time
+	err    error
+}
+
+// get returns the key with the given id, refreshing the set if it's stale
+// or the key is unknown, this handles key rotation
+func (k *keySet) get(kid string) (*publicKey, error) {
+	k.RLock()
+	key, ok := k.keys[kid]
+	stale := time.Since(k.updated) > k.opts.RefreshInterval
+	// don't refetch unknown keys more than once a minute
+	recent := time.Since(k.updated) < refreshBackoff
+	k.RUnlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+
+	if !ok && recent {
+		return nil, ErrKeyNotFound
+	}
+
+	if err := k.fetch(); err != nil {
+		// use the cached key if we failed to refresh
+		if ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrKeySource, err)
+	}
+
+	k.RLock()
+	defer k.RUnlock()
+
+	key, ok = k.keys[kid]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return key, nil
+}
+
+// fetch refreshes the keys, backing off after a failure so requests aren't
+// held up by a JWKS url which is unavailable
+func (k *keySet) fetch() error {
+	k.RLock()
+	failed, err := k.failed, k.err
+	k.RUnlock()
+
+	if time.Since(failed) < refreshBackoff {
+		return err
+	}
+
+	_, err, _ = k.sg.Do(k.opts.JWKS, func() (interface{}, error) {
+		err := k.refresh()
+
+		k.Lock()
+		if err != nil {
+			k.failed = time.Now()
+		} else {
+			k.failed = time.Time{}
+		}
+		k.err = err
+		k.Unlock()
+
+		return nil, err
+	})
+
+	return err
+}
+
+func (k *keySet) refresh() error {
+	rsp, err := k.opts.Client.Get(k.opts.JWKS)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error fetching keys: %s", rsp.Status)
+	}
+
+	var set struct {
+		Keys []JSONWebKey `json:"keys"`
+	}
+
+	if err := json.NewDecoder(rsp.Body).Decode(&set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]*publicKey, len(set.Keys))
+
+	for _, jwk := range set.Keys {
+		// skip encryption keys
+		if len(jwk.Use) > 0 && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = &publicKey{key: key, alg: jwk.Alg}
+	}
+
+	k.Lock()
+	k.keys = keys
+	k.updated = time.Now()
+	k.Unlock()
+
+	return nil
+}
+
+// PublicKey decodes the public key
+func (j JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", j.Crv)
+		}
+		x, err := decodeInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %s", j.Kty)
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
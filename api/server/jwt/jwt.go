@@ -0,0 +1,328 @@
+// Package jwt provides a http handler which validates JWTs signed by keys from a JWKS url
+//
+// Verified claims are passed downstream as the X-Jwt-Sub, X-Jwt-Iss and X-Jwt-Claims
+// headers and request metadata, any such headers sent by the client are removed.
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/metadata"
+)
+
+const (
+	// HeaderPrefix is the prefix of headers set from verified claims
+	HeaderPrefix = "X-Jwt-"
+)
+
+var (
+	// ErrInvalidToken is returned for malformed or badly signed tokens
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrExpired is returned when the token expired or isn't valid yet
+	ErrExpired = errors.New("token expired")
+	// ErrNoExpiry is returned for tokens without an expiry
+	ErrNoExpiry = errors.New("token has no expiry")
+)
+
+// Claims are the claims of a verified token
+type Claims map[string]interface{}
+
+// Subject returns the sub claim
+func (c Claims) Subject() string {
+	s, _ := c["sub"].(string)
+	return s
+}
+
+// Issuer returns the iss claim
+func (c Claims) Issuer() string {
+	s, _ := c["iss"].(string)
+	return s
+}
+
+// Audience returns the aud claim which may be a string or list
+func (c Claims) Audience() []string {
+	switch v := c["aud"].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var aud []string
+		for _, a := range v {
+			if s, ok := a.(string); ok {
+				aud = append(aud, s)
+			}
+		}
+		return aud
+	}
+	return nil
+}
+
+// Scopes returns the space separated scope claim
+func (c Claims) Scopes() []string {
+	s, _ := c["scope"].(string)
+	return strings.Fields(s)
+}
+
+func (c Claims) time(key string) (time.Time, bool) {
+	v, ok := c[key].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(v), 0), true
+}
+
+// Verifier validates tokens
+type Verifier struct {
+	opts Options
+	keys *keySet
+}
+
+// NewVerifier returns a verifier using the keys from the JWKS url
+func NewVerifier(opts ...Option) *Verifier {
+	options := NewOptions(opts...)
+
+	return &Verifier{
+		opts: options,
+		keys: &keySet{opts: options},
+	}
+}
+
+// Verify checks the signature, expiry, issuer and audience of the token
+func (v *Verifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	key, err := v.keys.get(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	now := time.Now()
+
+	exp, ok := claims.time("exp")
+	if !ok && !v.opts.AllowNoExpiry {
+		return nil, ErrNoExpiry
+	}
+	if ok && now.After(exp.Add(v.opts.Leeway)) {
+		return nil, ErrExpired
+	}
+
+	if nbf, ok := claims.time("nbf"); ok && now.Add(v.opts.Leeway).Before(nbf) {
+		return nil, ErrExpired
+	}
+
+	if len(v.opts.Issuer) > 0 && claims.Issuer() != v.opts.Issuer {
+		return nil, fmt.Errorf("invalid issuer %s", claims.Issuer())
+	}
+
+	if len(v.opts.Audience) > 0 && !contains(claims.Audience(), v.opts.Audience) {
+		return nil, errors.New("invalid audience")
+	}
+
+	return claims, nil
+}
+
+// curves are the curves of the ecdsa algorithms
+var curves = map[string]string{
+	"ES256": "P-256",
+	"ES384": "P-384",
+	"ES512": "P-521",
+}
+
+func verifySignature(alg string, key *publicKey, signed string, sig []byte) error {
+	var hash crypto.Hash
+
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported algorithm %s", alg)
+	}
+
+	// the token can't pick an algorithm other than that of the key
+	if len(key.alg) > 0 && key.alg != alg {
+		return ErrInvalidToken
+	}
+
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %s", alg)
+	}
+
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS":
+		pub, ok := key.key.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidToken
+		}
+		if err := rsa.VerifyPKCS1v15(pub, hash, digest, sig); err != nil {
+			return ErrInvalidToken
+		}
+	case "PS":
+		pub, ok := key.key.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidToken
+		}
+		if err := rsa.VerifyPSS(pub, hash, digest, sig, nil); err != nil {
+			return ErrInvalidToken
+		}
+	case "ES":
+		pub, ok := key.key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve.Params().Name != curves[alg] || len(sig)%2 != 0 {
+			return ErrInvalidToken
+		}
+		r := new(big.Int).SetBytes(sig[:len(sig)/2])
+		s := new(big.Int).SetBytes(sig[len(sig)/2:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return ErrInvalidToken
+		}
+	default:
+		// none and symmetric algorithms are not accepted
+		return fmt.Errorf("unsupported algorithm %s", alg)
+	}
+
+	return nil
+}
+
+func decodeSegment(s string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+type jwtHandler struct {
+	verifier *Verifier
+	handler  http.Handler
+}
+
+func (j *jwtHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// never trust claims sent by the client
+	for k := range r.Header {
+		if strings.HasPrefix(k, HeaderPrefix) {
+			r.Header.Del(k)
+		}
+	}
+
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		if j.verifier.opts.Optional {
+			j.handler.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := j.verifier.Verify(strings.TrimPrefix(auth, "Bearer "))
+	if errors.Is(err, ErrKeySource) {
+		// the token can't be verified through no fault of the client
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error verifying token: %v", err)
+		}
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	} else if err != nil {
+		// the reason isn't given to unauthenticated clients
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token", error_description="the token is invalid"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	scopes := claims.Scopes()
+	for _, s := range j.verifier.opts.Scopes {
+		if !contains(scopes, s) {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, strings.Join(j.verifier.opts.Scopes, " ")))
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+	}
+
+	b, _ := json.Marshal(claims)
+
+	md := metadata.Metadata{
+		HeaderPrefix + "Sub":    claims.Subject(),
+		HeaderPrefix + "Iss":    claims.Issuer(),
+		HeaderPrefix + "Claims": string(b),
+	}
+
+	for k, v := range md {
+		r.Header.Set(k, v)
+	}
+
+	ctx := metadata.MergeContext(r.Context(), md, true)
+	j.handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// NewHandler wraps a handler with token validation
+func NewHandler(h http.Handler, opts ...Option) http.Handler {
+	return &jwtHandler{
+		verifier: NewVerifier(opts...),
+		handler:  h,
+	}
+}
+
+// NewWrapper returns a wrapper which can be used with server.WrapHandler
+func NewWrapper(opts ...Option) func(http.Handler) http.Handler {
+	v := NewVerifier(opts...)
+
+	return func(h http.Handler) http.Handler {
+		return &jwtHandler{
+			verifier: v,
+			handler:  h,
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/metadata"
+)
+
+func sign(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	enc := func(v interface{}) string {
+		b, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	signed := enc(map[string]string{"alg": "RS256", "kid": kid}) + "." + enc(claims)
+
+	h := crypto.SHA256.New()
+	h.Write([]byte(signed))
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, h.Sum(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestHandler(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []JSONWebKey{{
+				Kid: "1",
+				Kty: "RSA",
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	defer jwks.Close()
+
+	var sub string
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sub, _ = metadata.Get(r.Context(), HeaderPrefix+"Sub")
+	})
+
+	h := NewHandler(ok,
+		WithJWKS(jwks.URL),
+		WithIssuer("https://issuer"),
+		WithAudience("api"),
+		WithScopes("read"),
+	)
+
+	exp := float64(time.Now().Add(time.Hour).Unix())
+
+	testData := []struct {
+		token string
+		code  int
+	}{
+		{"", http.StatusUnauthorized},
+		{"foo.bar.baz", http.StatusUnauthorized},
+		{sign(t, key, "1", map[string]interface{}{"sub": "john", "iss": "https://issuer", "aud": "api", "scope": "read write", "exp": exp}), http.StatusOK},
+		{sign(t, key, "1", map[string]interface{}{"sub": "john", "iss": "https://other", "aud": "api", "scope": "read", "exp": exp}), http.StatusUnauthorized},
+		{sign(t, key, "1", map[string]interface{}{"sub": "john", "iss": "https://issuer", "aud": []string{"web", "api"}, "scope": "read", "exp": float64(time.Now().Add(-time.Hour).Unix())}), http.StatusUnauthorized},
+		{sign(t, key, "2", map[string]interface{}{"sub": "john", "iss": "https://issuer", "aud": "api", "scope": "read", "exp": exp}), http.StatusUnauthorized},
+		{sign(t, key, "1", map[string]interface{}{"sub": "john", "iss": "https://issuer", "aud": "api", "scope": "write", "exp": exp}), http.StatusForbidden},
+		{sign(t, key, "1", map[string]interface{}{"sub": "john", "iss": "https://issuer", "aud": "api", "scope": "read"}), http.StatusUnauthorized},
+	}
+
+	for i, d := range testData {
+		sub = ""
+
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set(HeaderPrefix+"Sub", "spoofed")
+		if len(d.token) > 0 {
+			r.Header.Set("Authorization", "Bearer "+d.token)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != d.code {
+			t.Fatalf("%d: expected %d got %d %s", i, d.code, w.Code, w.Header().Get("WWW-Authenticate"))
+		}
+
+		if d.code == http.StatusOK && sub != "john" {
+			t.Fatalf("%d: expected sub john got %s", i, sub)
+		}
+	}
+}
+
+func TestAllowNoExpiry(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []JSONWebKey{{
+				Kid: "1",
+				Kty: "RSA",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	defer jwks.Close()
+
+	token := sign(t, key, "1", map[string]interface{}{"sub": "john"})
+
+	if _, err := NewVerifier(WithJWKS(jwks.URL)).Verify(token); err != ErrNoExpiry {
+		t.Fatalf("Expected ErrNoExpiry got %v", err)
+	}
+	if _, err := NewVerifier(WithJWKS(jwks.URL), AllowNoExpiry(true)).Verify(token); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeySetFailure(t *testing.T) {
+	var fetches int32
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		time.Sleep(time.Millisecond * 50)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer jwks.Close()
+
+	keys := &keySet{opts: NewOptions(WithJWKS(jwks.URL))}
+
+	// concurrent requests share a single fetch
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := keys.get("1"); err == nil {
+				t.Error("Expected an error fetching keys")
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the failure is cached rather than fetching again
+	if _, err := keys.get("1"); err == nil {
+		t.Fatal("Expected an error fetching keys")
+	}
+
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Fatalf("Expected 1 fetch got %d", n)
+	}
+}
+
+func TestKeyAlgorithm(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []JSONWebKey{{
+				Kid: "rsa",
+				Kty: "RSA",
+				Alg: "PS256",
+				N:   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+			}, {
+				Kid: "ec",
+				Kty: "EC",
+				Crv: "P-384",
+				X:   base64.RawURLEncoding.EncodeToString(ecKey.X.Bytes()),
+				Y:   base64.RawURLEncoding.EncodeToString(ecKey.Y.Bytes()),
+			}},
+		})
+	}))
+	defer jwks.Close()
+
+	v := NewVerifier(WithJWKS(jwks.URL), AllowNoExpiry(true))
+
+	// the key is restricted to PS256
+	if _, err := v.Verify(sign(t, rsaKey, "rsa", map[string]interface{}{"sub": "john"})); err != ErrInvalidToken {
+		t.Fatalf("Expected ErrInvalidToken for an alg other than the key's got %v", err)
+	}
+
+	// a P-384 key signing with ES256
+	enc := func(v interface{}) string {
+		b, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := enc(map[string]string{"alg": "ES256", "kid": "ec"}) + "." + enc(map[string]string{"sub": "john"})
+
+	h := crypto.SHA256.New()
+	h.Write([]byte(signed))
+
+	r, s, err := ecdsa.Sign(rand.Reader, ecKey, h.Sum(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := make([]byte, 96)
+	r.FillBytes(sig[:48])
+	s.FillBytes(sig[48:])
+
+	if _, err := v.Verify(signed + "." + base64.RawURLEncoding.EncodeToString(sig)); err != ErrInvalidToken {
+		t.Fatalf("Expected ErrInvalidToken for a curve other than the alg's got %v", err)
+	}
+}
+
+func TestHandlerKeySourceFailure(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer jwks.Close()
+
+	h := NewHandler(http.NotFoundHandler(), WithJWKS(jwks.URL))
+
+	testData := []struct {
+		token string
+		code  int
+	}{
+		// the keys can't be fetched
+		{sign(t, key, "1", map[string]interface{}{"sub": "john"}), http.StatusServiceUnavailable},
+		{"foo.bar.baz", http.StatusUnauthorized},
+	}
+
+	for i, d := range testData {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Authorization", "Bearer "+d.token)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != d.code {
+			t.Fatalf("%d: expected %d got %d", i, d.code, w.Code)
+		}
+		if v := w.Header().Get("WWW-Authenticate") + w.Body.String(); strings.Contains(v, jwks.URL) || strings.Contains(v, "fetching") {
+			t.Fatalf("%d: expected no internal errors got %q", i, v)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package jwt
+
+import (
+	"net/http"
+	"time"
+)
+
+type Options struct {
+	// JWKS url the signing keys are fetched from
+	JWKS string
+	// Issuer the token must be issued by, blank allows any
+	Issuer string
+	// Audience the token must be issued for, blank allows any
+	Audience string
+	// Scopes the token must have
+	Scopes []string
+	// Optional allows requests without a token through
+	Optional bool
+	// Leeway allowed when checking expiry
+	Leeway time.Duration
+	// AllowNoExpiry accepts tokens without an exp claim
+	AllowNoExpiry bool
+	// RefreshInterval is how often keys are refreshed
+	RefreshInterval time.Duration
+	// Client used to fetch keys
+	Client *http.Client
+}
+
+type Option func(o *Options)
+
+// NewOptions fills in the blanks
+func NewOptions(opts ...Option) Options {
+	options := Options{
+		Leeway:          time.Minute,
+		RefreshInterval: time.Hour,
+		Client:          &http.Client{Timeout: time.Second * 10},
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return options
+}
+
+// WithJWKS sets the url the signing keys are fetched from
+func WithJWKS(url string) Option {
+	return func(o *Options) {
+		o.JWKS = url
+	}
+}
+
+// WithIssuer sets the required issuer
+func WithIssuer(iss string) Option {
+	return func(o *Options) {
+		o.Issuer = iss
+	}
+}
+
+// WithAudience sets the required audience
+func WithAudience(aud string) Option {
+	return func(o *Options) {
+		o.Audience = aud
+	}
+}
+
+// WithScopes sets the scopes required to access any route
+func WithScopes(s ...string) Option {
+	return func(o *Options) {
+		o.Scopes = s
+	}
+}
+
+// Optional allows requests without a token, invalid tokens are still rejected
+func Optional(b bool) Option {
+	return func(o *Options) {
+		o.Optional = b
+	}
+}
+
+// WithLeeway sets the clock skew allowed when checking expiry
+func WithLeeway(d time.Duration) Option {
+	return func(o *Options) {
+		o.Leeway = d
+	}
+}
+
+// AllowNoExpiry accepts tokens without an exp claim, they're rejected by
+// default as they would be valid forever
+func AllowNoExpiry(b bool) Option {
+	return func(o *Options) {
+		o.AllowNoExpiry = b
+	}
+}
+
+// WithRefreshInterval sets how often the keys are refreshed
+func WithRefreshInterval(d time.Duration) Option {
+	return func(o *Options) {
+		o.RefreshInterval = d
+	}
+}
+
+// WithClient sets the http client used to fetch keys
+func WithClient(c *http.Client) Option {
+	return func(o *Options) {
+		o.Client = c
+	}
+}
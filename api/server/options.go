@@ -3,9 +3,11 @@ package server
 import (
 	"crypto/tls"
 	"net/http"
+	"time"
 
 	"github.com/asim/go-micro/v3/api/resolver"
 	"github.com/asim/go-micro/v3/api/server/acme"
+	"github.com/asim/go-micro/v3/api/server/cors"
 )
 
 type Option func(o *Options)
@@ -13,12 +15,26 @@ type Option func(o *Options)
 type Options struct {
 	EnableACME   bool
 	EnableCORS   bool
+	CORSConfig   *cors.Config
 	ACMEProvider acme.Provider
 	EnableTLS    bool
 	ACMEHosts    []string
 	TLSConfig    *tls.Config
 	Resolver     resolver.Resolver
 	Wrappers     []Wrapper
+
+	// MaxBodySize is the max request body in bytes, 0 is unlimited
+	MaxBodySize int64
+	// MaxHeaderBytes is the max size of request headers
+	MaxHeaderBytes int
+	// ReadHeaderTimeout is how long clients have to send headers
+	ReadHeaderTimeout time.Duration
+	// ReadTimeout is how long clients have to send the whole request
+	ReadTimeout time.Duration
+	// WriteTimeout is how long a response may take, this applies to streams
+	WriteTimeout time.Duration
+	// IdleTimeout is how long keep-alive connections are kept open
+	IdleTimeout time.Duration
 }
 
 type Wrapper func(h http.Handler) http.Handler
@@ -35,6 +51,14 @@ func EnableCORS(b bool) Option {
 	}
 }
 
+// CORSConfig enables CORS with the given policy
+func CORSConfig(c cors.Config) Option {
+	return func(o *Options) {
+		o.EnableCORS = true
+		o.CORSConfig = &c
+	}
+}
+
 func EnableACME(b bool) Option {
 	return func(o *Options) {
 		o.EnableACME = b
@@ -70,3 +94,46 @@ func Resolver(r resolver.Resolver) Option {
 		o.Resolver = r
 	}
 }
+
+// MaxBodySize limits the size of request bodies, larger requests get a 413
+func MaxBodySize(n int64) Option {
+	return func(o *Options) {
+		o.MaxBodySize = n
+	}
+}
+
+// MaxHeaderBytes limits the size of request headers
+func MaxHeaderBytes(n int) Option {
+	return func(o *Options) {
+		o.MaxHeaderBytes = n
+	}
+}
+
+// ReadHeaderTimeout sets how long clients have to send headers
+func ReadHeaderTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.ReadHeaderTimeout = d
+	}
+}
+
+// ReadTimeout sets how long clients have to send the whole request
+func ReadTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.ReadTimeout = d
+	}
+}
+
+// WriteTimeout sets how long a response may take. This also bounds
+// websocket and event streams so should be left unset if they're used
+func WriteTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.WriteTimeout = d
+	}
+}
+
+// IdleTimeout sets how long keep-alive connections are kept open
+func IdleTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.IdleTimeout = d
+	}
+}
@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/store"
+	"github.com/google/uuid"
+)
+
+// Counter tracks the number of requests made for a key within a window
+type Counter interface {
+	// Incr increments the count for the key returning the count and the time the window resets
+	Incr(key string, window time.Duration) (int64, time.Time, error)
+}
+
+type memoryCounter struct {
+	sync.Mutex
+	windows map[string]*window
+	// when expired windows were last cleared
+	swept time.Time
+}
+
+type window struct {
+	Count int64
+	Reset time.Time
+}
+
+func (m *memoryCounter) Incr(key string, d time.Duration) (int64, time.Time, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	now := time.Now()
+
+	// clear out the windows of idle callers once per window
+	if now.Sub(m.swept) > d {
+		for k, v := range m.windows {
+			if now.After(v.Reset) {
+				delete(m.windows, k)
+			}
+		}
+		m.swept = now
+	}
+
+	w, ok := m.windows[key]
+	if !ok || now.After(w.Reset) {
+		w = &window{Reset: now.Add(d)}
+		m.windows[key] = w
+	}
+
+	w.Count++
+
+	return w.Count, w.Reset, nil
+}
+
+// storeCounter writes a record per request rather than incrementing a
+// count so gateways sharing the store never overwrite each other, the count
+// is the number of records in the window
+type storeCounter struct {
+	store store.Store
+}
+
+func (s *storeCounter) Incr(key string, d time.Duration) (int64, time.Time, error) {
+	// windows are aligned so every gateway counts in the same one
+	start := time.Now().Truncate(d)
+	reset := start.Add(d)
+	prefix := fmt.Sprintf("%s/%d/", key, start.UnixNano())
+
+	rec := &store.Record{Key: prefix + uuid.New().String()}
+	if err := s.store.Write(rec, store.WriteExpiry(reset)); err != nil {
+		return 0, time.Time{}, err
+	}
+
+	// the count includes every request recorded before ours
+	keys, err := s.store.List(store.ListPrefix(prefix))
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	return int64(len(keys)), reset, nil
+}
+
+// NewMemoryCounter returns a counter local to the process
+func NewMemoryCounter() Counter {
+	return &memoryCounter{
+		windows: make(map[string]*window),
+	}
+}
+
+// NewStoreCounter returns a counter backed by a store e.g redis so limits
+// can be shared between gateways. Windows are aligned to the window duration
+// and each request is a record in the store until its window ends.
+func NewStoreCounter(s store.Store) Counter {
+	return &storeCounter{
+		store: s,
+	}
+}
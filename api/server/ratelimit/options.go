@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"net/http"
+	"time"
+)
+
+type Options struct {
+	// Rules applied in order, the first matching rule is used
+	Rules []Rule
+	// Limit used when no rule matches, 0 is unlimited
+	Limit int64
+	// Window the default limit applies to
+	Window time.Duration
+	// Counter used to track requests
+	Counter Counter
+	// Identity returns the caller identity of a request
+	Identity func(r *http.Request) string
+}
+
+type Option func(o *Options)
+
+// Rule limits the requests made to paths matching the pattern
+type Rule struct {
+	// Pattern is a path e.g /foo, a path.Match pattern e.g /foo/*/bar
+	// or a prefix when ending in /** e.g /foo/**
+	Pattern string
+	// Limit is the number of requests allowed within the window
+	Limit int64
+	// Window is the duration of the window
+	Window time.Duration
+}
+
+// NewOptions fills in the blanks
+func NewOptions(opts ...Option) Options {
+	options := Options{
+		Window: time.Minute,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if options.Counter == nil {
+		options.Counter = NewMemoryCounter()
+	}
+
+	if options.Identity == nil {
+		options.Identity = DefaultIdentity
+	}
+
+	return options
+}
+
+// WithRule adds a rule limiting requests matching the pattern
+func WithRule(pattern string, limit int64, window time.Duration) Option {
+	return func(o *Options) {
+		o.Rules = append(o.Rules, Rule{
+			Pattern: pattern,
+			Limit:   limit,
+			Window:  window,
+		})
+	}
+}
+
+// WithLimit sets the limit used when no rule matches
+func WithLimit(limit int64, window time.Duration) Option {
+	return func(o *Options) {
+		o.Limit = limit
+		o.Window = window
+	}
+}
+
+// WithCounter sets the counter used to track requests
+func WithCounter(c Counter) Option {
+	return func(o *Options) {
+		o.Counter = c
+	}
+}
+
+// WithIdentity sets the func used to identify the caller
+func WithIdentity(fn func(r *http.Request) string) Option {
+	return func(o *Options) {
+		o.Identity = fn
+	}
+}
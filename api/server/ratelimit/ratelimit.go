@@ -0,0 +1,145 @@
+// Package ratelimit provides a http handler which limits requests per route and caller
+//
+// Usage:
+//
+//	srv := http.NewServer(":8080", server.WrapHandler(ratelimit.NewWrapper(
+//		ratelimit.WithRule("/greeter/**", 100, time.Minute),
+//		ratelimit.WithLimit(1000, time.Minute),
+//	)))
+//
+// Callers are limited per account when the apikey or jwt handlers wrap the
+// rate limiter, otherwise per remote address.
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asim/go-micro/v3/api/server/apikey"
+	"github.com/asim/go-micro/v3/api/server/jwt"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/metadata"
+)
+
+type rateLimitHandler struct {
+	opts    Options
+	handler http.Handler
+}
+
+func (h *rateLimitHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	pattern, limit, window := h.match(r.URL.Path)
+
+	// unlimited
+	if limit <= 0 {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	key := pattern + ":" + h.opts.Identity(r)
+
+	count, reset, err := h.opts.Counter.Incr(key, window)
+	if err != nil {
+		// fail open rather than rejecting everything
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Rate limit counter error: %v", err)
+		}
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	remaining := limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	secs := strconv.Itoa(int(math.Ceil(time.Until(reset).Seconds())))
+
+	w.Header().Set("RateLimit-Limit", strconv.FormatInt(limit, 10))
+	w.Header().Set("RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+	w.Header().Set("RateLimit-Reset", secs)
+
+	if count > limit {
+		w.Header().Set("Retry-After", secs)
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+
+	h.handler.ServeHTTP(w, r)
+}
+
+// match returns the first rule matching the path or the default limit
+func (h *rateLimitHandler) match(p string) (string, int64, time.Duration) {
+	for _, rule := range h.opts.Rules {
+		if Match(rule.Pattern, p) {
+			return rule.Pattern, rule.Limit, rule.Window
+		}
+	}
+	return "*", h.opts.Limit, h.opts.Window
+}
+
+// Match returns true if the path matches the rule pattern
+func Match(pattern, p string) bool {
+	if strings.HasSuffix(pattern, "/**") {
+		prefix := strings.TrimSuffix(pattern, "**")
+		return strings.HasPrefix(p, prefix) || p == strings.TrimSuffix(prefix, "/")
+	}
+	if pattern == p {
+		return true
+	}
+	ok, _ := path.Match(pattern, p)
+	return ok
+}
+
+// DefaultIdentity identifies the caller by the account verified by the apikey
+// or jwt handlers or by the remote address. Credentials sent by the client are
+// never used as a caller could send a new one for every request.
+func DefaultIdentity(r *http.Request) string {
+	ctx := r.Context()
+
+	if id, ok := metadata.Get(ctx, apikey.HeaderPrefix+"Id"); ok && len(id) > 0 {
+		return hash("key:" + id)
+	}
+	if sub, ok := metadata.Get(ctx, jwt.HeaderPrefix+"Sub"); ok && len(sub) > 0 {
+		iss, _ := metadata.Get(ctx, jwt.HeaderPrefix+"Iss")
+		return hash("jwt:" + iss + ":" + sub)
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return hash("ip:" + host)
+}
+
+// hash keeps identities out of the counter keys
+func hash(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+// NewHandler wraps a handler with rate limiting
+func NewHandler(h http.Handler, opts ...Option) http.Handler {
+	return &rateLimitHandler{
+		opts:    NewOptions(opts...),
+		handler: h,
+	}
+}
+
+// NewWrapper returns a wrapper which can be used with server.WrapHandler
+func NewWrapper(opts ...Option) func(http.Handler) http.Handler {
+	options := NewOptions(opts...)
+
+	return func(h http.Handler) http.Handler {
+		return &rateLimitHandler{
+			opts:    options,
+			handler: h,
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/api/server/apikey"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/store"
+)
+
+func TestMatch(t *testing.T) {
+	testData := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"/foo", "/foo", true},
+		{"/foo", "/foo/bar", false},
+		{"/foo/*", "/foo/bar", true},
+		{"/foo/*/baz", "/foo/bar/baz", true},
+		{"/foo/**", "/foo", true},
+		{"/foo/**", "/foo/bar/baz", true},
+		{"/foo/**", "/foobar", false},
+	}
+
+	for _, d := range testData {
+		if v := Match(d.pattern, d.path); v != d.match {
+			t.Fatalf("Expected %v for pattern %s path %s got %v", d.match, d.pattern, d.path, v)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	h := NewHandler(ok, WithRule("/foo/**", 2, time.Minute))
+
+	// the account verified by the apikey handler
+	call := func(path, id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", path, nil)
+		r = r.WithContext(metadata.NewContext(r.Context(), metadata.Metadata{apikey.HeaderPrefix + "Id": id}))
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := call("/foo/bar", "a"); w.Code != 200 {
+			t.Fatalf("Expected 200 got %d", w.Code)
+		}
+	}
+
+	w := call("/foo/bar", "a")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 got %d", w.Code)
+	}
+	if v := w.Header().Get("RateLimit-Remaining"); v != "0" {
+		t.Fatalf("Expected 0 remaining got %s", v)
+	}
+	if v := w.Header().Get("Retry-After"); len(v) == 0 {
+		t.Fatal("Expected Retry-After header")
+	}
+
+	// a different caller has its own limit
+	if w := call("/foo/bar", "b"); w.Code != 200 {
+		t.Fatalf("Expected 200 got %d", w.Code)
+	}
+
+	// no default limit
+	if w := call("/bar", "a"); w.Code != 200 {
+		t.Fatalf("Expected 200 got %d", w.Code)
+	}
+}
+
+func TestDefaultIdentity(t *testing.T) {
+	request := func(addr, key string, md metadata.Metadata) *http.Request {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = addr
+		if len(key) > 0 {
+			r.Header.Set("X-Api-Key", key)
+			r.Header.Set("Authorization", "Bearer "+key)
+		}
+		if md != nil {
+			r = r.WithContext(metadata.NewContext(r.Context(), md))
+		}
+		return r
+	}
+
+	// credentials which weren't verified are ignored
+	a := DefaultIdentity(request("10.0.0.1:1234", "foo", nil))
+	b := DefaultIdentity(request("10.0.0.1:5678", "bar", nil))
+	if a != b {
+		t.Fatal("Expected the remote address to identify callers without an account")
+	}
+	if a == DefaultIdentity(request("10.0.0.2:1234", "", nil)) {
+		t.Fatal("Expected different remote addresses to be different callers")
+	}
+
+	key := DefaultIdentity(request("10.0.0.1:1234", "", metadata.Metadata{apikey.HeaderPrefix + "Id": "1"}))
+	if key == a {
+		t.Fatal("Expected the verified key to identify the caller")
+	}
+
+	jwt := DefaultIdentity(request("10.0.0.1:1234", "", metadata.Metadata{"X-Jwt-Iss": "foo", "X-Jwt-Sub": "1"}))
+	if jwt == a || jwt == key {
+		t.Fatal("Expected the verified token to identify the caller")
+	}
+
+	// identities are hashed
+	if len(key) != 64 || len(jwt) != 64 || len(a) != 64 {
+		t.Fatalf("Expected hashed identities got %s %s %s", key, jwt, a)
+	}
+}
+
+func TestMemoryCounterExpiry(t *testing.T) {
+	c := NewMemoryCounter().(*memoryCounter)
+
+	for i := 0; i < 10; i++ {
+		if _, _, err := c.Incr(strconv.Itoa(i), time.Millisecond*10); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	time.Sleep(time.Millisecond * 20)
+
+	// the windows of idle callers are cleared
+	if _, _, err := c.Incr("0", time.Millisecond*10); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.windows) != 1 {
+		t.Fatalf("Expected 1 window got %d", len(c.windows))
+	}
+}
+
+func TestStoreCounterShared(t *testing.T) {
+	s := store.NewMemoryStore()
+
+	// gateways sharing the store
+	counters := []Counter{NewStoreCounter(s), NewStoreCounter(s), NewStoreCounter(s), NewStoreCounter(s)}
+
+	const limit = 50
+
+	var wg sync.WaitGroup
+	var mtx sync.Mutex
+	var allowed int
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(c Counter) {
+			defer wg.Done()
+
+			count, _, err := c.Incr("key", time.Hour)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			mtx.Lock()
+			if count <= limit {
+				allowed++
+			}
+			mtx.Unlock()
+		}(counters[i%len(counters)])
+	}
+
+	wg.Wait()
+
+	if allowed > limit {
+		t.Fatalf("Expected at most %d requests allowed got %d", limit, allowed)
+	}
+
+	count, _, err := counters[0].Incr("key", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 101 {
+		t.Fatalf("Expected a count of 101 got %d", count)
+	}
+}
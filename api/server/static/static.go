@@ -0,0 +1,83 @@
+// Package static provides a http handler which serves files from a directory
+// with optional single page app fallback to the index
+package static
+
+import (
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type Options struct {
+	// SPA serves the index for unknown paths so client side routing works
+	SPA bool
+	// Index is the file served for directories and unknown paths
+	Index string
+}
+
+type Option func(o *Options)
+
+// SPA enables index fallback for unknown paths
+func SPA(b bool) Option {
+	return func(o *Options) {
+		o.SPA = b
+	}
+}
+
+// Index sets the index file, defaults to index.html
+func Index(name string) Option {
+	return func(o *Options) {
+		o.Index = name
+	}
+}
+
+type staticHandler struct {
+	opts Options
+	dir  string
+	fs   http.Handler
+}
+
+func (s *staticHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p := path.Clean("/" + r.URL.Path)
+
+	// serve files that exist as usual
+	if _, err := os.Stat(filepath.Join(s.dir, filepath.FromSlash(p))); err == nil || !s.opts.SPA {
+		s.fs.ServeHTTP(w, r)
+		return
+	}
+
+	// missing assets are a real 404
+	if len(path.Ext(p)) > 0 && !strings.HasSuffix(p, ".html") {
+		http.NotFound(w, r)
+		return
+	}
+
+	// only fallback for requests which could be page loads
+	if r.Method != "GET" && r.Method != "HEAD" {
+		http.NotFound(w, r)
+		return
+	}
+
+	// the index must not be cached so new deploys are picked up
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeFile(w, r, filepath.Join(s.dir, s.opts.Index))
+}
+
+// NewHandler returns a handler serving files from the directory
+func NewHandler(dir string, opts ...Option) http.Handler {
+	options := Options{
+		Index: "index.html",
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return &staticHandler{
+		opts: options,
+		dir:  dir,
+		fs:   http.FileServer(http.Dir(dir)),
+	}
+}
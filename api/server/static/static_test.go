@@ -0,0 +1,48 @@
+package static
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte("index"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "app.js"), []byte("app"), 0644)
+
+	testData := []struct {
+		spa  bool
+		path string
+		code int
+		body string
+	}{
+		{true, "/app.js", 200, "app"},
+		{true, "/", 200, "index"},
+		{true, "/users/1", 200, "index"},
+		{true, "/missing.js", 404, ""},
+		{false, "/users/1", 404, ""},
+	}
+
+	for _, d := range testData {
+		h := NewHandler(dir, SPA(d.spa))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", d.path, nil))
+
+		if w.Code != d.code {
+			t.Fatalf("%s: expected %d got %d", d.path, d.code, w.Code)
+		}
+		if len(d.body) > 0 && !strings.Contains(w.Body.String(), d.body) {
+			t.Fatalf("%s: expected %s got %s", d.path, d.body, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,273 @@
+// Package transform provides a http handler which rewrites requests and responses per route
+//
+// Rules can be loaded from config e.g
+//
+//	{
+//		"path": "^/v1/users/(.*)$",
+//		"request": {"rewrite": "/users/$1", "remove_headers": ["X-Internal"]},
+//		"response": {"rename_fields": {"user_name": "username"}, "remove_fields": ["password"]}
+//	}
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxBodySize is the max size of a body buffered to be transformed
+var MaxBodySize int64 = 10 * 1024 * 1024
+
+var (
+	errTooLarge = errors.New("body too large")
+	errUpgrade  = errors.New("upgraded responses can't be transformed")
+	errNotJSON  = errors.New("only json responses can be transformed")
+)
+
+// Rule is a set of transformations applied to requests matching the path
+type Rule struct {
+	// Path is a regular expression matched against the request path
+	Path string `json:"path"`
+	// Request transformations
+	Request Transform `json:"request"`
+	// Response transformations
+	Response Transform `json:"response"`
+}
+
+// Transform describes the changes made to a request or response
+type Transform struct {
+	// Rewrite replaces the request path, may reference groups from the rule path e.g $1
+	Rewrite string `json:"rewrite,omitempty"`
+	// SetHeaders sets the given headers
+	SetHeaders map[string]string `json:"set_headers,omitempty"`
+	// RemoveHeaders removes the given headers
+	RemoveHeaders []string `json:"remove_headers,omitempty"`
+	// RenameFields renames json fields, nested fields are dot separated e.g user.name.
+	// They're applied in the sorted order of the fields being renamed
+	RenameFields map[string]string `json:"rename_fields,omitempty"`
+	// RemoveFields removes json fields, nested fields are dot separated e.g user.password
+	RemoveFields []string `json:"remove_fields,omitempty"`
+}
+
+type rule struct {
+	Rule
+	re *regexp.Regexp
+}
+
+type transformHandler struct {
+	rules   []rule
+	handler http.Handler
+}
+
+func (t *transformHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var match *rule
+
+	for i, rl := range t.rules {
+		if rl.re.MatchString(r.URL.Path) {
+			match = &t.rules[i]
+			break
+		}
+	}
+
+	if match == nil {
+		t.handler.ServeHTTP(w, r)
+		return
+	}
+
+	if err := t.request(match, r); err == errTooLarge {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rsp := match.Response
+
+	// nothing to do to the body
+	if len(rsp.RenameFields) == 0 && len(rsp.RemoveFields) == 0 {
+		t.handler.ServeHTTP(&headerWriter{ResponseWriter: w, t: rsp}, r)
+		return
+	}
+
+	// fail closed as upgraded connections e.g websockets can't be transformed
+	if len(r.Header.Get("Upgrade")) > 0 {
+		http.Error(w, errUpgrade.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bw := &bufferWriter{header: make(http.Header), status: http.StatusOK, max: MaxBodySize}
+	t.handler.ServeHTTP(bw, r)
+
+	// fail closed rather than returning fields which should be removed
+	if bw.overflow {
+		http.Error(w, errTooLarge.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	body := bw.buf.Bytes()
+	switch {
+	case strings.Contains(bw.header.Get("Content-Type"), "json"):
+		b, err := transformJSON(rsp, body)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		body = b
+	case len(body) > 0:
+		// fail closed as the fields can't be found in other bodies
+		http.Error(w, errNotJSON.Error(), http.StatusBadGateway)
+		return
+	}
+
+	for k, v := range bw.header {
+		w.Header()[k] = v
+	}
+	setHeaders(w.Header(), rsp)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(bw.status)
+	w.Write(body)
+}
+
+func (t *transformHandler) request(rl *rule, r *http.Request) error {
+	req := rl.Request
+
+	setHeaders(r.Header, req)
+
+	if len(req.Rewrite) > 0 {
+		r.URL.Path = rl.re.ReplaceAllString(r.URL.Path, req.Rewrite)
+		r.URL.RawPath = ""
+		r.RequestURI = r.URL.RequestURI()
+	}
+
+	if len(req.RenameFields) == 0 && len(req.RemoveFields) == 0 {
+		return nil
+	}
+
+	if r.Body == nil || !strings.Contains(r.Header.Get("Content-Type"), "json") {
+		return nil
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if _, err := buf.ReadFrom(io.LimitReader(r.Body, MaxBodySize+1)); err != nil {
+		return err
+	}
+	r.Body.Close()
+
+	if int64(buf.Len()) > MaxBodySize {
+		return errTooLarge
+	}
+
+	b, err := transformJSON(req, buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	r.Body = readCloser{bytes.NewReader(b)}
+	r.ContentLength = int64(len(b))
+	r.Header.Set("Content-Length", strconv.Itoa(len(b)))
+
+	return nil
+}
+
+func setHeaders(h http.Header, t Transform) {
+	for _, k := range t.RemoveHeaders {
+		h.Del(k)
+	}
+	for k, v := range t.SetHeaders {
+		h.Set(k, v)
+	}
+}
+
+// transformJSON renames and removes fields in a json document
+func transformJSON(t Transform, b []byte) ([]byte, error) {
+	// numbers are kept as is rather than losing precision as floats
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid json")
+	}
+
+	// renames are applied in order of the fields so chained renames are stable
+	renames := make([]string, 0, len(t.RenameFields))
+	for from := range t.RenameFields {
+		renames = append(renames, from)
+	}
+	sort.Strings(renames)
+
+	for _, from := range renames {
+		to := t.RenameFields[from]
+		v = apply(v, strings.Split(from, "."), func(m map[string]interface{}, k string) {
+			if val, ok := m[k]; ok {
+				delete(m, k)
+				m[to] = val
+			}
+		})
+	}
+
+	for _, field := range t.RemoveFields {
+		v = apply(v, strings.Split(field, "."), func(m map[string]interface{}, k string) {
+			delete(m, k)
+		})
+	}
+
+	return json.Marshal(v)
+}
+
+// apply walks the path calling fn on the object holding the last key
+func apply(v interface{}, path []string, fn func(map[string]interface{}, string)) interface{} {
+	switch val := v.(type) {
+	case []interface{}:
+		for i := range val {
+			val[i] = apply(val[i], path, fn)
+		}
+	case map[string]interface{}:
+		if len(path) == 1 {
+			fn(val, path[0])
+			break
+		}
+		if next, ok := val[path[0]]; ok {
+			val[path[0]] = apply(next, path[1:], fn)
+		}
+	}
+	return v
+}
+
+// NewHandler wraps a handler applying the rules
+func NewHandler(h http.Handler, rules ...Rule) (http.Handler, error) {
+	t := &transformHandler{handler: h}
+
+	for _, r := range rules {
+		re, err := regexp.Compile(r.Path)
+		if err != nil {
+			return nil, err
+		}
+		t.rules = append(t.rules, rule{Rule: r, re: re})
+	}
+
+	return t, nil
+}
+
+// NewWrapper returns a wrapper which can be used with server.WrapHandler
+func NewWrapper(rules ...Rule) (func(http.Handler) http.Handler, error) {
+	// validate the rules up front
+	if _, err := NewHandler(nil, rules...); err != nil {
+		return nil, err
+	}
+
+	return func(h http.Handler) http.Handler {
+		t, _ := NewHandler(h, rules...)
+		return t
+	}, nil
+}
@@ -0,0 +1,188 @@
+package transform
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	backend := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/1" {
+			t.Fatalf("Expected rewritten path /users/1 got %s", r.URL.Path)
+		}
+		if v := r.Header.Get("X-Internal"); len(v) > 0 {
+			t.Fatalf("Expected X-Internal to be removed got %s", v)
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		if string(b) != `{"name":"john"}` {
+			t.Fatalf("Unexpected request body %s", string(b))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"user":{"user_name":"john","password":"secret"}}`))
+	})
+
+	h, err := NewHandler(backend, Rule{
+		Path: "^/v1/users/(.*)$",
+		Request: Transform{
+			Rewrite:       "/users/$1",
+			RemoveHeaders: []string{"X-Internal"},
+			RenameFields:  map[string]string{"username": "name"},
+		},
+		Response: Transform{
+			SetHeaders:   map[string]string{"X-Version": "v1"},
+			RenameFields: map[string]string{"user.user_name": "username"},
+			RemoveFields: []string{"user.password"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("POST", "/v1/users/1", strings.NewReader(`{"username":"john"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Internal", "true")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if v := w.Body.String(); v != `{"user":{"username":"john"}}` {
+		t.Fatalf("Unexpected response body %s", v)
+	}
+	if v := w.Header().Get("X-Version"); v != "v1" {
+		t.Fatalf("Expected X-Version header got %s", v)
+	}
+}
+
+func TestTransformJSON(t *testing.T) {
+	testData := []struct {
+		name   string
+		t      Transform
+		body   string
+		expect string
+	}{
+		{
+			name:   "large numbers",
+			t:      Transform{RemoveFields: []string{"password"}},
+			body:   `{"id":9007199254740993,"ts":1634371200123456789,"ratio":0.1,"password":"secret"}`,
+			expect: `{"id":9007199254740993,"ratio":0.1,"ts":1634371200123456789}`,
+		},
+		{
+			name:   "chained renames",
+			t:      Transform{RenameFields: map[string]string{"a": "b", "b": "c", "c": "d"}},
+			body:   `{"a":1}`,
+			expect: `{"d":1}`,
+		},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			// map order is random so run it a few times
+			for i := 0; i < 20; i++ {
+				b, err := transformJSON(d.t, []byte(d.body))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(b) != d.expect {
+					t.Fatalf("Expected %s got %s", d.expect, string(b))
+				}
+			}
+		})
+	}
+
+	if _, err := transformJSON(Transform{}, []byte(`{} {}`)); err == nil {
+		t.Fatal("Expected an error for trailing data")
+	}
+}
+
+func TestInvalidRule(t *testing.T) {
+	if _, err := NewWrapper(Rule{Path: "(("}); err == nil {
+		t.Fatal("Expected error for invalid path")
+	}
+}
+
+func TestFailClosed(t *testing.T) {
+	testData := []struct {
+		name        string
+		contentType string
+		body        string
+		upgrade     bool
+		code        int
+	}{
+		{name: "malformed json", body: `{"password":"secret"`, code: http.StatusInternalServerError},
+		{name: "too large", body: `{"password":"` + strings.Repeat("a", 64) + `"}`, code: http.StatusInternalServerError},
+		{name: "upgrade", body: `{"password":"secret"}`, upgrade: true, code: http.StatusInternalServerError},
+		{name: "not json", contentType: "text/plain", body: `password=secret`, code: http.StatusBadGateway},
+		{name: "empty", contentType: "text/plain", code: http.StatusOK},
+		{name: "valid", body: `{"password":"secret"}`, code: http.StatusOK},
+	}
+
+	defer func(n int64) { MaxBodySize = n }(MaxBodySize)
+	MaxBodySize = 32
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			backend := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ct := d.contentType
+				if len(ct) == 0 {
+					ct = "application/json"
+				}
+				w.Header().Set("Content-Type", ct)
+				w.Write([]byte(d.body))
+			})
+
+			h, err := NewHandler(backend, Rule{
+				Path:     "^/",
+				Response: Transform{RemoveFields: []string{"password"}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := httptest.NewRequest("GET", "/", nil)
+			if d.upgrade {
+				r.Header.Set("Connection", "Upgrade")
+				r.Header.Set("Upgrade", "websocket")
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != d.code {
+				t.Fatalf("Expected %d got %d", d.code, w.Code)
+			}
+			if strings.Contains(w.Body.String(), "secret") {
+				t.Fatalf("Expected the password to be removed got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequestTooLarge(t *testing.T) {
+	defer func(n int64) { MaxBodySize = n }(MaxBodySize)
+	MaxBodySize = 32
+
+	backend := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("Expected the request to be rejected")
+	})
+
+	h, err := NewHandler(backend, Rule{
+		Path:    "^/",
+		Request: Transform{RemoveFields: []string{"password"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"password":"`+strings.Repeat("a", 64)+`"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected 413 got %d", w.Code)
+	}
+}
@@ -0,0 +1,79 @@
+package transform
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+)
+
+type readCloser struct {
+	io.Reader
+}
+
+func (readCloser) Close() error {
+	return nil
+}
+
+// headerWriter applies header transformations before the response is written
+type headerWriter struct {
+	http.ResponseWriter
+	t           Transform
+	wroteHeader bool
+}
+
+func (h *headerWriter) WriteHeader(code int) {
+	if !h.wroteHeader {
+		h.wroteHeader = true
+		setHeaders(h.ResponseWriter.Header(), h.t)
+	}
+	h.ResponseWriter.WriteHeader(code)
+}
+
+func (h *headerWriter) Write(b []byte) (int, error) {
+	if !h.wroteHeader {
+		h.WriteHeader(http.StatusOK)
+	}
+	return h.ResponseWriter.Write(b)
+}
+
+func (h *headerWriter) Flush() {
+	if fl, ok := h.ResponseWriter.(http.Flusher); ok {
+		fl.Flush()
+	}
+}
+
+func (h *headerWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hj, ok := h.ResponseWriter.(http.Hijacker); ok {
+		return hj.Hijack()
+	}
+	return nil, nil, errors.New("hijacker not supported")
+}
+
+// bufferWriter buffers the response so the body can be transformed
+type bufferWriter struct {
+	header http.Header
+	status int
+	buf    bytes.Buffer
+	// max size of the body, overflow is set if it's exceeded
+	max      int64
+	overflow bool
+}
+
+func (b *bufferWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferWriter) WriteHeader(code int) {
+	b.status = code
+}
+
+func (b *bufferWriter) Write(p []byte) (int, error) {
+	if int64(b.buf.Len()+len(p)) > b.max {
+		b.overflow = true
+		return 0, errTooLarge
+	}
+	return b.buf.Write(p)
+}
@@ -0,0 +1,226 @@
+// Package apikey issues, lists, revokes and verifies api keys. Only a hash of
+// the key secret is kept in the store, the key is returned once when issued.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/asim/go-micro/v3/auth"
+	"github.com/asim/go-micro/v3/store"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidKey is returned for malformed or unknown keys
+	ErrInvalidKey = errors.New("invalid api key")
+	// ErrExpired is returned when the key expired
+	ErrExpired = errors.New("api key expired")
+	// ErrNotFound is returned when revoking an unknown key
+	ErrNotFound = errors.New("api key not found")
+)
+
+// Key is an issued api key, the secret is never stored
+type Key struct {
+	// ID of the key, it's the first part of the key
+	ID string `json:"id"`
+	// Name describing the key
+	Name string `json:"name"`
+	// Scopes granted to the key
+	Scopes []string `json:"scopes"`
+	// Metadata of the key e.g the owner
+	Metadata map[string]string `json:"metadata"`
+	// Created time of the key
+	Created time.Time `json:"created"`
+	// Expiry of the key, zero if it never expires
+	Expiry time.Time `json:"expiry,omitempty"`
+}
+
+// Expired returns true if the key expired
+func (k *Key) Expired() bool {
+	return !k.Expiry.IsZero() && time.Now().After(k.Expiry)
+}
+
+// HasScopes returns true if the key was granted all the scopes
+func (k *Key) HasScopes(scopes ...string) bool {
+	for _, s := range scopes {
+		var found bool
+		for _, ks := range k.Scopes {
+			if ks == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Account returns the account of the key for use with auth rules
+func (k *Key) Account() *auth.Account {
+	return &auth.Account{
+		ID:       k.ID,
+		Type:     "apikey",
+		Scopes:   k.Scopes,
+		Metadata: k.Metadata,
+	}
+}
+
+// record is the key kept in the store
+type record struct {
+	Key
+	// Hash of the key secret
+	Hash string `json:"hash"`
+}
+
+// Keys manages the api keys in the store
+type Keys struct {
+	opts Options
+}
+
+// NewKeys returns the api keys kept in the store
+func NewKeys(opts ...Option) *Keys {
+	return &Keys{
+		opts: NewOptions(opts...),
+	}
+}
+
+// Issue issues a key, the returned token is the key to give to the client
+// and can't be retrieved again
+func (k *Keys) Issue(name string, opts ...IssueOption) (*Key, string, error) {
+	var options IssueOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	r := &record{
+		Key: Key{
+			ID:       uuid.New().String(),
+			Name:     name,
+			Scopes:   options.Scopes,
+			Metadata: options.Metadata,
+			Created:  time.Now(),
+		},
+		Hash: hash(token),
+	}
+
+	var wopts []store.WriteOption
+	if options.TTL > 0 {
+		r.Expiry = r.Created.Add(options.TTL)
+		wopts = append(wopts, store.WriteExpiry(r.Expiry))
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := k.opts.Store.Write(&store.Record{
+		Key:   k.opts.Prefix + r.ID,
+		Value: b,
+	}, wopts...); err != nil {
+		return nil, "", err
+	}
+
+	key := r.Key
+	return &key, r.ID + "." + token, nil
+}
+
+// List returns the keys which haven't expired ordered by creation
+func (k *Keys) List() ([]*Key, error) {
+	recs, err := k.opts.Store.Read(k.opts.Prefix, store.ReadPrefix())
+	if err != nil && err != store.ErrNotFound {
+		return nil, err
+	}
+
+	keys := make([]*Key, 0, len(recs))
+	for _, rec := range recs {
+		var r record
+		if err := json.Unmarshal(rec.Value, &r); err != nil {
+			return nil, err
+		}
+		if r.Expired() {
+			continue
+		}
+		key := r.Key
+		keys = append(keys, &key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Created.Before(keys[j].Created)
+	})
+
+	return keys, nil
+}
+
+// Revoke deletes the key
+func (k *Keys) Revoke(id string) error {
+	if _, err := k.read(id); err != nil {
+		return err
+	}
+	return k.opts.Store.Delete(k.opts.Prefix + id)
+}
+
+// Verify returns the key if the token is a valid key
+func (k *Keys) Verify(token string) (*Key, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return nil, ErrInvalidKey
+	}
+
+	r, err := k.read(parts[0])
+	if err == ErrNotFound {
+		return nil, ErrInvalidKey
+	} else if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hash(parts[1])), []byte(r.Hash)) != 1 {
+		return nil, ErrInvalidKey
+	}
+	if r.Expired() {
+		return nil, ErrExpired
+	}
+
+	key := r.Key
+	return &key, nil
+}
+
+func (k *Keys) read(id string) (*record, error) {
+	recs, err := k.opts.Store.Read(k.opts.Prefix + id)
+	if err == store.ErrNotFound || (err == nil && len(recs) == 0) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	var r record
+	if err := json.Unmarshal(recs[0].Value, &r); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// hash returns the hex encoded sha256 of the secret, the secret is random
+// so a slow hash isn't needed
+func hash(secret string) string {
+	h := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,94 @@
+package apikey
+
+import (
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/store"
+)
+
+func TestKeys(t *testing.T) {
+	keys := NewKeys(WithStore(store.NewMemoryStore()))
+
+	key, token, err := keys.Issue("test", WithScopes("read", "write"), WithMetadata(map[string]string{"owner": "foo"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := keys.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.ID != key.ID || v.Name != "test" || v.Metadata["owner"] != "foo" {
+		t.Fatalf("Unexpected key %+v", v)
+	}
+	if !v.HasScopes("read", "write") || v.HasScopes("admin") {
+		t.Fatalf("Unexpected scopes %v", v.Scopes)
+	}
+
+	// the secret is checked
+	if _, err := keys.Verify(key.ID + ".bad"); err != ErrInvalidKey {
+		t.Fatalf("Expected invalid key got %v", err)
+	}
+	for _, bad := range []string{"", "bad", "bad.bad", key.ID + "."} {
+		if _, err := keys.Verify(bad); err != ErrInvalidKey {
+			t.Fatalf("Expected invalid key for %q got %v", bad, err)
+		}
+	}
+
+	if _, _, err := keys.Issue("other"); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := keys.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].ID != key.ID {
+		t.Fatalf("Expected 2 keys got %+v", list)
+	}
+
+	if err := keys.Revoke(key.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.Revoke(key.ID); err != ErrNotFound {
+		t.Fatalf("Expected not found got %v", err)
+	}
+	if _, err := keys.Verify(token); err != ErrInvalidKey {
+		t.Fatalf("Expected invalid key after revoke got %v", err)
+	}
+
+	list, err = keys.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("Expected 1 key got %d", len(list))
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	keys := NewKeys(WithStore(store.NewMemoryStore()))
+
+	_, token, err := keys.Issue("test", WithTTL(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Verify(token); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if _, err := keys.Verify(token); err != ErrExpired && err != ErrInvalidKey {
+		t.Fatalf("Expected expired key got %v", err)
+	}
+
+	list, err := keys.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 0 {
+		t.Fatalf("Expected no keys got %d", len(list))
+	}
+}
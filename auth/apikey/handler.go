@@ -0,0 +1,123 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"github.com/asim/go-micro/v3/errors"
+)
+
+type IssueRequest struct {
+	Name     string            `json:"name"`
+	Scopes   []string          `json:"scopes"`
+	Metadata map[string]string `json:"metadata"`
+	// TTL of the key in seconds, the key never expires if zero
+	TTL int64 `json:"ttl"`
+}
+
+type IssueResponse struct {
+	Key *Key `json:"key"`
+	// Token is the api key, it's only returned when issued
+	Token string `json:"token"`
+}
+
+type ListRequest struct{}
+
+type ListResponse struct {
+	Keys []*Key `json:"keys"`
+}
+
+type RevokeRequest struct {
+	ID string `json:"id"`
+}
+
+type RevokeResponse struct{}
+
+type VerifyRequest struct {
+	Token string `json:"token"`
+}
+
+type VerifyResponse struct {
+	Key *Key `json:"key"`
+}
+
+// APIKey is a handler managing the keys, access to it should be restricted
+// with auth rules e.g
+//
+//	micro.RegisterHandler(service.Server(), apikey.NewHandler(keys))
+type APIKey struct {
+	id   string
+	keys *Keys
+}
+
+// NewHandler returns the handler managing the keys
+func NewHandler(keys *Keys) *APIKey {
+	return &APIKey{
+		id:   "go.micro.apikey",
+		keys: keys,
+	}
+}
+
+// Issue issues a key
+func (a *APIKey) Issue(ctx context.Context, req *IssueRequest, rsp *IssueResponse) error {
+	if len(req.Name) == 0 {
+		return errors.BadRequest(a.id, "name required")
+	}
+	if req.TTL < 0 {
+		return errors.BadRequest(a.id, "invalid ttl %d", req.TTL)
+	}
+
+	key, token, err := a.keys.Issue(req.Name,
+		WithScopes(req.Scopes...),
+		WithMetadata(req.Metadata),
+		WithTTL(time.Duration(req.TTL)*time.Second),
+	)
+	if err != nil {
+		return errors.InternalServerError(a.id, "%v", err)
+	}
+
+	rsp.Key = key
+	rsp.Token = token
+
+	return nil
+}
+
+// List lists the keys
+func (a *APIKey) List(ctx context.Context, req *ListRequest, rsp *ListResponse) error {
+	keys, err := a.keys.List()
+	if err != nil {
+		return errors.InternalServerError(a.id, "%v", err)
+	}
+	rsp.Keys = keys
+	return nil
+}
+
+// Revoke revokes a key
+func (a *APIKey) Revoke(ctx context.Context, req *RevokeRequest, rsp *RevokeResponse) error {
+	if len(req.ID) == 0 {
+		return errors.BadRequest(a.id, "id required")
+	}
+
+	switch err := a.keys.Revoke(req.ID); err {
+	case nil:
+		return nil
+	case ErrNotFound:
+		return errors.NotFound(a.id, "api key %s not found", req.ID)
+	default:
+		return errors.InternalServerError(a.id, "%v", err)
+	}
+}
+
+// Verify verifies a key
+func (a *APIKey) Verify(ctx context.Context, req *VerifyRequest, rsp *VerifyResponse) error {
+	key, err := a.keys.Verify(req.Token)
+	switch err {
+	case nil:
+		rsp.Key = key
+		return nil
+	case ErrInvalidKey, ErrExpired:
+		return errors.Unauthorized(a.id, "%v", err)
+	default:
+		return errors.InternalServerError(a.id, "%v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/asim/go-micro/v3/store"
+)
+
+type Options struct {
+	// Store the hashed keys are kept in
+	Store store.Store
+	// Prefix of the store keys
+	Prefix string
+}
+
+type Option func(o *Options)
+
+// NewOptions fills in the blanks
+func NewOptions(opts ...Option) Options {
+	options := Options{
+		Store:  store.DefaultStore,
+		Prefix: "apikey/",
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return options
+}
+
+// WithStore sets the store the keys are kept in
+func WithStore(s store.Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// WithPrefix sets the prefix of the store keys
+func WithPrefix(p string) Option {
+	return func(o *Options) {
+		o.Prefix = p
+	}
+}
+
+type IssueOptions struct {
+	// Scopes granted to the key
+	Scopes []string
+	// Metadata of the key e.g the owner
+	Metadata map[string]string
+	// TTL of the key, the key never expires if zero
+	TTL time.Duration
+}
+
+type IssueOption func(o *IssueOptions)
+
+// WithScopes sets the scopes granted to the key
+func WithScopes(s ...string) IssueOption {
+	return func(o *IssueOptions) {
+		o.Scopes = s
+	}
+}
+
+// WithMetadata sets the metadata of the key
+func WithMetadata(md map[string]string) IssueOption {
+	return func(o *IssueOptions) {
+		o.Metadata = md
+	}
+}
+
+// WithTTL sets how long the key is valid for
+func WithTTL(d time.Duration) IssueOption {
+	return func(o *IssueOptions) {
+		o.TTL = d
+	}
+}
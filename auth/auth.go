@@ -21,6 +21,10 @@ var (
 	ErrInvalidToken = errors.New("invalid token provided")
 	// ErrForbidden is when a user does not have the necessary scope to access a resource
 	ErrForbidden = errors.New("resource forbidden")
+	// ErrInvalidIssuer is when the token was issued by another namespace
+	ErrInvalidIssuer = errors.New("token issued by another namespace")
+	// ErrInvalidAudience is when the token was issued for another service
+	ErrInvalidAudience = errors.New("token issued for another service")
 )
 
 // Auth provides authentication and authorization
@@ -59,6 +63,8 @@ type Account struct {
 	Type string `json:"type"`
 	// Issuer of the account
 	Issuer string `json:"issuer"`
+	// Audience the account's token was issued for, blank if any
+	Audience string `json:"audience"`
 	// Any other associated metadata
 	Metadata map[string]string `json:"metadata"`
 	// Scopes the account has access to
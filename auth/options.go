@@ -143,6 +143,8 @@ type TokenOptions struct {
 	RefreshToken string
 	// Expiry is the time the token should live for
 	Expiry time.Duration
+	// Audience is the service the token is issued for, blank for any
+	Audience string
 }
 
 type TokenOption func(o *TokenOptions)
@@ -161,6 +163,13 @@ func WithCredentials(id, secret string) TokenOption {
 	}
 }
 
+// WithAudience limits the token to the given service
+func WithAudience(aud string) TokenOption {
+	return func(o *TokenOptions) {
+		o.Audience = aud
+	}
+}
+
 func WithToken(rt string) TokenOption {
 	return func(o *TokenOptions) {
 		o.RefreshToken = rt
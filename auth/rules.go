@@ -13,18 +13,16 @@ func Verify(rules []*Rule, acc *Account, res *Resource) error {
 	// the rule is only to be applied if the type matches the resource or is catch-all (*)
 	validTypes := []string{"*", res.Type}
 
-	// the rule is only to be applied if the name matches the resource or is catch-all (*)
-	validNames := []string{"*", res.Name}
+	// the rule is only to be applied if the name matches the resource or is catch-all (*),
+	// names can also have wildcards e.g. go.micro.service.* would include go.micro.service.foo
+	validNames := append([]string{"*", res.Name}, wildcards(res.Name, ".")...)
 
 	// rules can have wildcard excludes on endpoints since this can also be a path for web services,
-	// e.g. /foo/* would include /foo/bar. We also want to check for wildcards and the exact endpoint
+	// e.g. /foo/* would include /foo/bar and Foo.* would include Foo.Bar. We also want to check for
+	// wildcards and the exact endpoint
 	validEndpoints := []string{"*", res.Endpoint}
-	if comps := strings.Split(res.Endpoint, "/"); len(comps) > 1 {
-		for i := 1; i < len(comps)+1; i++ {
-			wildcard := fmt.Sprintf("%v/*", strings.Join(comps[0:i], "/"))
-			validEndpoints = append(validEndpoints, wildcard)
-		}
-	}
+	validEndpoints = append(validEndpoints, wildcards(res.Endpoint, "/")...)
+	validEndpoints = append(validEndpoints, wildcards(res.Endpoint, ".")...)
 
 	// filter the rules to the ones which match the criteria above
 	filteredRules := make([]*Rule, 0)
@@ -79,6 +77,21 @@ func Verify(rules []*Rule, acc *Account, res *Resource) error {
 	return ErrForbidden
 }
 
+// wildcards returns the wildcards which include the value when split by the separator,
+// e.g. /foo/* and /foo/bar/* for /foo/bar
+func wildcards(val, sep string) []string {
+	comps := strings.Split(val, sep)
+	if len(comps) < 2 {
+		return nil
+	}
+
+	var res []string
+	for i := 1; i < len(comps)+1; i++ {
+		res = append(res, fmt.Sprintf("%v%v*", strings.Join(comps[0:i], sep), sep))
+	}
+	return res
+}
+
 // include is a helper function which checks to see if the slice contains the value. includes is
 // not case sensitive.
 func include(slice []string, val string) bool {
@@ -0,0 +1,48 @@
+package rules
+
+import (
+	"github.com/asim/go-micro/v3/config"
+)
+
+type Options struct {
+	// Config the rules are loaded from and watched
+	Config config.Config
+	// Path of the rules in the config
+	Path []string
+	// DenyByDefault denies access when no rule matches the resource
+	DenyByDefault bool
+}
+
+type Option func(o *Options)
+
+// Config sets the config the rules are loaded from
+func Config(c config.Config) Option {
+	return func(o *Options) {
+		o.Config = c
+	}
+}
+
+// Path sets the path of the rules in the config, defaults to auth.rules
+func Path(path ...string) Option {
+	return func(o *Options) {
+		o.Path = path
+	}
+}
+
+// DenyByDefault denies access to resources no rule applies to, by
+// default access is granted
+func DenyByDefault(b bool) Option {
+	return func(o *Options) {
+		o.DenyByDefault = b
+	}
+}
+
+func NewOptions(opts ...Option) Options {
+	options := Options{
+		Path: []string{"auth", "rules"},
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
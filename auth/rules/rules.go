@@ -0,0 +1,285 @@
+// Package rules provides access rules which are loaded from config, e.g
+//
+//	{
+//		"auth": {
+//			"rules": [{
+//				"id": "admin",
+//				"scope": "admin",
+//				"resource": {"type": "service", "name": "go.micro.service.foo", "endpoint": "Foo.*"},
+//				"access": "granted",
+//				"priority": 1
+//			}]
+//		}
+//	}
+package rules
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/auth"
+	"github.com/asim/go-micro/v3/config"
+	"github.com/asim/go-micro/v3/logger"
+)
+
+// rule is the config representation of an auth.Rule
+type rule struct {
+	ID       string         `json:"id"`
+	Scope    string         `json:"scope"`
+	Resource *auth.Resource `json:"resource"`
+	// Access is granted or denied, defaults to granted
+	Access   string `json:"access"`
+	Priority int32  `json:"priority"`
+}
+
+// defaultRule grants any account access when no other rule applies,
+// requests without an account are forbidden
+var defaultRule = &auth.Rule{
+	ID:       "default",
+	Scope:    auth.ScopeAccount,
+	Resource: &auth.Resource{Type: "*", Name: "*", Endpoint: "*"},
+	Access:   auth.AccessGranted,
+	Priority: math.MinInt32,
+}
+
+type rules struct {
+	opts Options
+	exit chan bool
+	once sync.Once
+
+	sync.RWMutex
+	// rules granted using Grant
+	granted []*auth.Rule
+	// rules loaded from the config
+	loaded []*auth.Rule
+	// watcher of the config, stopped on close
+	watcher config.Watcher
+}
+
+// NewRules returns rules which are loaded from the config if set. The
+// rules implement io.Closer to stop watching the config
+func NewRules(opts ...Option) auth.Rules {
+	r := &rules{
+		opts: NewOptions(opts...),
+		exit: make(chan bool),
+	}
+
+	if r.opts.Config != nil {
+		if err := r.load(); err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Error loading auth rules: %v", err)
+			}
+		}
+		go r.watch()
+	}
+
+	return r
+}
+
+// parse converts the config rules
+func parse(rs []*rule) ([]*auth.Rule, error) {
+	res := make([]*auth.Rule, 0, len(rs))
+
+	for i, r := range rs {
+		if r.Resource == nil {
+			return nil, fmt.Errorf("rule %d: resource required", i)
+		}
+
+		rule := &auth.Rule{
+			ID:       r.ID,
+			Scope:    r.Scope,
+			Resource: r.Resource,
+			Priority: r.Priority,
+		}
+		if len(rule.ID) == 0 {
+			rule.ID = fmt.Sprintf("config.%d", i)
+		}
+		if len(rule.Resource.Type) == 0 {
+			rule.Resource.Type = "*"
+		}
+		if len(rule.Resource.Name) == 0 {
+			rule.Resource.Name = "*"
+		}
+		if len(rule.Resource.Endpoint) == 0 {
+			rule.Resource.Endpoint = "*"
+		}
+
+		switch r.Access {
+		case "", "granted":
+			rule.Access = auth.AccessGranted
+		case "denied":
+			rule.Access = auth.AccessDenied
+		default:
+			return nil, fmt.Errorf("rule %d: unknown access %s", i, r.Access)
+		}
+
+		res = append(res, rule)
+	}
+
+	return res, nil
+}
+
+// apply replaces the loaded rules, the current rules are kept if any rule is invalid
+func (r *rules) apply(rs []*rule) error {
+	loaded, err := parse(rs)
+	if err != nil {
+		return err
+	}
+
+	r.Lock()
+	r.loaded = loaded
+	r.Unlock()
+
+	return nil
+}
+
+// load reads the rules from the config
+func (r *rules) load() error {
+	var rs []*rule
+	if err := r.opts.Config.Get(r.opts.Path...).Scan(&rs); err != nil {
+		return err
+	}
+	return r.apply(rs)
+}
+
+// watch reloads the rules when the config changes until closed
+func (r *rules) watch() {
+	var attempts int
+
+	for {
+		select {
+		case <-r.exit:
+			return
+		default:
+		}
+
+		w, err := r.opts.Config.Watch(r.opts.Path...)
+		if err != nil {
+			attempts++
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Error watching auth rules: %v", err)
+			}
+			select {
+			case <-r.exit:
+				return
+			case <-time.After(time.Duration(attempts) * time.Second):
+			}
+			continue
+		}
+
+		// reset if we get here
+		attempts = 0
+
+		// the watcher is stopped if closed meanwhile
+		r.Lock()
+		select {
+		case <-r.exit:
+			r.Unlock()
+			w.Stop()
+			return
+		default:
+			r.watcher = w
+		}
+		r.Unlock()
+
+		for {
+			v, err := w.Next()
+			if err != nil {
+				select {
+				case <-r.exit:
+					return
+				default:
+				}
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Errorf("Error getting next auth rules: %v", err)
+				}
+				w.Stop()
+				break
+			}
+
+			var rs []*rule
+			if err := v.Scan(&rs); err != nil {
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Errorf("Error decoding auth rules: %v", err)
+				}
+				continue
+			}
+			if err := r.apply(rs); err != nil {
+				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+					logger.Errorf("Unable to apply auth rules: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// Close stops watching the config for changes of the rules
+func (r *rules) Close() error {
+	r.once.Do(func() {
+		r.Lock()
+		close(r.exit)
+		w := r.watcher
+		r.watcher = nil
+		r.Unlock()
+
+		if w != nil {
+			w.Stop()
+		}
+	})
+	return nil
+}
+
+// Verify an account has access to a resource using the rules
+func (r *rules) Verify(acc *auth.Account, res *auth.Resource, opts ...auth.VerifyOption) error {
+	rules, _ := r.List()
+	if !r.opts.DenyByDefault {
+		rules = append(rules, defaultRule)
+	}
+	return auth.Verify(rules, acc, res)
+}
+
+// Grant access to a resource
+func (r *rules) Grant(rule *auth.Rule) error {
+	r.Lock()
+	defer r.Unlock()
+
+	// replace the rule with the same id
+	for i, g := range r.granted {
+		if g.ID == rule.ID {
+			r.granted[i] = rule
+			return nil
+		}
+	}
+
+	r.granted = append(r.granted, rule)
+	return nil
+}
+
+// Revoke access to a resource
+func (r *rules) Revoke(rule *auth.Rule) error {
+	r.Lock()
+	defer r.Unlock()
+
+	granted := make([]*auth.Rule, 0, len(r.granted))
+	for _, g := range r.granted {
+		if g.ID != rule.ID {
+			granted = append(granted, g)
+		}
+	}
+	r.granted = granted
+
+	return nil
+}
+
+// List returns the granted and loaded rules
+func (r *rules) List(opts ...auth.ListOption) ([]*auth.Rule, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	rules := make([]*auth.Rule, 0, len(r.granted)+len(r.loaded))
+	rules = append(rules, r.granted...)
+	rules = append(rules, r.loaded...)
+	return rules, nil
+}
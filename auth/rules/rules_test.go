@@ -0,0 +1,143 @@
+package rules
+
+import (
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/auth"
+	"github.com/asim/go-micro/v3/config"
+	"github.com/asim/go-micro/v3/config/source/memory"
+)
+
+func TestRules(t *testing.T) {
+	data := []byte(`{
+		"auth": {
+			"rules": [
+				{"scope": "admin", "resource": {"type": "service", "name": "go.micro.service.foo", "endpoint": "Foo.*"}},
+				{"scope": "*", "resource": {"type": "service", "name": "go.micro.service.foo"}, "access": "denied"}
+			]
+		}
+	}`)
+
+	c, err := config.NewConfig(config.WithSource(memory.NewSource(memory.WithJSON(data))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	admin := &auth.Account{ID: "admin", Scopes: []string{"admin"}}
+	user := &auth.Account{ID: "user"}
+
+	foo := &auth.Resource{Type: "service", Name: "go.micro.service.foo", Endpoint: "Foo.Bar"}
+	bar := &auth.Resource{Type: "service", Name: "go.micro.service.bar", Endpoint: "Bar.Baz"}
+
+	tt := []struct {
+		name     string
+		deny     bool
+		account  *auth.Account
+		resource *auth.Resource
+		err      error
+	}{
+		{name: "Granted", account: admin, resource: foo},
+		{name: "Denied", account: user, resource: foo, err: auth.ErrForbidden},
+		{name: "NoAccount", resource: foo, err: auth.ErrForbidden},
+		{name: "AllowByDefault", account: user, resource: bar},
+		{name: "DenyByDefault", deny: true, account: user, resource: bar, err: auth.ErrForbidden},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRules(Config(c), DenyByDefault(tc.deny))
+			defer r.(io.Closer).Close()
+
+			if err := r.Verify(tc.account, tc.resource); err != tc.err {
+				t.Errorf("Expected %v but got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestClose(t *testing.T) {
+	c, err := config.NewConfig(config.WithSource(memory.NewSource(memory.WithJSON([]byte(`{}`)))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRules(Config(c)).(*rules)
+
+	// wait for the config to be watched
+	for i := 0; ; i++ {
+		r.RLock()
+		w := r.watcher
+		r.RUnlock()
+		if w != nil {
+			break
+		}
+		if i == 100 {
+			t.Fatal("Expected the config to be watched")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the watch goroutine returns
+	for i := 0; watching(); i++ {
+		if i == 100 {
+			t.Fatal("Expected the config to no longer be watched")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+// watching reports whether a goroutine is watching the config
+func watching() bool {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+	return strings.Contains(string(buf), "rules.(*rules).watch")
+}
+
+func TestGrant(t *testing.T) {
+	r := NewRules(DenyByDefault(true))
+
+	acc := &auth.Account{ID: "user"}
+	res := &auth.Resource{Type: "service", Name: "go.micro.service.foo", Endpoint: "Foo.Bar"}
+
+	if err := r.Verify(acc, res); err != auth.ErrForbidden {
+		t.Fatalf("Expected %v but got %v", auth.ErrForbidden, err)
+	}
+
+	rule := &auth.Rule{ID: "foo", Scope: auth.ScopeAccount, Resource: res}
+	if err := r.Grant(rule); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Verify(acc, res); err != nil {
+		t.Fatalf("Expected nil but got %v", err)
+	}
+
+	if err := r.Revoke(rule); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Verify(acc, res); err != auth.ErrForbidden {
+		t.Fatalf("Expected %v but got %v", auth.ErrForbidden, err)
+	}
+}
+
+func TestInvalidRules(t *testing.T) {
+	if _, err := parse([]*rule{{Scope: "*"}}); err == nil {
+		t.Error("Expected an error for a rule without a resource")
+	}
+	if _, err := parse([]*rule{{Resource: &auth.Resource{}, Access: "maybe"}}); err == nil {
+		t.Error("Expected an error for unknown access")
+	}
+}
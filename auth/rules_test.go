@@ -276,6 +276,68 @@ func TestVerify(t *testing.T) {
 			},
 			Error: ErrForbidden,
 		},
+		{
+			Name:     "WildcardMethodEndpointValid",
+			Resource: srvResource,
+			Account:  &Account{},
+			Rules: []*Rule{
+				&Rule{
+					Scope: "*",
+					Resource: &Resource{
+						Type:     srvResource.Type,
+						Name:     srvResource.Name,
+						Endpoint: "Foo.*",
+					},
+				},
+			},
+		},
+		{
+			Name:     "WildcardMethodEndpointInvalid",
+			Resource: srvResource,
+			Account:  &Account{},
+			Rules: []*Rule{
+				&Rule{
+					Scope: "*",
+					Resource: &Resource{
+						Type:     srvResource.Type,
+						Name:     srvResource.Name,
+						Endpoint: "Bar.*",
+					},
+				},
+			},
+			Error: ErrForbidden,
+		},
+		{
+			Name:     "WildcardNameValid",
+			Resource: srvResource,
+			Account:  &Account{},
+			Rules: []*Rule{
+				&Rule{
+					Scope: "*",
+					Resource: &Resource{
+						Type:     srvResource.Type,
+						Name:     "go.micro.service.*",
+						Endpoint: srvResource.Endpoint,
+					},
+				},
+			},
+		},
+		{
+			Name:     "WildcardNameInvalid",
+			Resource: srvResource,
+			Account:  &Account{},
+			Rules: []*Rule{
+				&Rule{
+					Scope: "*",
+					Resource: &Resource{
+						Type:     srvResource.Type,
+						Name:     "go.micro.web.*",
+						Endpoint: srvResource.Endpoint,
+					},
+				},
+			},
+			Error: ErrForbidden,
+		},
 	}
 
 	for _, tc := range tt {
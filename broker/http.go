@@ -19,6 +19,7 @@ import (
 
 	"github.com/asim/go-micro/v3/codec/json"
 	merr "github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/logger"
 	"github.com/asim/go-micro/v3/registry"
 	"github.com/asim/go-micro/v3/registry/cache"
 	maddr "github.com/asim/go-micro/v3/util/addr"
@@ -269,9 +270,14 @@ func (h *httpBroker) run(l net.Listener) {
 		// heartbeat for each subscriber
 		case <-t.C:
 			h.RLock()
+			log := logger.NewHelper(h.opts.Logger)
 			for _, subs := range h.subscribers {
 				for _, sub := range subs {
-					_ = h.r.Register(sub.svc, registry.RegisterTTL(registerTTL))
+					if err := h.r.Register(sub.svc, registry.RegisterTTL(registerTTL)); err != nil {
+						if logger.V(logger.ErrorLevel, log) {
+							log.Errorf("Broker [http] failed to register subscriber to %s: %v", sub.topic, err)
+						}
+					}
 				}
 			}
 			h.RUnlock()
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 
 	"github.com/asim/go-micro/v3/codec"
+	"github.com/asim/go-micro/v3/logger"
 	"github.com/asim/go-micro/v3/registry"
 )
 
@@ -20,6 +21,8 @@ type Options struct {
 	TLSConfig *tls.Config
 	// Registry used for clustering
 	Registry registry.Registry
+	// Logger of the broker, the logger.DefaultLogger if nil
+	Logger logger.Logger
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -108,6 +111,13 @@ func Queue(name string) SubscribeOption {
 	}
 }
 
+// Logger to log with
+func Logger(l logger.Logger) Option {
+	return func(o *Options) {
+		o.Logger = l
+	}
+}
+
 func Registry(r registry.Registry) Option {
 	return func(o *Options) {
 		o.Registry = r
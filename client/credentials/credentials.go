@@ -0,0 +1,110 @@
+// Package credentials provides the tokens a client attaches to requests to
+// protected services. Tokens are cached and refreshed before they expire.
+package credentials
+
+import (
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/auth"
+	"github.com/asim/go-micro/v3/logger"
+)
+
+var (
+	// DefaultRefreshBefore is how long before expiry a token is refreshed
+	DefaultRefreshBefore = time.Minute
+)
+
+// Provider provides the token attached to requests
+type Provider interface {
+	// Token returns a valid token, refreshing it if needed
+	Token() (*auth.Token, error)
+	// String returns the name of the provider
+	String() string
+	// Close releases the resources of the provider e.g a file watcher
+	Close() error
+}
+
+// cache caches the token returned by fetch until it's about to expire, a
+// token without an expiry is cached until reset
+type cache struct {
+	name  string
+	fetch func() (*auth.Token, error)
+
+	sync.Mutex
+	token *auth.Token
+}
+
+func (c *cache) Token() (*auth.Token, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.token != nil && !expires(c.token, DefaultRefreshBefore) {
+		return c.token, nil
+	}
+
+	tok, err := c.fetch()
+	if err != nil {
+		// keep using the current token until it expires
+		if c.token != nil && !expires(c.token, 0) {
+			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+				logger.Warnf("Error refreshing %s token: %v", c.name, err)
+			}
+			return c.token, nil
+		}
+		return nil, err
+	}
+
+	c.token = tok
+
+	return tok, nil
+}
+
+// reset drops the cached token so the next call fetches a new one
+func (c *cache) reset() {
+	c.Lock()
+	c.token = nil
+	c.Unlock()
+}
+
+func (c *cache) String() string {
+	return c.name
+}
+
+func (c *cache) Close() error {
+	return nil
+}
+
+// expires returns true if the token expires within d
+func expires(t *auth.Token, d time.Duration) bool {
+	if t.Expiry.IsZero() {
+		return false
+	}
+	return time.Until(t.Expiry) <= d
+}
+
+type static struct {
+	token *auth.Token
+}
+
+func (s *static) Token() (*auth.Token, error) {
+	return s.token, nil
+}
+
+func (s *static) String() string {
+	return "static"
+}
+
+func (s *static) Close() error {
+	return nil
+}
+
+// Static returns a provider of a token which never expires
+func Static(token string) Provider {
+	return &static{
+		token: &auth.Token{
+			AccessToken: token,
+			Created:     time.Now(),
+		},
+	}
+}
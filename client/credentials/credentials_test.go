@@ -0,0 +1,251 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestStatic(t *testing.T) {
+	tok, err := Static("foo").Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken != "foo" {
+		t.Fatalf("Expected token foo got %s", tok.AccessToken)
+	}
+}
+
+func TestClientCredentials(t *testing.T) {
+	var requests int32
+	var fail atomic.Value
+	fail.Store(false)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+
+		if fail.Load().(bool) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if id, secret, _ := r.BasicAuth(); id != "id" || secret != "secret" {
+			http.Error(w, "invalid client", http.StatusUnauthorized)
+			return
+		}
+		if r.FormValue("grant_type") != "client_credentials" || r.FormValue("scope") != "a b" {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		// the first token expires within the refresh window
+		expires := int64(3600)
+		if n == 1 {
+			expires = 30
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("token%d", n),
+			"token_type":   "bearer",
+			"expires_in":   expires,
+		})
+	}))
+	defer srv.Close()
+
+	before := DefaultRefreshBefore
+	defer func() { DefaultRefreshBefore = before }()
+
+	p := ClientCredentials(srv.URL, "id", "secret", "a", "b")
+
+	tok, err := p.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken != "token1" {
+		t.Fatalf("Expected token1 got %s", tok.AccessToken)
+	}
+
+	// the token is cached until it's about to expire
+	DefaultRefreshBefore = time.Second
+	if tok, _ = p.Token(); tok.AccessToken != "token1" {
+		t.Fatalf("Expected cached token1 got %s", tok.AccessToken)
+	}
+
+	DefaultRefreshBefore = before
+	if tok, _ = p.Token(); tok.AccessToken != "token2" {
+		t.Fatalf("Expected refreshed token2 got %s", tok.AccessToken)
+	}
+
+	// a failed refresh keeps the current token until it expires
+	DefaultRefreshBefore = 2 * time.Hour
+	fail.Store(true)
+	tok, err = p.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken != "token2" {
+		t.Fatalf("Expected current token2 got %s", tok.AccessToken)
+	}
+
+	// invalid credentials return an error
+	fail.Store(false)
+	if _, err := ClientCredentials(srv.URL, "id", "bad").Token(); err == nil {
+		t.Fatal("Expected error for invalid credentials")
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(path, []byte("foo\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := File(path)
+
+	tok, err := p.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.AccessToken != "foo" {
+		t.Fatalf("Expected token foo got %s", tok.AccessToken)
+	}
+
+	// replace the file as kubernetes does
+	tmp := filepath.Join(dir, "token.tmp")
+	if err := ioutil.WriteFile(tmp, []byte("bar"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		tok, err := p.Token()
+		if err == nil && tok.AccessToken == "bar" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected token bar got %v %v", tok, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileChanged(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the token is a symlink to the current data as in kubernetes secrets
+	for _, d := range []string{"data1", "data2"} {
+		if err := os.Mkdir(filepath.Join(dir, d), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, d, "token"), []byte(d), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("data1", filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("..data/token", filepath.Join(dir, "token")); err != nil {
+		t.Fatal(err)
+	}
+
+	// stop the watcher so only the events below are checked
+	f := File(filepath.Join(dir, "token")).(*file)
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// other files in the directory are ignored
+	if f.changed(fsnotify.Event{Name: filepath.Join(dir, "other"), Op: fsnotify.Write}) {
+		t.Fatal("Expected changes to other files to be ignored")
+	}
+	if !f.changed(fsnotify.Event{Name: filepath.Join(dir, "token"), Op: fsnotify.Write}) {
+		t.Fatal("Expected changes to the token file to be seen")
+	}
+
+	// swap the data symlink
+	tmp := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink("data2", tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	if !f.changed(fsnotify.Event{Name: filepath.Join(dir, "..data"), Op: fsnotify.Create}) {
+		t.Fatal("Expected the symlink swap to be seen")
+	}
+	if f.changed(fsnotify.Event{Name: filepath.Join(dir, "..data"), Op: fsnotify.Create}) {
+		t.Fatal("Expected the swap to be seen once")
+	}
+}
+
+type testClient struct {
+	client.Client
+	md metadata.Metadata
+}
+
+func (t *testClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	t.md, _ = metadata.FromContext(ctx)
+	return nil
+}
+
+func TestWrapper(t *testing.T) {
+	testData := []struct {
+		name     string
+		provider Provider
+		header   string
+		opts     []client.CallOption
+		expect   string
+	}{
+		{name: "no provider"},
+		{name: "provider", provider: Static("foo"), expect: "Bearer foo"},
+		{name: "header set", provider: Static("foo"), header: "Bearer bar", expect: "Bearer bar"},
+		{name: "request provider", header: "Bearer bar", opts: []client.CallOption{WithProvider(Static("baz"))}, expect: "Bearer baz"},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			tc := &testClient{}
+			c := NewClientWrapper(d.provider)(tc)
+
+			ctx := context.TODO()
+			if len(d.header) > 0 {
+				ctx = metadata.Set(ctx, "Authorization", d.header)
+			}
+
+			if err := c.Call(ctx, nil, nil, d.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if v, _ := tc.md.Get("Authorization"); v != d.expect {
+				t.Fatalf("Expected authorization %q got %q", d.expect, v)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package credentials
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/asim/go-micro/v3/auth"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/fsnotify/fsnotify"
+)
+
+type file struct {
+	*cache
+
+	path string
+	fw   *fsnotify.Watcher
+	// the file the path resolves to, it changes when a symlink is swapped
+	target string
+}
+
+func (f *file) fetch() (*auth.Token, error) {
+	b, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	token := strings.TrimSpace(string(b))
+	if len(token) == 0 {
+		return nil, fmt.Errorf("no token in %s", f.path)
+	}
+
+	return &auth.Token{
+		AccessToken: token,
+		Created:     time.Now(),
+	}, nil
+}
+
+// changed returns true if the event is for the token file or a symlink in
+// the path of the token file was swapped
+func (f *file) changed(ev fsnotify.Event) bool {
+	if filepath.Clean(ev.Name) == f.path {
+		return true
+	}
+
+	target, err := filepath.EvalSymlinks(f.path)
+	if err != nil || target == f.target {
+		return false
+	}
+	f.target = target
+	return true
+}
+
+// watch resets the token when the file changes. The directory is watched so
+// files replaced by a rename or symlink swap e.g kubernetes secrets are seen.
+func (f *file) watch(fw *fsnotify.Watcher) {
+	for {
+		select {
+		case ev, ok := <-fw.Events:
+			if !ok {
+				return
+			}
+			if f.changed(ev) {
+				f.reset()
+			}
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return
+			}
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Error watching token file %s: %v", f.path, err)
+			}
+		}
+	}
+}
+
+// File returns a provider of the token in the file, the token is reloaded
+// when the file changes
+func File(path string) Provider {
+	path = filepath.Clean(path)
+	target, _ := filepath.EvalSymlinks(path)
+
+	f := &file{path: path, target: target}
+	f.cache = &cache{
+		name:  "file",
+		fetch: f.fetch,
+	}
+
+	fw, err := fsnotify.NewWatcher()
+	if err == nil {
+		err = fw.Add(filepath.Dir(path))
+	}
+	if err != nil {
+		if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			logger.Warnf("Unable to watch token file %s, the token won't be reloaded: %v", path, err)
+		}
+		if fw != nil {
+			fw.Close()
+		}
+		return f
+	}
+
+	f.fw = fw
+	go f.watch(fw)
+
+	return f
+}
+
+// Close stops watching the token file
+func (f *file) Close() error {
+	if f.fw == nil {
+		return nil
+	}
+	return f.fw.Close()
+}
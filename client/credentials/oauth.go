@@ -0,0 +1,95 @@
+package credentials
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/asim/go-micro/v3/auth"
+)
+
+// oauthToken is the token response of an oauth2 token endpoint
+type oauthToken struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type clientCredentials struct {
+	url    string
+	id     string
+	secret string
+	scopes []string
+	client *http.Client
+}
+
+func (c *clientCredentials) fetch() (*auth.Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.scopes) > 0 {
+		form.Set("scope", strings.Join(c.scopes, " "))
+	}
+
+	req, err := http.NewRequest("POST", c.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.id), url.QueryEscape(c.secret))
+
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	b, err := ioutil.ReadAll(io.LimitReader(rsp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token request failed: %s: %s", rsp.Status, strings.TrimSpace(string(b)))
+	}
+
+	var tok oauthToken
+	if err := json.Unmarshal(b, &tok); err != nil {
+		return nil, err
+	}
+	if len(tok.AccessToken) == 0 {
+		return nil, fmt.Errorf("token request failed: no access token")
+	}
+
+	now := time.Now()
+	t := &auth.Token{
+		AccessToken:  tok.AccessToken,
+		RefreshToken: tok.RefreshToken,
+		Created:      now,
+	}
+	if tok.ExpiresIn > 0 {
+		t.Expiry = now.Add(time.Duration(tok.ExpiresIn) * time.Second)
+	}
+
+	return t, nil
+}
+
+// ClientCredentials returns a provider of tokens requested from the oauth2
+// token url using the client credentials grant
+func ClientCredentials(tokenURL, id, secret string, scopes ...string) Provider {
+	c := &clientCredentials{
+		url:    tokenURL,
+		id:     id,
+		secret: secret,
+		scopes: scopes,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+	return &cache{
+		name:  "oauth2",
+		fetch: c.fetch,
+	}
+}
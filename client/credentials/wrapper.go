@@ -0,0 +1,98 @@
+package credentials
+
+import (
+	"context"
+
+	"github.com/asim/go-micro/v3/auth"
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/metadata"
+)
+
+type providerKey struct{}
+
+// WithProvider is a CallOption which sets the provider of the token for the
+// request, overriding the provider of the wrapper and the authorization header
+func WithProvider(p Provider) client.CallOption {
+	return func(o *client.CallOptions) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, providerKey{}, p)
+	}
+}
+
+func fromOptions(o client.CallOptions) (Provider, bool) {
+	if o.Context == nil {
+		return nil, false
+	}
+	p, ok := o.Context.Value(providerKey{}).(Provider)
+	return p, ok
+}
+
+type credentialsWrapper struct {
+	client.Client
+	provider Provider
+}
+
+// setToken sets the authorization header of the request, the header isn't
+// overridden unless the provider is set per request
+func (c *credentialsWrapper) setToken(ctx context.Context, opts ...client.CallOption) (context.Context, error) {
+	var options client.CallOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	p := c.provider
+	if v, ok := fromOptions(options); ok {
+		p = v
+	} else if _, ok := metadata.Get(ctx, "Authorization"); ok {
+		return ctx, nil
+	}
+
+	if p == nil {
+		return ctx, nil
+	}
+
+	tok, err := p.Token()
+	if err != nil {
+		return ctx, errors.Unauthorized("go.micro.client", "error getting %s token: %v", p.String(), err)
+	}
+
+	return metadata.Set(ctx, "Authorization", auth.BearerScheme+tok.AccessToken), nil
+}
+
+func (c *credentialsWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	ctx, err := c.setToken(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	return c.Client.Call(ctx, req, rsp, opts...)
+}
+
+func (c *credentialsWrapper) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	ctx, err := c.setToken(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.Stream(ctx, req, opts...)
+}
+
+func (c *credentialsWrapper) Publish(ctx context.Context, p client.Message, opts ...client.PublishOption) error {
+	ctx, err := c.setToken(ctx)
+	if err != nil {
+		return err
+	}
+	return c.Client.Publish(ctx, p, opts...)
+}
+
+// NewClientWrapper returns a client wrapper attaching the token of the
+// provider to requests. The provider can be nil if set per request.
+func NewClientWrapper(p Provider) client.Wrapper {
+	return func(c client.Client) client.Client {
+		return &credentialsWrapper{
+			Client:   c,
+			provider: p,
+		}
+	}
+}
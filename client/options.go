@@ -6,9 +6,11 @@ import (
 
 	"github.com/asim/go-micro/v3/broker"
 	"github.com/asim/go-micro/v3/codec"
+	"github.com/asim/go-micro/v3/logger"
 	"github.com/asim/go-micro/v3/registry"
 	"github.com/asim/go-micro/v3/selector"
 	"github.com/asim/go-micro/v3/transport"
+	"github.com/asim/go-micro/v3/util/pool"
 )
 
 type Options struct {
@@ -28,6 +30,8 @@ type Options struct {
 	// Connection Pool
 	PoolSize int
 	PoolTTL  time.Duration
+	// PoolOptions such as the idle ttl and health probe of connections
+	PoolOptions []pool.Option
 
 	// Response cache
 	Cache *Cache
@@ -38,6 +42,9 @@ type Options struct {
 	// Default Call Options
 	CallOptions CallOptions
 
+	// Logger of the client, the logger.DefaultLogger if nil
+	Logger logger.Logger
+
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -157,6 +164,22 @@ func PoolTTL(d time.Duration) Option {
 	}
 }
 
+// PoolOptions adds options of the connection pool e.g
+//
+//	client.PoolOptions(pool.IdleTTL(time.Minute), pool.Interval(time.Second*30))
+func PoolOptions(opts ...pool.Option) Option {
+	return func(o *Options) {
+		o.PoolOptions = append(o.PoolOptions, opts...)
+	}
+}
+
+// Logger to log with
+func Logger(l logger.Logger) Option {
+	return func(o *Options) {
+		o.Logger = l
+	}
+}
+
 // Registry to find nodes for a given service
 func Registry(r registry.Registry) Option {
 	return func(o *Options) {
@@ -14,7 +14,8 @@ func RetryAlways(ctx context.Context, req Request, retryCount int, err error) (b
 	return true, nil
 }
 
-// RetryOnError retries a request on a 500 or timeout error
+// RetryOnError retries a request on a 500 or timeout error or an error marked
+// as retryable
 func RetryOnError(ctx context.Context, req Request, retryCount int, err error) (bool, error) {
 	if err == nil {
 		return false, nil
@@ -25,6 +26,10 @@ func RetryOnError(ctx context.Context, req Request, retryCount int, err error) (
 		return false, nil
 	}
 
+	if e.Retryable {
+		return true, nil
+	}
+
 	switch e.Code {
 	// retry on timeout or internal server error
 	case 408, 500:
@@ -33,3 +38,14 @@ func RetryOnError(ctx context.Context, req Request, retryCount int, err error) (
 		return false, nil
 	}
 }
+
+// RetryOnRetryable retries a request on an error errors.IsRetryable reports as
+// retryable e.g a timeout or an unavailable service, it's used with
+// client.Retry(client.RetryOnRetryable)
+func RetryOnRetryable(ctx context.Context, req Request, retryCount int, err error) (bool, error) {
+	if err == nil {
+		return false, nil
+	}
+
+	return errors.IsRetryable(errors.Parse(err.Error())), nil
+}
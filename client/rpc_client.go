@@ -27,18 +27,34 @@ type rpcClient struct {
 	pool pool.Pool
 }
 
-func newRpcClient(opt ...Option) Client {
-	opts := NewOptions(opt...)
-
-	p := pool.NewPool(
+func newPool(opts Options) pool.Pool {
+	return pool.NewPool(append([]pool.Option{
 		pool.Size(opts.PoolSize),
 		pool.TTL(opts.PoolTTL),
 		pool.Transport(opts.Transport),
-	)
+	}, opts.PoolOptions...)...)
+}
+
+// PoolStats returns the stats of the connection pool of a client created by
+// NewClient, false if it's another client or its pool doesn't count them
+func PoolStats(c Client) (pool.Stats, bool) {
+	rc, ok := c.(*rpcClient)
+	if !ok {
+		return pool.Stats{}, false
+	}
+	sp, ok := rc.pool.(pool.StatsPool)
+	if !ok {
+		return pool.Stats{}, false
+	}
+	return sp.Stats(), true
+}
+
+func newRpcClient(opt ...Option) Client {
+	opts := NewOptions(opt...)
 
 	rc := &rpcClient{
 		opts: opts,
-		pool: p,
+		pool: newPool(opts),
 		seq:  0,
 	}
 	rc.once.Store(false)
@@ -225,7 +241,7 @@ func (r *rpcClient) stream(ctx context.Context, node *registry.Node, req Request
 		dOpts = append(dOpts, transport.WithTimeout(opts.DialTimeout))
 	}
 
-	c, err := r.opts.Transport.Dial(address, dOpts...)
+	c, err := transport.DialContext(ctx, r.opts.Transport, address, dOpts...)
 	if err != nil {
 		return nil, errors.InternalServerError("go.micro.client", "connection error: %v", err)
 	}
@@ -296,21 +312,18 @@ func (r *rpcClient) Init(opts ...Option) error {
 	size := r.opts.PoolSize
 	ttl := r.opts.PoolTTL
 	tr := r.opts.Transport
+	popts := len(r.opts.PoolOptions)
 
 	for _, o := range opts {
 		o(&r.opts)
 	}
 
 	// update pool configuration if the options changed
-	if size != r.opts.PoolSize || ttl != r.opts.PoolTTL || tr != r.opts.Transport {
+	if size != r.opts.PoolSize || ttl != r.opts.PoolTTL || tr != r.opts.Transport || popts != len(r.opts.PoolOptions) {
 		// close existing pool
 		r.pool.Close()
 		// create new pool
-		r.pool = pool.NewPool(
-			pool.Size(r.opts.PoolSize),
-			pool.TTL(r.opts.PoolTTL),
-			pool.Transport(r.opts.Transport),
-		)
+		r.pool = newPool(r.opts)
 	}
 
 	return nil
@@ -8,6 +8,7 @@ import (
 	"github.com/asim/go-micro/v3/errors"
 	"github.com/asim/go-micro/v3/registry"
 	"github.com/asim/go-micro/v3/selector"
+	"github.com/asim/go-micro/v3/transport"
 )
 
 func newTestRegistry() registry.Registry {
@@ -158,3 +159,80 @@ func TestCallWrapper(t *testing.T) {
 		t.Fatal("wrapper not called")
 	}
 }
+
+func TestPoolStats(t *testing.T) {
+	tr := transport.NewMemoryTransport()
+
+	l, err := tr.Listen(":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go l.Accept(func(s transport.Socket) {
+		var msg transport.Message
+		s.Recv(&msg)
+	})
+
+	c := NewClient(Transport(tr))
+
+	// dial a connection through the pool of the client
+	p := c.(*rpcClient).pool
+	conn, err := p.Get(l.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Release(conn, nil)
+
+	stats, ok := PoolStats(c)
+	if !ok {
+		t.Fatal("Expected the stats of the pool")
+	}
+	if stats.Dials != 1 || stats.Open != 1 || stats.Idle != 1 {
+		t.Fatalf("Unexpected stats %+v", stats)
+	}
+
+	// other clients don't have stats
+	if _, ok := PoolStats(&testClient{c}); ok {
+		t.Fatal("Expected no stats for another client")
+	}
+}
+
+type testClient struct {
+	Client
+}
+
+func TestRetryOnError(t *testing.T) {
+	testData := []struct {
+		err       error
+		retry     bool
+		retryable bool
+	}{
+		{nil, false, false},
+		{fmt.Errorf("boom"), false, false},
+		{errors.InternalServerError("test.error", "boom"), true, false},
+		{errors.Timeout("test.error", "timeout"), true, true},
+		{errors.New("test.error", "unavailable", 503), false, true},
+		{errors.BadRequest("test.error", "bad"), false, false},
+		{errors.Retryable(errors.BadRequest("test.error", "bad")), true, true},
+	}
+
+	for _, d := range testData {
+		retry, err := RetryOnError(context.TODO(), nil, 1, d.err)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if retry != d.retry {
+			t.Fatalf("Expected %v to be retried %v", d.err, d.retry)
+		}
+
+		// the wider policy is opt in
+		retry, err = RetryOnRetryable(context.TODO(), nil, 1, d.err)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if retry != d.retryable {
+			t.Fatalf("Expected %v to be retried %v with RetryOnRetryable", d.err, d.retryable)
+		}
+	}
+}
@@ -207,18 +207,27 @@ func (c *rpcCodec) Write(m *codec.Message, body interface{}) error {
 
 	// send the request
 	if err := c.client.Send(&msg); err != nil {
-		return errors.InternalServerError("go.micro.client.transport", err.Error())
+		return transportError(err)
 	}
 
 	return nil
 }
 
+// transportError returns the error of the transport, messages which are too
+// large aren't worth retrying so they don't fail as an internal error
+func transportError(err error) error {
+	if errs.Is(err, transport.ErrMessageTooLarge) {
+		return errors.RequestEntityTooLarge("go.micro.client.transport", err.Error())
+	}
+	return errors.InternalServerError("go.micro.client.transport", err.Error())
+}
+
 func (c *rpcCodec) ReadHeader(m *codec.Message, r codec.MessageType) error {
 	var tm transport.Message
 
 	// read message from transport
 	if err := c.client.Recv(&tm); err != nil {
-		return errors.InternalServerError("go.micro.client.transport", err.Error())
+		return transportError(err)
 	}
 
 	c.buf.rbuf.Reset()
@@ -191,6 +191,11 @@ var (
 			EnvVars: []string{"MICRO_TRANSPORT_ADDRESS"},
 			Usage:   "Comma-separated list of transport addresses",
 		},
+		&cli.StringFlag{
+			Name:    "transport_compression",
+			EnvVars: []string{"MICRO_TRANSPORT_COMPRESSION"},
+			Usage:   "Comma-separated list of compressions negotiated by the transport e.g gzip",
+		},
 		&cli.StringFlag{
 			Name:    "tracer",
 			EnvVars: []string{"MICRO_TRACER"},
@@ -404,6 +409,18 @@ func (c *cmd) Before(ctx *cli.Context) error {
 		authOpts = append(authOpts, auth.Namespace(ctx.String("auth_namespace")))
 	}
 
+	// Set the auth
+	if name := ctx.String("auth"); len(name) > 0 && (*c.opts.Auth).String() != name {
+		a, ok := c.opts.Auths[name]
+		if !ok {
+			return fmt.Errorf("Unsupported auth: %s", name)
+		}
+
+		*c.opts.Auth = a(authOpts...)
+	} else if len(authOpts) > 0 {
+		(*c.opts.Auth).Init(authOpts...)
+	}
+
 	// Set the registry
 	if name := ctx.String("registry"); len(name) > 0 && (*c.opts.Registry).String() != name {
 		r, ok := c.opts.Registries[name]
@@ -507,6 +524,12 @@ func (c *cmd) Before(ctx *cli.Context) error {
 		}
 	}
 
+	if len(ctx.String("transport_compression")) > 0 {
+		if err := (*c.opts.Transport).Init(transport.Compression(strings.Split(ctx.String("transport_compression"), ",")...)); err != nil {
+			logger.Fatalf("Error configuring transport: %v", err)
+		}
+	}
+
 	if len(ctx.String("store_address")) > 0 {
 		if err := (*c.opts.Store).Init(store.Nodes(strings.Split(ctx.String("store_address"), ",")...)); err != nil {
 			logger.Fatalf("Error configuring store: %v", err)
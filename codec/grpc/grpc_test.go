@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/asim/go-micro/v3/codec/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type testConn struct {
+	*bytes.Buffer
+}
+
+func (testConn) Close() error {
+	return nil
+}
+
+func TestReadBodyRawMessages(t *testing.T) {
+	conn := testConn{new(bytes.Buffer)}
+	c := &Codec{Conn: conn, ContentType: "application/grpc+proto"}
+
+	if err := encode(0, []byte("first-message"), conn); err != nil {
+		t.Fatal(err)
+	}
+	first := new(proto.Message)
+	if err := c.ReadBody(first); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := encode(0, []byte("SECOND-MESSAGE"), conn); err != nil {
+		t.Fatal(err)
+	}
+	second := new(proto.Message)
+	if err := c.ReadBody(second); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(first.Data); got != "first-message" {
+		t.Fatalf("Expected the first message to be unchanged, got %q", got)
+	}
+	if got := string(second.Data); got != "SECOND-MESSAGE" {
+		t.Fatalf("Expected SECOND-MESSAGE, got %q", got)
+	}
+}
+
+func benchmarkReadBody(b *testing.B, size int) {
+	body, err := proto.Marshaler{}.Marshal(wrapperspb.Bytes(make([]byte, size)))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	conn := testConn{new(bytes.Buffer)}
+	c := &Codec{Conn: conn, ContentType: "application/grpc+proto"}
+	m := new(wrapperspb.BytesValue)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		encode(0, body, conn)
+		if err := c.ReadBody(m); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadBody1K(b *testing.B)  { benchmarkReadBody(b, 1024) }
+func BenchmarkReadBody64K(b *testing.B) { benchmarkReadBody(b, 64*1024) }
@@ -11,6 +11,8 @@ var (
 	maxInt         = int(^uint(0) >> 1)
 )
 
+// decode reads a message, the returned bytes aren't pooled since raw
+// messages keep them
 func decode(r io.Reader) (uint8, []byte, error) {
 	header := make([]byte, 5)
 
@@ -40,7 +42,7 @@ func decode(r io.Reader) (uint8, []byte, error) {
 
 	msg := make([]byte, int(length))
 
-	if _, err := r.Read(msg); err != nil {
+	if _, err := io.ReadFull(r, msg); err != nil {
 		if err == io.EOF {
 			err = io.ErrUnexpectedEOF
 		}
@@ -4,26 +4,24 @@ import (
 	"bytes"
 	"encoding/json"
 
+	"github.com/asim/go-micro/v3/util/buf"
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
-	"github.com/oxtoacart/bpool"
 )
 
 var jsonpbMarshaler = &jsonpb.Marshaler{}
 
-// create buffer pool with 16 instances each preallocated with 256 bytes
-var bufferPool = bpool.NewSizedBufferPool(16, 256)
-
 type Marshaler struct{}
 
 func (j Marshaler) Marshal(v interface{}) ([]byte, error) {
 	if pb, ok := v.(proto.Message); ok {
-		buf := bufferPool.Get()
-		defer bufferPool.Put(buf)
-		if err := jsonpbMarshaler.Marshal(buf, pb); err != nil {
+		b := buf.Get()
+		defer buf.Put(b)
+		if err := jsonpbMarshaler.Marshal(b, pb); err != nil {
 			return nil, err
 		}
-		return buf.Bytes(), nil
+		// the pooled buffer is reused
+		return append([]byte(nil), b.Bytes()...), nil
 	}
 	return json.Marshal(v)
 }
@@ -0,0 +1,24 @@
+package json
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func benchmarkMarshal(b *testing.B, size int) {
+	m := wrapperspb.String(strings.Repeat("a", size))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := (Marshaler{}).Marshal(m); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshal1K(b *testing.B)  { benchmarkMarshal(b, 1024) }
+func BenchmarkMarshal64K(b *testing.B) { benchmarkMarshal(b, 64*1024) }
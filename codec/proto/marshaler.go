@@ -1,15 +1,33 @@
 package proto
 
 import (
-	"bytes"
+	"sync"
 
 	"github.com/asim/go-micro/v3/codec"
 	"github.com/golang/protobuf/proto"
-	"github.com/oxtoacart/bpool"
 )
 
-// create buffer pool with 16 instances each preallocated with 256 bytes
-var bufferPool = bpool.NewSizedBufferPool(16, 256)
+// maxBufferSize is the capacity beyond which buffers aren't pooled
+const maxBufferSize = 1024 * 1024
+
+// buffers are reused to marshal messages
+var buffers = sync.Pool{
+	New: func() interface{} {
+		return proto.NewBuffer(nil)
+	},
+}
+
+func getBuffer() *proto.Buffer {
+	return buffers.Get().(*proto.Buffer)
+}
+
+func putBuffer(b *proto.Buffer) {
+	if cap(b.Bytes()) > maxBufferSize {
+		return
+	}
+	b.Reset()
+	buffers.Put(b)
+}
 
 type Marshaler struct{}
 
@@ -19,18 +37,8 @@ func (Marshaler) Marshal(v interface{}) ([]byte, error) {
 		return nil, codec.ErrInvalidMessage
 	}
 
-	// looks not good, but allows to reuse underlining bytes
-	buf := bufferPool.Get()
-	pbuf := proto.NewBuffer(buf.Bytes())
-	defer func() {
-		bufferPool.Put(bytes.NewBuffer(pbuf.Bytes()))
-	}()
-
-	if err := pbuf.Marshal(pb); err != nil {
-		return nil, err
-	}
-
-	return pbuf.Bytes(), nil
+	// marshal allocates the bytes at their size so there's nothing to pool
+	return proto.Marshal(pb)
 }
 
 func (Marshaler) Unmarshal(data []byte, v interface{}) error {
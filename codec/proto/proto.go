@@ -3,9 +3,9 @@ package proto
 
 import (
 	"io"
-	"io/ioutil"
 
 	"github.com/asim/go-micro/v3/codec"
+	"github.com/asim/go-micro/v3/util/buf"
 	"github.com/golang/protobuf/proto"
 )
 
@@ -13,6 +13,10 @@ type Codec struct {
 	Conn io.ReadWriteCloser
 }
 
+type unmarshaler interface {
+	Unmarshal([]byte) error
+}
+
 func (c *Codec) ReadHeader(m *codec.Message, t codec.MessageType) error {
 	return nil
 }
@@ -21,15 +25,22 @@ func (c *Codec) ReadBody(b interface{}) error {
 	if b == nil {
 		return nil
 	}
-	buf, err := ioutil.ReadAll(c.Conn)
-	if err != nil {
+	rb := buf.Get()
+	defer buf.Put(rb)
+	if _, err := rb.ReadFrom(c.Conn); err != nil {
 		return err
 	}
 	m, ok := b.(proto.Message)
 	if !ok {
 		return codec.ErrInvalidMessage
 	}
-	return proto.Unmarshal(buf, m)
+	// messages unmarshalling themselves like raw messages may keep the
+	// bytes they're given and the pooled buffer is reused, so they get a
+	// copy. Generated messages copy what they keep.
+	if _, ok := m.(unmarshaler); ok {
+		return proto.Unmarshal(append([]byte(nil), rb.Bytes()...), m)
+	}
+	return proto.Unmarshal(rb.Bytes(), m)
 }
 
 func (c *Codec) Write(m *codec.Message, b interface{}) error {
@@ -41,11 +52,12 @@ func (c *Codec) Write(m *codec.Message, b interface{}) error {
 	if !ok {
 		return codec.ErrInvalidMessage
 	}
-	buf, err := proto.Marshal(p)
-	if err != nil {
+	pbuf := getBuffer()
+	defer putBuffer(pbuf)
+	if err := pbuf.Marshal(p); err != nil {
 		return err
 	}
-	_, err = c.Conn.Write(buf)
+	_, err := c.Conn.Write(pbuf.Bytes())
 	return err
 }
 
@@ -0,0 +1,98 @@
+package proto
+
+import (
+	"bytes"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type testConn struct {
+	*bytes.Buffer
+}
+
+func (testConn) Close() error {
+	return nil
+}
+
+func TestReadBodyRawMessages(t *testing.T) {
+	conn := testConn{new(bytes.Buffer)}
+	c := NewCodec(conn)
+
+	conn.WriteString("first-message")
+	first := new(Message)
+	if err := c.ReadBody(first); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.WriteString("SECOND-MESSAGE")
+	second := new(Message)
+	if err := c.ReadBody(second); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(first.Data); got != "first-message" {
+		t.Fatalf("Expected the first message to be unchanged, got %q", got)
+	}
+	if got := string(second.Data); got != "SECOND-MESSAGE" {
+		t.Fatalf("Expected SECOND-MESSAGE, got %q", got)
+	}
+}
+
+func benchmarkReadBody(b *testing.B, size int) {
+	body, err := Marshaler{}.Marshal(wrapperspb.Bytes(make([]byte, size)))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	conn := testConn{new(bytes.Buffer)}
+	c := NewCodec(conn)
+	m := new(wrapperspb.BytesValue)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		conn.Write(body)
+		if err := c.ReadBody(m); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkWrite(b *testing.B, size int) {
+	conn := testConn{new(bytes.Buffer)}
+	c := NewCodec(conn)
+	m := wrapperspb.Bytes(make([]byte, size))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		conn.Reset()
+		if err := c.Write(nil, m); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkMarshal(b *testing.B, size int) {
+	m := wrapperspb.Bytes(make([]byte, size))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := (Marshaler{}).Marshal(m); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadBody1K(b *testing.B)  { benchmarkReadBody(b, 1024) }
+func BenchmarkReadBody64K(b *testing.B) { benchmarkReadBody(b, 64*1024) }
+func BenchmarkWrite1K(b *testing.B)     { benchmarkWrite(b, 1024) }
+func BenchmarkWrite64K(b *testing.B)    { benchmarkWrite(b, 64*1024) }
+func BenchmarkMarshal1K(b *testing.B)   { benchmarkMarshal(b, 1024) }
+func BenchmarkMarshal64K(b *testing.B)  { benchmarkMarshal(b, 64*1024) }
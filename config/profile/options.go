@@ -0,0 +1,56 @@
+package profile
+
+import (
+	"github.com/asim/go-micro/v3/config/source"
+)
+
+var (
+	// DefaultPath is the file profiles are read from
+	DefaultPath = "micro.json"
+	// DefaultEnvPrefix is the prefix of environment variables overriding
+	// profiles e.g MICRO_PROFILE_PRODUCTION_REGISTRY_NAME=etcd
+	DefaultEnvPrefix = "MICRO_PROFILE"
+)
+
+type Options struct {
+	// Path of the file profiles are read from, it's skipped if missing
+	Path string
+	// EnvPrefix of environment variables overriding the file
+	EnvPrefix string
+	// Sources read after the file and environment
+	Sources []source.Source
+}
+
+type Option func(o *Options)
+
+// Path sets the file profiles are read from
+func Path(p string) Option {
+	return func(o *Options) {
+		o.Path = p
+	}
+}
+
+// EnvPrefix sets the prefix of environment variables overriding profiles
+func EnvPrefix(p string) Option {
+	return func(o *Options) {
+		o.EnvPrefix = p
+	}
+}
+
+// Source adds a source profiles are read from
+func Source(s source.Source) Option {
+	return func(o *Options) {
+		o.Sources = append(o.Sources, s)
+	}
+}
+
+func NewOptions(opts ...Option) Options {
+	options := Options{
+		Path:      DefaultPath,
+		EnvPrefix: DefaultEnvPrefix,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
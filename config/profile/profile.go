@@ -0,0 +1,446 @@
+// Package profile initialises services from profiles declared in a config
+// file and the environment rather than flags and code e.g
+//
+//	// wrappers are selected by the name they're registered with
+//	profile.DefaultHandlerWrappers["prometheus"] = prometheus.NewHandlerWrapper()
+//
+//	service := micro.NewService(
+//		micro.Name("greeter"),
+//		profile.Load("production"),
+//	)
+//
+// with a micro.json of
+//
+//	{
+//		"production": {
+//			"registry": {"name": "etcd", "address": "10.0.0.1:2379"},
+//			"broker": {"name": "nats", "address": ["10.0.0.2:4222"]},
+//			"tls": {"cert": "/etc/micro/cert.pem", "key": "/etc/micro/key.pem", "ca": "/etc/micro/ca.pem"},
+//			"client": {"retries": 3, "timeout": "10s"},
+//			"wrappers": {"handler": ["prometheus"]}
+//		}
+//	}
+//
+// Any value can be overridden by the environment e.g
+// MICRO_PROFILE_PRODUCTION_CLIENT_RETRIES=5. Flags still take precedence
+// when the service is initialised.
+//
+// Profiles are loaded with profile.Load rather than a micro.Profile option
+// since micro.Profile already sets the debug profiler, and the package
+// imports micro to apply profiles so micro can't import it in turn.
+package profile
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asim/go-micro/v3"
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/cmd"
+	"github.com/asim/go-micro/v3/config"
+	"github.com/asim/go-micro/v3/config/source/env"
+	"github.com/asim/go-micro/v3/config/source/file"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/server"
+	"github.com/asim/go-micro/v3/transport"
+)
+
+var (
+	// DefaultClientWrappers are the client wrappers profiles can select,
+	// none are registered by default
+	DefaultClientWrappers = map[string]client.Wrapper{}
+	// DefaultHandlerWrappers are the handler wrappers profiles can select,
+	// none are registered by default
+	DefaultHandlerWrappers = map[string]server.HandlerWrapper{}
+	// DefaultSubscriberWrappers are the subscriber wrappers profiles can
+	// select, none are registered by default
+	DefaultSubscriberWrappers = map[string]server.SubscriberWrapper{}
+
+	// built in implementations, plugins are found in the cmd defaults
+	registries = map[string]func(...registry.Option) registry.Registry{
+		"mdns":   registry.NewRegistry,
+		"memory": registry.NewMemoryRegistry,
+	}
+	brokers = map[string]func(...broker.Option) broker.Broker{
+		"http": broker.NewBroker,
+	}
+	transports = map[string]func(...transport.Option) transport.Transport{
+		"http": func(opts ...transport.Option) transport.Transport {
+			return transport.NewHTTPTransport(opts...)
+		},
+	}
+)
+
+// Profile is the declarative configuration of a service
+type Profile struct {
+	Registry  Plugin   `json:"registry"`
+	Broker    Plugin   `json:"broker"`
+	Transport Plugin   `json:"transport"`
+	Server    Server   `json:"server"`
+	Client    Client   `json:"client"`
+	TLS       *TLS     `json:"tls"`
+	Wrappers  Wrappers `json:"wrappers"`
+}
+
+// Plugin selects the implementation of a component and its addresses
+type Plugin struct {
+	Name    string `json:"name"`
+	Address Addrs  `json:"address"`
+}
+
+// Server configures the server of the service
+type Server struct {
+	Address   string            `json:"address"`
+	Advertise string            `json:"advertise"`
+	Metadata  map[string]string `json:"metadata"`
+	// TTL and Interval of the registration
+	TTL      Duration `json:"ttl"`
+	Interval Duration `json:"interval"`
+}
+
+// Client configures the client of the service
+type Client struct {
+	Retries *int     `json:"retries"`
+	Timeout Duration `json:"timeout"`
+	Pool    int      `json:"pool"`
+}
+
+// TLS secures the transport, broker, registry and server with the files
+type TLS struct {
+	Cert string `json:"cert"`
+	Key  string `json:"key"`
+	// CA verifies peers instead of the system roots
+	CA string `json:"ca"`
+	// Insecure skips the verification of peers
+	Insecure bool `json:"insecure"`
+}
+
+// Wrappers are the names of the wrappers to use, in order
+type Wrappers struct {
+	Client     Names `json:"client"`
+	Handler    Names `json:"handler"`
+	Subscriber Names `json:"subscriber"`
+}
+
+// Addrs are a list of addresses, or a comma separated string of them
+type Addrs []string
+
+func (a *Addrs) UnmarshalJSON(b []byte) error {
+	list, err := unmarshalList(b)
+	*a = list
+	return err
+}
+
+// Names are a list of names, or a comma separated string of them
+type Names []string
+
+func (n *Names) UnmarshalJSON(b []byte) error {
+	list, err := unmarshalList(b)
+	*n = list
+	return err
+}
+
+func unmarshalList(b []byte) ([]string, error) {
+	var list []string
+	if err := json.Unmarshal(b, &list); err == nil {
+		return list, nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); len(v) > 0 {
+			list = append(list, v)
+		}
+	}
+	return list, nil
+}
+
+// Duration is a duration such as "10s", or a number of seconds
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		var secs float64
+		if err := json.Unmarshal(b, &secs); err != nil {
+			return err
+		}
+		*d = Duration(secs * float64(time.Second))
+		return nil
+	}
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		*d = Duration(secs * float64(time.Second))
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Read returns the profile from the file and environment
+func Read(name string, opts ...Option) (*Profile, error) {
+	options := NewOptions(opts...)
+
+	var copts []config.Option
+	if _, err := os.Stat(options.Path); err == nil {
+		copts = append(copts, config.WithSource(file.NewSource(file.WithPath(options.Path))))
+	}
+	if len(options.EnvPrefix) > 0 {
+		copts = append(copts, config.WithSource(env.NewSource(env.WithStrippedPrefix(options.EnvPrefix))))
+	}
+	for _, s := range options.Sources {
+		copts = append(copts, config.WithSource(s))
+	}
+
+	c, err := config.NewConfig(copts...)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	// environment variables are lower case
+	v := c.Get(strings.ToLower(name))
+	var m map[string]interface{}
+	if err := v.Scan(&m); err != nil || len(m) == 0 {
+		return nil, fmt.Errorf("profile %s not found", name)
+	}
+
+	p := new(Profile)
+	if err := v.Scan(p); err != nil {
+		return nil, fmt.Errorf("invalid profile %s: %v", name, err)
+	}
+	return p, nil
+}
+
+// Load returns a service option initialising the service with the profile,
+// it should come after the options of the components it configures
+func Load(name string, opts ...Option) micro.Option {
+	return func(o *micro.Options) {
+		p, err := Read(name, opts...)
+		if err != nil {
+			logger.Fatalf("Error loading profile: %v", err)
+		}
+		if err := p.Apply(o); err != nil {
+			logger.Fatalf("Error applying profile %s: %v", name, err)
+		}
+	}
+}
+
+// Apply initialises the service options with the profile
+func (p *Profile) Apply(o *micro.Options) error {
+	var tlsConfig *tls.Config
+	if p.TLS != nil {
+		var err error
+		if tlsConfig, err = p.TLS.Config(); err != nil {
+			return err
+		}
+	}
+
+	// the registry first as the broker uses it
+	if err := p.applyRegistry(o, tlsConfig); err != nil {
+		return err
+	}
+	if err := p.applyBroker(o, tlsConfig); err != nil {
+		return err
+	}
+	if err := p.applyTransport(o, tlsConfig); err != nil {
+		return err
+	}
+
+	// server options
+	var sopts []server.Option
+	if len(p.Server.Address) > 0 {
+		sopts = append(sopts, server.Address(p.Server.Address))
+	}
+	if len(p.Server.Advertise) > 0 {
+		sopts = append(sopts, server.Advertise(p.Server.Advertise))
+	}
+	if len(p.Server.Metadata) > 0 {
+		sopts = append(sopts, server.Metadata(p.Server.Metadata))
+	}
+	if p.Server.TTL > 0 {
+		sopts = append(sopts, server.RegisterTTL(time.Duration(p.Server.TTL)))
+	}
+	if p.Server.Interval > 0 {
+		sopts = append(sopts, server.RegisterInterval(time.Duration(p.Server.Interval)))
+	}
+	if tlsConfig != nil {
+		sopts = append(sopts, server.TLSConfig(tlsConfig))
+	}
+	if len(sopts) > 0 {
+		if err := o.Server.Init(sopts...); err != nil {
+			return err
+		}
+	}
+
+	// client options
+	var copts []client.Option
+	if p.Client.Retries != nil {
+		copts = append(copts, client.Retries(*p.Client.Retries))
+	}
+	if p.Client.Timeout > 0 {
+		copts = append(copts, client.RequestTimeout(time.Duration(p.Client.Timeout)))
+	}
+	if p.Client.Pool > 0 {
+		copts = append(copts, client.PoolSize(p.Client.Pool))
+	}
+	if len(copts) > 0 {
+		if err := o.Client.Init(copts...); err != nil {
+			return err
+		}
+	}
+
+	return p.applyWrappers(o)
+}
+
+func (p *Profile) applyRegistry(o *micro.Options, tlsConfig *tls.Config) error {
+	var ropts []registry.Option
+	if len(p.Registry.Address) > 0 {
+		ropts = append(ropts, registry.Addrs(p.Registry.Address...))
+	}
+	if tlsConfig != nil {
+		ropts = append(ropts, registry.Secure(true), registry.TLSConfig(tlsConfig))
+	}
+
+	if len(p.Registry.Name) == 0 {
+		if len(ropts) == 0 {
+			return nil
+		}
+		return o.Registry.Init(ropts...)
+	}
+
+	fn, ok := registries[p.Registry.Name]
+	if !ok {
+		fn, ok = cmd.DefaultRegistries[p.Registry.Name]
+	}
+	if !ok {
+		return fmt.Errorf("registry %s not found", p.Registry.Name)
+	}
+	micro.Registry(fn(ropts...))(o)
+	return nil
+}
+
+func (p *Profile) applyBroker(o *micro.Options, tlsConfig *tls.Config) error {
+	bopts := []broker.Option{broker.Registry(o.Registry)}
+	if len(p.Broker.Address) > 0 {
+		bopts = append(bopts, broker.Addrs(p.Broker.Address...))
+	}
+	if tlsConfig != nil {
+		bopts = append(bopts, broker.Secure(true), broker.TLSConfig(tlsConfig))
+	}
+
+	if len(p.Broker.Name) == 0 {
+		if len(bopts) == 1 {
+			return nil
+		}
+		return o.Broker.Init(bopts...)
+	}
+
+	fn, ok := brokers[p.Broker.Name]
+	if !ok {
+		fn, ok = cmd.DefaultBrokers[p.Broker.Name]
+	}
+	if !ok {
+		return fmt.Errorf("broker %s not found", p.Broker.Name)
+	}
+	micro.Broker(fn(bopts...))(o)
+	return nil
+}
+
+func (p *Profile) applyTransport(o *micro.Options, tlsConfig *tls.Config) error {
+	var topts []transport.Option
+	if len(p.Transport.Address) > 0 {
+		topts = append(topts, transport.Addrs(p.Transport.Address...))
+	}
+	if tlsConfig != nil {
+		topts = append(topts, transport.Secure(true), transport.TLSConfig(tlsConfig))
+	}
+
+	if len(p.Transport.Name) == 0 {
+		if len(topts) == 0 {
+			return nil
+		}
+		return o.Transport.Init(topts...)
+	}
+
+	fn, ok := transports[p.Transport.Name]
+	if !ok {
+		fn, ok = cmd.DefaultTransports[p.Transport.Name]
+	}
+	if !ok {
+		return fmt.Errorf("transport %s not found", p.Transport.Name)
+	}
+	micro.Transport(fn(topts...))(o)
+	return nil
+}
+
+func (p *Profile) applyWrappers(o *micro.Options) error {
+	for _, name := range p.Wrappers.Client {
+		w, ok := DefaultClientWrappers[name]
+		if !ok {
+			return fmt.Errorf("client wrapper %s not found", name)
+		}
+		micro.WrapClient(w)(o)
+	}
+	for _, name := range p.Wrappers.Handler {
+		w, ok := DefaultHandlerWrappers[name]
+		if !ok {
+			return fmt.Errorf("handler wrapper %s not found", name)
+		}
+		micro.WrapHandler(w)(o)
+	}
+	for _, name := range p.Wrappers.Subscriber {
+		w, ok := DefaultSubscriberWrappers[name]
+		if !ok {
+			return fmt.Errorf("subscriber wrapper %s not found", name)
+		}
+		micro.WrapSubscriber(w)(o)
+	}
+	return nil
+}
+
+// Config returns the tls config of the files
+func (t *TLS) Config() (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: t.Insecure,
+	}
+
+	if len(t.Cert) > 0 || len(t.Key) > 0 {
+		cert, err := tls.LoadX509KeyPair(t.Cert, t.Key)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(t.CA) > 0 {
+		b, err := ioutil.ReadFile(t.CA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificates found in %s", t.CA)
+		}
+		// verify both servers and clients
+		config.RootCAs = pool
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
@@ -0,0 +1,101 @@
+package profile
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3"
+	"github.com/asim/go-micro/v3/server"
+)
+
+func TestRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "micro.json")
+	data := []byte(`{
+		"production": {
+			"registry": {"name": "memory"},
+			"broker": {"name": "http", "address": "10.0.0.1:8001,10.0.0.2:8001"},
+			"server": {"address": ":9090", "ttl": "30s", "interval": 15},
+			"client": {"retries": 0, "timeout": "10s"},
+			"wrappers": {"handler": ["foo", "bar"]}
+		}
+	}`)
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("MICRO_PROFILE_PRODUCTION_SERVER_ADVERTISE", "10.0.0.3:9090")
+	defer os.Unsetenv("MICRO_PROFILE_PRODUCTION_SERVER_ADVERTISE")
+
+	p, err := Read("production", Path(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Registry.Name != "memory" {
+		t.Errorf("Expected registry memory, got %v", p.Registry.Name)
+	}
+	if len(p.Broker.Address) != 2 || p.Broker.Address[1] != "10.0.0.2:8001" {
+		t.Errorf("Expected two broker addresses, got %v", p.Broker.Address)
+	}
+	if time.Duration(p.Server.TTL) != 30*time.Second {
+		t.Errorf("Expected ttl 30s, got %v", time.Duration(p.Server.TTL))
+	}
+	if time.Duration(p.Server.Interval) != 15*time.Second {
+		t.Errorf("Expected interval 15s, got %v", time.Duration(p.Server.Interval))
+	}
+	if p.Server.Advertise != "10.0.0.3:9090" {
+		t.Errorf("Expected advertise from the environment, got %v", p.Server.Advertise)
+	}
+	if p.Client.Retries == nil || *p.Client.Retries != 0 {
+		t.Errorf("Expected retries 0, got %v", p.Client.Retries)
+	}
+	if len(p.Wrappers.Handler) != 2 {
+		t.Errorf("Expected two handler wrappers, got %v", p.Wrappers.Handler)
+	}
+
+	if _, err := Read("staging", Path(path)); err == nil {
+		t.Error("Expected an error reading a missing profile")
+	}
+}
+
+func TestApplyWrappers(t *testing.T) {
+	var wrapped bool
+	DefaultHandlerWrappers["prometheus"] = func(fn server.HandlerFunc) server.HandlerFunc {
+		wrapped = true
+		return fn
+	}
+	defer delete(DefaultHandlerWrappers, "prometheus")
+
+	o := micro.Options{Server: server.NewServer()}
+
+	p := &Profile{Wrappers: Wrappers{Handler: Names{"prometheus"}}}
+	if err := p.Apply(&o); err != nil {
+		t.Fatal(err)
+	}
+
+	wrappers := o.Server.Options().HdlrWrappers
+	if len(wrappers) != 1 {
+		t.Fatalf("Expected one handler wrapper, got %d", len(wrappers))
+	}
+	wrappers[0](func(context.Context, server.Request, interface{}) error {
+		return nil
+	})
+	if !wrapped {
+		t.Error("Expected the registered wrapper to be applied")
+	}
+
+	p = &Profile{Wrappers: Wrappers{Handler: Names{"missing"}}}
+	if err := p.Apply(&o); err == nil {
+		t.Error("Expected an error applying an unregistered wrapper")
+	}
+}
@@ -0,0 +1,110 @@
+package stats
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Request is a completed request to a route
+type Request struct {
+	// Service the request was sent to
+	Service string
+	// Endpoint is the method or path called
+	Endpoint string
+	// Duration of the request
+	Duration time.Duration
+	// Bytes received from the caller
+	BytesIn uint64
+	// Bytes sent to the caller
+	BytesOut uint64
+	// Error returned if any
+	Error error
+}
+
+// Route are the stats of requests to a service endpoint
+type Route struct {
+	Service  string
+	Endpoint string
+	// Total requests
+	Requests uint64
+	// Total errors
+	Errors uint64
+	// Requests or streams in progress
+	Active int64
+	// Total bytes received and sent
+	BytesIn  uint64
+	BytesOut uint64
+	// Total and max latency
+	Latency    time.Duration
+	MaxLatency time.Duration
+}
+
+// Routes records per route stats e.g for a proxy
+type Routes struct {
+	sync.RWMutex
+	routes map[string]*Route
+}
+
+// NewRoutes returns an empty set of route stats
+func NewRoutes() *Routes {
+	return &Routes{
+		routes: make(map[string]*Route),
+	}
+}
+
+func (r *Routes) get(service, endpoint string) *Route {
+	key := service + ":" + endpoint
+	route, ok := r.routes[key]
+	if !ok {
+		route = &Route{Service: service, Endpoint: endpoint}
+		r.routes[key] = route
+	}
+	return route
+}
+
+// Start marks a request to the route as active
+func (r *Routes) Start(service, endpoint string) {
+	r.Lock()
+	r.get(service, endpoint).Active++
+	r.Unlock()
+}
+
+// Record records a completed request started with Start
+func (r *Routes) Record(req *Request) {
+	r.Lock()
+	defer r.Unlock()
+
+	route := r.get(req.Service, req.Endpoint)
+	route.Active--
+	route.Requests++
+	if req.Error != nil {
+		route.Errors++
+	}
+	route.BytesIn += req.BytesIn
+	route.BytesOut += req.BytesOut
+	route.Latency += req.Duration
+	if req.Duration > route.MaxLatency {
+		route.MaxLatency = req.Duration
+	}
+}
+
+// Read returns a copy of the stats ordered by service and endpoint
+func (r *Routes) Read() []*Route {
+	r.RLock()
+	routes := make([]*Route, 0, len(r.routes))
+	for _, route := range r.routes {
+		v := *route
+		routes = append(routes, &v)
+	}
+	r.RUnlock()
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Service == routes[j].Service {
+			return routes[i].Endpoint < routes[j].Endpoint
+		}
+		return routes[i].Service < routes[j].Service
+	})
+
+	return routes
+}
@@ -3,7 +3,9 @@
 package errors
 
 import (
+	"context"
 	"encoding/json"
+	er "errors"
 	"fmt"
 	"net/http"
 )
@@ -15,14 +17,62 @@ func (e *Error) Error() string {
 	return string(b)
 }
 
+// wrapError is an *Error along with the error it wraps. The cause is kept
+// out of the generated Error so it's never sent with the error.
+type wrapError struct {
+	err   *Error
+	cause error
+}
+
+func (w *wrapError) Error() string {
+	return w.err.Error()
+}
+
+// Unwrap returns the cause of the error
+func (w *wrapError) Unwrap() error {
+	return w.cause
+}
+
+// Is reports whether the target is an *Error with the same code
+func (w *wrapError) Is(target error) bool {
+	return w.err.Is(target)
+}
+
+// As sets the target to the *Error so the error is found by errors.As
+func (w *wrapError) As(target interface{}) bool {
+	t, ok := target.(**Error)
+	if !ok {
+		return false
+	}
+	*t = w.err
+	return true
+}
+
 // New generates a custom error.
 func New(id, detail string, code int32) error {
 	return &Error{
 		Id:     id,
 		Code:   code,
 		Detail: detail,
-		Status: http.StatusText(int(code)),
+		Status: statusText(code),
+	}
+}
+
+// Wrap generates an error with the code which wraps the cause, so it's
+// found by errors.Is and errors.As. The cause isn't sent with the error.
+func Wrap(cause error, id string, code int32, format string, a ...interface{}) error {
+	e := &Error{
+		Id:     id,
+		Code:   code,
+		Detail: fmt.Sprintf(format, a...),
+		Status: statusText(code),
+	}
+	// keep the cause retryable
+	var verr *Error
+	if er.As(cause, &verr) && verr != nil {
+		e.Retryable = verr.Retryable
 	}
+	return &wrapError{err: e, cause: cause}
 }
 
 // Parse tries to parse a JSON string into an error. If that
@@ -106,6 +156,16 @@ func Conflict(id, format string, a ...interface{}) error {
 	}
 }
 
+// RequestEntityTooLarge generates a 413 error.
+func RequestEntityTooLarge(id, format string, a ...interface{}) error {
+	return &Error{
+		Id:     id,
+		Code:   413,
+		Detail: fmt.Sprintf(format, a...),
+		Status: http.StatusText(413),
+	}
+}
+
 // InternalServerError generates a 500 error.
 func InternalServerError(id, format string, a ...interface{}) error {
 	return &Error{
@@ -116,6 +176,15 @@ func InternalServerError(id, format string, a ...interface{}) error {
 	}
 }
 
+// Is reports whether the target is an *Error with the same code, see Equal
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok || t == nil || e == nil {
+		return false
+	}
+	return e.Code == t.Code
+}
+
 // Equal tries to compare errors
 func Equal(err1 error, err2 error) bool {
 	verr1, ok1 := err1.(*Error)
@@ -136,11 +205,30 @@ func Equal(err1 error, err2 error) bool {
 	return true
 }
 
-// FromError try to convert go error to *Error
+// FromError converts any error to an *Error. An *Error in the chain of the
+// error is returned as is, context errors become timeouts and cancellations
+// and other errors are parsed or become internal server errors.
 func FromError(err error) *Error {
-	if verr, ok := err.(*Error); ok && verr != nil {
+	if err == nil {
+		return nil
+	}
+
+	var verr *Error
+	if er.As(err, &verr) && verr != nil {
 		return verr
 	}
 
-	return Parse(err.Error())
+	switch {
+	case er.Is(err, context.DeadlineExceeded):
+		return New("", err.Error(), 408).(*Error)
+	case er.Is(err, context.Canceled):
+		return New("", err.Error(), StatusClientClosedRequest).(*Error)
+	}
+
+	e := Parse(err.Error())
+	if e.Code == 0 {
+		e.Code = 500
+		e.Status = http.StatusText(500)
+	}
+	return e
 }
@@ -25,6 +25,7 @@ type Error struct {
 	Code                 int32    `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
 	Detail               string   `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
 	Status               string   `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Retryable            bool     `protobuf:"varint,5,opt,name=retryable,proto3" json:"retryable,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -83,6 +84,13 @@ func (m *Error) GetStatus() string {
 	return ""
 }
 
+func (m *Error) GetRetryable() bool {
+	if m != nil {
+		return m.Retryable
+	}
+	return false
+}
+
 func init() {
 	proto.RegisterType((*Error)(nil), "errors.Error")
 }
@@ -90,13 +98,14 @@ func init() {
 func init() { proto.RegisterFile("errors/errors.proto", fileDescriptor_85c4eef3398a32b2) }
 
 var fileDescriptor_85c4eef3398a32b2 = []byte{
-	// 118 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x4e, 0x2d, 0x2a, 0xca,
+	// 132 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0xe3, 0x12, 0x4e, 0x2d, 0x2a, 0xca,
 	0x2f, 0x2a, 0xd6, 0x87, 0x50, 0x7a, 0x05, 0x45, 0xf9, 0x25, 0xf9, 0x42, 0x6c, 0x10, 0x9e, 0x52,
-	0x34, 0x17, 0xab, 0x2b, 0x88, 0x25, 0xc4, 0xc7, 0xc5, 0x94, 0x99, 0x22, 0xc1, 0xa8, 0xc0, 0xa8,
-	0xc1, 0x19, 0xc4, 0x94, 0x99, 0x22, 0x24, 0xc4, 0xc5, 0x92, 0x9c, 0x9f, 0x92, 0x2a, 0xc1, 0xa4,
-	0xc0, 0xa8, 0xc1, 0x1a, 0x04, 0x66, 0x0b, 0x89, 0x71, 0xb1, 0xa5, 0xa4, 0x96, 0x24, 0x66, 0xe6,
-	0x48, 0x30, 0x83, 0xd5, 0x41, 0x79, 0x20, 0xf1, 0xe2, 0x92, 0xc4, 0x92, 0xd2, 0x62, 0x09, 0x16,
-	0x88, 0x38, 0x84, 0x97, 0xc4, 0x06, 0xb6, 0xcb, 0x18, 0x10, 0x00, 0x00, 0xff, 0xff, 0xca, 0xc3,
-	0xcb, 0x29, 0x82, 0x00, 0x00, 0x00,
+	0x25, 0x17, 0xab, 0x2b, 0x88, 0x25, 0xc4, 0xc7, 0xc5, 0x94, 0x99, 0x22, 0xc1, 0xa8, 0xc0, 0xa8,
+	0xc1, 0x19, 0x04, 0x64, 0x09, 0x09, 0x71, 0xb1, 0x24, 0xe7, 0xa7, 0xa4, 0x4a, 0x30, 0x01, 0x45,
+	0x58, 0x83, 0xc0, 0x6c, 0x21, 0x31, 0x2e, 0xb6, 0x94, 0xd4, 0x92, 0xc4, 0xcc, 0x1c, 0x09, 0x66,
+	0xb0, 0x3a, 0x28, 0x0f, 0x24, 0x5e, 0x5c, 0x92, 0x58, 0x52, 0x5a, 0x2c, 0xc1, 0x02, 0x11, 0x87,
+	0xf0, 0x84, 0x64, 0xb8, 0x38, 0x8b, 0x52, 0x4b, 0x8a, 0x2a, 0x13, 0x93, 0x72, 0x52, 0x25, 0x58,
+	0x81, 0x52, 0x1c, 0x41, 0x08, 0x81, 0x24, 0x36, 0xb0, 0x4b, 0x8c, 0x01, 0xd3, 0xa0, 0x38, 0xe4,
+	0xa0, 0x00, 0x00, 0x00,
 }
@@ -1,7 +1,9 @@
 package errors
 
 import (
+	"context"
 	er "errors"
+	"fmt"
 	"net/http"
 	"testing"
 )
@@ -75,3 +77,97 @@ func TestErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestWrap(t *testing.T) {
+	cause := er.New("connection refused")
+	err := Wrap(cause, "go.micro.test", 503, "unable to connect: %v", cause)
+
+	if !er.Is(err, cause) {
+		t.Fatal("Expected the cause to be found")
+	}
+	if !er.Is(err, &Error{Code: 503}) {
+		t.Fatal("Expected the error to match errors with the same code")
+	}
+	if er.Is(err, NotFound("go.micro.test", "not found")) {
+		t.Fatal("Expected the error not to match errors with another code")
+	}
+
+	var verr *Error
+	if !er.As(fmt.Errorf("call failed: %w", err), &verr) || verr.Detail != "unable to connect: connection refused" {
+		t.Fatalf("Expected the error to be found got %v", verr)
+	}
+
+	// the cause isn't sent with the error
+	if pe := Parse(err.Error()); er.Unwrap(pe) != nil || pe.Code != 503 {
+		t.Fatalf("Unexpected parsed error %v", pe)
+	}
+}
+
+func TestFromAnyError(t *testing.T) {
+	testData := []struct {
+		err  error
+		code int32
+	}{
+		{context.DeadlineExceeded, 408},
+		{fmt.Errorf("call: %w", context.Canceled), StatusClientClosedRequest},
+		{er.New("boom"), 500},
+		{fmt.Errorf("call: %w", BadRequest("go.micro.test", "bad")), 400},
+	}
+
+	for _, d := range testData {
+		e := FromError(d.err)
+		if e.Code != d.code {
+			t.Fatalf("Expected %d for %v got %d", d.code, d.err, e.Code)
+		}
+		if len(e.Status) == 0 {
+			t.Fatalf("Expected a status for %v", d.err)
+		}
+	}
+
+	if FromError(nil) != nil {
+		t.Fatal("Expected nil for a nil error")
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	testData := []struct {
+		err       error
+		retryable bool
+	}{
+		{nil, false},
+		{er.New("boom"), false},
+		{InternalServerError("go.micro.test", "boom"), false},
+		{Timeout("go.micro.test", "timeout"), true},
+		{New("go.micro.test", "unavailable", 503), true},
+		{Retryable(InternalServerError("go.micro.test", "boom")), true},
+		{Retryable(er.New("boom")), true},
+		{Wrap(Retryable(er.New("boom")), "go.micro.test", 500, "wrapped"), true},
+	}
+
+	for _, d := range testData {
+		if IsRetryable(d.err) != d.retryable {
+			t.Fatalf("Expected %v to be retryable %v", d.err, d.retryable)
+		}
+	}
+
+	// the flag is sent with the error
+	err := Retryable(InternalServerError("go.micro.test", "boom"))
+	if !IsRetryable(Parse(err.Error())) {
+		t.Fatal("Expected the parsed error to be retryable")
+	}
+}
+
+func TestStatus(t *testing.T) {
+	for _, code := range []int32{200, 400, 401, 403, 404, 408, 409, 412, 429, 499, 500, 501, 503} {
+		if c := CodeFromGRPC(GRPCCode(code)); c != code {
+			t.Fatalf("Expected %d to map back to itself got %d", code, c)
+		}
+	}
+
+	if s := HTTPStatus(New("go.micro.test", "bad", 0)); s != http.StatusInternalServerError {
+		t.Fatalf("Expected 500 got %d", s)
+	}
+	if s := HTTPStatus(NotFound("go.micro.test", "missing")); s != http.StatusNotFound {
+		t.Fatalf("Expected 404 got %d", s)
+	}
+}
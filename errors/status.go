@@ -0,0 +1,159 @@
+package errors
+
+import (
+	er "errors"
+	"net/http"
+)
+
+// StatusClientClosedRequest is the code of a request canceled by the client
+const StatusClientClosedRequest = 499
+
+// gRPC status codes as defined by google.golang.org/grpc/codes
+const (
+	grpcOK uint32 = iota
+	grpcCanceled
+	grpcUnknown
+	grpcInvalidArgument
+	grpcDeadlineExceeded
+	grpcNotFound
+	grpcAlreadyExists
+	grpcPermissionDenied
+	grpcResourceExhausted
+	grpcFailedPrecondition
+	grpcAborted
+	grpcOutOfRange
+	grpcUnimplemented
+	grpcInternal
+	grpcUnavailable
+	grpcDataLoss
+	grpcUnauthenticated
+)
+
+func statusText(code int32) string {
+	if code == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(int(code))
+}
+
+// Retryable returns the error marked as retryable, the error itself is left
+// unchanged and is the cause of the returned error
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	e := FromError(err)
+	return &wrapError{
+		err: &Error{
+			Id:        e.Id,
+			Code:      e.Code,
+			Detail:    e.Detail,
+			Status:    e.Status,
+			Retryable: true,
+		},
+		cause: err,
+	}
+}
+
+// IsRetryable returns true if the error is marked as retryable or its code is
+// that of a temporary failure e.g a timeout or an unavailable service
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var verr *Error
+	if !er.As(err, &verr) || verr == nil {
+		return false
+	}
+	if verr.Retryable {
+		return true
+	}
+
+	switch verr.Code {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// HTTPStatus returns the http status to respond with for the error, errors
+// with codes which aren't http error statuses are internal server errors
+func HTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	code := int(FromError(err).Code)
+	if code < 400 || code > 599 {
+		return http.StatusInternalServerError
+	}
+	return code
+}
+
+// GRPCCode returns the gRPC status code of the code of an error
+func GRPCCode(code int32) uint32 {
+	switch code {
+	case http.StatusOK:
+		return grpcOK
+	case http.StatusBadRequest:
+		return grpcInvalidArgument
+	case http.StatusUnauthorized:
+		return grpcUnauthenticated
+	case http.StatusForbidden:
+		return grpcPermissionDenied
+	case http.StatusNotFound:
+		return grpcNotFound
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return grpcUnimplemented
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return grpcDeadlineExceeded
+	case http.StatusConflict:
+		return grpcAlreadyExists
+	case http.StatusPreconditionFailed:
+		return grpcFailedPrecondition
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return grpcResourceExhausted
+	case StatusClientClosedRequest:
+		return grpcCanceled
+	case http.StatusInternalServerError:
+		return grpcInternal
+	case http.StatusServiceUnavailable:
+		return grpcUnavailable
+	}
+	return grpcUnknown
+}
+
+// CodeFromGRPC returns the code of an error for a gRPC status code
+func CodeFromGRPC(code uint32) int32 {
+	switch code {
+	case grpcOK:
+		return http.StatusOK
+	case grpcCanceled:
+		return StatusClientClosedRequest
+	case grpcInvalidArgument, grpcOutOfRange:
+		return http.StatusBadRequest
+	case grpcDeadlineExceeded:
+		return http.StatusRequestTimeout
+	case grpcNotFound:
+		return http.StatusNotFound
+	case grpcAlreadyExists, grpcAborted:
+		return http.StatusConflict
+	case grpcPermissionDenied:
+		return http.StatusForbidden
+	case grpcResourceExhausted:
+		return http.StatusTooManyRequests
+	case grpcFailedPrecondition:
+		return http.StatusPreconditionFailed
+	case grpcUnimplemented:
+		return http.StatusNotImplemented
+	case grpcUnavailable:
+		return http.StatusServiceUnavailable
+	case grpcUnauthenticated:
+		return http.StatusUnauthorized
+	}
+	return http.StatusInternalServerError
+}
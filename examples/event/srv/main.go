@@ -5,7 +5,7 @@ import (
 
 	"github.com/asim/go-micro/v3"
 	proto "github.com/asim/go-micro/v3/api/proto"
-	"github.com/asim/go-micro/v3/util/log"
+	"github.com/asim/go-micro/v3/logger"
 )
 
 // All methods of Event will be executed when a message is received
@@ -13,7 +13,7 @@ type Event struct{}
 
 // Method can be of any name
 func (e *Event) Process(ctx context.Context, event *proto.Event) error {
-	log.Logf("Received event %+v\n", event)
+	logger.Infof("Received event %+v", event)
 	// do something with event
 	return nil
 }
@@ -28,6 +28,6 @@ func main() {
 	micro.RegisterSubscriber("go.micro.evt.user", service.Server(), new(Event))
 
 	if err := service.Run(); err != nil {
-		log.Fatal(err)
+		logger.Fatal(err)
 	}
 }
@@ -7,14 +7,14 @@ import (
 
 	hello "github.com/asim/go-micro/examples/v3/greeter/srv/proto/hello"
 	"github.com/asim/go-micro/v3"
-	"github.com/asim/go-micro/v3/util/log"
+	"github.com/asim/go-micro/v3/logger"
 	"google.golang.org/grpc"
 )
 
 type Say struct{}
 
 func (s *Say) Hello(ctx context.Context, req *hello.Request, rsp *hello.Response) error {
-	log.Log("Received Say.Hello request")
+	logger.Info("Received Say.Hello request")
 	rsp.Msg = "Hello " + req.Name
 	return nil
 }
@@ -39,6 +39,6 @@ func main() {
 
 	// Run server
 	if err := service.Run(); err != nil {
-		log.Fatal(err)
+		logger.Fatal(err)
 	}
 }
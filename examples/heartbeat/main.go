@@ -4,7 +4,7 @@ import (
 	"time"
 
 	"github.com/asim/go-micro/v3"
-	"github.com/asim/go-micro/v3/util/log"
+	"github.com/asim/go-micro/v3/logger"
 )
 
 func main() {
@@ -16,6 +16,6 @@ func main() {
 	service.Init()
 
 	if err := service.Run(); err != nil {
-		log.Fatal(err)
+		logger.Fatal(err)
 	}
 }
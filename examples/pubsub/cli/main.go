@@ -7,7 +7,7 @@ import (
 	"context"
 	proto "github.com/asim/go-micro/examples/v3/pubsub/srv/proto"
 	"github.com/asim/go-micro/v3"
-	"github.com/asim/go-micro/v3/util/log"
+	"github.com/asim/go-micro/v3/logger"
 	"github.com/pborman/uuid"
 )
 
@@ -23,11 +23,11 @@ func sendEv(topic string, p micro.Publisher) {
 			Message:   fmt.Sprintf("Messaging you all day on %s", topic),
 		}
 
-		log.Logf("publishing %+v\n", ev)
+		logger.Infof("publishing %+v", ev)
 
 		// publish an event
 		if err := p.Publish(context.Background(), ev); err != nil {
-			log.Logf("error publishing: %v", err)
+			logger.Infof("error publishing: %v", err)
 		}
 	}
 }
@@ -3,9 +3,9 @@ package main
 import (
 	proto "github.com/asim/go-micro/examples/v3/pubsub/srv/proto"
 	"github.com/asim/go-micro/v3"
+	"github.com/asim/go-micro/v3/logger"
 	"github.com/asim/go-micro/v3/metadata"
 	"github.com/asim/go-micro/v3/server"
-	"github.com/asim/go-micro/v3/util/log"
 
 	"context"
 )
@@ -17,7 +17,7 @@ type Sub struct{}
 // Method can be of any name
 func (s *Sub) Process(ctx context.Context, event *proto.Event) error {
 	md, _ := metadata.FromContext(ctx)
-	log.Logf("[pubsub.1] Received event %+v with metadata %+v\n", event, md)
+	logger.Infof("[pubsub.1] Received event %+v with metadata %+v", event, md)
 	// do something with event
 	return nil
 }
@@ -25,7 +25,7 @@ func (s *Sub) Process(ctx context.Context, event *proto.Event) error {
 // Alternatively a function can be used
 func subEv(ctx context.Context, event *proto.Event) error {
 	md, _ := metadata.FromContext(ctx)
-	log.Logf("[pubsub.2] Received event %+v with metadata %+v\n", event, md)
+	logger.Infof("[pubsub.2] Received event %+v with metadata %+v", event, md)
 	// do something with event
 	return nil
 }
@@ -45,6 +45,6 @@ func main() {
 	micro.RegisterSubscriber("example.topic.pubsub.2", service.Server(), subEv, server.SubscriberQueue("queue.pubsub"))
 
 	if err := service.Run(); err != nil {
-		log.Fatal(err)
+		logger.Fatal(err)
 	}
 }
@@ -1,9 +1,28 @@
 package logger
 
-import "context"
+import (
+	"context"
+
+	"github.com/asim/go-micro/v3/metadata"
+)
 
 type loggerKey struct{}
 
+// Extractor returns fields to log from a context e.g the ids of the request
+type Extractor func(ctx context.Context) map[string]interface{}
+
+var (
+	// DefaultExtractors are used by Extract to find the fields of a context
+	DefaultExtractors = []Extractor{MetadataExtractor}
+
+	// metadata keys extracted by the MetadataExtractor
+	metadataFields = map[string]string{
+		"Micro-Trace-Id": "trace_id",
+		"Micro-Span-Id":  "span_id",
+		"Micro-Id":       "request_id",
+	}
+)
+
 func FromContext(ctx context.Context) (Logger, bool) {
 	l, ok := ctx.Value(loggerKey{}).(Logger)
 	return l, ok
@@ -12,3 +31,30 @@ func FromContext(ctx context.Context) (Logger, bool) {
 func NewContext(ctx context.Context, l Logger) context.Context {
 	return context.WithValue(ctx, loggerKey{}, l)
 }
+
+// MetadataExtractor extracts the trace, span and request ids from the
+// metadata of the context
+func MetadataExtractor(ctx context.Context) map[string]interface{} {
+	md, ok := metadata.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	fields := make(map[string]interface{})
+	for k, f := range metadataFields {
+		if v, ok := md.Get(k); ok && len(v) > 0 {
+			fields[f] = v
+		}
+	}
+	return fields
+}
+
+// Extract returns a helper logging with the logger of the context, or the
+// default logger, and the fields found in the context by the extractors
+func Extract(ctx context.Context) *Helper {
+	l, ok := FromContext(ctx)
+	if !ok {
+		l = DefaultLogger
+	}
+	return NewHelper(l).WithContext(ctx)
+}
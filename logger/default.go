@@ -1,11 +1,13 @@
 package logger
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -22,6 +24,18 @@ func init() {
 	DefaultLogger = NewHelper(NewLogger(WithLevel(lvl)))
 }
 
+var (
+	// buffers reused between log entries
+	bufferPool = sync.Pool{
+		New: func() interface{} {
+			return new(bytes.Buffer)
+		},
+	}
+
+	// guards writes to outputs shared by loggers
+	outMutex sync.Mutex
+)
+
 type defaultLogger struct {
 	sync.RWMutex
 	opts Options
@@ -29,6 +43,8 @@ type defaultLogger struct {
 
 // Init (opts...) should only overwrite provided options
 func (l *defaultLogger) Init(opts ...Option) error {
+	l.Lock()
+	defer l.Unlock()
 	for _, o := range opts {
 		o(&l.opts)
 	}
@@ -39,11 +55,19 @@ func (l *defaultLogger) String() string {
 	return "default"
 }
 
+// Fields returns a new logger logging the fields along with those of the
+// logger, the logger itself is left unchanged
 func (l *defaultLogger) Fields(fields map[string]interface{}) Logger {
-	l.Lock()
-	l.opts.Fields = copyFields(fields)
-	l.Unlock()
-	return l
+	l.RLock()
+	opts := l.opts
+	opts.Fields = copyFields(l.opts.Fields)
+	l.RUnlock()
+
+	for k, v := range fields {
+		opts.Fields[k] = v
+	}
+
+	return &defaultLogger{opts: opts}
 }
 
 func copyFields(src map[string]interface{}) map[string]interface{} {
@@ -79,85 +103,106 @@ func logCallerfilePath(loggingFilePath string) string {
 }
 
 func (l *defaultLogger) Log(level Level, v ...interface{}) {
-	// TODO decide does we need to write message if log level not used?
-	if !l.opts.Level.Enabled(level) {
+	if !l.enabled(level) {
 		return
 	}
+	l.log(level, fmt.Sprint(v...))
+}
+
+func (l *defaultLogger) Logf(level Level, format string, v ...interface{}) {
+	if !l.enabled(level) {
+		return
+	}
+	l.log(level, fmt.Sprintf(format, v...))
+}
 
+func (l *defaultLogger) enabled(level Level) bool {
+	l.RLock()
+	defer l.RUnlock()
+	return l.opts.Level.Enabled(level)
+}
+
+// log writes the entry as a line of sorted key=value fields followed by the
+// message e.g 2006-01-02 15:04:05 file=server/rpc_server.go:10 level=info msg
+func (l *defaultLogger) log(level Level, msg string) {
 	l.RLock()
 	fields := copyFields(l.opts.Fields)
+	out := l.opts.Out
+	skip := l.opts.CallerSkipCount
 	l.RUnlock()
 
 	fields["level"] = level.String()
 
-	if _, file, line, ok := runtime.Caller(l.opts.CallerSkipCount); ok {
-		fields["file"] = fmt.Sprintf("%s:%d", logCallerfilePath(file), line)
+	// skip log itself along with the caller skip count
+	if _, file, line, ok := runtime.Caller(skip + 1); ok {
+		fields["file"] = logCallerfilePath(file) + ":" + strconv.Itoa(line)
 	}
 
 	rec := dlog.Record{
 		Timestamp: time.Now(),
-		Message:   fmt.Sprint(v...),
+		Message:   msg,
 		Metadata:  make(map[string]string, len(fields)),
 	}
 
 	keys := make([]string, 0, len(fields))
 	for k, v := range fields {
 		keys = append(keys, k)
-		rec.Metadata[k] = fmt.Sprintf("%v", v)
+		rec.Metadata[k] = formatValue(v)
 	}
-
 	sort.Strings(keys)
-	metadata := ""
-
-	for _, k := range keys {
-		metadata += fmt.Sprintf(" %s=%v", k, fields[k])
-	}
 
 	dlog.DefaultLog.Write(rec)
 
-	t := rec.Timestamp.Format("2006-01-02 15:04:05")
-	fmt.Printf("%s %s %v\n", t, metadata, rec.Message)
-}
-
-func (l *defaultLogger) Logf(level Level, format string, v ...interface{}) {
-	//	 TODO decide does we need to write message if log level not used?
-	if level < l.opts.Level {
+	if out == nil {
 		return
 	}
 
-	l.RLock()
-	fields := copyFields(l.opts.Fields)
-	l.RUnlock()
-
-	fields["level"] = level.String()
-
-	if _, file, line, ok := runtime.Caller(l.opts.CallerSkipCount); ok {
-		fields["file"] = fmt.Sprintf("%s:%d", logCallerfilePath(file), line)
-	}
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufferPool.Put(buf)
 
-	rec := dlog.Record{
-		Timestamp: time.Now(),
-		Message:   fmt.Sprintf(format, v...),
-		Metadata:  make(map[string]string, len(fields)),
-	}
+	buf.WriteString(rec.Timestamp.Format("2006-01-02 15:04:05"))
+	for _, k := range keys {
+		buf.WriteByte(' ')
+		buf.WriteString(k)
+		buf.WriteByte('=')
+		buf.WriteString(quoteValue(rec.Metadata[k]))
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(msg)
+	buf.WriteByte('\n')
+
+	outMutex.Lock()
+	out.Write(buf.Bytes())
+	outMutex.Unlock()
+}
 
-	keys := make([]string, 0, len(fields))
-	for k, v := range fields {
-		keys = append(keys, k)
-		rec.Metadata[k] = fmt.Sprintf("%v", v)
+// formatValue formats the value of a field avoiding fmt for common types
+func formatValue(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case error:
+		return t.Error()
+	case fmt.Stringer:
+		return t.String()
+	case int:
+		return strconv.Itoa(t)
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		return fmt.Sprintf("%v", v)
 	}
+}
 
-	sort.Strings(keys)
-	metadata := ""
-
-	for _, k := range keys {
-		metadata += fmt.Sprintf(" %s=%v", k, fields[k])
+// quoteValue quotes values which would otherwise be ambiguous to parse
+func quoteValue(v string) string {
+	if len(v) == 0 || strings.ContainsAny(v, " =\"\t\n") {
+		return strconv.Quote(v)
 	}
-
-	dlog.DefaultLog.Write(rec)
-
-	t := rec.Timestamp.Format("2006-01-02 15:04:05")
-	fmt.Printf("%s %s %v\n", t, metadata, rec.Message)
+	return v
 }
 
 func (l *defaultLogger) Options() Options {
@@ -175,7 +220,7 @@ func NewLogger(opts ...Option) Logger {
 	options := Options{
 		Level:           InfoLevel,
 		Fields:          make(map[string]interface{}),
-		Out:             os.Stderr,
+		Out:             os.Stdout,
 		CallerSkipCount: 2,
 		Context:         context.Background(),
 	}
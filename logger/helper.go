@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"os"
 )
 
@@ -9,10 +10,43 @@ type Helper struct {
 	fields map[string]interface{}
 }
 
+// NewHelper returns a helper logging with the logger, or the DefaultLogger
+// if it's nil
 func NewHelper(log Logger) *Helper {
+	if log == nil {
+		log = DefaultLogger
+	}
+	// keep the fields of a helper rather than nesting it
+	if h, ok := log.(*Helper); ok {
+		return &Helper{Logger: h.Logger, fields: copyFields(h.fields)}
+	}
 	return &Helper{Logger: log}
 }
 
+// Fields returns the logger of the helper with the fields of the helper and
+// those given
+func (h *Helper) Fields(fields map[string]interface{}) Logger {
+	nfields := copyFields(h.fields)
+	for k, v := range fields {
+		nfields[k] = v
+	}
+	return h.Logger.Fields(nfields)
+}
+
+func (h *Helper) Log(level Level, args ...interface{}) {
+	if !h.Logger.Options().Level.Enabled(level) {
+		return
+	}
+	h.Logger.Fields(h.fields).Log(level, args...)
+}
+
+func (h *Helper) Logf(level Level, template string, args ...interface{}) {
+	if !h.Logger.Options().Level.Enabled(level) {
+		return
+	}
+	h.Logger.Fields(h.fields).Logf(level, template, args...)
+}
+
 func (h *Helper) Info(args ...interface{}) {
 	if !h.Logger.Options().Level.Enabled(InfoLevel) {
 		return
@@ -106,9 +140,21 @@ func (h *Helper) WithError(err error) *Helper {
 }
 
 func (h *Helper) WithFields(fields map[string]interface{}) *Helper {
-	nfields := copyFields(fields)
-	for k, v := range h.fields {
+	nfields := copyFields(h.fields)
+	for k, v := range fields {
 		nfields[k] = v
 	}
 	return &Helper{Logger: h.Logger, fields: nfields}
 }
+
+// WithContext returns a helper logging the fields found in the context by
+// the DefaultExtractors e.g the trace and request ids
+func (h *Helper) WithContext(ctx context.Context) *Helper {
+	nfields := copyFields(h.fields)
+	for _, e := range DefaultExtractors {
+		for k, v := range e(ctx) {
+			nfields[k] = v
+		}
+	}
+	return &Helper{Logger: h.Logger, fields: nfields}
+}
@@ -67,52 +67,52 @@ func GetLevel(levelStr string) (Level, error) {
 }
 
 func Info(args ...interface{}) {
-	DefaultLogger.Log(InfoLevel, args...)
+	logger().Log(InfoLevel, args...)
 }
 
 func Infof(template string, args ...interface{}) {
-	DefaultLogger.Logf(InfoLevel, template, args...)
+	logger().Logf(InfoLevel, template, args...)
 }
 
 func Trace(args ...interface{}) {
-	DefaultLogger.Log(TraceLevel, args...)
+	logger().Log(TraceLevel, args...)
 }
 
 func Tracef(template string, args ...interface{}) {
-	DefaultLogger.Logf(TraceLevel, template, args...)
+	logger().Logf(TraceLevel, template, args...)
 }
 
 func Debug(args ...interface{}) {
-	DefaultLogger.Log(DebugLevel, args...)
+	logger().Log(DebugLevel, args...)
 }
 
 func Debugf(template string, args ...interface{}) {
-	DefaultLogger.Logf(DebugLevel, template, args...)
+	logger().Logf(DebugLevel, template, args...)
 }
 
 func Warn(args ...interface{}) {
-	DefaultLogger.Log(WarnLevel, args...)
+	logger().Log(WarnLevel, args...)
 }
 
 func Warnf(template string, args ...interface{}) {
-	DefaultLogger.Logf(WarnLevel, template, args...)
+	logger().Logf(WarnLevel, template, args...)
 }
 
 func Error(args ...interface{}) {
-	DefaultLogger.Log(ErrorLevel, args...)
+	logger().Log(ErrorLevel, args...)
 }
 
 func Errorf(template string, args ...interface{}) {
-	DefaultLogger.Logf(ErrorLevel, template, args...)
+	logger().Logf(ErrorLevel, template, args...)
 }
 
 func Fatal(args ...interface{}) {
-	DefaultLogger.Log(FatalLevel, args...)
+	logger().Log(FatalLevel, args...)
 	os.Exit(1)
 }
 
 func Fatalf(template string, args ...interface{}) {
-	DefaultLogger.Logf(FatalLevel, template, args...)
+	logger().Logf(FatalLevel, template, args...)
 	os.Exit(1)
 }
 
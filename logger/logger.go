@@ -3,7 +3,7 @@ package logger
 
 var (
 	// Default logger
-	DefaultLogger Logger = NewHelper(NewLogger())
+	DefaultLogger Logger = &Helper{Logger: NewLogger()}
 )
 
 // Logger is a generic logging interface
@@ -22,6 +22,20 @@ type Logger interface {
 	String() string
 }
 
+// logger returns the logger the package level functions log with. The
+// logger of the default helper is used directly so the caller is reported
+// right, the fields are only copied if the helper has any.
+func logger() Logger {
+	h, ok := DefaultLogger.(*Helper)
+	if !ok {
+		return DefaultLogger
+	}
+	if len(h.fields) == 0 {
+		return h.Logger
+	}
+	return h.Fields(nil)
+}
+
 func Init(opts ...Option) error {
 	return DefaultLogger.Init(opts...)
 }
@@ -31,11 +45,11 @@ func Fields(fields map[string]interface{}) Logger {
 }
 
 func Log(level Level, v ...interface{}) {
-	DefaultLogger.Log(level, v...)
+	logger().Log(level, v...)
 }
 
 func Logf(level Level, format string, v ...interface{}) {
-	DefaultLogger.Logf(level, format, v...)
+	logger().Logf(level, format, v...)
 }
 
 func String() string {
@@ -1,7 +1,13 @@
 package logger
 
 import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
 	"testing"
+
+	"github.com/asim/go-micro/v3/metadata"
 )
 
 func TestLogger(t *testing.T) {
@@ -16,3 +22,91 @@ func TestLogger(t *testing.T) {
 
 	l.Fields(map[string]interface{}{"key3": "val4"}).Log(InfoLevel, "test_msg")
 }
+
+func TestFields(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := NewLogger(WithOutput(buf), WithFields(map[string]interface{}{"service": "test"}))
+
+	l.Fields(map[string]interface{}{"key": "val"}).Log(InfoLevel, "first")
+	l.Log(InfoLevel, "second")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines got %d: %s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], " key=val ") || !strings.Contains(lines[0], " service=test ") {
+		t.Fatalf("Expected the fields in %q", lines[0])
+	}
+	if strings.Contains(lines[1], "key=val") {
+		t.Fatalf("Expected the logger to be unchanged by Fields got %q", lines[1])
+	}
+	if !strings.HasSuffix(lines[1], " level=info service=test second") {
+		t.Fatalf("Unexpected line %q", lines[1])
+	}
+}
+
+func TestLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h := NewHelper(NewLogger(WithOutput(buf), WithLevel(WarnLevel)))
+
+	h.Info("info")
+	h.Warnf("warn %d", 1)
+
+	if out := buf.String(); strings.Contains(out, "info") || !strings.Contains(out, "warn 1") {
+		t.Fatalf("Unexpected output %q", out)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := NewLogger(WithOutput(buf))
+
+	ctx := metadata.NewContext(context.Background(), metadata.Metadata{
+		"Micro-Id":       "1",
+		"Micro-Trace-Id": "2",
+	})
+	ctx = NewContext(ctx, l)
+
+	Extract(ctx).WithFields(map[string]interface{}{"msg": "hello world"}).Info("test")
+
+	out := buf.String()
+	for _, f := range []string{"request_id=1", "trace_id=2", `msg="hello world"`, "file=logger/logger_test.go"} {
+		if !strings.Contains(out, f) {
+			t.Fatalf("Expected %s in %q", f, out)
+		}
+	}
+	if strings.Contains(out, "span_id") {
+		t.Fatalf("Unexpected span_id in %q", out)
+	}
+}
+
+func TestDefaultLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	defer func(l Logger) {
+		DefaultLogger = l
+	}(DefaultLogger)
+
+	// the package level functions report their caller with or without fields
+	DefaultLogger = NewHelper(NewLogger(WithOutput(buf)))
+	Info("first")
+	DefaultLogger = NewHelper(NewLogger(WithOutput(buf))).WithFields(map[string]interface{}{"key": "val"})
+	Logf(InfoLevel, "second %d", 2)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines got %d: %s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "file=logger/logger_test.go") {
+			t.Fatalf("Expected the caller in %q", line)
+		}
+	}
+	if !strings.Contains(lines[1], " key=val ") || !strings.HasSuffix(lines[1], "second 2") {
+		t.Fatalf("Unexpected line %q", lines[1])
+	}
+
+	if out := NewLogger().Options().Out; out != os.Stdout {
+		t.Fatalf("Expected the default output to be stdout got %v", out)
+	}
+}
@@ -12,7 +12,7 @@ type Options struct {
 	Level Level
 	// fields to always be logged
 	Fields map[string]interface{}
-	// It's common to set this to a file, or leave it default which is `os.Stderr`
+	// It's common to set this to a file, or leave it default which is `os.Stdout`
 	Out io.Writer
 	// Caller skip frame count for file:line info
 	CallerSkipCount int
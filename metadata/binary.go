@@ -0,0 +1,64 @@
+package metadata
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// BinarySuffix is the suffix of keys with binary values, as in grpc the
+// values are base64 encoded
+const BinarySuffix = "-Bin"
+
+// binaryKey returns the canonical key with the binary suffix
+func binaryKey(key string) string {
+	key = CanonicalKey(key)
+	if !strings.HasSuffix(key, BinarySuffix) {
+		key += BinarySuffix
+	}
+	return key
+}
+
+// SetBinary sets the base64 encoded value of the key, the key is suffixed
+// with -Bin unless it already is
+func (md Metadata) SetBinary(key string, val []byte) {
+	md.Set(binaryKey(key), base64.StdEncoding.EncodeToString(val))
+}
+
+// GetBinary returns the decoded value of the key, with or without the -Bin
+// suffix, it's not ok if the value isn't base64 encoded
+func (md Metadata) GetBinary(key string) ([]byte, bool) {
+	vals, ok := md.BinaryValues(key)
+	if !ok || len(vals) == 0 {
+		return nil, false
+	}
+	return vals[0], true
+}
+
+// AddBinary appends the base64 encoded values to those of the key
+func (md Metadata) AddBinary(key string, vals ...[]byte) {
+	enc := make([]string, len(vals))
+	for i, v := range vals {
+		enc[i] = base64.StdEncoding.EncodeToString(v)
+	}
+	md.Add(binaryKey(key), enc...)
+}
+
+// BinaryValues returns all the decoded values of the key
+func (md Metadata) BinaryValues(key string) ([][]byte, bool) {
+	val, ok := md.Get(binaryKey(key))
+	if !ok {
+		return nil, false
+	}
+
+	vals := splitValues(val)
+	dec := make([][]byte, len(vals))
+	for i, v := range vals {
+		// accept values with or without padding
+		b, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(v, "="))
+		if err != nil {
+			return nil, false
+		}
+		dec[i] = b
+	}
+	return dec, true
+}
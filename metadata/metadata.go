@@ -3,6 +3,7 @@ package metadata
 
 import (
 	"context"
+	"net/textproto"
 	"strings"
 )
 
@@ -11,8 +12,18 @@ type metadataKey struct{}
 // Metadata is our way of representing request headers internally.
 // They're used at the RPC level and translate back and forth
 // from Transport headers.
+//
+// Keys are case insensitive and accessed in their canonical form e.g
+// micro-id is Micro-Id. Multiple values of a key are joined with commas
+// as in http headers, values added containing commas or quotes are quoted.
 type Metadata map[string]string
 
+// CanonicalKey returns the canonical form of the key, the form keys are
+// stored in e.g micro-id and MICRO-ID are Micro-Id
+func CanonicalKey(key string) string {
+	return textproto.CanonicalMIMEHeaderKey(key)
+}
+
 func (md Metadata) Get(key string) (string, bool) {
 	// attempt to get as is
 	val, ok := md[key]
@@ -20,20 +31,97 @@ func (md Metadata) Get(key string) (string, bool) {
 		return val, ok
 	}
 
-	// attempt to get lower case
-	val, ok = md[strings.Title(key)]
+	// attempt to get the canonical key
+	val, ok = md[CanonicalKey(key)]
 	return val, ok
 }
 
 func (md Metadata) Set(key, val string) {
-	md[key] = val
+	md.Delete(key)
+	md[CanonicalKey(key)] = val
 }
 
 func (md Metadata) Delete(key string) {
 	// delete key as-is
 	delete(md, key)
-	// delete also Title key
-	delete(md, strings.Title(key))
+	// delete also canonical key
+	delete(md, CanonicalKey(key))
+}
+
+// Values returns all the values of the key
+func (md Metadata) Values(key string) []string {
+	val, ok := md.Get(key)
+	if !ok {
+		return nil
+	}
+	return splitValues(val)
+}
+
+// Add appends the values to those of the key
+func (md Metadata) Add(key string, vals ...string) {
+	if len(vals) == 0 {
+		return
+	}
+	quoted := make([]string, len(vals))
+	for i, v := range vals {
+		quoted[i] = quoteValue(v)
+	}
+	md.append(key, strings.Join(quoted, ","))
+}
+
+// append adds the already joined values to those of the key
+func (md Metadata) append(key, val string) {
+	if cur, ok := md.Get(key); ok && len(cur) > 0 {
+		val = cur + "," + val
+	}
+	md.Set(key, val)
+}
+
+var quoteReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// quoteValue quotes values which would otherwise be split or trimmed
+func quoteValue(v string) string {
+	if !strings.ContainsAny(v, `,"`) && strings.TrimSpace(v) == v {
+		return v
+	}
+	return `"` + quoteReplacer.Replace(v) + `"`
+}
+
+// splitValues splits the comma separated values, unquoting quoted values
+// and trimming the space around the rest
+func splitValues(val string) []string {
+	var vals []string
+	var cur []byte
+	// the length of cur excluding trailing space
+	keep := 0
+	quoted := false
+
+	for i := 0; i < len(val); i++ {
+		c := val[i]
+		switch {
+		case quoted && c == '\\' && i+1 < len(val):
+			i++
+			cur = append(cur, val[i])
+			keep = len(cur)
+		case c == '"':
+			quoted = !quoted
+			keep = len(cur)
+		case c == ',' && !quoted:
+			vals = append(vals, string(cur[:keep]))
+			cur = cur[:0]
+			keep = 0
+		case !quoted && (c == ' ' || c == '\t'):
+			// leading space is skipped
+			if len(cur) > 0 {
+				cur = append(cur, c)
+			}
+		default:
+			cur = append(cur, c)
+			keep = len(cur)
+		}
+	}
+
+	return append(vals, string(cur[:keep]))
 }
 
 // Copy makes a copy of the metadata
@@ -45,6 +133,15 @@ func Copy(md Metadata) Metadata {
 	return cmd
 }
 
+// canonical returns a copy of the metadata with canonical keys
+func canonical(md Metadata) Metadata {
+	cmd := make(Metadata, len(md))
+	for k, v := range md {
+		cmd[CanonicalKey(k)] = v
+	}
+	return cmd
+}
+
 // Delete key from metadata
 func Delete(ctx context.Context, k string) context.Context {
 	return Set(ctx, k, "")
@@ -57,29 +154,30 @@ func Set(ctx context.Context, k, v string) context.Context {
 		md = make(Metadata)
 	}
 	if v == "" {
-		delete(md, k)
+		md.Delete(k)
 	} else {
-		md[k] = v
+		md.Set(k, v)
 	}
 	return context.WithValue(ctx, metadataKey{}, md)
 }
 
 // Get returns a single value from metadata in the context
 func Get(ctx context.Context, key string) (string, bool) {
-	md, ok := FromContext(ctx)
+	md, ok := ctx.Value(metadataKey{}).(Metadata)
 	if !ok {
 		return "", ok
 	}
-	// attempt to get as is
-	val, ok := md[key]
-	if ok {
-		return val, ok
-	}
-
-	// attempt to get lower case
-	val, ok = md[strings.Title(key)]
+	return md.Get(key)
+}
 
-	return val, ok
+// Append adds the values to those of the key in the metadata of the context
+func Append(ctx context.Context, key string, vals ...string) context.Context {
+	md, ok := FromContext(ctx)
+	if !ok {
+		md = make(Metadata)
+	}
+	md.Add(key, vals...)
+	return context.WithValue(ctx, metadataKey{}, md)
 }
 
 // FromContext returns metadata from the given context
@@ -88,19 +186,12 @@ func FromContext(ctx context.Context) (Metadata, bool) {
 	if !ok {
 		return nil, ok
 	}
-
-	// capitalise all values
-	newMD := make(Metadata, len(md))
-	for k, v := range md {
-		newMD[strings.Title(k)] = v
-	}
-
-	return newMD, ok
+	return canonical(md), ok
 }
 
 // NewContext creates a new context with the given metadata
 func NewContext(ctx context.Context, md Metadata) context.Context {
-	return context.WithValue(ctx, metadataKey{}, md)
+	return context.WithValue(ctx, metadataKey{}, canonical(md))
 }
 
 // MergeContext merges metadata to existing metadata, overwriting if specified
@@ -109,11 +200,9 @@ func MergeContext(ctx context.Context, patchMd Metadata, overwrite bool) context
 		ctx = context.Background()
 	}
 	md, _ := ctx.Value(metadataKey{}).(Metadata)
-	cmd := make(Metadata, len(md))
-	for k, v := range md {
-		cmd[k] = v
-	}
+	cmd := canonical(md)
 	for k, v := range patchMd {
+		k = CanonicalKey(k)
 		if _, ok := cmd[k]; ok && !overwrite {
 			// skip
 		} else if v != "" {
@@ -124,3 +213,20 @@ func MergeContext(ctx context.Context, patchMd Metadata, overwrite bool) context
 	}
 	return context.WithValue(ctx, metadataKey{}, cmd)
 }
+
+// Merge merges the metadata into that of the context, the values of keys
+// which exist in both are appended rather than overwritten
+func Merge(ctx context.Context, patchMd Metadata) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	md, _ := ctx.Value(metadataKey{}).(Metadata)
+	cmd := canonical(md)
+	for k, v := range patchMd {
+		if len(v) == 0 {
+			continue
+		}
+		cmd.append(k, v)
+	}
+	return context.WithValue(ctx, metadataKey{}, cmd)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestMetadataSet(t *testing.T) {
@@ -113,3 +114,138 @@ func TestMergeContext(t *testing.T) {
 		})
 	}
 }
+
+func TestMetadataCanonical(t *testing.T) {
+	ctx := NewContext(context.TODO(), Metadata{
+		"micro-id": "1",
+		"X-FOO":    "bar",
+	})
+
+	for _, k := range []string{"Micro-Id", "micro-id", "MICRO-ID"} {
+		if v, ok := Get(ctx, k); !ok || v != "1" {
+			t.Fatalf("Expected 1 for %s got %s", k, v)
+		}
+	}
+
+	md, _ := FromContext(ctx)
+	if v := md["X-Foo"]; v != "bar" {
+		t.Fatalf("Expected X-Foo to be bar got %v", md)
+	}
+
+	md.Set("x-foo", "baz")
+	if len(md) != 2 || md["X-Foo"] != "baz" {
+		t.Fatalf("Expected X-Foo to be replaced got %v", md)
+	}
+
+	ctx = MergeContext(ctx, Metadata{"MICRO-ID": "2"}, true)
+	if v, _ := Get(ctx, "micro-id"); v != "2" {
+		t.Fatalf("Expected the merged value 2 got %s", v)
+	}
+}
+
+func TestMetadataValues(t *testing.T) {
+	md := Metadata{}
+	md.Add("Accept", "a")
+	md.Add("accept", "b", "c")
+
+	if v := md.Values("ACCEPT"); !reflect.DeepEqual(v, []string{"a", "b", "c"}) {
+		t.Fatalf("Unexpected values %v", v)
+	}
+	if md.Values("Missing") != nil {
+		t.Fatal("Expected no values for a missing key")
+	}
+
+	ctx := Append(context.TODO(), "Accept", "a")
+	ctx = Append(ctx, "accept", "b")
+	ctx = Merge(ctx, Metadata{"Accept": "c", "Foo": "bar"})
+
+	cmd, _ := FromContext(ctx)
+	if v := cmd.Values("Accept"); !reflect.DeepEqual(v, []string{"a", "b", "c"}) {
+		t.Fatalf("Unexpected values %v", v)
+	}
+	if v, _ := cmd.Get("Foo"); v != "bar" {
+		t.Fatalf("Expected Foo to be merged got %v", cmd)
+	}
+}
+
+func TestMetadataValuesQuoted(t *testing.T) {
+	vals := []string{"Tue, 15 Nov 1994 08:12:31 GMT", `say "hi"`, `C:\dir`, " padded ", "", "plain"}
+
+	md := Metadata{}
+	md.Add("Date", vals...)
+	if v := md.Values("date"); !reflect.DeepEqual(v, vals) {
+		t.Fatalf("Expected %q got %q", vals, v)
+	}
+
+	// values are still appended once quoted
+	ctx := Append(context.TODO(), "Date", vals[0])
+	ctx = Merge(ctx, Metadata{"Date": md["Date"]})
+
+	cmd, _ := FromContext(ctx)
+	if v := cmd.Values("Date"); !reflect.DeepEqual(v, append([]string{vals[0]}, vals...)) {
+		t.Fatalf("Unexpected merged values %q", v)
+	}
+}
+
+func TestMetadataBinary(t *testing.T) {
+	md := Metadata{}
+	md.SetBinary("trace", []byte{0, 1, 2})
+	md.AddBinary("Trace-Bin", []byte("foo"))
+
+	if _, ok := md["Trace-Bin"]; !ok {
+		t.Fatalf("Expected the Trace-Bin key got %v", md)
+	}
+
+	vals, ok := md.BinaryValues("trace")
+	if !ok || !reflect.DeepEqual(vals, [][]byte{{0, 1, 2}, []byte("foo")}) {
+		t.Fatalf("Unexpected values %v", vals)
+	}
+
+	// values sent without padding
+	md.Set("Key-Bin", "AAEC")
+	if v, ok := md.GetBinary("key-bin"); !ok || !reflect.DeepEqual(v, []byte{0, 1, 2}) {
+		t.Fatalf("Unexpected value %v", v)
+	}
+
+	md.Set("Bad-Bin", "%%%")
+	if _, ok := md.GetBinary("bad"); ok {
+		t.Fatal("Expected an invalid value not to be ok")
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	ctx := SetCaller(context.TODO(), "foo", "Foo.Bar")
+	ctx = SetBaggage(ctx, "user-id", "1")
+
+	if service, endpoint := Caller(ctx); service != "foo" || endpoint != "Foo.Bar" {
+		t.Fatalf("Unexpected caller %v %v", service, endpoint)
+	}
+	if v, ok := GetBaggage(ctx, "User-Id"); !ok || v != "1" {
+		t.Fatalf("Unexpected baggage %v", v)
+	}
+	if items := Baggage(ctx); !reflect.DeepEqual(items, map[string]string{"User-Id": "1"}) {
+		t.Fatalf("Unexpected baggage %v", items)
+	}
+
+	// the caller is replaced on the next hop
+	ctx = SetCaller(ctx, "bar", "")
+	if service, endpoint := Caller(ctx); service != "bar" || endpoint != "" {
+		t.Fatalf("Unexpected caller %v %v", service, endpoint)
+	}
+
+	// deadlines are passed on through the metadata
+	dctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	md, _ := FromContext(SetDeadline(dctx))
+
+	ctx, cancel = WithDeadline(NewContext(context.TODO(), md))
+	defer cancel()
+
+	want, _ := dctx.Deadline()
+	if d, ok := ctx.Deadline(); !ok || !d.Equal(want) {
+		t.Fatalf("Expected deadline %v got %v", want, d)
+	}
+	if b, ok := Budget(ctx); !ok || b <= 0 || b > time.Minute {
+		t.Fatalf("Unexpected budget %v", b)
+	}
+}
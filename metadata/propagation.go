@@ -0,0 +1,116 @@
+package metadata
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The keys propagated on every hop. Requests carry their budget in the
+// Timeout header, messages only carry the absolute deadline in unix
+// nanoseconds if the publisher sets it with SetDeadline.
+const (
+	CallerServiceKey  = "Micro-From-Service"
+	CallerEndpointKey = "Micro-From-Endpoint"
+	DeadlineKey       = "Micro-Deadline"
+	BaggagePrefix     = "Micro-Baggage-"
+)
+
+// Caller returns the service and endpoint which made the request
+func Caller(ctx context.Context) (service, endpoint string) {
+	md, ok := FromContext(ctx)
+	if !ok {
+		return "", ""
+	}
+	service, _ = md.Get(CallerServiceKey)
+	endpoint, _ = md.Get(CallerEndpointKey)
+	return service, endpoint
+}
+
+// SetCaller sets the service and endpoint making the request
+func SetCaller(ctx context.Context, service, endpoint string) context.Context {
+	md, ok := FromContext(ctx)
+	if !ok {
+		md = make(Metadata)
+	}
+	md.Set(CallerServiceKey, service)
+	if len(endpoint) > 0 {
+		md.Set(CallerEndpointKey, endpoint)
+	} else {
+		md.Delete(CallerEndpointKey)
+	}
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// SetBaggage sets a baggage item, which is passed on to every service the
+// request or message reaches
+func SetBaggage(ctx context.Context, key, val string) context.Context {
+	return Set(ctx, BaggagePrefix+key, val)
+}
+
+// GetBaggage returns a baggage item
+func GetBaggage(ctx context.Context, key string) (string, bool) {
+	return Get(ctx, BaggagePrefix+key)
+}
+
+// Baggage returns all the baggage items keyed without the prefix
+func Baggage(ctx context.Context) map[string]string {
+	md, ok := FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	items := make(map[string]string)
+	for k, v := range md {
+		if strings.HasPrefix(k, BaggagePrefix) && len(k) > len(BaggagePrefix) {
+			items[k[len(BaggagePrefix):]] = v
+		}
+	}
+	return items
+}
+
+// Budget returns the time remaining before the deadline of the context
+func Budget(ctx context.Context) (time.Duration, bool) {
+	d, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(d), true
+}
+
+// SetDeadline sets the deadline of the context in the metadata, it's used
+// by publishers to have subscribers handle the message within the deadline
+func SetDeadline(ctx context.Context) context.Context {
+	d, ok := ctx.Deadline()
+	if !ok {
+		return ctx
+	}
+	return Set(ctx, DeadlineKey, strconv.FormatInt(d.UnixNano(), 10))
+}
+
+// Deadline returns the deadline set in the metadata
+func (md Metadata) Deadline() (time.Time, bool) {
+	v, ok := md.Get(DeadlineKey)
+	if !ok {
+		return time.Time{}, false
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, n), true
+}
+
+// WithDeadline returns a context with the deadline set in its metadata,
+// if there isn't one the cancel func is a no-op
+func WithDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	md, ok := FromContext(ctx)
+	if !ok {
+		return ctx, func() {}
+	}
+	d, ok := md.Deadline()
+	if !ok {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, d)
+}
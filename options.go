@@ -11,6 +11,7 @@ import (
 	"github.com/asim/go-micro/v3/config"
 	"github.com/asim/go-micro/v3/debug/profile"
 	"github.com/asim/go-micro/v3/debug/trace"
+	"github.com/asim/go-micro/v3/logger"
 	"github.com/asim/go-micro/v3/registry"
 	"github.com/asim/go-micro/v3/runtime"
 	"github.com/asim/go-micro/v3/selector"
@@ -151,6 +152,16 @@ func Tracer(t trace.Tracer) Option {
 	}
 }
 
+// Logger sets the logger of the client, server, broker and registry
+func Logger(l logger.Logger) Option {
+	return func(o *Options) {
+		o.Client.Init(client.Logger(l))
+		o.Server.Init(server.Logger(l))
+		o.Broker.Init(broker.Logger(l))
+		o.Registry.Init(registry.Logger(l))
+	}
+}
+
 // Auth sets the auth for the service
 func Auth(a auth.Auth) Option {
 	return func(o *Options) {
@@ -138,7 +138,7 @@ func (j *jwt) Token(opts ...auth.TokenOption) (*auth.Token, error) {
 		return nil, err
 	}
 
-	access, err := j.jwt.Generate(account, jwtToken.WithExpiry(options.Expiry))
+	access, err := j.jwt.Generate(account, jwtToken.WithExpiry(options.Expiry), jwtToken.WithAudience(options.Audience))
 	if err != nil {
 		return nil, err
 	}
@@ -52,6 +52,7 @@ func (j *JWT) Generate(acc *auth.Account, opts ...GenerateOption) (*Token, error
 		acc.Type, acc.Scopes, acc.Metadata, jwt.StandardClaims{
 			Subject:   acc.ID,
 			Issuer:    acc.Issuer,
+			Audience:  options.Audience,
 			ExpiresAt: expiry.Unix(),
 		},
 	})
@@ -97,6 +98,7 @@ func (j *JWT) Inspect(t string) (*auth.Account, error) {
 	return &auth.Account{
 		ID:       claims.Subject,
 		Issuer:   claims.Issuer,
+		Audience: claims.Audience,
 		Type:     claims.Type,
 		Scopes:   claims.Scopes,
 		Metadata: claims.Metadata,
@@ -45,7 +45,7 @@ func TestInspect(t *testing.T) {
 		subject := "test"
 
 		acc := &auth.Account{ID: subject, Scopes: scopes, Metadata: md}
-		tok, err := j.Generate(acc)
+		tok, err := j.Generate(acc, WithAudience("go.micro.service.foo"))
 		if err != nil {
 			t.Fatalf("Generate returned %v error, expected nil", err)
 		}
@@ -63,6 +63,9 @@ func TestInspect(t *testing.T) {
 		if len(tok2.Metadata) != len(md) {
 			t.Errorf("Inspect returned %v as the token metadata, expected %v", tok2.Metadata, md)
 		}
+		if tok2.Audience != "go.micro.service.foo" {
+			t.Errorf("Inspect returned %v as the token audience, expected %v", tok2.Audience, "go.micro.service.foo")
+		}
 	})
 
 	t.Run("Expired token", func(t *testing.T) {
@@ -53,6 +53,8 @@ func NewOptions(opts ...Option) Options {
 type GenerateOptions struct {
 	// Expiry for the token
 	Expiry time.Duration
+	// Audience the token is issued for
+	Audience string
 }
 
 type GenerateOption func(o *GenerateOptions)
@@ -64,6 +66,13 @@ func WithExpiry(d time.Duration) GenerateOption {
 	}
 }
 
+// WithAudience sets the service the token is issued for
+func WithAudience(aud string) GenerateOption {
+	return func(o *GenerateOptions) {
+		o.Audience = aud
+	}
+}
+
 // NewGenerateOptions from a slice of options
 func NewGenerateOptions(opts ...GenerateOption) GenerateOptions {
 	var options GenerateOptions
@@ -2,9 +2,7 @@ package grpc
 
 import (
 	"github.com/asim/go-micro/v3/errors"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"net/http"
 )
 
 func microError(err error) error {
@@ -35,34 +33,5 @@ func microError(err error) error {
 	}
 
 	// fallback
-	return errors.New("go.micro.client", s.Message(), microStatusFromGrpcCode(s.Code()))
+	return errors.New("go.micro.client", s.Message(), errors.CodeFromGRPC(uint32(s.Code())))
 }
-
-func microStatusFromGrpcCode(code codes.Code) int32 {
-	switch code {
-	case codes.OK:
-		return http.StatusOK
-	case codes.InvalidArgument:
-		return http.StatusBadRequest
-	case codes.DeadlineExceeded:
-		return http.StatusRequestTimeout
-	case codes.NotFound:
-		return http.StatusNotFound
-	case codes.AlreadyExists:
-		return http.StatusConflict
-	case codes.PermissionDenied:
-		return http.StatusForbidden
-	case codes.Unauthenticated:
-		return http.StatusUnauthorized
-	case codes.FailedPrecondition:
-		return http.StatusPreconditionFailed
-	case codes.Unimplemented:
-		return http.StatusNotImplemented
-	case codes.Internal:
-		return http.StatusInternalServerError
-	case codes.Unavailable:
-		return http.StatusServiceUnavailable
-	}
-
-	return http.StatusInternalServerError
-}
\ No newline at end of file
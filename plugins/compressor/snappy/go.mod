@@ -0,0 +1,10 @@
+module github.com/asim/go-micro/plugins/compressor/snappy/v3
+
+go 1.16
+
+require (
+	github.com/asim/go-micro/v3 v3.5.2-0.20210630062103-c13bb07171bc
+	github.com/golang/snappy v0.0.3
+)
+
+replace github.com/asim/go-micro/v3 => ../../../../go-micro
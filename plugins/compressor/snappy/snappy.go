@@ -0,0 +1,24 @@
+// Package snappy provides a snappy compression of transport messages
+package snappy
+
+import (
+	"io"
+
+	"github.com/asim/go-micro/v3/transport"
+	"github.com/golang/snappy"
+)
+
+func init() {
+	transport.DefaultCompressors["snappy"] = Compressor{}
+}
+
+// Compressor compresses messages using the snappy framing format
+type Compressor struct{}
+
+func (Compressor) Writer(w io.Writer) io.WriteCloser {
+	return snappy.NewBufferedWriter(w)
+}
+
+func (Compressor) Reader(r io.Reader) (io.Reader, error) {
+	return snappy.NewReader(r), nil
+}
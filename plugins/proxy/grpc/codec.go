@@ -0,0 +1,55 @@
+package grpc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// frame is a raw message which is passed through without decoding
+type frame struct {
+	payload []byte
+}
+
+// frameCodec passes frames through as is so any method can be proxied
+// without the generated code for it. The name is the content subtype
+// sent to backends so it matches what the client sent.
+type frameCodec struct {
+	name string
+}
+
+// newCodec returns a codec for the content type e.g application/grpc+json
+func newCodec(ct string) frameCodec {
+	if idx := strings.Index(ct, "+"); idx > 0 {
+		return frameCodec{name: ct[idx+1:]}
+	}
+	return frameCodec{name: "proto"}
+}
+
+func (frameCodec) Marshal(v interface{}) ([]byte, error) {
+	f, ok := v.(*frame)
+	if !ok {
+		return nil, fmt.Errorf("proxy codec can't marshal %T", v)
+	}
+	return f.payload, nil
+}
+
+func (frameCodec) Unmarshal(data []byte, v interface{}) error {
+	f, ok := v.(*frame)
+	if !ok {
+		return fmt.Errorf("proxy codec can't unmarshal %T", v)
+	}
+	// the buffer may be reused by grpc
+	f.payload = append(f.payload[:0], data...)
+	return nil
+}
+
+func (c frameCodec) Name() string {
+	if len(c.name) == 0 {
+		return "proto"
+	}
+	return c.name
+}
+
+func (c frameCodec) String() string {
+	return c.Name()
+}
@@ -0,0 +1,14 @@
+module github.com/asim/go-micro/plugins/proxy/grpc/v3
+
+go 1.16
+
+require (
+	github.com/asim/go-micro/plugins/registry/memory/v3 v3.0.0-20210630062103-c13bb07171bc
+	github.com/asim/go-micro/v3 v3.5.2-0.20210630062103-c13bb07171bc
+	google.golang.org/grpc v1.38.0
+)
+
+replace (
+	github.com/asim/go-micro/plugins/registry/memory/v3 => ../../../plugins/registry/memory
+	github.com/asim/go-micro/v3 => ../../../../go-micro
+)
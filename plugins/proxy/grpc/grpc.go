@@ -0,0 +1,514 @@
+// Package grpc provides a transparent grpc proxy
+//
+// Any method, including streams, is forwarded to a backend resolved via the
+// registry without decoding the messages, so no generated code is required.
+//
+// Usage:
+//
+//	p := grpc.NewProxy(grpc.WithRegistry(registry))
+//
+//	l, _ := net.Listen("tcp", ":8081")
+//	p.Serve(l)
+//
+// Clients then dial the proxy using the service name as the authority or
+// set the Micro-Service header. Unary calls to mucp services are translated
+// to go-micro rpc calls so grpc clients can reach them while they migrate.
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/asim/go-micro/v3/debug/stats"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/selector"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrNoService is returned when the service for a call can't be resolved
+	ErrNoService = errors.New("no service specified")
+
+	// DefaultIdleTimeout is how long a backend connection is kept without calls
+	DefaultIdleTimeout = time.Minute * 5
+)
+
+// Proxy forwards grpc calls to backends
+type Proxy struct {
+	opts Options
+
+	sync.Mutex
+	conns map[string]*backend
+	srv   *grpc.Server
+	// closed to stop watching the backends, set once a backend is dialed
+	exit chan bool
+}
+
+// backend is a connection to a backend node
+type backend struct {
+	conn *grpc.ClientConn
+	// calls in flight
+	active int
+	// last time a call finished
+	used time.Time
+	// the node was deregistered, it's closed once the calls finish
+	removed bool
+}
+
+// NewProxy returns a new grpc proxy
+func NewProxy(opts ...Option) *Proxy {
+	return &Proxy{
+		opts:  NewOptions(opts...),
+		conns: make(map[string]*backend),
+	}
+}
+
+// Options returns the proxy options
+func (p *Proxy) Options() Options {
+	return p.opts
+}
+
+// ServerOptions returns the options required to proxy calls from a grpc.Server
+func (p *Proxy) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.CustomCodec(frameCodec{}),
+		grpc.UnknownServiceHandler(p.Handler),
+	}
+}
+
+// Serve accepts connections on the listener and proxies calls
+func (p *Proxy) Serve(l net.Listener) error {
+	p.Lock()
+	if p.srv == nil {
+		p.srv = grpc.NewServer(append(p.ServerOptions(), p.opts.ServerOptions...)...)
+	}
+	srv := p.srv
+	p.Unlock()
+
+	return srv.Serve(l)
+}
+
+// Stop gracefully stops the server and closes backend connections
+func (p *Proxy) Stop() error {
+	p.Lock()
+	srv := p.srv
+	p.srv = nil
+	p.Unlock()
+
+	// calls in flight need the lock to get their backend connection
+	if srv != nil {
+		srv.GracefulStop()
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if p.exit != nil {
+		close(p.exit)
+		p.exit = nil
+	}
+
+	for addr, b := range p.conns {
+		b.conn.Close()
+		delete(p.conns, addr)
+	}
+
+	return nil
+}
+
+// Stats returns the stats of calls proxied by service and method
+func (p *Proxy) Stats() []*stats.Route {
+	return p.opts.Stats.Read()
+}
+
+// Handler proxies a call to the backend, it's a grpc.StreamHandler
+func (p *Proxy) Handler(srv interface{}, stream grpc.ServerStream) error {
+	method, ok := grpc.MethodFromServerStream(stream)
+	if !ok {
+		return status.Error(codes.Internal, "method not found in stream")
+	}
+
+	md, _ := metadata.FromIncomingContext(stream.Context())
+
+	service, err := p.opts.Resolver(method, md)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	cs := &countStream{ServerStream: stream}
+	start := time.Now()
+	p.opts.Stats.Start(service, method)
+
+	err = p.proxy(cs, service, method, md)
+
+	p.record(&stats.Request{
+		Service:  service,
+		Endpoint: method,
+		Duration: time.Since(start),
+		BytesIn:  atomic.LoadUint64(&cs.in),
+		BytesOut: atomic.LoadUint64(&cs.out),
+		Error:    err,
+	})
+
+	return err
+}
+
+// record reports a completed call to the stats, hooks and logger
+func (p *Proxy) record(req *stats.Request) {
+	p.opts.Stats.Record(req)
+	// include proxied calls in the debug handler stats
+	stats.DefaultStats.Record(req.Error)
+
+	for _, fn := range p.opts.Hooks {
+		fn(req)
+	}
+
+	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+		logger.Fields(map[string]interface{}{
+			"service":   req.Service,
+			"method":    req.Endpoint,
+			"code":      status.Code(req.Error).String(),
+			"duration":  req.Duration.String(),
+			"bytes_in":  req.BytesIn,
+			"bytes_out": req.BytesOut,
+		}).Log(logger.DebugLevel, "proxy")
+	}
+}
+
+// proxy forwards the call to a backend node of the service
+func (p *Proxy) proxy(stream grpc.ServerStream, service, method string, md metadata.MD) error {
+	ctx := stream.Context()
+
+	node, err := p.next(service)
+	if err != nil {
+		return err
+	}
+
+	// translate calls to services which don't speak grpc
+	if node.Metadata["protocol"] == "mucp" {
+		err := p.translate(stream, service, method, md, node)
+		p.opts.Selector.Mark(service, node, err)
+		return err
+	}
+
+	conn, err := p.conn(node.Address)
+	if err != nil {
+		p.opts.Selector.Mark(service, node, err)
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	defer p.release(node.Address, conn)
+
+	// keep the content subtype the client sent
+	var ct string
+	if v := md.Get("content-type"); len(v) > 0 {
+		ct = v[0]
+	}
+
+	// pass through the metadata without the pseudo headers
+	md = md.Copy()
+	delete(md, ":authority")
+	delete(md, "content-type")
+
+	ctx, cancel := context.WithCancel(metadata.NewOutgoingContext(ctx, md))
+	defer cancel()
+
+	desc := &grpc.StreamDesc{
+		ServerStreams: true,
+		ClientStreams: true,
+	}
+
+	cs, err := grpc.NewClientStream(ctx, desc, conn, method, grpc.ForceCodec(newCodec(ct)))
+	if err != nil {
+		p.opts.Selector.Mark(service, node, err)
+		return err
+	}
+
+	// client to backend
+	sendErr := make(chan error, 1)
+	go func() {
+		sendErr <- forward(stream, cs)
+	}()
+
+	// backend to client
+	recvErr := make(chan error, 1)
+	go func() {
+		recvErr <- reply(cs, stream)
+	}()
+
+	for {
+		select {
+		case err := <-sendErr:
+			if err != io.EOF {
+				// the client went away so cancel the backend call
+				cancel()
+				return status.Error(codes.Internal, err.Error())
+			}
+			// the client finished sending
+			cs.CloseSend()
+			sendErr = nil
+		case err := <-recvErr:
+			stream.SetTrailer(cs.Trailer())
+			if err != io.EOF {
+				// the backend status is passed back as is
+				return err
+			}
+			p.opts.Selector.Mark(service, node, nil)
+			return nil
+		}
+	}
+}
+
+// next returns a backend node for the service
+func (p *Proxy) next(service string) (*registry.Node, error) {
+	next, err := p.opts.Selector.Select(service, selector.WithFilter(filterGrpc))
+	if err == selector.ErrNotFound {
+		return nil, status.Errorf(codes.NotFound, "service %s not found", service)
+	} else if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	node, err := next()
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "service %s: %v", service, err)
+	}
+
+	return node, nil
+}
+
+// conn returns a cached connection to the address, it's released once the
+// call is done
+func (p *Proxy) conn(addr string) (*grpc.ClientConn, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	// close the connections of nodes which leave or aren't used
+	if p.exit == nil {
+		p.exit = make(chan bool)
+		go p.watch(p.exit)
+		go p.expire(p.exit)
+	}
+
+	if b, ok := p.conns[addr]; ok {
+		// the node may have registered again
+		b.removed = false
+		b.active++
+		return b.conn, nil
+	}
+
+	var opts []grpc.DialOption
+	if p.opts.Credentials != nil {
+		opts = append(opts, grpc.WithTransportCredentials(p.opts.Credentials))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	opts = append(opts, p.opts.DialOptions...)
+
+	conn, err := grpc.Dial(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	p.conns[addr] = &backend{conn: conn, active: 1}
+	return conn, nil
+}
+
+// release marks a call using the connection as done
+func (p *Proxy) release(addr string, conn *grpc.ClientConn) {
+	p.Lock()
+	defer p.Unlock()
+
+	b, ok := p.conns[addr]
+	if !ok || b.conn != conn {
+		return
+	}
+
+	b.active--
+	b.used = time.Now()
+
+	if b.removed && b.active == 0 {
+		b.conn.Close()
+		delete(p.conns, addr)
+	}
+}
+
+// remove closes the connection to a node which left, once its calls finish
+func (p *Proxy) remove(addr string) {
+	p.Lock()
+	defer p.Unlock()
+
+	b, ok := p.conns[addr]
+	if !ok {
+		return
+	}
+
+	b.removed = true
+	if b.active == 0 {
+		b.conn.Close()
+		delete(p.conns, addr)
+	}
+}
+
+// expire closes the connections which weren't used for the idle timeout
+func (p *Proxy) expire(exit chan bool) {
+	if p.opts.IdleTimeout <= 0 {
+		return
+	}
+
+	t := time.NewTicker(p.opts.IdleTimeout / 2)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-exit:
+			return
+		case <-t.C:
+		}
+
+		p.Lock()
+		for addr, b := range p.conns {
+			if b.active == 0 && time.Since(b.used) > p.opts.IdleTimeout {
+				b.conn.Close()
+				delete(p.conns, addr)
+			}
+		}
+		p.Unlock()
+	}
+}
+
+// watch closes the connections to nodes which are deregistered
+func (p *Proxy) watch(exit chan bool) {
+	for {
+		w, err := p.opts.Registry.Watch()
+		if err != nil {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("Error watching the registry: %v", err)
+			}
+			select {
+			case <-exit:
+				return
+			case <-time.After(time.Second):
+				continue
+			}
+		}
+
+		done := make(chan bool)
+		go func() {
+			select {
+			case <-exit:
+			case <-done:
+			}
+			w.Stop()
+		}()
+
+		for {
+			res, err := w.Next()
+			if err != nil {
+				break
+			}
+			if res.Action != "delete" || res.Service == nil {
+				continue
+			}
+			for _, node := range res.Service.Nodes {
+				p.remove(node.Address)
+			}
+		}
+		close(done)
+
+		// watch again unless the proxy stopped
+		select {
+		case <-exit:
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// countStream counts the bytes of the messages sent and received, the
+// counts are updated atomically as forward may still be receiving
+type countStream struct {
+	grpc.ServerStream
+	in, out uint64
+}
+
+func (c *countStream) RecvMsg(m interface{}) error {
+	err := c.ServerStream.RecvMsg(m)
+	if f, ok := m.(*frame); ok && err == nil {
+		atomic.AddUint64(&c.in, uint64(len(f.payload)))
+	}
+	return err
+}
+
+func (c *countStream) SendMsg(m interface{}) error {
+	err := c.ServerStream.SendMsg(m)
+	if f, ok := m.(*frame); ok && err == nil {
+		atomic.AddUint64(&c.out, uint64(len(f.payload)))
+	}
+	return err
+}
+
+// forward sends messages from the client to the backend
+func forward(src grpc.ServerStream, dst grpc.ClientStream) error {
+	for {
+		f := new(frame)
+		if err := src.RecvMsg(f); err != nil {
+			return err
+		}
+		if err := dst.SendMsg(f); err != nil {
+			return err
+		}
+	}
+}
+
+// reply sends headers and messages from the backend to the client
+func reply(src grpc.ClientStream, dst grpc.ServerStream) error {
+	md, err := src.Header()
+	if err != nil {
+		return err
+	}
+
+	if err := dst.SendHeader(md); err != nil {
+		return err
+	}
+
+	for {
+		f := new(frame)
+		if err := src.RecvMsg(f); err != nil {
+			return err
+		}
+		if err := dst.SendMsg(f); err != nil {
+			return err
+		}
+	}
+}
+
+// filterGrpc only returns nodes which speak grpc or mucp
+func filterGrpc(old []*registry.Service) []*registry.Service {
+	var services []*registry.Service
+
+	for _, service := range old {
+		serv := new(registry.Service)
+		*serv = *service
+		serv.Nodes = nil
+
+		for _, node := range service.Nodes {
+			if p := node.Metadata["protocol"]; len(p) == 0 || p == "grpc" || p == "mucp" {
+				serv.Nodes = append(serv.Nodes, node)
+			}
+		}
+
+		if len(serv.Nodes) > 0 {
+			services = append(services, serv)
+		}
+	}
+
+	return services
+}
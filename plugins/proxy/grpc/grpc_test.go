@@ -0,0 +1,390 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/plugins/registry/memory/v3"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/selector"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// echo replies to every message received
+func echo(srv interface{}, stream grpc.ServerStream) error {
+	method, _ := grpc.MethodFromServerStream(stream)
+	if method == "/test.Echo/Fail" {
+		return status.Error(codes.PermissionDenied, "denied")
+	}
+
+	for {
+		f := new(frame)
+		if err := stream.RecvMsg(f); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		f.payload = append([]byte("echo "), f.payload...)
+		if err := stream.SendMsg(f); err != nil {
+			return err
+		}
+	}
+}
+
+func TestProxy(t *testing.T) {
+	bl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := grpc.NewServer(grpc.CustomCodec(frameCodec{}), grpc.UnknownServiceHandler(echo))
+	go backend.Serve(bl)
+	defer backend.Stop()
+
+	r := memory.NewRegistry()
+	r.Register(&registry.Service{
+		Name: "echo",
+		Nodes: []*registry.Node{{
+			Id:       "echo-1",
+			Address:  bl.Addr().String(),
+			Metadata: map[string]string{"protocol": "grpc"},
+		}},
+	})
+
+	pl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewProxy(WithRegistry(r))
+	go p.Serve(pl)
+	defer p.Stop()
+
+	conn, err := grpc.Dial(pl.Addr().String(), grpc.WithInsecure(), grpc.WithAuthority("echo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// unary
+	rsp := new(frame)
+	if err := conn.Invoke(context.TODO(), "/test.Echo/Call", &frame{payload: []byte("hello")}, rsp, grpc.ForceCodec(frameCodec{})); err != nil {
+		t.Fatal(err)
+	}
+	if string(rsp.payload) != "echo hello" {
+		t.Fatalf("Expected echo hello got %s", rsp.payload)
+	}
+
+	// bidirectional stream
+	stream, err := conn.NewStream(context.TODO(), &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}, "/test.Echo/Stream", grpc.ForceCodec(frameCodec{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, msg := range []string{"one", "two", "three"} {
+		if err := stream.SendMsg(&frame{payload: []byte(msg)}); err != nil {
+			t.Fatal(err)
+		}
+		f := new(frame)
+		if err := stream.RecvMsg(f); err != nil {
+			t.Fatal(err)
+		}
+		if string(f.payload) != "echo "+msg {
+			t.Fatalf("Expected echo %s got %s", msg, f.payload)
+		}
+	}
+
+	stream.CloseSend()
+	if err := stream.RecvMsg(new(frame)); err != io.EOF {
+		t.Fatalf("Expected EOF got %v", err)
+	}
+
+	// backend status is passed through
+	err = conn.Invoke(context.TODO(), "/test.Echo/Fail", &frame{}, new(frame), grpc.ForceCodec(frameCodec{}))
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("Expected permission denied got %v", err)
+	}
+
+	// unknown service
+	conn2, err := grpc.Dial(pl.Addr().String(), grpc.WithInsecure(), grpc.WithAuthority("missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn2.Close()
+
+	err = conn2.Invoke(context.TODO(), "/test.Echo/Call", &frame{}, new(frame), grpc.ForceCodec(frameCodec{}))
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected not found got %v", err)
+	}
+
+	// stats are recorded by route
+	var found bool
+	for _, route := range p.Stats() {
+		if route.Service != "echo" || route.Endpoint != "/test.Echo/Call" {
+			continue
+		}
+		found = true
+		if route.Requests != 1 || route.Active != 0 {
+			t.Fatalf("Expected 1 request got %d active %d", route.Requests, route.Active)
+		}
+		if route.BytesIn != 5 || route.BytesOut != 10 {
+			t.Fatalf("Expected 5 bytes in 10 out got %d %d", route.BytesIn, route.BytesOut)
+		}
+	}
+	if !found {
+		t.Fatal("Expected stats for /test.Echo/Call")
+	}
+}
+
+// markSelector records the errors nodes are marked with
+type markSelector struct {
+	selector.Selector
+
+	sync.Mutex
+	marks []error
+}
+
+func (m *markSelector) Mark(service string, node *registry.Node, err error) {
+	m.Lock()
+	m.marks = append(m.marks, err)
+	m.Unlock()
+	m.Selector.Mark(service, node, err)
+}
+
+func TestMark(t *testing.T) {
+	bl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := grpc.NewServer(grpc.CustomCodec(frameCodec{}), grpc.UnknownServiceHandler(echo))
+	go backend.Serve(bl)
+	defer backend.Stop()
+
+	r := memory.NewRegistry()
+	r.Register(&registry.Service{
+		Name: "echo",
+		Nodes: []*registry.Node{{
+			Id:       "echo-1",
+			Address:  bl.Addr().String(),
+			Metadata: map[string]string{"protocol": "grpc"},
+		}},
+	})
+
+	pl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &markSelector{Selector: selector.NewSelector(selector.Registry(r))}
+	p := NewProxy(WithRegistry(r), WithSelector(s))
+	go p.Serve(pl)
+
+	conn, err := grpc.Dial(pl.Addr().String(), grpc.WithInsecure(), grpc.WithAuthority("echo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := conn.Invoke(context.TODO(), "/test.Echo/Call", &frame{payload: []byte("hello")}, new(frame), grpc.ForceCodec(frameCodec{})); err != nil {
+		t.Fatal(err)
+	}
+
+	// successful calls are marked so the selector knows the node is healthy
+	s.Lock()
+	marks := s.marks
+	s.Unlock()
+	if len(marks) != 1 || marks[0] != nil {
+		t.Fatalf("Expected a single successful mark got %v", marks)
+	}
+
+	if err := p.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// selfSigned returns a certificate for 127.0.0.1 and a pool trusting it
+func selfSigned(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "echo"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+func TestTLS(t *testing.T) {
+	cert, pool := selfSigned(t)
+
+	bl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{cert}})),
+		grpc.CustomCodec(frameCodec{}),
+		grpc.UnknownServiceHandler(echo),
+	)
+	go backend.Serve(bl)
+	defer backend.Stop()
+
+	r := memory.NewRegistry()
+	r.Register(&registry.Service{
+		Name: "echo",
+		Nodes: []*registry.Node{{
+			Id:       "echo-1",
+			Address:  bl.Addr().String(),
+			Metadata: map[string]string{"protocol": "grpc"},
+		}},
+	})
+
+	pl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewProxy(
+		WithRegistry(r),
+		WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: pool})),
+	)
+	go p.Serve(pl)
+	defer p.Stop()
+
+	conn, err := grpc.Dial(pl.Addr().String(), grpc.WithInsecure(), grpc.WithAuthority("echo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	rsp := new(frame)
+	if err := conn.Invoke(context.TODO(), "/test.Echo/Call", &frame{payload: []byte("hello")}, rsp, grpc.ForceCodec(frameCodec{})); err != nil {
+		t.Fatal(err)
+	}
+	if string(rsp.payload) != "echo hello" {
+		t.Fatalf("Expected echo hello got %s", rsp.payload)
+	}
+}
+
+// waitConns waits until the proxy holds the number of backend connections
+func waitConns(t *testing.T, p *Proxy, n int) {
+	for i := 0; i < 100; i++ {
+		p.Lock()
+		l := len(p.conns)
+		p.Unlock()
+		if l == n {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("Expected %d backend connections", n)
+}
+
+func TestClose(t *testing.T) {
+	bl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := grpc.NewServer(grpc.CustomCodec(frameCodec{}), grpc.UnknownServiceHandler(echo))
+	go backend.Serve(bl)
+	defer backend.Stop()
+
+	service := &registry.Service{
+		Name: "echo",
+		Nodes: []*registry.Node{{
+			Id:       "echo-1",
+			Address:  bl.Addr().String(),
+			Metadata: map[string]string{"protocol": "grpc"},
+		}},
+	}
+
+	testData := []struct {
+		name    string
+		opts    []Option
+		closeFn func(r registry.Registry)
+	}{
+		{
+			name: "deregistered",
+			opts: []Option{WithIdleTimeout(0)},
+			closeFn: func(r registry.Registry) {
+				r.Deregister(service)
+			},
+		},
+		{
+			name:    "idle",
+			opts:    []Option{WithIdleTimeout(100 * time.Millisecond)},
+			closeFn: func(r registry.Registry) {},
+		},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			r := memory.NewRegistry()
+			r.Register(service)
+
+			pl, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p := NewProxy(append(d.opts, WithRegistry(r))...)
+			go p.Serve(pl)
+			defer p.Stop()
+
+			conn, err := grpc.Dial(pl.Addr().String(), grpc.WithInsecure(), grpc.WithAuthority("echo"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			if err := conn.Invoke(context.TODO(), "/test.Echo/Call", &frame{payload: []byte("hello")}, new(frame), grpc.ForceCodec(frameCodec{})); err != nil {
+				t.Fatal(err)
+			}
+			waitConns(t, p, 1)
+
+			d.closeFn(r)
+			waitConns(t, p, 0)
+		})
+	}
+}
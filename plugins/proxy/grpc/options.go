@@ -0,0 +1,158 @@
+package grpc
+
+import (
+	"strings"
+	"time"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/debug/stats"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/selector"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+// StatsHook is called with every completed call
+type StatsHook func(*stats.Request)
+
+// Resolver returns the name of the service a call is proxied to
+type Resolver func(method string, md metadata.MD) (string, error)
+
+type Options struct {
+	// Registry backends are looked up in
+	Registry registry.Registry
+	// Selector used to pick a backend node
+	Selector selector.Selector
+	// Resolver returns the service for a call
+	Resolver Resolver
+	// DialOptions used when connecting to backends
+	DialOptions []grpc.DialOption
+	// Credentials used when connecting to backends, insecure if not set
+	Credentials credentials.TransportCredentials
+	// IdleTimeout after which unused backend connections are closed
+	IdleTimeout time.Duration
+	// ServerOptions used for the proxy server
+	ServerOptions []grpc.ServerOption
+	// Client used to call mucp backends
+	Client client.Client
+	// Stats records per route stats
+	Stats *stats.Routes
+	// Hooks called with every completed call
+	Hooks []StatsHook
+}
+
+type Option func(o *Options)
+
+// NewOptions fills in the blanks
+func NewOptions(opts ...Option) Options {
+	options := Options{
+		Registry: registry.DefaultRegistry,
+		Resolver: DefaultResolver,
+		Client:   client.DefaultClient,
+		Stats:    stats.NewRoutes(),
+		// close connections to backends which are no longer used
+		IdleTimeout: DefaultIdleTimeout,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if options.Selector == nil {
+		options.Selector = selector.NewSelector(selector.Registry(options.Registry))
+	}
+
+	return options
+}
+
+// WithRegistry sets the registry backends are looked up in
+func WithRegistry(r registry.Registry) Option {
+	return func(o *Options) {
+		o.Registry = r
+	}
+}
+
+// WithSelector sets the selector used to pick backend nodes
+func WithSelector(s selector.Selector) Option {
+	return func(o *Options) {
+		o.Selector = s
+	}
+}
+
+// WithResolver sets the function which resolves the service for a call
+func WithResolver(r Resolver) Option {
+	return func(o *Options) {
+		o.Resolver = r
+	}
+}
+
+// WithDialOptions sets the options used to connect to backends, credentials
+// are set with WithTransportCredentials
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *Options) {
+		o.DialOptions = append(o.DialOptions, opts...)
+	}
+}
+
+// WithTransportCredentials sets the credentials used to connect to backends
+// e.g credentials.NewTLS(config), backends are dialed insecure if not set
+func WithTransportCredentials(c credentials.TransportCredentials) Option {
+	return func(o *Options) {
+		o.Credentials = c
+	}
+}
+
+// WithIdleTimeout sets how long a backend connection is kept without calls,
+// connections are kept until the node is deregistered if zero
+func WithIdleTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.IdleTimeout = d
+	}
+}
+
+// WithServerOptions sets the options of the proxy server e.g credentials
+func WithServerOptions(opts ...grpc.ServerOption) Option {
+	return func(o *Options) {
+		o.ServerOptions = append(o.ServerOptions, opts...)
+	}
+}
+
+// WithClient sets the client used to call mucp backends
+func WithClient(c client.Client) Option {
+	return func(o *Options) {
+		o.Client = c
+	}
+}
+
+// WithStats sets the route stats calls are recorded in
+func WithStats(r *stats.Routes) Option {
+	return func(o *Options) {
+		o.Stats = r
+	}
+}
+
+// WithStatsHook adds a hook called with every completed call e.g to export metrics
+func WithStatsHook(fn StatsHook) Option {
+	return func(o *Options) {
+		o.Hooks = append(o.Hooks, fn)
+	}
+}
+
+// DefaultResolver uses the Micro-Service header and falls back to the
+// host the client dialed so clients can address services by name
+func DefaultResolver(method string, md metadata.MD) (string, error) {
+	if v := md.Get("micro-service"); len(v) > 0 && len(v[0]) > 0 {
+		return v[0], nil
+	}
+
+	if v := md.Get(":authority"); len(v) > 0 && len(v[0]) > 0 {
+		host := v[0]
+		if idx := strings.LastIndex(host, ":"); idx > 0 {
+			host = host[:idx]
+		}
+		return host, nil
+	}
+
+	return "", ErrNoService
+}
@@ -0,0 +1,180 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/asim/go-micro/v3/client"
+	raw "github.com/asim/go-micro/v3/codec/bytes"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/registry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	gmetadata "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// translate calls a mucp backend for a grpc call. Messages are passed
+// through as is so only the content type is converted, a client sending
+// protobuf reaches the backend as application/protobuf. Endpoints the
+// backend registered as streams are called as mucp streams.
+func (p *Proxy) translate(stream grpc.ServerStream, service, method string, md gmetadata.MD, node *registry.Node) error {
+	var ct string
+	if v := md.Get("content-type"); len(v) > 0 {
+		ct = v[0]
+	}
+
+	ct, ok := contentType(p.opts.Client, ct)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "content type %s not supported by %s", ct, service)
+	}
+
+	endpoint, err := endpointName(method)
+	if err != nil {
+		return status.Error(codes.Unimplemented, err.Error())
+	}
+
+	// pass through the metadata
+	hdr := make(metadata.Metadata)
+	for k, v := range md {
+		if strings.HasPrefix(k, ":") || k == "content-type" || len(v) == 0 {
+			continue
+		}
+		hdr[k] = v[0]
+	}
+
+	ctx, cancel := context.WithCancel(metadata.NewContext(stream.Context(), hdr))
+	defer cancel()
+
+	if p.isStream(service, endpoint) {
+		return p.translateStream(ctx, stream, service, endpoint, ct, node)
+	}
+
+	// a unary call has a single request
+	in := new(frame)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	if err := stream.RecvMsg(new(frame)); err != io.EOF {
+		return status.Errorf(codes.Unimplemented, "%s of %s isn't a stream", endpoint, service)
+	}
+
+	req := p.opts.Client.NewRequest(service, endpoint, &raw.Frame{Data: in.payload}, client.WithContentType(ct))
+	rsp := new(raw.Frame)
+
+	if err := p.opts.Client.Call(ctx, req, rsp, client.WithAddress(node.Address)); err != nil {
+		return grpcError(err)
+	}
+
+	return stream.SendMsg(&frame{payload: rsp.Data})
+}
+
+// translateStream pipes the messages of the grpc call through a mucp stream.
+// mucp streams can't be half closed so the backend ends the stream.
+func (p *Proxy) translateStream(ctx context.Context, stream grpc.ServerStream, service, endpoint, ct string, node *registry.Node) error {
+	req := p.opts.Client.NewRequest(service, endpoint, &raw.Frame{}, client.WithContentType(ct), client.StreamingRequest())
+
+	cs, err := p.opts.Client.Stream(ctx, req, client.WithAddress(node.Address))
+	if err != nil {
+		return grpcError(err)
+	}
+	defer cs.Close()
+
+	// mucp streams don't watch the context, close it to unblock the backend
+	go func() {
+		<-ctx.Done()
+		cs.Close()
+	}()
+
+	// client to backend
+	go func() {
+		for {
+			in := new(frame)
+			if err := stream.RecvMsg(in); err != nil {
+				return
+			}
+			if err := cs.Send(&raw.Frame{Data: in.payload}); err != nil {
+				return
+			}
+		}
+	}()
+
+	// backend to client
+	for {
+		rsp := new(raw.Frame)
+		if err := cs.Recv(rsp); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return grpcError(err)
+		}
+		if err := stream.SendMsg(&frame{payload: rsp.Data}); err != nil {
+			return err
+		}
+	}
+}
+
+// isStream returns true if the service registered the endpoint as a stream
+func (p *Proxy) isStream(service, endpoint string) bool {
+	services, err := p.opts.Registry.GetService(service)
+	if err != nil {
+		return false
+	}
+
+	for _, srv := range services {
+		for _, ep := range srv.Endpoints {
+			if ep.Name == endpoint && ep.Metadata["stream"] == "true" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// grpcError converts a mucp error to a grpc status
+func grpcError(err error) error {
+	e := errors.FromError(err)
+	return status.Error(grpcCode(e.Code), e.Detail)
+}
+
+// endpointName converts a grpc method to an endpoint e.g /helloworld.Greeter/Hello => Greeter.Hello
+func endpointName(method string) (string, error) {
+	parts := strings.Split(strings.TrimPrefix(method, "/"), "/")
+	if len(parts) != 2 {
+		return "", errors.BadRequest("go.micro.proxy", "invalid method %s", method)
+	}
+
+	service := parts[0]
+	if idx := strings.LastIndex(service, "."); idx >= 0 {
+		service = service[idx+1:]
+	}
+
+	return service + "." + parts[1], nil
+}
+
+// contentType converts a grpc content type to one supported by the client codecs
+func contentType(c client.Client, ct string) (string, bool) {
+	switch ct {
+	case "", "application/grpc", "application/grpc+proto":
+		ct = "application/protobuf"
+	case "application/grpc+json":
+		ct = "application/json"
+	}
+
+	if _, ok := c.Options().Codecs[ct]; ok {
+		return ct, true
+	}
+
+	if _, ok := client.DefaultCodecs[ct]; ok {
+		return ct, true
+	}
+
+	return ct, false
+}
+
+// grpcCode maps micro error codes which follow http status codes to grpc codes
+func grpcCode(code int32) codes.Code {
+	return codes.Code(errors.GRPCCode(code))
+}
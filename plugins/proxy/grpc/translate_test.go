@@ -0,0 +1,138 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/asim/go-micro/plugins/registry/memory/v3"
+	"github.com/asim/go-micro/v3/client"
+	raw "github.com/asim/go-micro/v3/codec/bytes"
+	"github.com/asim/go-micro/v3/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// Test is a mucp handler with unary and streaming endpoints
+type Test struct{}
+
+func (t *Test) Call(ctx context.Context, req *raw.Frame, rsp *raw.Frame) error {
+	rsp.Data = append([]byte("echo "), req.Data...)
+	return nil
+}
+
+func (t *Test) Stream(ctx context.Context, stream server.Stream) error {
+	for {
+		f := new(raw.Frame)
+		if err := stream.Recv(f); err != nil {
+			return nil
+		}
+		f.Data = append([]byte("echo "), f.Data...)
+		if err := stream.Send(f); err != nil {
+			return err
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	r := memory.NewRegistry()
+
+	srv := server.NewServer(
+		server.Name("test"),
+		server.Address("127.0.0.1:0"),
+		server.Registry(r),
+	)
+	if err := srv.Handle(srv.NewHandler(new(Test))); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	pl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewProxy(WithRegistry(r), WithClient(client.NewClient(client.Registry(r))))
+	go p.Serve(pl)
+	defer p.Stop()
+
+	conn, err := grpc.Dial(pl.Addr().String(), grpc.WithInsecure(), grpc.WithAuthority("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// unary
+	rsp := new(frame)
+	if err := conn.Invoke(context.TODO(), "/test.Test/Call", &frame{payload: []byte("hello")}, rsp, grpc.ForceCodec(frameCodec{})); err != nil {
+		t.Fatal(err)
+	}
+	if string(rsp.payload) != "echo hello" {
+		t.Fatalf("Expected echo hello got %s", rsp.payload)
+	}
+
+	// bidirectional stream
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}, "/test.Test/Stream", grpc.ForceCodec(frameCodec{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, msg := range []string{"one", "two", "three"} {
+		if err := stream.SendMsg(&frame{payload: []byte(msg)}); err != nil {
+			t.Fatal(err)
+		}
+		f := new(frame)
+		if err := stream.RecvMsg(f); err != nil {
+			t.Fatal(err)
+		}
+		if string(f.payload) != "echo "+msg {
+			t.Fatalf("Expected echo %s got %s", msg, f.payload)
+		}
+	}
+}
+
+func TestEndpointName(t *testing.T) {
+	testData := []struct {
+		method   string
+		endpoint string
+		err      bool
+	}{
+		{"/helloworld.Greeter/Hello", "Greeter.Hello", false},
+		{"/Greeter/Hello", "Greeter.Hello", false},
+		{"/go.micro.srv.foo.Foo/Bar", "Foo.Bar", false},
+		{"/Greeter", "", true},
+	}
+
+	for _, d := range testData {
+		endpoint, err := endpointName(d.method)
+		if (err != nil) != d.err {
+			t.Fatalf("%s: expected error %v got %v", d.method, d.err, err)
+		}
+		if endpoint != d.endpoint {
+			t.Fatalf("%s: expected %s got %s", d.method, d.endpoint, endpoint)
+		}
+	}
+}
+
+func TestGrpcCode(t *testing.T) {
+	testData := map[int32]codes.Code{
+		400: codes.InvalidArgument,
+		404: codes.NotFound,
+		408: codes.DeadlineExceeded,
+		500: codes.Internal,
+		503: codes.Unavailable,
+		0:   codes.Unknown,
+	}
+
+	for code, expect := range testData {
+		if c := grpcCode(code); c != expect {
+			t.Fatalf("%d: expected %v got %v", code, expect, c)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/debug/stats"
+	merrors "github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/selector"
+)
+
+var (
+	// DefaultMaxBodySize is the max size of a request body translated to a
+	// mucp call, the body is read into memory unlike proxied bodies
+	DefaultMaxBodySize int64 = 10 * 1024 * 1024
+)
+
+// ProxyResolver returns the service a request is for and the path to send to it
+type ProxyResolver func(r *http.Request) (service string, path string)
+
+// ProxyOptions are the options of the http reverse proxy
+type ProxyOptions struct {
+	// Registry backends are looked up in
+	Registry registry.Registry
+	// Selector used to pick a backend node
+	Selector selector.Selector
+	// Resolver returns the service and path for a request
+	Resolver ProxyResolver
+	// Client used to call mucp backends
+	Client client.Client
+	// Stats records per route stats
+	Stats *stats.Routes
+	// Hooks called with every completed request
+	Hooks []StatsHook
+	// MaxBodySize of requests translated to mucp calls, no limit if zero
+	MaxBodySize int64
+}
+
+type ProxyOption func(o *ProxyOptions)
+
+// ProxyRegistry sets the registry backends are looked up in
+func ProxyRegistry(r registry.Registry) ProxyOption {
+	return func(o *ProxyOptions) {
+		o.Registry = r
+	}
+}
+
+// ProxySelector sets the selector used to pick backend nodes
+func ProxySelector(s selector.Selector) ProxyOption {
+	return func(o *ProxyOptions) {
+		o.Selector = s
+	}
+}
+
+// ProxyResolve sets the function which resolves the service and path of a request
+func ProxyResolve(fn ProxyResolver) ProxyOption {
+	return func(o *ProxyOptions) {
+		o.Resolver = fn
+	}
+}
+
+// ProxyClient sets the client used to call mucp backends
+func ProxyClient(c client.Client) ProxyOption {
+	return func(o *ProxyOptions) {
+		o.Client = c
+	}
+}
+
+// ProxyStats sets the route stats requests are recorded in
+func ProxyStats(r *stats.Routes) ProxyOption {
+	return func(o *ProxyOptions) {
+		o.Stats = r
+	}
+}
+
+// ProxyStatsHook adds a hook called with every completed request e.g to export metrics
+func ProxyStatsHook(fn StatsHook) ProxyOption {
+	return func(o *ProxyOptions) {
+		o.Hooks = append(o.Hooks, fn)
+	}
+}
+
+// ProxyMaxBodySize sets the max size of request bodies translated to mucp calls
+func ProxyMaxBodySize(n int64) ProxyOption {
+	return func(o *ProxyOptions) {
+		o.MaxBodySize = n
+	}
+}
+
+// DefaultProxyResolver uses the Micro-Service header if set and otherwise
+// the first path segment as the service, which is stripped from the path
+// e.g /greeter/hello is sent to the greeter service as /hello
+func DefaultProxyResolver(r *http.Request) (string, string) {
+	if service := r.Header.Get("Micro-Service"); len(service) > 0 {
+		return service, r.URL.Path
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if len(parts) < 2 {
+		return parts[0], "/"
+	}
+
+	return parts[0], "/" + parts[1]
+}
+
+// Proxy is a reverse proxy to http services registered in the registry
+// with metadata protocol=http. Request and response bodies are streamed
+// and websocket upgrades are passed through. Requests to services with
+// metadata protocol=mucp are translated to rpc calls e.g POST /greeter/say/hello
+// calls Say.Hello on the greeter service.
+type Proxy struct {
+	opts ProxyOptions
+}
+
+// Stats returns the stats of requests proxied by service and path
+func (p *Proxy) Stats() []*stats.Route {
+	return p.opts.Stats.Read()
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	service, path := p.opts.Resolver(r)
+	if len(service) == 0 {
+		http.Error(w, "service not specified", http.StatusBadRequest)
+		return
+	}
+
+	cw := &countWriter{ResponseWriter: w}
+	cb := &countBody{ReadCloser: r.Body}
+	r.Body = cb
+
+	start := time.Now()
+	p.opts.Stats.Start(service, path)
+
+	p.proxy(cw, r, service, path)
+
+	var err error
+	if cw.status >= 500 {
+		err = merrors.New(service, http.StatusText(cw.status), int32(cw.status))
+	}
+
+	p.record(&stats.Request{
+		Service:  service,
+		Endpoint: path,
+		Duration: time.Since(start),
+		BytesIn:  atomic.LoadUint64(&cb.n),
+		BytesOut: atomic.LoadUint64(&cw.n),
+		Error:    err,
+	}, cw.status)
+}
+
+// proxy sends the request to a backend node of the service
+func (p *Proxy) proxy(w http.ResponseWriter, r *http.Request, service, path string) {
+
+	next, err := p.opts.Selector.Select(service, selector.WithFilter(filterHTTP))
+	if err == selector.ErrNotFound {
+		http.Error(w, "service "+service+" not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	node, err := next()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	// translate requests to services which don't speak http
+	if node.Metadata["protocol"] == "mucp" {
+		if err := p.translate(w, r, service, path, node); err != nil {
+			p.opts.Selector.Mark(service, node, err)
+		}
+		return
+	}
+
+	scheme := "http"
+	if node.Metadata["secure"] == "true" {
+		scheme = "https"
+	}
+
+	rp := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = scheme
+			req.URL.Host = node.Address
+			req.URL.Path = path
+			req.URL.RawPath = ""
+			req.Header.Del("Micro-Service")
+			// don't send the default go user agent
+			if _, ok := req.Header["User-Agent"]; !ok {
+				req.Header.Set("User-Agent", "")
+			}
+		},
+		// flush immediately so streamed responses aren't buffered
+		FlushInterval: -1,
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("proxy error for %s: %v", service, err)
+			}
+			p.opts.Selector.Mark(service, node, err)
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+
+	rp.ServeHTTP(w, r)
+}
+
+// NewProxy returns a reverse proxy to http services in the registry
+//
+// Usage:
+//
+//	p := http.NewProxy(http.ProxyRegistry(etcd.NewRegistry()))
+//
+//	// GET /greeter/hello is sent to the greeter service as GET /hello
+//	http.ListenAndServe(":8080", p)
+func NewProxy(opts ...ProxyOption) *Proxy {
+	options := ProxyOptions{
+		Registry: registry.DefaultRegistry,
+		Resolver: DefaultProxyResolver,
+		Client:   client.DefaultClient,
+		Stats:    stats.NewRoutes(),
+		// the body of mucp calls is read into memory
+		MaxBodySize: DefaultMaxBodySize,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if options.Selector == nil {
+		options.Selector = selector.NewSelector(selector.Registry(options.Registry))
+	}
+
+	return &Proxy{
+		opts: options,
+	}
+}
+
+// filterHTTP only returns nodes which speak http or mucp
+func filterHTTP(old []*registry.Service) []*registry.Service {
+	var services []*registry.Service
+
+	for _, service := range old {
+		serv := new(registry.Service)
+		*serv = *service
+		serv.Nodes = nil
+
+		for _, node := range service.Nodes {
+			if p := node.Metadata["protocol"]; p == "http" || p == "mucp" {
+				serv.Nodes = append(serv.Nodes, node)
+			}
+		}
+
+		if len(serv.Nodes) > 0 {
+			services = append(services, serv)
+		}
+	}
+
+	return services
+}
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/asim/go-micro/plugins/registry/memory/v3"
+	"github.com/asim/go-micro/v3/registry"
+)
+
+func TestProxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		w.Write([]byte(r.Method + " " + r.URL.Path + " " + string(b)))
+	}))
+	defer backend.Close()
+
+	r := memory.NewRegistry()
+	r.Register(&registry.Service{
+		Name: "greeter",
+		Nodes: []*registry.Node{{
+			Id:       "greeter-1",
+			Address:  strings.TrimPrefix(backend.URL, "http://"),
+			Metadata: map[string]string{"protocol": "http"},
+		}},
+	})
+	r.Register(&registry.Service{
+		Name: "rpc",
+		Nodes: []*registry.Node{{
+			Id:       "rpc-1",
+			Address:  "127.0.0.1:1",
+			Metadata: map[string]string{"protocol": "mucp"},
+		}},
+	})
+
+	p := NewProxy(ProxyRegistry(r))
+
+	testData := []struct {
+		method string
+		path   string
+		ct     string
+		body   string
+		code   int
+		expect string
+	}{
+		{"POST", "/greeter/say/hello", "", "john", 200, "POST /say/hello john"},
+		{"GET", "/greeter", "", "", 200, "GET / "},
+		{"GET", "/missing/foo", "", "", 404, ""},
+		{"POST", "/rpc/foo", "text/plain", "foo", 415, ""},
+		{"POST", "/rpc", "application/json", "{}", 400, ""},
+	}
+
+	for _, d := range testData {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(d.method, d.path, strings.NewReader(d.body))
+		if len(d.ct) > 0 {
+			req.Header.Set("Content-Type", d.ct)
+		}
+		p.ServeHTTP(w, req)
+
+		if w.Code != d.code {
+			t.Fatalf("%s: expected %d got %d %s", d.path, d.code, w.Code, w.Body.String())
+		}
+		if len(d.expect) > 0 && w.Body.String() != d.expect {
+			t.Fatalf("%s: expected %q got %q", d.path, d.expect, w.Body.String())
+		}
+	}
+
+	// stats are recorded by route
+	var found bool
+	for _, route := range p.Stats() {
+		if route.Service != "greeter" || route.Endpoint != "/say/hello" {
+			continue
+		}
+		found = true
+		if route.Requests != 1 || route.Errors != 0 || route.Active != 0 {
+			t.Fatalf("expected 1 request got %d errors %d active %d", route.Requests, route.Errors, route.Active)
+		}
+		if route.BytesIn != 4 || route.BytesOut != 20 {
+			t.Fatalf("expected 4 bytes in 20 out got %d %d", route.BytesIn, route.BytesOut)
+		}
+	}
+	if !found {
+		t.Fatal("expected stats for /say/hello")
+	}
+}
+
+func TestEndpointName(t *testing.T) {
+	testData := map[string]string{
+		"/say/hello":    "Say.Hello",
+		"/foo/bar/baz/": "FooBar.Baz",
+		"/hello":        "Hello.Call",
+		"/":             "",
+	}
+
+	for path, expect := range testData {
+		if ep := endpointName(path); ep != expect {
+			t.Fatalf("%s: expected %s got %s", path, expect, ep)
+		}
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	r := memory.NewRegistry()
+	r.Register(&registry.Service{
+		Name: "rpc",
+		Nodes: []*registry.Node{{
+			Id:       "rpc-1",
+			Address:  "127.0.0.1:1",
+			Metadata: map[string]string{"protocol": "mucp"},
+		}},
+	})
+
+	p := NewProxy(ProxyRegistry(r), ProxyMaxBodySize(8))
+
+	// the declared length and chunked bodies are both limited
+	for _, length := range []int64{16, -1} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/rpc/foo/bar", strings.NewReader(`{"name":"john"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.ContentLength = length
+		p.ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("expected %d for length %d got %d %s", http.StatusRequestEntityTooLarge, length, w.Code, w.Body.String())
+		}
+	}
+}
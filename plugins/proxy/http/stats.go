@@ -0,0 +1,90 @@
+package http
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/asim/go-micro/v3/debug/stats"
+	"github.com/asim/go-micro/v3/logger"
+)
+
+// StatsHook is called with every completed request
+type StatsHook func(*stats.Request)
+
+// record reports a completed request to the stats, hooks and logger
+func (p *Proxy) record(req *stats.Request, code int) {
+	p.opts.Stats.Record(req)
+	// include proxied requests in the debug handler stats
+	stats.DefaultStats.Record(req.Error)
+
+	for _, fn := range p.opts.Hooks {
+		fn(req)
+	}
+
+	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+		logger.Fields(map[string]interface{}{
+			"service":   req.Service,
+			"path":      req.Endpoint,
+			"status":    code,
+			"duration":  req.Duration.String(),
+			"bytes_in":  req.BytesIn,
+			"bytes_out": req.BytesOut,
+		}).Log(logger.DebugLevel, "proxy")
+	}
+}
+
+// countBody counts the bytes read from the request body
+type countBody struct {
+	io.ReadCloser
+	n uint64
+}
+
+func (c *countBody) Read(b []byte) (int, error) {
+	n, err := c.ReadCloser.Read(b)
+	atomic.AddUint64(&c.n, uint64(n))
+	return n, err
+}
+
+// countWriter records the status and counts the bytes written
+type countWriter struct {
+	http.ResponseWriter
+	status int
+	n      uint64
+}
+
+func (c *countWriter) WriteHeader(code int) {
+	if c.status == 0 {
+		c.status = code
+	}
+	c.ResponseWriter.WriteHeader(code)
+}
+
+func (c *countWriter) Write(b []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	n, err := c.ResponseWriter.Write(b)
+	atomic.AddUint64(&c.n, uint64(n))
+	return n, err
+}
+
+func (c *countWriter) Flush() {
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (c *countWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := c.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("hijacking not supported")
+	}
+	if c.status == 0 {
+		c.status = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
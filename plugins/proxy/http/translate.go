@@ -0,0 +1,115 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/asim/go-micro/v3/client"
+	raw "github.com/asim/go-micro/v3/codec/bytes"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/registry"
+)
+
+// translate calls a mucp backend for a http request. The body is passed
+// through as is using the request content type so it must be supported
+// by one of the client codecs e.g application/json or application/protobuf.
+func (p *Proxy) translate(w http.ResponseWriter, r *http.Request, service, path string, node *registry.Node) error {
+	ct := r.Header.Get("Content-Type")
+	if idx := strings.IndexRune(ct, ';'); idx >= 0 {
+		ct = ct[:idx]
+	}
+	if len(ct) == 0 {
+		ct = "application/json"
+	}
+
+	if !supported(p.opts.Client, ct) {
+		http.Error(w, "content type "+ct+" not supported", http.StatusUnsupportedMediaType)
+		return nil
+	}
+
+	endpoint := endpointName(path)
+	if len(endpoint) == 0 {
+		http.Error(w, "endpoint not specified", http.StatusBadRequest)
+		return nil
+	}
+
+	// the body is read into memory so it's limited
+	max := p.opts.MaxBodySize
+	if max > 0 {
+		if r.ContentLength > max {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return nil
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, max)
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil && max > 0 && int64(len(b)) >= max {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return nil
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+	// an empty body isn't valid json
+	if len(b) == 0 && ct == "application/json" {
+		b = []byte(`{}`)
+	}
+
+	// pass through the headers
+	md := make(metadata.Metadata)
+	for k, v := range r.Header {
+		if k == "Content-Type" || k == "Micro-Service" || len(v) == 0 {
+			continue
+		}
+		md[k] = strings.Join(v, ",")
+	}
+
+	ctx := metadata.NewContext(r.Context(), md)
+	req := p.opts.Client.NewRequest(service, endpoint, &raw.Frame{Data: b}, client.WithContentType(ct))
+	rsp := new(raw.Frame)
+
+	if err := p.opts.Client.Call(ctx, req, rsp, client.WithAddress(node.Address)); err != nil {
+		e := errors.FromError(err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(errors.HTTPStatus(e))
+		w.Write([]byte(e.Error()))
+		return e
+	}
+
+	w.Header().Set("Content-Type", ct)
+	w.Write(rsp.Data)
+	return nil
+}
+
+// endpointName converts a path to an endpoint e.g /say/hello => Say.Hello
+func endpointName(path string) string {
+	var parts []string
+
+	for _, p := range strings.Split(strings.Trim(path, "/"), "/") {
+		if len(p) == 0 {
+			continue
+		}
+		parts = append(parts, strings.Title(p))
+	}
+
+	switch len(parts) {
+	case 0:
+		return ""
+	case 1:
+		return parts[0] + ".Call"
+	}
+
+	return strings.Join(parts[:len(parts)-1], "") + "." + parts[len(parts)-1]
+}
+
+// supported returns true if one of the client codecs handles the content type
+func supported(c client.Client, ct string) bool {
+	if _, ok := c.Options().Codecs[ct]; ok {
+		return true
+	}
+	_, ok := client.DefaultCodecs[ct]
+	return ok
+}
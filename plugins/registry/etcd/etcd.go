@@ -266,13 +266,16 @@ func (e *etcdRegistry) registerNode(s *registry.Service, node *registry.Node, op
 		}
 	}
 
-	if logger.V(logger.TraceLevel, logger.DefaultLogger) {
-		logger.Tracef("Registering %s id %s with lease %v and leaseID %v and ttl %v", service.Name, node.Id, lgr, lgr.ID, options.TTL)
-	}
 	// create an entry for the node
 	if lgr != nil {
+		if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+			logger.Tracef("Registering %s id %s with leaseID %v and ttl %v", service.Name, node.Id, lgr.ID, options.TTL)
+		}
 		_, err = e.client.Put(ctx, nodePath(service.Name, node.Id), encode(service), clientv3.WithLease(lgr.ID))
 	} else {
+		if logger.V(logger.TraceLevel, logger.DefaultLogger) {
+			logger.Tracef("Registering %s id %s without a ttl", service.Name, node.Id)
+		}
 		_, err = e.client.Put(ctx, nodePath(service.Name, node.Id), encode(service))
 	}
 	if err != nil {
@@ -36,6 +36,18 @@ func (m *Client) UpdatePod(podName string, pod *client.Pod) (*client.Pod, error)
 	return nil, nil
 }
 
+// AddPod adds a pod, e.g one created with the annotations of a service
+func (m *Client) AddPod(pod *client.Pod) {
+	m.Pods[pod.Metadata.Name] = pod
+
+	pstr, _ := json.Marshal(pod)
+
+	m.events <- watch.Event{
+		Type:   watch.Added,
+		Object: json.RawMessage(pstr),
+	}
+}
+
 // ListPods ...
 func (m *Client) ListPods(labels map[string]string) (*client.PodList, error) {
 	var pods []client.Pod
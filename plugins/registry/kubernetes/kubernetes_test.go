@@ -457,6 +457,66 @@ func TestWatcher(t *testing.T) {
 
 }
 
+func TestWatcherPodAdded(t *testing.T) {
+	r := setupRegistry()
+
+	w, err := r.Watch(registry.WatchService("foo.service"))
+	if err != nil {
+		t.Fatalf("Unexpected watch error: %v", err)
+	}
+	defer w.Stop()
+
+	// a pod created with the annotations of a service
+	svc := &registry.Service{
+		Name:    "foo.service",
+		Version: "1",
+		Nodes:   []*registry.Node{{Id: "foo.service:pod-3", Address: "10.0.0.100:80"}},
+	}
+	b, _ := json.Marshal(svc)
+	svcStr := string(b)
+
+	pod := &client.Pod{
+		Metadata: &client.Meta{
+			Name: "pod-3",
+			Labels: map[string]*string{
+				labelTypeKey: &labelTypeValueService,
+				svcSelectorPrefix + serviceName(svc.Name): &svcSelectorValue,
+			},
+			Annotations: map[string]*string{
+				annotationServiceKeyPrefix + serviceName(svc.Name): &svcStr,
+			},
+		},
+		Status: &client.Status{
+			PodIP: "10.0.0.100",
+			Phase: podRunning,
+		},
+	}
+
+	go mockClient.AddPod(pod)
+
+	res, err := w.Next()
+	if err != nil {
+		t.Fatalf("Unexpected next error: %v", err)
+	}
+	if res.Action != "create" || res.Service.Name != svc.Name {
+		t.Fatalf("Expected create of %s, got %s of %s", svc.Name, res.Action, res.Service.Name)
+	}
+	if !hasNodes(res.Service.Nodes, svc.Nodes) {
+		t.Fatalf("Expected nodes %v, got %v", svc.Nodes, res.Service.Nodes)
+	}
+
+	// remove pods
+	go teardownRegistry()
+
+	res, err = w.Next()
+	if err != nil {
+		t.Fatalf("Unexpected next error: %v", err)
+	}
+	if res.Action != "delete" || res.Service.Name != svc.Name {
+		t.Fatalf("Expected delete of %s, got %s of %s", svc.Name, res.Action, res.Service.Name)
+	}
+}
+
 func hasNodes(a, b []*registry.Node) bool {
 	found := 0
 	for _, nodeA := range a {
@@ -136,8 +136,9 @@ func (k *k8sWatcher) handleEvent(event watch.Event) {
 	}
 
 	switch event.Type {
-	case watch.Modified:
-		// Pod was modified
+	case watch.Added, watch.Modified:
+		// Pod was added e.g created with the annotations of a service,
+		// or modified
 
 		k.RLock()
 		cache := k.pods[pod.Metadata.Name]
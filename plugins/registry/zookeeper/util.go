@@ -33,7 +33,9 @@ func servicePath(s string) string {
 	return path.Join(prefix, strings.Replace(s, "/", "-", -1))
 }
 
-func createPath(path string, data []byte, client *zk.Conn) error {
+// createPath creates the znode with the flags e.g zk.FlagEphemeral, its
+// parents are created as persistent znodes
+func createPath(path string, data []byte, flags int32, client *zk.Conn) error {
 	exists, _, err := client.Exists(path)
 	if err != nil {
 		return err
@@ -58,7 +60,7 @@ func createPath(path string, data []byte, client *zk.Conn) error {
 		name += "/"
 	}
 
-	_, err = client.Create(path, data, int32(0), zk.WorldACL(zk.PermAll))
+	_, err = client.Create(path, data, flags, zk.WorldACL(zk.PermAll))
 	return err
 }
 
@@ -66,7 +66,7 @@ func configure(z *zookeeperRegistry, opts ...registry.Option) error {
 	}
 
 	// create our prefix path
-	if err := createPath(prefix, []byte{}, c); err != nil {
+	if err := createPath(prefix, []byte{}, 0, c); err != nil {
 		log.Error(err.Error())
 		return err
 	}
@@ -94,8 +94,9 @@ func (z *zookeeperRegistry) Deregister(s *registry.Service, opts ...registry.Der
 	z.Unlock()
 
 	for _, node := range s.Nodes {
+		// the node is already gone if its session expired
 		err := z.client.Delete(nodePath(s.Name, node.Id), -1)
-		if err != nil {
+		if err != nil && err != zk.ErrNoNode {
 			return err
 		}
 	}
@@ -124,10 +125,9 @@ func (z *zookeeperRegistry) Register(s *registry.Service, opts ...registry.Regis
 	v, ok := z.register[s.Name]
 	z.Unlock()
 
-	// the service is unchanged, skip registering
-	if ok && v == h {
-		return nil
-	}
+	// nodes of an unchanged service are only registered again if they
+	// expired with the session
+	unchanged := ok && v == h
 
 	service := &registry.Service{
 		Name:      s.Name,
@@ -143,6 +143,10 @@ func (z *zookeeperRegistry) Register(s *registry.Service, opts ...registry.Regis
 			return err
 		}
 
+		if exists && unchanged {
+			continue
+		}
+
 		srv, err := encode(service)
 		if err != nil {
 			return err
@@ -154,7 +158,8 @@ func (z *zookeeperRegistry) Register(s *registry.Service, opts ...registry.Regis
 				return err
 			}
 		} else {
-			err := createPath(nodePath(service.Name, node.Id), srv, z.client)
+			// nodes are removed when the session of the service ends
+			err := createPath(nodePath(service.Name, node.Id), srv, zk.FlagEphemeral, z.client)
 			if err != nil {
 				return err
 			}
@@ -304,7 +309,7 @@ func NewRegistry(opts ...registry.Option) registry.Registry {
 	}
 
 	// create our prefix path
-	if err := createPath(prefix, []byte{}, c); err != nil {
+	if err := createPath(prefix, []byte{}, 0, c); err != nil {
 		log.Error(err.Error())
 		return nil
 	}
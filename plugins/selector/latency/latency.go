@@ -0,0 +1,309 @@
+// Package latency is a selector which orders nodes by observed latency and advertised hops
+package latency
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/cmd"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/registry/cache"
+	"github.com/asim/go-micro/v3/selector"
+)
+
+/*
+   A latency based selector. Every node has a metric which is the moving average of the
+   latency of calls to it plus a cost for each hop the node advertises in its metadata
+   e.g nodes reached through a gateway register hops=1. Select returns the nodes ordered
+   by metric so calls go to the best route and retries to the next best. Nodes without
+   samples have a latency of zero so they're tried and measured. Samples expire so nodes
+   which were penalised or slow are measured again.
+*/
+
+type latencySelector struct {
+	so selector.Options
+	rc cache.Cache
+
+	hopCost   time.Duration
+	penalty   time.Duration
+	decay     float64
+	sampleTTL time.Duration
+
+	sync.RWMutex
+	// service to node id to moving average latency
+	latency map[string]map[string]*sample
+}
+
+// sample is the moving average latency of a node
+type sample struct {
+	latency time.Duration
+	updated time.Time
+}
+
+// route is a node and the metric it's ordered by
+type route struct {
+	node    *registry.Node
+	hops    int
+	latency time.Duration
+	metric  time.Duration
+}
+
+func init() {
+	cmd.DefaultSelectors["latency"] = NewSelector
+}
+
+// hops returns the hops advertised in the node metadata
+func hops(node *registry.Node) int {
+	if node.Metadata == nil {
+		return 0
+	}
+	h, err := strconv.Atoi(node.Metadata["hops"])
+	if err != nil || h < 0 {
+		return 0
+	}
+	return h
+}
+
+// order returns the routes of the nodes ordered by preference
+func (l *latencySelector) order(service string, nodes []*registry.Node) []route {
+	l.RLock()
+	latency := l.latency[service]
+	routes := make([]route, 0, len(nodes))
+	for _, node := range nodes {
+		h := hops(node)
+		var d time.Duration
+		if s, ok := latency[node.Id]; ok && !l.expired(s) {
+			d = s.latency
+		}
+		routes = append(routes, route{
+			node:    node,
+			hops:    h,
+			latency: d,
+			metric:  d + time.Duration(h)*l.hopCost,
+		})
+	}
+	l.RUnlock()
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].metric == routes[j].metric {
+			return routes[i].hops < routes[j].hops
+		}
+		return routes[i].metric < routes[j].metric
+	})
+
+	return routes
+}
+
+// expired returns true if the sample is too old to be used
+func (l *latencySelector) expired(s *sample) bool {
+	return l.sampleTTL > 0 && time.Since(s.updated) > l.sampleTTL
+}
+
+// Record adds a latency sample for the node
+func (l *latencySelector) Record(service string, node *registry.Node, d time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+
+	latency, ok := l.latency[service]
+	if !ok {
+		latency = make(map[string]*sample)
+		l.latency[service] = latency
+	}
+
+	s, ok := latency[node.Id]
+	if !ok || l.expired(s) {
+		latency[node.Id] = &sample{latency: d, updated: time.Now()}
+		return
+	}
+
+	s.latency = time.Duration(l.decay*float64(d) + (1-l.decay)*float64(s.latency))
+	s.updated = time.Now()
+}
+
+// evict removes the samples of nodes which are no longer registered
+func (l *latencySelector) evict(service string, services []*registry.Service) {
+	ids := make(map[string]bool)
+	for _, s := range services {
+		for _, node := range s.Nodes {
+			ids[node.Id] = true
+		}
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	for id := range l.latency[service] {
+		if !ids[id] {
+			delete(l.latency[service], id)
+		}
+	}
+}
+
+func (l *latencySelector) newCache() cache.Cache {
+	opts := make([]cache.Option, 0, 1)
+	if l.so.Context != nil {
+		if t, ok := l.so.Context.Value("selector_ttl").(time.Duration); ok {
+			opts = append(opts, cache.WithTTL(t))
+		}
+	}
+	return cache.New(l.so.Registry, opts...)
+}
+
+func (l *latencySelector) Init(opts ...selector.Option) error {
+	for _, o := range opts {
+		o(&l.so)
+	}
+	l.configure()
+
+	l.rc.Stop()
+	l.rc = l.newCache()
+
+	return nil
+}
+
+func (l *latencySelector) configure() {
+	if l.so.Context == nil {
+		return
+	}
+	if v, ok := l.so.Context.Value(hopCostKey{}).(time.Duration); ok {
+		l.hopCost = v
+	}
+	if v, ok := l.so.Context.Value(errorPenaltyKey{}).(time.Duration); ok {
+		l.penalty = v
+	}
+	if v, ok := l.so.Context.Value(decayKey{}).(float64); ok && v > 0 && v <= 1 {
+		l.decay = v
+	}
+	if v, ok := l.so.Context.Value(sampleTTLKey{}).(time.Duration); ok {
+		l.sampleTTL = v
+	}
+}
+
+func (l *latencySelector) Options() selector.Options {
+	return l.so
+}
+
+func (l *latencySelector) Select(service string, opts ...selector.SelectOption) (selector.Next, error) {
+	var sopts selector.SelectOptions
+	for _, opt := range opts {
+		opt(&sopts)
+	}
+
+	services, err := l.rc.GetService(service)
+	if err != nil {
+		if err == registry.ErrNotFound {
+			return nil, selector.ErrNotFound
+		}
+		return nil, err
+	}
+
+	// drop the samples of nodes which are gone before filtering
+	l.evict(service, services)
+
+	// apply the filters
+	for _, filter := range sopts.Filters {
+		services = filter(services)
+	}
+
+	var nodes []*registry.Node
+	for _, service := range services {
+		nodes = append(nodes, service.Nodes...)
+	}
+
+	if len(nodes) == 0 {
+		return nil, selector.ErrNotFound
+	}
+
+	routes := l.order(service, nodes)
+
+	var i int
+	var mtx sync.Mutex
+
+	// the best route first and then the next best for retries
+	return func() (*registry.Node, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		if i >= len(routes) {
+			i = 0
+		}
+		node := routes[i].node
+		i++
+		return node, nil
+	}, nil
+}
+
+// Mark records the error penalty for failed calls
+func (l *latencySelector) Mark(service string, node *registry.Node, err error) {
+	if err == nil {
+		return
+	}
+	l.Record(service, node, l.penalty)
+}
+
+func (l *latencySelector) Reset(service string) {
+	l.Lock()
+	delete(l.latency, service)
+	l.Unlock()
+}
+
+// Close stops the watcher and destroys the cache
+func (l *latencySelector) Close() error {
+	l.rc.Stop()
+	return nil
+}
+
+func (l *latencySelector) String() string {
+	return "latency"
+}
+
+// NewCallWrapper returns a client wrapper which records the latency of calls in
+// the selector. It does nothing if the selector isn't a latency selector.
+//
+//	s := latency.NewSelector()
+//	c := client.NewClient(client.Selector(s), client.WrapCall(latency.NewCallWrapper(s)))
+func NewCallWrapper(s selector.Selector) client.CallWrapper {
+	return func(fn client.CallFunc) client.CallFunc {
+		l, ok := s.(*latencySelector)
+		if !ok {
+			return fn
+		}
+
+		return func(ctx context.Context, node *registry.Node, req client.Request, rsp interface{}, opts client.CallOptions) error {
+			start := time.Now()
+			err := fn(ctx, node, req, rsp, opts)
+			// failures are recorded with the penalty by Mark
+			if err == nil {
+				l.Record(req.Service(), node, time.Since(start))
+			}
+			return err
+		}
+	}
+}
+
+func NewSelector(opts ...selector.Option) selector.Selector {
+	sopts := selector.Options{
+		Context:  context.TODO(),
+		Registry: registry.DefaultRegistry,
+	}
+
+	for _, opt := range opts {
+		opt(&sopts)
+	}
+
+	l := &latencySelector{
+		so:        sopts,
+		hopCost:   DefaultHopCost,
+		penalty:   DefaultErrorPenalty,
+		decay:     DefaultDecay,
+		sampleTTL: DefaultSampleTTL,
+		latency:   make(map[string]map[string]*sample),
+	}
+	l.configure()
+	l.rc = l.newCache()
+
+	return l
+}
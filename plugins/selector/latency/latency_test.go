@@ -0,0 +1,153 @@
+package latency
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/plugins/registry/memory/v3"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/selector"
+)
+
+func TestLatencySelector(t *testing.T) {
+	r := memory.NewRegistry()
+	r.Register(&registry.Service{
+		Name:    "foo",
+		Version: "latest",
+		Nodes: []*registry.Node{
+			{Id: "1", Address: "10.0.0.1:8080"},
+			{Id: "2", Address: "10.0.0.2:8080"},
+			{Id: "3", Address: "10.0.0.3:8080", Metadata: map[string]string{"hops": "2"}},
+		},
+	})
+
+	s := NewSelector(selector.Registry(r), HopCost(10*time.Millisecond)).(*latencySelector)
+
+	s.Record("foo", &registry.Node{Id: "1"}, 30*time.Millisecond)
+	s.Record("foo", &registry.Node{Id: "2"}, 5*time.Millisecond)
+	s.Record("foo", &registry.Node{Id: "3"}, time.Millisecond)
+
+	// 2 is fastest, 3 has 2 hops so 21ms and 1 is 30ms
+	next, err := s.Select("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"2", "3", "1", "2"} {
+		node, err := next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if node.Id != id {
+			t.Fatalf("Expected node %s got %s", id, node.Id)
+		}
+	}
+
+	// errors push the node to the back
+	s.Mark("foo", &registry.Node{Id: "2"}, errors.New("error"))
+
+	next, err = s.Select("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node, _ := next(); node.Id != "3" {
+		t.Fatalf("Expected node 3 got %s", node.Id)
+	}
+
+	// unknown services
+	if _, err := s.Select("bar"); err == nil {
+		t.Fatal("Expected error for unknown service")
+	}
+}
+
+func TestRecord(t *testing.T) {
+	s := NewSelector(Decay(0.5)).(*latencySelector)
+	node := &registry.Node{Id: "1"}
+
+	s.Record("foo", node, 10*time.Millisecond)
+	s.Record("foo", node, 20*time.Millisecond)
+
+	if d := s.latency["foo"]["1"].latency; d != 15*time.Millisecond {
+		t.Fatalf("Expected 15ms got %v", d)
+	}
+
+	// expired samples are replaced rather than averaged
+	s.latency["foo"]["1"].updated = time.Now().Add(-2 * DefaultSampleTTL)
+	s.Record("foo", node, 40*time.Millisecond)
+	if d := s.latency["foo"]["1"].latency; d != 40*time.Millisecond {
+		t.Fatalf("Expected 40ms got %v", d)
+	}
+
+	s.Reset("foo")
+	if _, ok := s.latency["foo"]; ok {
+		t.Fatal("Expected latency to be reset")
+	}
+}
+
+func TestSampleExpiry(t *testing.T) {
+	r := memory.NewRegistry()
+	r.Register(&registry.Service{
+		Name:    "foo",
+		Version: "latest",
+		Nodes: []*registry.Node{
+			{Id: "1", Address: "10.0.0.1:8080"},
+			{Id: "2", Address: "10.0.0.2:8080"},
+		},
+	})
+
+	s := NewSelector(selector.Registry(r), SampleTTL(time.Minute)).(*latencySelector)
+	defer s.Close()
+
+	s.Record("foo", &registry.Node{Id: "1"}, 5*time.Millisecond)
+	s.Mark("foo", &registry.Node{Id: "2"}, errors.New("error"))
+
+	next, err := s.Select("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node, _ := next(); node.Id != "1" {
+		t.Fatalf("Expected node 1 got %s", node.Id)
+	}
+
+	// once the penalty expires the node is tried again to be measured
+	s.Lock()
+	s.latency["foo"]["2"].updated = time.Now().Add(-2 * time.Minute)
+	s.Unlock()
+
+	next, err = s.Select("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node, _ := next(); node.Id != "2" {
+		t.Fatalf("Expected node 2 got %s", node.Id)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	r := memory.NewRegistry()
+	r.Register(&registry.Service{
+		Name:    "foo",
+		Version: "latest",
+		Nodes:   []*registry.Node{{Id: "1", Address: "10.0.0.1:8080"}},
+	})
+
+	s := NewSelector(selector.Registry(r)).(*latencySelector)
+	defer s.Close()
+
+	s.Record("foo", &registry.Node{Id: "1"}, time.Millisecond)
+	s.Record("foo", &registry.Node{Id: "2"}, time.Millisecond)
+
+	if _, err := s.Select("foo"); err != nil {
+		t.Fatal(err)
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+	if _, ok := s.latency["foo"]["2"]; ok {
+		t.Fatal("Expected the unknown node to be evicted")
+	}
+	if _, ok := s.latency["foo"]["1"]; !ok {
+		t.Fatal("Expected the registered node to be kept")
+	}
+}
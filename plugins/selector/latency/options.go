@@ -0,0 +1,55 @@
+package latency
+
+import (
+	"context"
+	"time"
+
+	"github.com/asim/go-micro/v3/selector"
+)
+
+var (
+	// DefaultHopCost is the latency added for every hop a node advertises
+	DefaultHopCost = 10 * time.Millisecond
+	// DefaultErrorPenalty is the latency recorded for a failed call
+	DefaultErrorPenalty = time.Second
+	// DefaultDecay is the weight given to the latest sample
+	DefaultDecay = 0.3
+	// DefaultSampleTTL is how long a sample is used before the node is measured again
+	DefaultSampleTTL = time.Minute
+)
+
+type hopCostKey struct{}
+type errorPenaltyKey struct{}
+type decayKey struct{}
+type sampleTTLKey struct{}
+
+// HopCost sets the latency added for every hop advertised in the node metadata
+func HopCost(d time.Duration) selector.Option {
+	return setOption(hopCostKey{}, d)
+}
+
+// ErrorPenalty sets the latency recorded when a call to a node fails
+func ErrorPenalty(d time.Duration) selector.Option {
+	return setOption(errorPenaltyKey{}, d)
+}
+
+// Decay sets the weight between 0 and 1 given to the latest latency sample
+func Decay(f float64) selector.Option {
+	return setOption(decayKey{}, f)
+}
+
+// SampleTTL sets how long the latency of a node is used without a new sample.
+// Once it expires the node has no latency so it's tried and measured again,
+// which lets penalised nodes recover. Zero keeps samples forever.
+func SampleTTL(d time.Duration) selector.Option {
+	return setOption(sampleTTLKey{}, d)
+}
+
+func setOption(k, v interface{}) selector.Option {
+	return func(o *selector.Options) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, k, v)
+	}
+}
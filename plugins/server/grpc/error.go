@@ -1,8 +1,6 @@
 package grpc
 
 import (
-	"net/http"
-
 	"github.com/asim/go-micro/v3/errors"
 	"google.golang.org/grpc/codes"
 )
@@ -13,30 +11,5 @@ func microError(err *errors.Error) codes.Code {
 		return codes.OK
 	}
 
-	switch err.Code {
-	case http.StatusOK:
-		return codes.OK
-	case http.StatusBadRequest:
-		return codes.InvalidArgument
-	case http.StatusRequestTimeout:
-		return codes.DeadlineExceeded
-	case http.StatusNotFound:
-		return codes.NotFound
-	case http.StatusConflict:
-		return codes.AlreadyExists
-	case http.StatusForbidden:
-		return codes.PermissionDenied
-	case http.StatusUnauthorized:
-		return codes.Unauthenticated
-	case http.StatusPreconditionFailed:
-		return codes.FailedPrecondition
-	case http.StatusNotImplemented:
-		return codes.Unimplemented
-	case http.StatusInternalServerError:
-		return codes.Internal
-	case http.StatusServiceUnavailable:
-		return codes.Unavailable
-	}
-
-	return codes.Unknown
+	return codes.Code(errors.GRPCCode(err.Code))
 }
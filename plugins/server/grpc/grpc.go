@@ -21,10 +21,12 @@ import (
 	meta "github.com/asim/go-micro/v3/metadata"
 	"github.com/asim/go-micro/v3/registry"
 	"github.com/asim/go-micro/v3/server"
+	"github.com/asim/go-micro/v3/transport"
 	"github.com/asim/go-micro/v3/util/addr"
 	"github.com/asim/go-micro/v3/util/backoff"
 	mgrpc "github.com/asim/go-micro/v3/util/grpc"
 	mnet "github.com/asim/go-micro/v3/util/net"
+	mls "github.com/asim/go-micro/v3/util/tls"
 	"github.com/golang/protobuf/proto"
 	"golang.org/x/net/netutil"
 
@@ -263,6 +265,11 @@ func (g *grpcServer) handler(srv interface{}, stream grpc.ServerStream) error {
 	if p, ok := peer.FromContext(stream.Context()); ok {
 		md["Remote"] = p.Addr.String()
 		ctx = peer.NewContext(ctx, p)
+
+		// set the peer if its certificate was verified
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 {
+			ctx = server.NewPeerContext(ctx, mls.PeerName(info.State.VerifiedChains[0][0]))
+		}
 	}
 
 	// set the timeout if we have it
@@ -646,14 +653,10 @@ func (g *grpcServer) Register() error {
 		advt = config.Address
 	}
 
-	if cnt := strings.Count(advt, ":"); cnt >= 1 {
-		// ipv6 address in format [host]:port or ipv4 host:port
-		host, port, err = net.SplitHostPort(advt)
-		if err != nil {
-			return err
-		}
-	} else {
-		host = advt
+	// ipv6 address in format [host]:port, a bare ipv6 address or ipv4 host:port
+	host, port, err = mnet.SplitHostPort(advt)
+	if err != nil {
+		return err
 	}
 
 	if ip := net.ParseIP(host); ip != nil {
@@ -792,14 +795,10 @@ func (g *grpcServer) Deregister() error {
 		advt = config.Address
 	}
 
-	if cnt := strings.Count(advt, ":"); cnt >= 1 {
-		// ipv6 address in format [host]:port or ipv4 host:port
-		host, port, err = net.SplitHostPort(advt)
-		if err != nil {
-			return err
-		}
-	} else {
-		host = advt
+	// ipv6 address in format [host]:port, a bare ipv6 address or ipv4 host:port
+	host, port, err = mnet.SplitHostPort(advt)
+	if err != nil {
+		return err
 	}
 
 	addr, err := addr.Extract(host)
@@ -871,19 +870,25 @@ func (g *grpcServer) Start() error {
 		err error
 	)
 
+	var lopts transport.ListenOptions
+	for _, o := range config.ListenOptions {
+		o(&lopts)
+	}
+
 	if l := g.getListener(); l != nil {
-		ts = l
+		ts = transport.LimitListener(l, lopts.MaxConnections, lopts.Reject)
 	} else {
+		ts, err = net.Listen("tcp", config.Address)
+		if err != nil {
+			return err
+		}
+
+		// limit before the handshake so the tls state is kept
+		ts = transport.LimitListener(ts, lopts.MaxConnections, lopts.Reject)
 
 		// check the tls config for secure connect
 		if tc := config.TLSConfig; tc != nil {
-			ts, err = tls.Listen("tcp", config.Address, tc)
-			// otherwise just plain tcp listener
-		} else {
-			ts, err = net.Listen("tcp", config.Address)
-		}
-		if err != nil {
-			return err
+			ts = tls.NewListener(ts, tc)
 		}
 	}
 
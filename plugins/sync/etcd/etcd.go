@@ -4,10 +4,12 @@ package etcd
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"path"
 	"strings"
 	gosync "sync"
+	"time"
 
 	"github.com/asim/go-micro/v3/sync"
 	"go.etcd.io/etcd/client/v3"
@@ -28,6 +30,106 @@ type etcdLock struct {
 	m *cc.Mutex
 }
 
+type etcdBarrier struct {
+	opts   sync.BarrierOptions
+	client *clientv3.Client
+	path   string
+	n      int
+}
+
+func (e *etcdBarrier) Wait() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// decide if we should wait
+	if e.opts.Wait > time.Duration(0) {
+		ctx, cancel = context.WithTimeout(ctx, e.opts.Wait)
+		defer cancel()
+	}
+
+	// the session is closed on return which revokes our key, so a
+	// participant that times out is withdrawn from the barrier
+	s, err := cc.NewSession(e.client)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	// each round waits under its own generation, named by the revision the
+	// generation key was last written at, so keys left over from the previous
+	// round whose sessions haven't closed yet aren't counted
+	genKey := path.Join(e.path, "generation")
+	waiter := fmt.Sprintf("%x", s.Lease())
+
+	var gen, rev int64
+	for {
+		grsp, err := e.client.Get(ctx, genKey)
+		if err != nil {
+			return e.err(ctx, err)
+		}
+		if len(grsp.Kvs) > 0 {
+			gen = grsp.Kvs[0].ModRevision
+		} else {
+			gen = 0
+		}
+
+		// join unless the round was released in the meantime
+		trsp, err := e.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(genKey), "=", gen)).
+			Then(clientv3.OpPut(e.waiters(gen, waiter), "", clientv3.WithLease(s.Lease()))).
+			Commit()
+		if err != nil {
+			return e.err(ctx, err)
+		}
+		if trsp.Succeeded {
+			rev = trsp.Header.Revision
+			break
+		}
+	}
+
+	waiters, err := e.client.Get(ctx, e.waiters(gen, ""), clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		return e.err(ctx, err)
+	}
+
+	// last to arrive releases everyone by starting the next generation, if
+	// the compare fails someone else already did
+	if waiters.Count >= int64(e.n) {
+		_, err := e.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(genKey), "=", gen)).
+			Then(clientv3.OpPut(genKey, "")).
+			Commit()
+		return e.err(ctx, err)
+	}
+
+	// the watch is cancelled with the context so nothing is left behind on timeout
+	for wrsp := range e.client.Watch(ctx, genKey, clientv3.WithRev(rev)) {
+		if err := wrsp.Err(); err != nil {
+			return e.err(ctx, err)
+		}
+		for _, ev := range wrsp.Events {
+			if ev.Type == clientv3.EventTypePut {
+				return nil
+			}
+		}
+	}
+
+	return e.err(ctx, ctx.Err())
+}
+
+// waiters returns the key of a waiter of the generation
+func (e *etcdBarrier) waiters(gen int64, waiter string) string {
+	return path.Join(e.path, fmt.Sprintf("%d", gen), "waiters") + "/" + waiter
+}
+
+// err returns ErrBarrierTimeout if the wait expired
+func (e *etcdBarrier) err(ctx context.Context, err error) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return sync.ErrBarrierTimeout
+	}
+	return err
+}
+
 type etcdLeader struct {
 	opts sync.LeaderOptions
 	s    *cc.Session
@@ -140,6 +242,27 @@ func (e *etcdSync) Unlock(id string) error {
 	return err
 }
 
+func (e *etcdSync) Barrier(id string, n int, opts ...sync.BarrierOption) (sync.Barrier, error) {
+	var options sync.BarrierOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if n < 1 {
+		return nil, errors.New("barrier requires at least one participant")
+	}
+
+	// make path
+	path := path.Join(e.path, "barrier", strings.Replace(e.options.Prefix+id, "/", "-", -1))
+
+	return &etcdBarrier{
+		opts:   options,
+		client: e.client,
+		path:   path,
+		n:      n,
+	}, nil
+}
+
 func (e *etcdSync) String() string {
 	return "etcd"
 }
@@ -0,0 +1,78 @@
+package etcd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/sync"
+)
+
+// newSync returns a sync connected to the etcd nodes in ETCD_ADDRS
+func newSync(t *testing.T) sync.Sync {
+	addr := os.Getenv("ETCD_ADDRS")
+	if len(addr) == 0 {
+		t.Skip("ETCD_ADDRS not set")
+	}
+
+	// a prefix of the test so runs don't share barriers
+	return NewSync(sync.Nodes(strings.Split(addr, ",")...), sync.Prefix(fmt.Sprintf("test-%d-", time.Now().UnixNano())))
+}
+
+func TestBarrier(t *testing.T) {
+	s := newSync(t)
+
+	if _, err := s.Barrier("test", 0); err == nil {
+		t.Fatal("Expected error for a barrier without participants")
+	}
+
+	// run the barrier twice to check it's reset after each round
+	for round := 0; round < 2; round++ {
+		errCh := make(chan error, 3)
+
+		for i := 0; i < 3; i++ {
+			b, err := s.Barrier("test", 3, sync.BarrierWait(time.Second*5))
+			if err != nil {
+				t.Fatal(err)
+			}
+			go func() {
+				errCh <- b.Wait()
+			}()
+		}
+
+		for i := 0; i < 3; i++ {
+			if err := <-errCh; err != nil {
+				t.Fatalf("Round %d: unexpected error %v", round, err)
+			}
+		}
+	}
+
+	// the participants of the previous rounds aren't counted in the next
+	b, err := s.Barrier("test", 2, sync.BarrierWait(time.Millisecond*500))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Wait(); err != sync.ErrBarrierTimeout {
+		t.Fatalf("Expected ErrBarrierTimeout got %v", err)
+	}
+}
+
+func TestBarrierTimeout(t *testing.T) {
+	s := newSync(t)
+
+	b, err := s.Barrier("test", 2, sync.BarrierWait(time.Millisecond*500))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.Wait(); err != sync.ErrBarrierTimeout {
+		t.Fatalf("Expected ErrBarrierTimeout got %v", err)
+	}
+
+	// the participant which timed out was withdrawn so one more isn't enough
+	if err := b.Wait(); err != sync.ErrBarrierTimeout {
+		t.Fatalf("Expected ErrBarrierTimeout got %v", err)
+	}
+}
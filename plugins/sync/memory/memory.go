@@ -2,6 +2,7 @@
 package memory
 
 import (
+	"errors"
 	gosync "sync"
 	"time"
 
@@ -11,8 +12,9 @@ import (
 type memorySync struct {
 	options sync.Options
 
-	mtx   gosync.RWMutex
-	locks map[string]*memoryLock
+	mtx      gosync.RWMutex
+	locks    map[string]*memoryLock
+	barriers map[string]*memoryBarrier
 }
 
 type memoryLock struct {
@@ -22,6 +24,74 @@ type memoryLock struct {
 	release chan bool
 }
 
+type memoryBarrier struct {
+	n       int
+	count   int
+	release chan bool
+}
+
+type memoryBarrierHandle struct {
+	opts sync.BarrierOptions
+	id   string
+	n    int
+	sync *memorySync
+}
+
+func (m *memoryBarrierHandle) Wait() error {
+	m.sync.mtx.Lock()
+
+	b, ok := m.sync.barriers[m.id]
+	if !ok {
+		b = &memoryBarrier{
+			n:       m.n,
+			release: make(chan bool),
+		}
+		m.sync.barriers[m.id] = b
+	} else if b.n != m.n {
+		m.sync.mtx.Unlock()
+		return sync.ErrBarrierMismatch
+	}
+
+	b.count++
+
+	// last to arrive releases everyone
+	if b.count >= b.n {
+		// reset the barrier for the next round
+		delete(m.sync.barriers, m.id)
+		close(b.release)
+		m.sync.mtx.Unlock()
+		return nil
+	}
+
+	m.sync.mtx.Unlock()
+
+	var wait <-chan time.Time
+
+	// decide if we should wait
+	if m.opts.Wait > time.Duration(0) {
+		wait = time.After(m.opts.Wait)
+	}
+
+	select {
+	case <-b.release:
+		return nil
+	case <-wait:
+		m.sync.mtx.Lock()
+		defer m.sync.mtx.Unlock()
+
+		// released while we were acquiring the lock
+		select {
+		case <-b.release:
+			return nil
+		default:
+		}
+
+		// withdraw from the barrier
+		b.count--
+		return sync.ErrBarrierTimeout
+	}
+}
+
 type memoryLeader struct {
 	opts   sync.LeaderOptions
 	id     string
@@ -185,6 +255,32 @@ func (m *memorySync) Unlock(id string) error {
 	return nil
 }
 
+func (m *memorySync) Barrier(id string, n int, opts ...sync.BarrierOption) (sync.Barrier, error) {
+	var options sync.BarrierOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if n < 1 {
+		return nil, errors.New("barrier requires at least one participant")
+	}
+
+	// participants of the round waiting must agree on n
+	m.mtx.RLock()
+	b, ok := m.barriers[id]
+	m.mtx.RUnlock()
+	if ok && b.n != n {
+		return nil, sync.ErrBarrierMismatch
+	}
+
+	return &memoryBarrierHandle{
+		opts: options,
+		id:   id,
+		n:    n,
+		sync: m,
+	}, nil
+}
+
 func (m *memorySync) String() string {
 	return "memory"
 }
@@ -196,7 +292,8 @@ func NewSync(opts ...sync.Option) sync.Sync {
 	}
 
 	return &memorySync{
-		options: options,
-		locks:   make(map[string]*memoryLock),
+		options:  options,
+		locks:    make(map[string]*memoryLock),
+		barriers: make(map[string]*memoryBarrier),
 	}
 }
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/sync"
+)
+
+func TestBarrier(t *testing.T) {
+	s := NewSync()
+
+	if _, err := s.Barrier("test", 0); err == nil {
+		t.Fatal("Expected error for a barrier without participants")
+	}
+
+	// run the barrier twice to check it's reset after each round
+	for round := 0; round < 2; round++ {
+		errCh := make(chan error, 3)
+
+		for i := 0; i < 3; i++ {
+			b, err := s.Barrier("test", 3, sync.BarrierWait(time.Second))
+			if err != nil {
+				t.Fatal(err)
+			}
+			go func() {
+				errCh <- b.Wait()
+			}()
+		}
+
+		for i := 0; i < 3; i++ {
+			if err := <-errCh; err != nil {
+				t.Fatalf("Round %d: unexpected error %v", round, err)
+			}
+		}
+	}
+}
+
+func TestBarrierTimeout(t *testing.T) {
+	s := NewSync()
+
+	b, err := s.Barrier("test", 2, sync.BarrierWait(time.Millisecond*10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.Wait(); err != sync.ErrBarrierTimeout {
+		t.Fatalf("Expected ErrBarrierTimeout got %v", err)
+	}
+
+	// the participant which timed out was withdrawn so one more isn't enough
+	if err := b.Wait(); err != sync.ErrBarrierTimeout {
+		t.Fatalf("Expected ErrBarrierTimeout got %v", err)
+	}
+}
+
+func TestBarrierMismatch(t *testing.T) {
+	s := NewSync()
+
+	b, err := s.Barrier("test", 2, sync.BarrierWait(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// created before the round starts, checked when it joins
+	other, err := s.Barrier("test", 3, sync.BarrierWait(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- b.Wait()
+	}()
+
+	// wait for the round to start
+	for {
+		if _, err := s.Barrier("test", 3); err == sync.ErrBarrierMismatch {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := other.Wait(); err != sync.ErrBarrierMismatch {
+		t.Fatalf("Expected ErrBarrierMismatch got %v", err)
+	}
+
+	// a matching participant releases the round
+	if err := b.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"context"
 	"crypto/tls"
 	"net"
+	"time"
 
+	"github.com/asim/go-micro/v3/cmd"
 	"github.com/asim/go-micro/v3/transport"
 	maddr "github.com/asim/go-micro/v3/util/addr"
 	mnet "github.com/asim/go-micro/v3/util/net"
@@ -13,10 +15,15 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 
 	pb "github.com/asim/go-micro/plugins/transport/grpc/v3/proto"
 )
 
+func init() {
+	cmd.DefaultTransports["grpc"] = NewTransport
+}
+
 type grpcTransport struct {
 	opts transport.Options
 }
@@ -25,6 +32,7 @@ type grpcTransportListener struct {
 	listener net.Listener
 	secure   bool
 	tls      *tls.Config
+	opts     transport.Options
 }
 
 func getTLSConfig(addr string) (*tls.Config, error) {
@@ -48,6 +56,12 @@ func getTLSConfig(addr string) (*tls.Config, error) {
 	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
 }
 
+// msgSize returns the grpc message size for a max body size, leaving room
+// for the message headers
+func msgSize(max int) int {
+	return max + headerSize
+}
+
 func (t *grpcTransportListener) Addr() string {
 	return t.listener.Addr().String()
 }
@@ -75,11 +89,36 @@ func (t *grpcTransportListener) Accept(fn func(transport.Socket)) error {
 		opts = append(opts, grpc.Creds(creds))
 	}
 
+	// ping clients and close idle connections, clients may ping as often
+	// as the server does
+	if t.opts.KeepAlive > 0 || t.opts.IdleTimeout > 0 {
+		params := keepalive.ServerParameters{MaxConnectionIdle: t.opts.IdleTimeout}
+		if t.opts.KeepAlive > 0 {
+			params.Time = t.opts.KeepAlive
+			opts = append(opts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+				MinTime:             t.opts.KeepAlive,
+				PermitWithoutStream: true,
+			}))
+		}
+		opts = append(opts, grpc.KeepaliveParams(params))
+	}
+
+	// let through messages up to the max recv size so they're rejected by
+	// the socket, which keeps their headers
+	if t.opts.MaxRecvSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(msgSize(t.opts.MaxRecvSize)))
+	}
+
 	// new service
 	srv := grpc.NewServer(opts...)
 
 	// register service
-	pb.RegisterTransportServer(srv, &microTransport{addr: t.listener.Addr().String(), fn: fn})
+	pb.RegisterTransportServer(srv, &microTransport{
+		addr:    t.listener.Addr().String(),
+		fn:      fn,
+		maxSend: t.opts.MaxSendSize,
+		maxRecv: t.opts.MaxRecvSize,
+	})
 
 	// start serving
 	return srv.Serve(t.listener)
@@ -94,9 +133,7 @@ func (t *grpcTransport) Dial(addr string, opts ...transport.DialOption) (transpo
 		opt(&dopts)
 	}
 
-	options := []grpc.DialOption{
-		grpc.WithTimeout(dopts.Timeout),
-	}
+	var options []grpc.DialOption
 
 	if t.opts.Secure || t.opts.TLSConfig != nil {
 		config := t.opts.TLSConfig
@@ -111,24 +148,49 @@ func (t *grpcTransport) Dial(addr string, opts ...transport.DialOption) (transpo
 		options = append(options, grpc.WithInsecure())
 	}
 
-	// dial the server
-	conn, err := grpc.Dial(addr, options...)
-	if err != nil {
-		return nil, err
+	// ping the server so idle connections aren't dropped by firewalls
+	if t.opts.KeepAlive > 0 {
+		options = append(options, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                t.opts.KeepAlive,
+			PermitWithoutStream: true,
+		}))
+	}
+
+	if t.opts.MaxRecvSize > 0 {
+		options = append(options, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(msgSize(t.opts.MaxRecvSize))))
+	}
+
+	// observe the connection to the server
+	if o := t.opts.Observer; o != nil {
+		options = append(options, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			start := time.Now()
+			c, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+			o.Dialed(addr, time.Since(start), err)
+			if err != nil {
+				return nil, err
+			}
+			return transport.ObserveConn(c, o), nil
+		}))
 	}
 
-	// create stream
-	stream, err := pb.NewTransportClient(conn).Stream(context.Background())
+	// block so the dial fails once the timeout is reached
+	ctx, cancel := context.WithTimeout(context.Background(), dopts.Timeout)
+	defer cancel()
+
+	// dial the server
+	conn, err := grpc.DialContext(ctx, addr, append(options, grpc.WithBlock())...)
 	if err != nil {
 		return nil, err
 	}
 
-	// return a client
+	// the stream is opened on the first send so it carries the headers
+	// proxies and meshes route on
 	return &grpcTransportClient{
-		conn:   conn,
-		stream: stream,
-		local:  "localhost",
-		remote: addr,
+		conn:    conn,
+		local:   "localhost",
+		remote:  addr,
+		maxSend: t.opts.MaxSendSize,
+		maxRecv: t.opts.MaxRecvSize,
 	}, nil
 }
 
@@ -139,16 +201,23 @@ func (t *grpcTransport) Listen(addr string, opts ...transport.ListenOption) (tra
 	}
 
 	ln, err := mnet.Listen(addr, func(addr string) (net.Listener, error) {
-		return net.Listen("tcp", addr)
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		return transport.ObserveListener(l, t.opts.Observer), nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	ln = transport.LimitListener(ln, options.MaxConnections, options.Reject)
+
 	return &grpcTransportListener{
 		listener: ln,
 		tls:      t.opts.TLSConfig,
 		secure:   t.opts.Secure,
+		opts:     t.opts,
 	}, nil
 }
 
@@ -1,8 +1,10 @@
 package grpc
 
 import (
+	"errors"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/asim/go-micro/v3/transport"
 )
@@ -109,3 +111,71 @@ func TestGRPCTransportCommunication(t *testing.T) {
 
 	close(done)
 }
+
+func TestGRPCTransportDialTimeout(t *testing.T) {
+	// nothing serves grpc on the listener
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	tr := NewTransport()
+	if _, err := tr.Dial(l.Addr().String(), transport.WithTimeout(time.Millisecond*100)); err == nil {
+		t.Fatal("Expected the dial to time out")
+	}
+}
+
+func TestGRPCTransportMaxSize(t *testing.T) {
+	tr := NewTransport(transport.MaxSendSize(8), transport.MaxRecvSize(4))
+
+	l, err := tr.Listen(":0")
+	if err != nil {
+		t.Fatalf("Unexpected listen err: %v", err)
+	}
+	defer l.Close()
+
+	errc := make(chan error, 2)
+	hdrc := make(chan map[string]string, 2)
+	go l.Accept(func(sock transport.Socket) {
+		defer sock.Close()
+		for i := 0; i < 2; i++ {
+			var m transport.Message
+			errc <- sock.Recv(&m)
+			hdrc <- m.Header
+		}
+	})
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected dial err: %v", err)
+	}
+	defer c.Close()
+
+	hdr := map[string]string{"Micro-Id": "1"}
+
+	// larger than the maximum send size
+	if err := c.Send(&transport.Message{Header: hdr, Body: []byte(`ping ping`)}); !errors.Is(err, transport.ErrMessageTooLarge) {
+		t.Fatalf("Expected %v got %v", transport.ErrMessageTooLarge, err)
+	}
+
+	// within the send size but larger than the receive size
+	if err := c.Send(&transport.Message{Header: hdr, Body: []byte(`ping`)}); err != nil {
+		t.Fatalf("Unexpected send err: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("Unexpected recv err: %v", err)
+	}
+	<-hdrc
+
+	if err := c.Send(&transport.Message{Header: hdr, Body: []byte(`ping!`)}); err != nil {
+		t.Fatalf("Unexpected send err: %v", err)
+	}
+	if err := <-errc; !errors.Is(err, transport.ErrMessageTooLarge) {
+		t.Fatalf("Expected %v got %v", transport.ErrMessageTooLarge, err)
+	}
+	// the headers are kept so the server can reply
+	if h := <-hdrc; h["Micro-Id"] != "1" {
+		t.Fatalf("Expected the headers of the message got %v", h)
+	}
+}
@@ -12,15 +12,19 @@ import (
 
 // microTransport satisfies the pb.TransportServer inteface
 type microTransport struct {
-	addr string
-	fn   func(transport.Socket)
+	addr    string
+	fn      func(transport.Socket)
+	maxSend int
+	maxRecv int
 }
 
 func (m *microTransport) Stream(ts pb.Transport_StreamServer) (err error) {
 
 	sock := &grpcTransportSocket{
-		stream: ts,
-		local:  m.addr,
+		stream:  ts,
+		local:   m.addr,
+		maxSend: m.maxSend,
+		maxRecv: m.maxRecv,
 	}
 
 	p, ok := peer.FromContext(ts.Context())
@@ -1,23 +1,69 @@
 package grpc
 
 import (
+	"context"
+	"sync"
+
 	"github.com/asim/go-micro/v3/transport"
 	pb "github.com/asim/go-micro/plugins/transport/grpc/v3/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
+// RoutingHeaders are the message headers sent as grpc metadata when the
+// stream is opened, so L7 load balancers and meshes can route on them. The
+// stream is reused for later messages to the same address.
+var RoutingHeaders = []string{"Micro-Service"}
+
+// headerSize is the room left for headers when limiting grpc message sizes
+const headerSize = 64 * 1024
+
 type grpcTransportClient struct {
-	conn   *grpc.ClientConn
+	conn *grpc.ClientConn
+
+	sync.Mutex
 	stream pb.Transport_StreamClient
+	err    error
 
-	local  string
-	remote string
+	local   string
+	remote  string
+	maxSend int
+	maxRecv int
 }
 
 type grpcTransportSocket struct {
-	stream pb.Transport_StreamServer
-	local  string
-	remote string
+	stream  pb.Transport_StreamServer
+	local   string
+	remote  string
+	maxSend int
+	maxRecv int
+}
+
+// getStream returns the stream, opening it with the routing headers of the
+// message if it isn't open yet
+func (g *grpcTransportClient) getStream(m *transport.Message) (pb.Transport_StreamClient, error) {
+	g.Lock()
+	defer g.Unlock()
+
+	if g.stream != nil || g.err != nil {
+		return g.stream, g.err
+	}
+
+	ctx := context.Background()
+	if m != nil {
+		md := metadata.MD{}
+		for _, k := range RoutingHeaders {
+			if v, ok := m.Header[k]; ok && len(v) > 0 {
+				md.Set(k, v)
+			}
+		}
+		if len(md) > 0 {
+			ctx = metadata.NewOutgoingContext(ctx, md)
+		}
+	}
+
+	g.stream, g.err = pb.NewTransportClient(g.conn).Stream(ctx)
+	return g.stream, g.err
 }
 
 func (g *grpcTransportClient) Local() string {
@@ -33,14 +79,19 @@ func (g *grpcTransportClient) Recv(m *transport.Message) error {
 		return nil
 	}
 
-	msg, err := g.stream.Recv()
+	stream, err := g.getStream(nil)
+	if err != nil {
+		return err
+	}
+
+	msg, err := stream.Recv()
 	if err != nil {
 		return err
 	}
 
 	m.Header = msg.Header
 	m.Body = msg.Body
-	return nil
+	return transport.CheckSize(len(m.Body), g.maxRecv)
 }
 
 func (g *grpcTransportClient) Send(m *transport.Message) error {
@@ -48,13 +99,28 @@ func (g *grpcTransportClient) Send(m *transport.Message) error {
 		return nil
 	}
 
-	return g.stream.Send(&pb.Message{
+	if err := transport.CheckSize(len(m.Body), g.maxSend); err != nil {
+		return err
+	}
+
+	stream, err := g.getStream(m)
+	if err != nil {
+		return err
+	}
+
+	return stream.Send(&pb.Message{
 		Header: m.Header,
 		Body:   m.Body,
 	})
 }
 
 func (g *grpcTransportClient) Close() error {
+	g.Lock()
+	if g.stream != nil {
+		// let the server know we're done before closing the connection
+		g.stream.CloseSend()
+	}
+	g.Unlock()
 	return g.conn.Close()
 }
 
@@ -78,7 +144,7 @@ func (g *grpcTransportSocket) Recv(m *transport.Message) error {
 
 	m.Header = msg.Header
 	m.Body = msg.Body
-	return nil
+	return transport.CheckSize(len(m.Body), g.maxRecv)
 }
 
 func (g *grpcTransportSocket) Send(m *transport.Message) error {
@@ -86,6 +152,10 @@ func (g *grpcTransportSocket) Send(m *transport.Message) error {
 		return nil
 	}
 
+	if err := transport.CheckSize(len(m.Body), g.maxSend); err != nil {
+		return err
+	}
+
 	return g.stream.Send(&pb.Message{
 		Header: m.Header,
 		Body:   m.Body,
@@ -20,9 +20,17 @@ type ntport struct {
 	addrs []string
 	opts  transport.Options
 	nopts nats.Options
+
+	// connection shared by the clients, it's closed once the clients
+	// using it are closed
+	sync.Mutex
+	conn *nats.Conn
+	refs map[*nats.Conn]int
 }
 
 type ntportClient struct {
+	t      *ntport
+	once   sync.Once
 	conn   *nats.Conn
 	addr   string
 	id     string
@@ -124,6 +132,10 @@ func (n *ntportClient) Remote() string {
 }
 
 func (n *ntportClient) Send(m *transport.Message) error {
+	if err := transport.CheckSize(len(m.Body), n.opts.MaxSendSize); err != nil {
+		return err
+	}
+
 	b, err := n.opts.Codec.Marshal(m)
 	if err != nil {
 		return err
@@ -166,13 +178,17 @@ func (n *ntportClient) Recv(m *transport.Message) error {
 	}
 
 	*m = mr
-	return nil
+	return transport.CheckSize(len(m.Body), n.opts.MaxRecvSize)
 }
 
 func (n *ntportClient) Close() error {
-	n.sub.Unsubscribe()
-	n.conn.Close()
-	return nil
+	var err error
+	n.once.Do(func() {
+		err = n.sub.Unsubscribe()
+		// the connection is shared with the other clients
+		n.t.release(n.conn)
+	})
+	return err
 }
 
 func (n *ntportSocket) Local() string {
@@ -219,10 +235,14 @@ func (n *ntportSocket) Recv(m *transport.Message) error {
 	if err := n.opts.Codec.Unmarshal(r.Data, m); err != nil {
 		return err
 	}
-	return nil
+	return transport.CheckSize(len(m.Body), n.opts.MaxRecvSize)
 }
 
 func (n *ntportSocket) Send(m *transport.Message) error {
+	if err := transport.CheckSize(len(m.Body), n.opts.MaxSendSize); err != nil {
+		return err
+	}
+
 	b, err := n.opts.Codec.Marshal(m)
 	if err != nil {
 		return err
@@ -341,20 +361,22 @@ func (n *ntportListener) Accept(fn func(transport.Socket)) error {
 	}
 }
 
-func (n *ntport) Dial(addr string, dialOpts ...transport.DialOption) (transport.Client, error) {
-	dopts := transport.DialOptions{
-		Timeout: transport.DefaultDialTimeout,
-	}
+// connect returns the connection shared by the clients, each client only
+// subscribes to its own inbox so dials don't open a connection every time
+func (n *ntport) connect(timeout time.Duration) (*nats.Conn, error) {
+	n.Lock()
+	defer n.Unlock()
 
-	for _, o := range dialOpts {
-		o(&dopts)
+	if n.conn != nil && !n.conn.IsClosed() {
+		n.refs[n.conn]++
+		return n.conn, nil
 	}
 
 	opts := n.nopts
 	opts.Servers = n.addrs
 	opts.Secure = n.opts.Secure
 	opts.TLSConfig = n.opts.TLSConfig
-	opts.Timeout = dopts.Timeout
+	opts.Timeout = timeout
 
 	// secure might not be set
 	if n.opts.TLSConfig != nil {
@@ -365,25 +387,64 @@ func (n *ntport) Dial(addr string, dialOpts ...transport.DialOption) (transport.
 	if err != nil {
 		return nil, err
 	}
+	n.conn = c
+	n.refs[c] = 1
+
+	return c, nil
+}
+
+// release closes the connection once it's no longer used by a client
+func (n *ntport) release(c *nats.Conn) {
+	n.Lock()
+	defer n.Unlock()
+
+	n.refs[c]--
+	if n.refs[c] > 0 {
+		return
+	}
+
+	delete(n.refs, c)
+	if n.conn == c {
+		n.conn = nil
+	}
+	c.Close()
+}
+
+func (n *ntport) Dial(addr string, dialOpts ...transport.DialOption) (transport.Client, error) {
+	dopts := transport.DialOptions{
+		Timeout: transport.DefaultDialTimeout,
+	}
+
+	for _, o := range dialOpts {
+		o(&dopts)
+	}
+
+	c, err := n.connect(dopts.Timeout)
+	if err != nil {
+		return nil, err
+	}
 
 	id := nats.NewInbox()
 	sub, err := c.SubscribeSync(id)
 	if err != nil {
+		n.release(c)
 		return nil, err
 	}
 
 	return &ntportClient{
+		t:      n,
 		conn:   c,
 		addr:   addr,
 		id:     id,
 		sub:    sub,
-		opts:   n.opts,
+		opts:   n.Options(),
 		local:  id,
 		remote: addr,
 	}, nil
 }
 
 func (n *ntport) Listen(addr string, listenOpts ...transport.ListenOption) (transport.Listener, error) {
+	n.Lock()
 	opts := n.nopts
 	opts.Servers = n.addrs
 	opts.Secure = n.opts.Secure
@@ -393,6 +454,8 @@ func (n *ntport) Listen(addr string, listenOpts ...transport.ListenOption) (tran
 	if n.opts.TLSConfig != nil {
 		opts.Secure = true
 	}
+	options := n.opts
+	n.Unlock()
 
 	c, err := opts.Connect()
 	if err != nil {
@@ -420,16 +483,25 @@ func (n *ntport) Listen(addr string, listenOpts ...transport.ListenOption) (tran
 		conn: c,
 		exit: make(chan bool, 1),
 		so:   make(map[string]*ntportSocket),
-		opts: n.opts,
+		opts: options,
 	}, nil
 }
 
 func (n *ntport) Init(opts ...transport.Option) error {
+	n.Lock()
+	defer n.Unlock()
+
 	configure(n, opts...)
+
+	// later dials connect with the new options, the current connection
+	// is closed once its clients are closed
+	n.conn = nil
 	return nil
 }
 
 func (n *ntport) Options() transport.Options {
+	n.Lock()
+	defer n.Unlock()
 	return n.opts
 }
 
@@ -447,6 +519,7 @@ func NewTransport(opts ...transport.Option) transport.Transport {
 
 	nt := &ntport{
 		opts: options,
+		refs: make(map[*nats.Conn]int),
 	}
 	configure(nt, opts...)
 	return nt
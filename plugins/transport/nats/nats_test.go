@@ -1,9 +1,14 @@
 package nats
 
 import (
+	"bufio"
+	"fmt"
+	"net"
 	"os"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-log/log"
 	"github.com/asim/go-micro/v3/server"
@@ -133,3 +138,136 @@ func TestListenAddr(t *testing.T) {
 		})
 	}
 }
+
+// testServer speaks enough of the nats protocol for clients to connect,
+// it tracks the connections which are open
+type testServer struct {
+	net.Listener
+
+	sync.Mutex
+	open int
+	seen int
+}
+
+func newTestServer(t *testing.T) *testServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testServer{Listener: l}
+	go s.serve()
+	return s
+}
+
+func (s *testServer) serve() {
+	for {
+		c, err := s.Accept()
+		if err != nil {
+			return
+		}
+
+		s.Lock()
+		s.open++
+		s.seen++
+		s.Unlock()
+
+		go func() {
+			defer func() {
+				c.Close()
+				s.Lock()
+				s.open--
+				s.Unlock()
+			}()
+
+			fmt.Fprintf(c, "INFO {\"server_id\":\"test\",\"max_payload\":1048576}\r\n")
+
+			r := bufio.NewReader(c)
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if strings.HasPrefix(line, "PING") {
+					fmt.Fprintf(c, "PONG\r\n")
+				}
+			}
+		}()
+	}
+}
+
+// conns returns the connections which are open and have been accepted
+func (s *testServer) conns() (int, int) {
+	// give closed connections a moment to be noticed
+	time.Sleep(time.Millisecond * 50)
+	s.Lock()
+	defer s.Unlock()
+	return s.open, s.seen
+}
+
+func TestDialClose(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	tr := NewTransport(transport.Addrs(s.Addr().String()))
+
+	c1, err := tr.Dial("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := tr.Dial("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the clients share a connection
+	if open, seen := s.conns(); open != 1 || seen != 1 {
+		t.Fatalf("Expected 1 connection got %d open of %d", open, seen)
+	}
+
+	// closing twice doesn't release the connection of the other client
+	c1.Close()
+	c1.Close()
+	if open, _ := s.conns(); open != 1 {
+		t.Fatalf("Expected the connection to stay open got %d open", open)
+	}
+
+	c2.Close()
+	if open, _ := s.conns(); open != 0 {
+		t.Fatalf("Expected the connection to be closed got %d open", open)
+	}
+}
+
+func TestInitResetsConnection(t *testing.T) {
+	s := newTestServer(t)
+	defer s.Close()
+
+	tr := NewTransport(transport.Addrs(s.Addr().String()))
+
+	c1, err := tr.Dial("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tr.Init(transport.Timeout(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	// dials after init use a new connection
+	c2, err := tr.Dial("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if open, seen := s.conns(); open != 2 || seen != 2 {
+		t.Fatalf("Expected 2 connections got %d open of %d", open, seen)
+	}
+
+	// the old connection is closed with its last client
+	c1.Close()
+	if open, _ := s.conns(); open != 1 {
+		t.Fatalf("Expected 1 connection got %d open", open)
+	}
+	c2.Close()
+	if open, _ := s.conns(); open != 0 {
+		t.Fatalf("Expected no connections got %d open", open)
+	}
+}
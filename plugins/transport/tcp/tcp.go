@@ -4,8 +4,11 @@ package tcp
 import (
 	"bufio"
 	"crypto/tls"
+	"encoding/binary"
 	"encoding/gob"
 	"errors"
+	"io"
+	"io/ioutil"
 	"net"
 	"time"
 
@@ -27,7 +30,10 @@ type tcpTransportClient struct {
 	enc      *gob.Encoder
 	dec      *gob.Decoder
 	encBuf   *bufio.Writer
+	decBuf   *bufio.Reader
 	timeout  time.Duration
+	maxSend  int
+	maxRecv  int
 }
 
 type tcpTransportSocket struct {
@@ -35,18 +41,72 @@ type tcpTransportSocket struct {
 	enc     *gob.Encoder
 	dec     *gob.Decoder
 	encBuf  *bufio.Writer
+	decBuf  *bufio.Reader
 	timeout time.Duration
+	maxSend int
+	maxRecv int
 }
 
 type tcpTransportListener struct {
 	listener net.Listener
 	timeout  time.Duration
+	maxSend  int
+	maxRecv  int
 }
 
 func init() {
 	cmd.DefaultTransports["tcp"] = NewTransport
 }
 
+// writeMessage writes the gob encoded header followed by the length
+// prefixed body so the size can be checked before the body is read
+func writeMessage(enc *gob.Encoder, w *bufio.Writer, m *transport.Message) error {
+	if err := enc.Encode(&transport.Message{Header: m.Header}); err != nil {
+		return err
+	}
+
+	var l [binary.MaxVarintLen64]byte
+	if _, err := w.Write(l[:binary.PutUvarint(l[:], uint64(len(m.Body)))]); err != nil {
+		return err
+	}
+	if _, err := w.Write(m.Body); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// readMessage reads a message written by writeMessage. Bodies larger than
+// max are skipped without being allocated and an error is returned with
+// the header set.
+func readMessage(dec *gob.Decoder, r *bufio.Reader, m *transport.Message, max int) error {
+	var hdr transport.Message
+	if err := dec.Decode(&hdr); err != nil {
+		return err
+	}
+	m.Header = hdr.Header
+
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return err
+	}
+
+	size := int(n)
+	if size < 0 || uint64(size) != n {
+		return transport.ErrMessageTooLarge
+	}
+
+	if err := transport.CheckSize(size, max); err != nil {
+		// discard the body so the next message can be read
+		io.CopyN(ioutil.Discard, r, int64(size))
+		return err
+	}
+
+	m.Body = make([]byte, size)
+	_, err = io.ReadFull(r, m.Body)
+	return err
+}
+
 func (t *tcpTransportClient) Local() string {
 	return t.conn.LocalAddr().String()
 }
@@ -56,14 +116,14 @@ func (t *tcpTransportClient) Remote() string {
 }
 
 func (t *tcpTransportClient) Send(m *transport.Message) error {
+	if err := transport.CheckSize(len(m.Body), t.maxSend); err != nil {
+		return err
+	}
 	// set timeout if its greater than 0
 	if t.timeout > time.Duration(0) {
 		t.conn.SetDeadline(time.Now().Add(t.timeout))
 	}
-	if err := t.enc.Encode(m); err != nil {
-		return err
-	}
-	return t.encBuf.Flush()
+	return writeMessage(t.enc, t.encBuf, m)
 }
 
 func (t *tcpTransportClient) Recv(m *transport.Message) error {
@@ -71,7 +131,7 @@ func (t *tcpTransportClient) Recv(m *transport.Message) error {
 	if t.timeout > time.Duration(0) {
 		t.conn.SetDeadline(time.Now().Add(t.timeout))
 	}
-	return t.dec.Decode(&m)
+	return readMessage(t.dec, t.decBuf, m, t.maxRecv)
 }
 
 func (t *tcpTransportClient) Close() error {
@@ -96,18 +156,18 @@ func (t *tcpTransportSocket) Recv(m *transport.Message) error {
 		t.conn.SetDeadline(time.Now().Add(t.timeout))
 	}
 
-	return t.dec.Decode(&m)
+	return readMessage(t.dec, t.decBuf, m, t.maxRecv)
 }
 
 func (t *tcpTransportSocket) Send(m *transport.Message) error {
+	if err := transport.CheckSize(len(m.Body), t.maxSend); err != nil {
+		return err
+	}
 	// set timeout if its greater than 0
 	if t.timeout > time.Duration(0) {
 		t.conn.SetDeadline(time.Now().Add(t.timeout))
 	}
-	if err := t.enc.Encode(m); err != nil {
-		return err
-	}
-	return t.encBuf.Flush()
+	return writeMessage(t.enc, t.encBuf, m)
 }
 
 func (t *tcpTransportSocket) Close() error {
@@ -145,12 +205,17 @@ func (t *tcpTransportListener) Accept(fn func(transport.Socket)) error {
 		}
 
 		encBuf := bufio.NewWriter(c)
+		// the decoder reads from the buffer directly so it doesn't read ahead of the body
+		decBuf := bufio.NewReader(c)
 		sock := &tcpTransportSocket{
 			timeout: t.timeout,
+			maxSend: t.maxSend,
+			maxRecv: t.maxRecv,
 			conn:    c,
 			encBuf:  encBuf,
+			decBuf:  decBuf,
 			enc:     gob.NewEncoder(encBuf),
-			dec:     gob.NewDecoder(c),
+			dec:     gob.NewDecoder(decBuf),
 		}
 
 		go func() {
@@ -196,14 +261,18 @@ func (t *tcpTransport) Dial(addr string, opts ...transport.DialOption) (transpor
 	}
 
 	encBuf := bufio.NewWriter(conn)
+	decBuf := bufio.NewReader(conn)
 
 	return &tcpTransportClient{
 		dialOpts: dopts,
 		conn:     conn,
 		encBuf:   encBuf,
+		decBuf:   decBuf,
 		enc:      gob.NewEncoder(encBuf),
-		dec:      gob.NewDecoder(conn),
+		dec:      gob.NewDecoder(decBuf),
 		timeout:  t.opts.Timeout,
+		maxSend:  t.opts.MaxSendSize,
+		maxRecv:  t.opts.MaxRecvSize,
 	}, nil
 }
 
@@ -240,13 +309,23 @@ func (t *tcpTransport) Listen(addr string, opts ...transport.ListenOption) (tran
 				}
 				config = &tls.Config{Certificates: []tls.Certificate{cert}}
 			}
-			return tls.Listen("tcp", addr, config)
+			l, err := net.Listen("tcp", addr)
+			if err != nil {
+				return nil, err
+			}
+			// limit before the handshake so the tls state is kept
+			l = transport.LimitListener(l, options.MaxConnections, options.Reject)
+			return tls.NewListener(l, config), nil
 		}
 
 		l, err = mnet.Listen(addr, fn)
 	} else {
 		fn := func(addr string) (net.Listener, error) {
-			return net.Listen("tcp", addr)
+			l, err := net.Listen("tcp", addr)
+			if err != nil {
+				return nil, err
+			}
+			return transport.LimitListener(l, options.MaxConnections, options.Reject), nil
 		}
 
 		l, err = mnet.Listen(addr, fn)
@@ -258,6 +337,8 @@ func (t *tcpTransport) Listen(addr string, opts ...transport.ListenOption) (tran
 
 	return &tcpTransportListener{
 		timeout:  t.opts.Timeout,
+		maxSend:  t.opts.MaxSendSize,
+		maxRecv:  t.opts.MaxRecvSize,
 		listener: l,
 	}, nil
 }
@@ -1,7 +1,12 @@
 package tcp
 
 import (
+	"bufio"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
 	"io"
+	"net"
 	"strings"
 	"testing"
 	"time"
@@ -229,3 +234,110 @@ func TestTCPTransportTimeout(t *testing.T) {
 
 	<-done
 }
+
+func TestTCPTransportMaxSize(t *testing.T) {
+	tr := NewTransport(transport.MaxSendSize(8), transport.MaxRecvSize(4))
+
+	l, err := tr.Listen(":0")
+	if err != nil {
+		t.Fatalf("Unexpected listen err: %v", err)
+	}
+	defer l.Close()
+
+	errc := make(chan error, 2)
+	go l.Accept(func(sock transport.Socket) {
+		defer sock.Close()
+		for i := 0; i < 2; i++ {
+			var m transport.Message
+			errc <- sock.Recv(&m)
+		}
+	})
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected dial err: %v", err)
+	}
+	defer c.Close()
+
+	// larger than the maximum send size
+	if err := c.Send(&transport.Message{Body: []byte(`ping ping`)}); !errors.Is(err, transport.ErrMessageTooLarge) {
+		t.Fatalf("Expected %v got %v", transport.ErrMessageTooLarge, err)
+	}
+
+	// within the send size but larger than the receive size
+	if err := c.Send(&transport.Message{Body: []byte(`ping`)}); err != nil {
+		t.Fatalf("Unexpected send err: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("Unexpected recv err: %v", err)
+	}
+
+	if err := c.Send(&transport.Message{Body: []byte(`ping!`)}); err != nil {
+		t.Fatalf("Unexpected send err: %v", err)
+	}
+	if err := <-errc; !errors.Is(err, transport.ErrMessageTooLarge) {
+		t.Fatalf("Expected %v got %v", transport.ErrMessageTooLarge, err)
+	}
+}
+
+func TestTCPTransportMaxSizeBeforeRead(t *testing.T) {
+	tr := NewTransport(transport.MaxRecvSize(4))
+
+	l, err := tr.Listen(":0")
+	if err != nil {
+		t.Fatalf("Unexpected listen err: %v", err)
+	}
+	defer l.Close()
+
+	type result struct {
+		msg transport.Message
+		err error
+	}
+
+	results := make(chan result, 2)
+	go l.Accept(func(sock transport.Socket) {
+		defer sock.Close()
+		for i := 0; i < 2; i++ {
+			var m transport.Message
+			err := sock.Recv(&m)
+			results <- result{m, err}
+		}
+	})
+
+	conn, err := net.Dial("tcp", l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected dial err: %v", err)
+	}
+	defer conn.Close()
+
+	w := bufio.NewWriter(conn)
+	enc := gob.NewEncoder(w)
+
+	// a header followed by a body claiming to be a terabyte but never sent
+	if err := enc.Encode(&transport.Message{Header: map[string]string{"Micro-Id": "1"}}); err != nil {
+		t.Fatal(err)
+	}
+	var buf [binary.MaxVarintLen64]byte
+	w.Write(buf[:binary.PutUvarint(buf[:], 1<<40)])
+	w.Flush()
+
+	select {
+	case res := <-results:
+		t.Fatalf("Expected the body not to be read got %v", res.err)
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	// the peer gives up sending the body
+	conn.(*net.TCPConn).CloseWrite()
+
+	res := <-results
+	if !errors.Is(res.err, transport.ErrMessageTooLarge) {
+		t.Fatalf("Expected %v got %v", transport.ErrMessageTooLarge, res.err)
+	}
+	if v := res.msg.Header["Micro-Id"]; v != "1" {
+		t.Fatalf("Expected the header to be read got %q", v)
+	}
+	if res.msg.Body != nil {
+		t.Fatalf("Expected no body got %d bytes", len(res.msg.Body))
+	}
+}
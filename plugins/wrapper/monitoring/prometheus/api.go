@@ -0,0 +1,81 @@
+package prometheus
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/asim/go-micro/v3/api/server/access"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	apiCounter   *prometheus.CounterVec
+	apiHistogram *prometheus.HistogramVec
+)
+
+func init() {
+	labels := []string{
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "service"),
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "endpoint"),
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "method"),
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "code"),
+	}
+
+	apiCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sapi_request_total", DefaultMetricPrefix),
+			Help: "Api requests processed, partitioned by backend service, endpoint, method and status code",
+		},
+		labels,
+	)
+
+	apiHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: fmt.Sprintf("%sapi_request_duration_seconds", DefaultMetricPrefix),
+			Help: "Api request time in seconds, partitioned by backend service, endpoint, method and status code",
+		},
+		labels,
+	)
+
+	for _, collector := range []prometheus.Collector{apiCounter, apiHistogram} {
+		if err := prometheus.DefaultRegisterer.Register(collector); err != nil {
+			// if already registered, skip fatal
+			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+				logger.Fatal(err)
+			}
+		}
+	}
+}
+
+// NewAPIReporter returns an access reporter recording api gateway metrics
+// labelled by the backend service the request was routed to e.g
+//
+//	server.WrapHandler(access.NewWrapper(access.WithReporter(prometheus.NewAPIReporter())))
+func NewAPIReporter() access.Reporter {
+	return func(r access.Record) {
+		service := r.Service
+		// requests which weren't routed to a backend
+		if len(service) == 0 {
+			service = "none"
+		}
+
+		code := strconv.Itoa(r.Status)
+		method := apiMethod(r.Method)
+
+		apiCounter.WithLabelValues(service, r.Endpoint, method, code).Inc()
+		apiHistogram.WithLabelValues(service, r.Endpoint, method, code).Observe(r.Duration.Seconds())
+	}
+}
+
+// apiMethod returns the method if it's a standard http method, otherwise
+// "OTHER" so clients can't create labels by sending arbitrary methods
+func apiMethod(m string) string {
+	switch m {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return m
+	}
+	return "OTHER"
+}
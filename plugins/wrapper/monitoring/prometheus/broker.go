@@ -0,0 +1,98 @@
+package prometheus
+
+import (
+	"fmt"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	brokerCounter   *prometheus.CounterVec
+	brokerHistogram *prometheus.HistogramVec
+)
+
+func init() {
+	labels := []string{
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "name"),
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "version"),
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "id"),
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "topic"),
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "operation"),
+	}
+
+	brokerCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sbroker_message_total", DefaultMetricPrefix),
+			Help: "Broker messages published and received, partitioned by topic, operation and status",
+		},
+		append(labels, fmt.Sprintf("%s%s", DefaultLabelPrefix, "status")),
+	)
+
+	brokerHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: fmt.Sprintf("%sbroker_message_duration_seconds", DefaultMetricPrefix),
+			Help: "Broker publish and handler time in seconds, partitioned by topic and operation",
+		},
+		labels,
+	)
+
+	for _, collector := range []prometheus.Collector{brokerCounter, brokerHistogram} {
+		if err := prometheus.DefaultRegisterer.Register(collector); err != nil {
+			// if already registered, skip fatal
+			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+				logger.Fatal(err)
+			}
+		}
+	}
+}
+
+type brokerWrapper struct {
+	broker.Broker
+	options Options
+}
+
+func (w *brokerWrapper) observe(topic, operation string, fn func() error) error {
+	timer := prometheus.NewTimer(prometheus.ObserverFunc(func(v float64) {
+		brokerHistogram.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, topic, operation).Observe(v)
+	}))
+	defer timer.ObserveDuration()
+
+	err := fn()
+	if err == nil {
+		brokerCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, topic, operation, "success").Inc()
+	} else {
+		brokerCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, topic, operation, "failure").Inc()
+	}
+
+	return err
+}
+
+func (w *brokerWrapper) Publish(topic string, m *broker.Message, opts ...broker.PublishOption) error {
+	return w.observe(topic, "publish", func() error {
+		return w.Broker.Publish(topic, m, opts...)
+	})
+}
+
+func (w *brokerWrapper) Subscribe(topic string, h broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	return w.Broker.Subscribe(topic, func(e broker.Event) error {
+		return w.observe(topic, "receive", func() error {
+			return h(e)
+		})
+	}, opts...)
+}
+
+// NewBroker wraps the broker to record metrics for messages published and
+// received directly with the broker
+func NewBroker(b broker.Broker, opts ...Option) broker.Broker {
+	options := Options{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &brokerWrapper{
+		Broker:  b,
+		options: options,
+	}
+}
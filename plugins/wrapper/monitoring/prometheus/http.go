@@ -0,0 +1,70 @@
+package prometheus
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/asim/go-micro/v3"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// DefaultMetricsPath is the path metrics are served on
+	DefaultMetricsPath = "/metrics"
+)
+
+// NewHandler returns a http handler serving the metrics e.g to mount on an
+// existing http server
+func NewHandler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ServeMetrics returns a service option which serves the metrics on the
+// address while the service is running e.g
+//
+//	service := micro.NewService(
+//		micro.Name("greeter"),
+//		prometheus.ServeMetrics(":9090"),
+//	)
+func ServeMetrics(address string) micro.Option {
+	return func(o *micro.Options) {
+		var srv *http.Server
+
+		o.BeforeStart = append(o.BeforeStart, func() error {
+			l, err := net.Listen("tcp", address)
+			if err != nil {
+				return err
+			}
+
+			mux := http.NewServeMux()
+			mux.Handle(DefaultMetricsPath, NewHandler())
+			srv = &http.Server{Handler: mux}
+
+			go func() {
+				if err := srv.Serve(l); err != nil && err != http.ErrServerClosed {
+					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+						logger.Errorf("Error serving metrics: %v", err)
+					}
+				}
+			}()
+
+			if logger.V(logger.InfoLevel, logger.DefaultLogger) {
+				logger.Infof("Serving metrics on %s%s", l.Addr(), DefaultMetricsPath)
+			}
+
+			return nil
+		})
+
+		o.AfterStop = append(o.AfterStop, func() error {
+			if srv == nil {
+				return nil
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return srv.Shutdown(ctx)
+		})
+	}
+}
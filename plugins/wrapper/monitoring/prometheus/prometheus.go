@@ -25,6 +25,8 @@ type Options struct {
 	Name    string
 	Version string
 	ID      string
+	// Registerer of the transport metrics
+	Registerer prometheus.Registerer
 }
 
 type Option func(*Options)
@@ -47,6 +49,14 @@ func ServiceID(id string) Option {
 	}
 }
 
+// Registerer sets the registerer of the transport metrics, they're
+// registered with the default registerer otherwise
+func Registerer(r prometheus.Registerer) Option {
+	return func(opts *Options) {
+		opts.Registerer = r
+	}
+}
+
 func init() {
 
 	if opsCounter == nil {
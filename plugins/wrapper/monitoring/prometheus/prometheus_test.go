@@ -3,14 +3,21 @@ package prometheus_test
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/asim/go-micro/v3/api/server/access"
 	"github.com/asim/go-micro/v3/broker"
 	bmemory "github.com/asim/go-micro/plugins/broker/memory/v3"
 	"github.com/asim/go-micro/v3/client"
 	"github.com/asim/go-micro/v3/selector"
 	"github.com/asim/go-micro/plugins/registry/memory/v3"
 	"github.com/asim/go-micro/v3/server"
+	"github.com/asim/go-micro/v3/store"
+	"github.com/asim/go-micro/v3/transport"
 	promwrapper "github.com/asim/go-micro/plugins/wrapper/monitoring/prometheus/v3"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
@@ -165,6 +172,149 @@ func TestPrometheusMetrics(t *testing.T) {
 	assert.Equal(t, *metric.Metric[1].Counter.Value, float64(1))
 }
 
+func TestBrokerStoreMetrics(t *testing.T) {
+	opts := []promwrapper.Option{
+		promwrapper.ServiceName("test"),
+		promwrapper.ServiceVersion("1.0.0"),
+		promwrapper.ServiceID("id-1"),
+	}
+
+	// the memory broker delivers messages as they're published, subscriber
+	// errors go to the error handler rather than back to the publisher
+	brk := promwrapper.NewBroker(bmemory.NewBroker(broker.ErrorHandler(func(broker.Event) error {
+		return nil
+	})), opts...)
+	assert.NoError(t, brk.Connect())
+	defer brk.Disconnect()
+
+	_, err := brk.Subscribe("topic", func(e broker.Event) error {
+		return fmt.Errorf("test error")
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, brk.Publish("topic", &broker.Message{Body: []byte("foo")}))
+
+	st := promwrapper.NewStore(store.NewMemoryStore(), opts...)
+	assert.NoError(t, st.Write(&store.Record{Key: "foo", Value: []byte("bar")}))
+	_, err = st.Read("missing")
+	assert.Equal(t, store.ErrNotFound, err)
+
+	list, _ := prometheus.DefaultGatherer.Gather()
+
+	statuses := func(name string) map[string]string {
+		metric := findMetricByName(list, dto.MetricType_COUNTER, name)
+		if metric == nil {
+			t.Fatalf("no %s metrics returned", name)
+		}
+		s := make(map[string]string)
+		for _, m := range metric.Metric {
+			var op, status string
+			for _, v := range m.Label {
+				switch *v.Name {
+				case "micro_operation":
+					op = *v.Value
+				case "micro_status":
+					status = *v.Value
+				}
+			}
+			s[op] = status
+		}
+		return s
+	}
+
+	assert.Equal(t, map[string]string{"publish": "success", "receive": "failure"}, statuses("micro_broker_message_total"))
+	assert.Equal(t, map[string]string{"write": "success", "read": "not_found"}, statuses("micro_store_request_total"))
+
+	// the metrics are served by the handler
+	w := httptest.NewRecorder()
+	promwrapper.NewHandler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	b, _ := ioutil.ReadAll(w.Body)
+	assert.True(t, strings.Contains(string(b), "micro_store_request_duration_seconds"))
+}
+
+func TestTransportMetrics(t *testing.T) {
+	// a registry of the test so the metrics start from zero on every run
+	reg := prometheus.NewRegistry()
+	tr := transport.NewHTTPTransport(transport.Observe(promwrapper.NewTransportObserver(promwrapper.ServiceName("test"), promwrapper.Registerer(reg))))
+
+	l, err := tr.Listen("127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+
+	// keep the accepted connection open until the metrics are gathered
+	done := make(chan bool)
+	defer close(done)
+
+	go l.Accept(func(sock transport.Socket) {
+		defer sock.Close()
+		var m transport.Message
+		if err := sock.Recv(&m); err != nil {
+			return
+		}
+		sock.Send(&m)
+		<-done
+	})
+
+	c, err := tr.Dial(l.Addr())
+	assert.NoError(t, err)
+	defer c.Close()
+
+	m := &transport.Message{Header: map[string]string{"Content-Type": "application/json"}, Body: []byte(`{}`)}
+	assert.NoError(t, c.Send(m))
+	assert.NoError(t, c.Recv(m))
+
+	list, _ := reg.Gather()
+
+	// both ends of the connection are open
+	metric := findMetricByName(list, dto.MetricType_GAUGE, "micro_transport_connections")
+	if metric == nil {
+		t.Fatal("no transport connection metrics returned")
+	}
+	assert.Equal(t, 2.0, metric.Metric[0].GetGauge().GetValue())
+
+	metric = findMetricByName(list, dto.MetricType_COUNTER, "micro_transport_bytes_total")
+	if metric == nil {
+		t.Fatal("no transport byte metrics returned")
+	}
+	assert.Equal(t, 2, len(metric.Metric))
+
+	metric = findMetricByName(list, dto.MetricType_HISTOGRAM, "micro_transport_dial_duration_seconds")
+	if metric == nil {
+		t.Fatal("no transport dial metrics returned")
+	}
+	assert.Equal(t, uint64(1), metric.Metric[0].GetHistogram().GetSampleCount())
+}
+
+func TestAPIReporter(t *testing.T) {
+	report := promwrapper.NewAPIReporter()
+	for _, method := range []string{"GET", "PURGE", "X-RANDOM"} {
+		report(access.Record{
+			Method:   method,
+			Status:   200,
+			Duration: time.Millisecond,
+			Service:  "greeter",
+			Endpoint: "unknown",
+		})
+	}
+
+	list, _ := prometheus.DefaultGatherer.Gather()
+
+	metric := findMetricByName(list, dto.MetricType_COUNTER, "micro_api_request_total")
+	if metric == nil {
+		t.Fatal("no api metrics returned")
+	}
+
+	// non standard methods share a label
+	methods := make(map[string]float64)
+	for _, m := range metric.Metric {
+		for _, v := range m.Label {
+			if *v.Name == "micro_method" {
+				methods[*v.Value] += m.GetCounter().GetValue()
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{"GET": 1, "OTHER": 2}, methods)
+}
+
 func findMetricByName(list []*dto.MetricFamily, tp dto.MetricType, name string) *dto.MetricFamily {
 	for _, metric := range list {
 		if *metric.Name == name && *metric.Type == tp {
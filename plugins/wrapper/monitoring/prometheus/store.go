@@ -0,0 +1,121 @@
+package prometheus
+
+import (
+	"fmt"
+
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/store"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	storeCounter   *prometheus.CounterVec
+	storeHistogram *prometheus.HistogramVec
+)
+
+func init() {
+	labels := []string{
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "name"),
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "version"),
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "id"),
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "store"),
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "operation"),
+	}
+
+	storeCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%sstore_request_total", DefaultMetricPrefix),
+			Help: "Store requests processed, partitioned by store, operation and status",
+		},
+		append(labels, fmt.Sprintf("%s%s", DefaultLabelPrefix, "status")),
+	)
+
+	storeHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: fmt.Sprintf("%sstore_request_duration_seconds", DefaultMetricPrefix),
+			Help: "Store request time in seconds, partitioned by store and operation",
+		},
+		labels,
+	)
+
+	for _, collector := range []prometheus.Collector{storeCounter, storeHistogram} {
+		if err := prometheus.DefaultRegisterer.Register(collector); err != nil {
+			// if already registered, skip fatal
+			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+				logger.Fatal(err)
+			}
+		}
+	}
+}
+
+type storeWrapper struct {
+	store.Store
+	options Options
+}
+
+func (w *storeWrapper) observe(operation string, fn func() error) error {
+	name := w.Store.String()
+
+	timer := prometheus.NewTimer(prometheus.ObserverFunc(func(v float64) {
+		storeHistogram.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, name, operation).Observe(v)
+	}))
+	defer timer.ObserveDuration()
+
+	err := fn()
+	switch err {
+	case nil:
+		storeCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, name, operation, "success").Inc()
+	case store.ErrNotFound:
+		// a missing key isn't a failure of the store
+		storeCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, name, operation, "not_found").Inc()
+	default:
+		storeCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, name, operation, "failure").Inc()
+	}
+
+	return err
+}
+
+func (w *storeWrapper) Read(key string, opts ...store.ReadOption) ([]*store.Record, error) {
+	var recs []*store.Record
+	err := w.observe("read", func() error {
+		var err error
+		recs, err = w.Store.Read(key, opts...)
+		return err
+	})
+	return recs, err
+}
+
+func (w *storeWrapper) Write(r *store.Record, opts ...store.WriteOption) error {
+	return w.observe("write", func() error {
+		return w.Store.Write(r, opts...)
+	})
+}
+
+func (w *storeWrapper) Delete(key string, opts ...store.DeleteOption) error {
+	return w.observe("delete", func() error {
+		return w.Store.Delete(key, opts...)
+	})
+}
+
+func (w *storeWrapper) List(opts ...store.ListOption) ([]string, error) {
+	var keys []string
+	err := w.observe("list", func() error {
+		var err error
+		keys, err = w.Store.List(opts...)
+		return err
+	})
+	return keys, err
+}
+
+// NewStore wraps the store to record metrics for reads, writes, deletes and lists
+func NewStore(s store.Store, opts ...Option) store.Store {
+	options := Options{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &storeWrapper{
+		Store:   s,
+		options: options,
+	}
+}
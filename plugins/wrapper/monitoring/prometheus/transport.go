@@ -0,0 +1,118 @@
+package prometheus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/transport"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type transportObserver struct {
+	options Options
+
+	connections *prometheus.GaugeVec
+	bytes       *prometheus.CounterVec
+	dials       *prometheus.HistogramVec
+	handshakes  *prometheus.CounterVec
+}
+
+// NewTransportObserver records the connections of a transport e.g
+//
+//	transport.NewHTTPTransport(transport.Observe(prometheus.NewTransportObserver()))
+//
+// the metrics are registered with the default registerer unless one is set
+func NewTransportObserver(opts ...Option) transport.Observer {
+	options := Options{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	reg := options.Registerer
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+
+	labels := []string{
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "name"),
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "version"),
+		fmt.Sprintf("%s%s", DefaultLabelPrefix, "id"),
+	}
+
+	o := &transportObserver{options: options}
+
+	o.connections = register(reg, prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%stransport_connections", DefaultMetricPrefix),
+			Help: "Open transport connections, dialed and accepted",
+		},
+		labels,
+	)).(*prometheus.GaugeVec)
+
+	o.bytes = register(reg, prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%stransport_bytes_total", DefaultMetricPrefix),
+			Help: "Bytes written to and read from transport connections, partitioned by direction",
+		},
+		append(labels, fmt.Sprintf("%s%s", DefaultLabelPrefix, "direction")),
+	)).(*prometheus.CounterVec)
+
+	o.dials = register(reg, prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: fmt.Sprintf("%stransport_dial_duration_seconds", DefaultMetricPrefix),
+			Help: "Transport dial time in seconds, partitioned by status",
+		},
+		append(labels, fmt.Sprintf("%s%s", DefaultLabelPrefix, "status")),
+	)).(*prometheus.HistogramVec)
+
+	o.handshakes = register(reg, prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%stransport_handshake_failures_total", DefaultMetricPrefix),
+			Help: "Failed tls handshakes of transport connections",
+		},
+		labels,
+	)).(*prometheus.CounterVec)
+
+	return o
+}
+
+// register registers the collector returning the one already registered
+// by another observer
+func register(reg prometheus.Registerer, c prometheus.Collector) prometheus.Collector {
+	if err := reg.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector
+		}
+		logger.Fatal(err)
+	}
+	return c
+}
+
+func (o *transportObserver) Dialed(addr string, latency time.Duration, err error) {
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	o.dials.WithLabelValues(o.options.Name, o.options.Version, o.options.ID, status).Observe(latency.Seconds())
+}
+
+func (o *transportObserver) HandshakeFailed(remote string, err error) {
+	o.handshakes.WithLabelValues(o.options.Name, o.options.Version, o.options.ID).Inc()
+}
+
+func (o *transportObserver) Opened(local, remote string) {
+	o.connections.WithLabelValues(o.options.Name, o.options.Version, o.options.ID).Inc()
+}
+
+func (o *transportObserver) Closed(local, remote string) {
+	o.connections.WithLabelValues(o.options.Name, o.options.Version, o.options.ID).Dec()
+}
+
+func (o *transportObserver) Sent(remote string, n int) {
+	o.bytes.WithLabelValues(o.options.Name, o.options.Version, o.options.ID, "sent").Add(float64(n))
+}
+
+func (o *transportObserver) Received(remote string, n int) {
+	o.bytes.WithLabelValues(o.options.Name, o.options.Version, o.options.ID, "received").Add(float64(n))
+}
@@ -0,0 +1,75 @@
+package opentelemetry
+
+import (
+	"context"
+
+	"github.com/asim/go-micro/v3/broker"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type otelBroker struct {
+	broker.Broker
+	opts Options
+}
+
+func (o *otelBroker) Publish(topic string, m *broker.Message, opts ...broker.PublishOption) error {
+	var options broker.PublishOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	ctx := options.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, span := o.opts.TracerProvider.Tracer(instrumentationName).Start(ctx, "Pub to "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messagingAttributes(topic)...),
+	)
+
+	// copy the message so the caller's headers aren't changed
+	msg := &broker.Message{
+		Header: make(map[string]string, len(m.Header)),
+		Body:   m.Body,
+	}
+	for k, v := range m.Header {
+		msg.Header[k] = v
+	}
+	o.opts.Propagator.Inject(ctx, metadataCarrier(msg.Header))
+
+	err := o.Broker.Publish(topic, msg, opts...)
+	finish(span, err)
+	return err
+}
+
+func (o *otelBroker) Subscribe(topic string, h broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+	handler := func(e broker.Event) error {
+		ctx := context.Background()
+		if m := e.Message(); m != nil {
+			ctx = o.opts.Propagator.Extract(ctx, metadataCarrier(m.Header))
+		}
+
+		_, span := o.opts.TracerProvider.Tracer(instrumentationName).Start(ctx, "Sub from "+topic,
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(messagingAttributes(topic)...),
+		)
+
+		err := h(e)
+		finish(span, err)
+		return err
+	}
+
+	return o.Broker.Subscribe(topic, handler, opts...)
+}
+
+// NewBroker wraps the broker to create spans for messages published and
+// received directly with the broker, the span context is passed in the
+// message headers. The parent of a publication is taken from the
+// broker.PublishContext option.
+func NewBroker(b broker.Broker, opts ...Option) broker.Broker {
+	return &otelBroker{
+		Broker: b,
+		opts:   NewOptions(opts...),
+	}
+}
@@ -0,0 +1,16 @@
+module github.com/asim/go-micro/plugins/wrapper/trace/opentelemetry/v3
+
+go 1.16
+
+require (
+	github.com/asim/go-micro/plugins/broker/memory/v3 v3.0.0-20210630062103-c13bb07171bc
+	github.com/asim/go-micro/v3 v3.5.2-0.20210630062103-c13bb07171bc
+	go.opentelemetry.io/otel v1.0.0
+	go.opentelemetry.io/otel/sdk v1.0.0
+	go.opentelemetry.io/otel/trace v1.0.0
+)
+
+replace (
+	github.com/asim/go-micro/plugins/broker/memory/v3 => ../../../../plugins/broker/memory
+	github.com/asim/go-micro/v3 => ../../../../../go-micro
+)
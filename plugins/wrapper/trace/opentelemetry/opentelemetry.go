@@ -0,0 +1,202 @@
+// Package opentelemetry provides wrappers for OpenTelemetry tracing. Spans are
+// created for calls, handlers, publications and subscribers and the span
+// context is passed in the request metadata and broker headers.
+package opentelemetry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/registry"
+	"github.com/asim/go-micro/v3/server"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/asim/go-micro/plugins/wrapper/trace/opentelemetry"
+)
+
+// metadataCarrier passes the span context in metadata, keys are case
+// insensitive as they're transported as headers
+type metadataCarrier metadata.Metadata
+
+func (c metadataCarrier) Get(key string) string {
+	v, _ := metadata.Metadata(c).Get(key)
+	return v
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.Metadata(c).Delete(key)
+	metadata.Metadata(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// start starts a span which is a child of any span in the context and
+// injects it in the outgoing metadata
+func start(ctx context.Context, o Options, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	md, ok := metadata.FromContext(ctx)
+	if !ok {
+		md = make(metadata.Metadata)
+	}
+
+	ctx, span := o.TracerProvider.Tracer(instrumentationName).Start(ctx, name,
+		trace.WithSpanKind(kind),
+		trace.WithAttributes(attrs...),
+	)
+
+	o.Propagator.Inject(ctx, metadataCarrier(md))
+
+	return metadata.NewContext(ctx, md), span
+}
+
+// extract returns the context with the span context of the incoming metadata
+func extract(ctx context.Context, o Options) context.Context {
+	md, ok := metadata.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	return o.Propagator.Extract(ctx, metadataCarrier(md))
+}
+
+// finish records the error if any and ends the span
+func finish(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func rpcAttributes(service, endpoint string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.RPCSystemKey.String("go-micro"),
+		semconv.RPCServiceKey.String(service),
+		semconv.RPCMethodKey.String(endpoint),
+	}
+}
+
+func messagingAttributes(topic string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("go-micro"),
+		semconv.MessagingDestinationKey.String(topic),
+	}
+}
+
+type otelWrapper struct {
+	client.Client
+	opts Options
+}
+
+func (o *otelWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	name := fmt.Sprintf("%s.%s", req.Service(), req.Endpoint())
+	ctx, span := start(ctx, o.opts, name, trace.SpanKindClient, rpcAttributes(req.Service(), req.Endpoint())...)
+	err := o.Client.Call(ctx, req, rsp, opts...)
+	finish(span, err)
+	return err
+}
+
+func (o *otelWrapper) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	name := fmt.Sprintf("%s.%s", req.Service(), req.Endpoint())
+	ctx, span := start(ctx, o.opts, name, trace.SpanKindClient, rpcAttributes(req.Service(), req.Endpoint())...)
+	s, err := o.Client.Stream(ctx, req, opts...)
+	if err != nil {
+		finish(span, err)
+		return nil, err
+	}
+	return &stream{Stream: s, span: span}, nil
+}
+
+func (o *otelWrapper) Publish(ctx context.Context, p client.Message, opts ...client.PublishOption) error {
+	name := fmt.Sprintf("Pub to %s", p.Topic())
+	ctx, span := start(ctx, o.opts, name, trace.SpanKindProducer, messagingAttributes(p.Topic())...)
+	err := o.Client.Publish(ctx, p, opts...)
+	finish(span, err)
+	return err
+}
+
+// stream ends the span when the stream is closed
+type stream struct {
+	client.Stream
+	span trace.Span
+	once sync.Once
+}
+
+func (s *stream) Close() error {
+	err := s.Stream.Close()
+	s.once.Do(func() {
+		finish(s.span, s.Stream.Error())
+	})
+	return err
+}
+
+// NewClientWrapper returns a client wrapper creating spans for calls, streams
+// and publications
+func NewClientWrapper(opts ...Option) client.Wrapper {
+	options := NewOptions(opts...)
+
+	return func(c client.Client) client.Client {
+		return &otelWrapper{
+			Client: c,
+			opts:   options,
+		}
+	}
+}
+
+// NewCallWrapper returns a call wrapper creating a span for every call to a node
+func NewCallWrapper(opts ...Option) client.CallWrapper {
+	options := NewOptions(opts...)
+
+	return func(cf client.CallFunc) client.CallFunc {
+		return func(ctx context.Context, node *registry.Node, req client.Request, rsp interface{}, opts client.CallOptions) error {
+			name := fmt.Sprintf("%s.%s", req.Service(), req.Endpoint())
+			attrs := append(rpcAttributes(req.Service(), req.Endpoint()), semconv.NetPeerNameKey.String(node.Address))
+			ctx, span := start(ctx, options, name, trace.SpanKindClient, attrs...)
+			err := cf(ctx, node, req, rsp, opts)
+			finish(span, err)
+			return err
+		}
+	}
+}
+
+// NewHandlerWrapper returns a handler wrapper creating a span for every request
+func NewHandlerWrapper(opts ...Option) server.HandlerWrapper {
+	options := NewOptions(opts...)
+
+	return func(h server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			name := fmt.Sprintf("%s.%s", req.Service(), req.Endpoint())
+			ctx, span := start(extract(ctx, options), options, name, trace.SpanKindServer, rpcAttributes(req.Service(), req.Endpoint())...)
+			err := h(ctx, req, rsp)
+			finish(span, err)
+			return err
+		}
+	}
+}
+
+// NewSubscriberWrapper returns a subscriber wrapper creating a span for every message
+func NewSubscriberWrapper(opts ...Option) server.SubscriberWrapper {
+	options := NewOptions(opts...)
+
+	return func(next server.SubscriberFunc) server.SubscriberFunc {
+		return func(ctx context.Context, msg server.Message) error {
+			name := "Sub from " + msg.Topic()
+			ctx, span := start(extract(ctx, options), options, name, trace.SpanKindConsumer, messagingAttributes(msg.Topic())...)
+			err := next(ctx, msg)
+			finish(span, err)
+			return err
+		}
+	}
+}
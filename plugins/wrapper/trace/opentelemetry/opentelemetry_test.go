@@ -0,0 +1,149 @@
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/plugins/broker/memory/v3"
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/server"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type testClient struct {
+	client.Client
+	ctx context.Context
+}
+
+func (t *testClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	t.ctx = ctx
+	return nil
+}
+
+type testClientRequest struct {
+	client.Request
+}
+
+func (t *testClientRequest) Service() string  { return "test" }
+func (t *testClientRequest) Endpoint() string { return "Test.Method" }
+
+type testServerRequest struct {
+	server.Request
+}
+
+func (t *testServerRequest) Service() string  { return "test" }
+func (t *testServerRequest) Endpoint() string { return "Test.Method" }
+
+func testOptions() (*tracetest.SpanRecorder, []Option) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	return sr, []Option{
+		WithTracerProvider(tp),
+		WithPropagator(propagation.TraceContext{}),
+	}
+}
+
+func TestClientHandler(t *testing.T) {
+	sr, opts := testOptions()
+
+	tc := &testClient{}
+	c := NewClientWrapper(opts...)(tc)
+
+	if err := c.Call(context.Background(), &testClientRequest{}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the server receives the metadata of the call
+	var handlerSpan trace.SpanContext
+	h := NewHandlerWrapper(opts...)(func(ctx context.Context, req server.Request, rsp interface{}) error {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		return nil
+	})
+	if err := h(tc.ctx, &testServerRequest{}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans got %d", len(spans))
+	}
+
+	call, handler := spans[0], spans[1]
+	if call.Name() != "test.Test.Method" || call.SpanKind() != trace.SpanKindClient {
+		t.Fatalf("Unexpected call span %s %v", call.Name(), call.SpanKind())
+	}
+	if handler.SpanKind() != trace.SpanKindServer {
+		t.Fatalf("Unexpected handler span kind %v", handler.SpanKind())
+	}
+	if handler.Parent().SpanID() != call.SpanContext().SpanID() {
+		t.Fatal("Expected the handler span to be a child of the call span")
+	}
+	if handlerSpan.TraceID() != call.SpanContext().TraceID() {
+		t.Fatal("Expected the handler context to have the trace of the call")
+	}
+}
+
+func TestBroker(t *testing.T) {
+	sr, opts := testOptions()
+
+	b := NewBroker(memory.NewBroker(), opts...)
+	if err := b.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Disconnect()
+
+	done := make(chan bool, 1)
+	if _, err := b.Subscribe("test", func(e broker.Event) error {
+		done <- true
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &broker.Message{Header: map[string]string{"foo": "bar"}}
+	if err := b.Publish("test", msg); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Message not received")
+	}
+
+	if len(msg.Header) != 1 {
+		t.Fatalf("Expected the message headers not to change got %v", msg.Header)
+	}
+
+	// the consumer span ends after the handler returns
+	deadline := time.Now().Add(time.Second)
+	for len(sr.Ended()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	spans := sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans got %d", len(spans))
+	}
+
+	var pub, sub sdktrace.ReadOnlySpan
+	for _, s := range spans {
+		switch s.SpanKind() {
+		case trace.SpanKindProducer:
+			pub = s
+		case trace.SpanKindConsumer:
+			sub = s
+		}
+	}
+	if pub == nil || sub == nil {
+		t.Fatal("Expected producer and consumer spans")
+	}
+	if sub.Parent().SpanID() != pub.SpanContext().SpanID() {
+		t.Fatal("Expected the consumer span to be a child of the producer span")
+	}
+}
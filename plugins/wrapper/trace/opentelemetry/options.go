@@ -0,0 +1,68 @@
+package opentelemetry
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Options struct {
+	// TracerProvider spans are created with, defaults to the global provider
+	TracerProvider trace.TracerProvider
+	// Propagator used to pass the span context in metadata and broker
+	// headers, defaults to the global propagator
+	Propagator propagation.TextMapPropagator
+	// Exporter spans are exported to when used as a service option
+	Exporter sdktrace.SpanExporter
+	// Sampler of the tracer provider created for the exporter
+	Sampler sdktrace.Sampler
+}
+
+type Option func(o *Options)
+
+// NewOptions fills in the blanks
+func NewOptions(opts ...Option) Options {
+	var options Options
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if options.TracerProvider == nil {
+		options.TracerProvider = otel.GetTracerProvider()
+	}
+	if options.Propagator == nil {
+		options.Propagator = otel.GetTextMapPropagator()
+	}
+
+	return options
+}
+
+// WithTracerProvider sets the tracer provider
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *Options) {
+		o.TracerProvider = tp
+	}
+}
+
+// WithPropagator sets the propagator e.g propagation.TraceContext{}
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(o *Options) {
+		o.Propagator = p
+	}
+}
+
+// WithExporter sets the exporter spans are exported to e.g otlp or jaeger
+func WithExporter(e sdktrace.SpanExporter) Option {
+	return func(o *Options) {
+		o.Exporter = e
+	}
+}
+
+// WithSampler sets the sampler of the tracer provider created for the exporter
+func WithSampler(s sdktrace.Sampler) Option {
+	return func(o *Options) {
+		o.Sampler = s
+	}
+}
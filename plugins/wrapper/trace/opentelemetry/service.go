@@ -0,0 +1,59 @@
+package opentelemetry
+
+import (
+	"context"
+
+	"github.com/asim/go-micro/v3"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+// NewTracerProvider returns a tracer provider exporting the spans of the
+// service with a batcher, it should be shut down when the service stops
+func NewTracerProvider(name string, opts ...Option) *sdktrace.TracerProvider {
+	options := NewOptions(opts...)
+
+	tpopts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(name),
+		)),
+	}
+	if options.Exporter != nil {
+		tpopts = append(tpopts, sdktrace.WithBatcher(options.Exporter))
+	}
+	if options.Sampler != nil {
+		tpopts = append(tpopts, sdktrace.WithSampler(options.Sampler))
+	}
+
+	return sdktrace.NewTracerProvider(tpopts...)
+}
+
+// Tracing returns a service option which traces calls, handlers and
+// subscribers e.g
+//
+//	service := micro.NewService(
+//		micro.Name("greeter"),
+//		opentelemetry.Tracing(opentelemetry.WithExporter(exporter)),
+//	)
+//
+// If an exporter is set the spans are exported with the name of the service,
+// so the option must come after micro.Name, and are flushed when it stops.
+func Tracing(opts ...Option) micro.Option {
+	return func(o *micro.Options) {
+		options := NewOptions(opts...)
+
+		if options.Exporter != nil {
+			tp := NewTracerProvider(o.Server.Options().Name, opts...)
+			o.AfterStop = append(o.AfterStop, func() error {
+				return tp.Shutdown(context.Background())
+			})
+			opts = append(opts[:len(opts):len(opts)], WithTracerProvider(tp))
+		}
+
+		micro.WrapClient(NewClientWrapper(opts...))(o)
+		micro.WrapHandler(NewHandlerWrapper(opts...))(o)
+		micro.WrapSubscriber(NewSubscriberWrapper(opts...))(o)
+	}
+}
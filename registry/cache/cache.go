@@ -321,6 +321,9 @@ func (c *cache) run() {
 	c.running = true
 	c.Unlock()
 
+	// log with the logger of the registry
+	log := logger.NewHelper(c.Registry.Options().Logger)
+
 	// reset watcher on exit
 	defer func() {
 		c.Lock()
@@ -352,8 +355,8 @@ func (c *cache) run() {
 			c.setStatus(err)
 
 			if a > 3 {
-				if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-					logger.Debug("rcache: ", err, " backing off ", d)
+				if logger.V(logger.DebugLevel, log) {
+					log.Debug("rcache: ", err, " backing off ", d)
 				}
 				a = 0
 			}
@@ -377,8 +380,8 @@ func (c *cache) run() {
 			c.setStatus(err)
 
 			if b > 3 {
-				if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-					logger.Debug("rcache: ", err, " backing off ", d)
+				if logger.V(logger.DebugLevel, log) {
+					log.Debug("rcache: ", err, " backing off ", d)
 				}
 				b = 0
 			}
@@ -168,6 +168,8 @@ func (m *mdnsRegistry) Register(service *Service, opts ...RegisterOption) error
 	m.Lock()
 	defer m.Unlock()
 
+	log := logger.NewHelper(m.opts.Logger)
+
 	entries, ok := m.services[service.Name]
 	// first entry, create wildcard used for list queries
 	if !ok {
@@ -234,8 +236,8 @@ func (m *mdnsRegistry) Register(service *Service, opts ...RegisterOption) error
 		}
 		port, _ := strconv.Atoi(pt)
 
-		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-			logger.Debugf("[mdns] registry create new service with ip: %s for: %s", net.ParseIP(host).String(), host)
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("[mdns] registry create new service with ip: %s for: %s", net.ParseIP(host).String(), host)
 		}
 		// we got here, new node
 		s, err := mdns.NewMDNSService(
@@ -305,6 +307,7 @@ func (m *mdnsRegistry) Deregister(service *Service, opts ...DeregisterOption) er
 }
 
 func (m *mdnsRegistry) GetService(service string, opts ...GetOption) ([]*Service, error) {
+	log := logger.NewHelper(m.opts.Logger)
 	serviceMap := make(map[string]*Service)
 	entries := make(chan *mdns.ServiceEntry, 10)
 	done := make(chan bool)
@@ -359,8 +362,8 @@ func (m *mdnsRegistry) GetService(service string, opts ...GetOption) ([]*Service
 				} else if len(e.AddrV6) > 0 {
 					addr = "[" + e.AddrV6.String() + "]"
 				} else {
-					if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-						logger.Infof("[mdns]: invalid endpoint received: %v", e)
+					if logger.V(logger.InfoLevel, log) {
+						log.Infof("[mdns]: invalid endpoint received: %v", e)
 					}
 					continue
 				}
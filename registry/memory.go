@@ -69,12 +69,13 @@ func (m *memRegistry) ttlPrune() {
 		select {
 		case <-prune.C:
 			m.Lock()
+			log := logger.NewHelper(m.options.Logger)
 			for name, records := range m.records {
 				for version, record := range records {
 					for id, n := range record.Nodes {
 						if n.TTL != 0 && time.Since(n.LastSeen) > n.TTL {
-							if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-								logger.Debugf("Registry TTL expired for node %s of service %s", n.Id, name)
+							if logger.V(logger.DebugLevel, log) {
+								log.Debugf("Registry TTL expired for node %s of service %s", n.Id, name)
 							}
 							delete(m.records[name][version].Nodes, id)
 						}
@@ -145,6 +146,8 @@ func (m *memRegistry) Register(s *Service, opts ...RegisterOption) error {
 	m.Lock()
 	defer m.Unlock()
 
+	log := logger.NewHelper(m.options.Logger)
+
 	var options RegisterOptions
 	for _, o := range opts {
 		o(&options)
@@ -158,8 +161,8 @@ func (m *memRegistry) Register(s *Service, opts ...RegisterOption) error {
 
 	if _, ok := m.records[s.Name][s.Version]; !ok {
 		m.records[s.Name][s.Version] = r
-		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-			logger.Debugf("Registry added new service: %s, version: %s", s.Name, s.Version)
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("Registry added new service: %s, version: %s", s.Name, s.Version)
 		}
 		go m.sendEvent(&Result{Action: "update", Service: s})
 		return nil
@@ -186,8 +189,8 @@ func (m *memRegistry) Register(s *Service, opts ...RegisterOption) error {
 	}
 
 	if addedNodes {
-		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-			logger.Debugf("Registry added new node to service: %s, version: %s", s.Name, s.Version)
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("Registry added new node to service: %s, version: %s", s.Name, s.Version)
 		}
 		go m.sendEvent(&Result{Action: "update", Service: s})
 		return nil
@@ -195,8 +198,8 @@ func (m *memRegistry) Register(s *Service, opts ...RegisterOption) error {
 
 	// refresh TTL and timestamp
 	for _, n := range s.Nodes {
-		if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-			logger.Debugf("Updated registration for service: %s, version: %s", s.Name, s.Version)
+		if logger.V(logger.DebugLevel, log) {
+			log.Debugf("Updated registration for service: %s, version: %s", s.Name, s.Version)
 		}
 		m.records[s.Name][s.Version].Nodes[n.Id].TTL = options.TTL
 		m.records[s.Name][s.Version].Nodes[n.Id].LastSeen = time.Now()
@@ -209,27 +212,29 @@ func (m *memRegistry) Deregister(s *Service, opts ...DeregisterOption) error {
 	m.Lock()
 	defer m.Unlock()
 
+	log := logger.NewHelper(m.options.Logger)
+
 	if _, ok := m.records[s.Name]; ok {
 		if _, ok := m.records[s.Name][s.Version]; ok {
 			for _, n := range s.Nodes {
 				if _, ok := m.records[s.Name][s.Version].Nodes[n.Id]; ok {
-					if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-						logger.Debugf("Registry removed node from service: %s, version: %s", s.Name, s.Version)
+					if logger.V(logger.DebugLevel, log) {
+						log.Debugf("Registry removed node from service: %s, version: %s", s.Name, s.Version)
 					}
 					delete(m.records[s.Name][s.Version].Nodes, n.Id)
 				}
 			}
 			if len(m.records[s.Name][s.Version].Nodes) == 0 {
 				delete(m.records[s.Name], s.Version)
-				if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-					logger.Debugf("Registry removed service: %s, version: %s", s.Name, s.Version)
+				if logger.V(logger.DebugLevel, log) {
+					log.Debugf("Registry removed service: %s, version: %s", s.Name, s.Version)
 				}
 			}
 		}
 		if len(m.records[s.Name]) == 0 {
 			delete(m.records, s.Name)
-			if logger.V(logger.DebugLevel, logger.DefaultLogger) {
-				logger.Debugf("Registry removed service: %s", s.Name)
+			if logger.V(logger.DebugLevel, log) {
+				log.Debugf("Registry removed service: %s", s.Name)
 			}
 		}
 		go m.sendEvent(&Result{Action: "delete", Service: s})
@@ -4,6 +4,8 @@ import (
 	"context"
 	"crypto/tls"
 	"time"
+
+	"github.com/asim/go-micro/v3/logger"
 )
 
 type Options struct {
@@ -11,6 +13,8 @@ type Options struct {
 	Timeout   time.Duration
 	Secure    bool
 	TLSConfig *tls.Config
+	// Logger of the registry, the logger.DefaultLogger if nil
+	Logger logger.Logger
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -57,6 +61,13 @@ func Timeout(t time.Duration) Option {
 	}
 }
 
+// Logger to log with
+func Logger(l logger.Logger) Option {
+	return func(o *Options) {
+		o.Logger = l
+	}
+}
+
 // Secure communication with the registry
 func Secure(b bool) Option {
 	return func(o *Options) {
@@ -2,11 +2,12 @@ package runtime
 
 import (
 	"archive/tar"
+	"bufio"
+	"bytes"
 	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,7 +15,10 @@ import (
 	"time"
 
 	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/runtime/local/build"
+	golang "github.com/asim/go-micro/v3/runtime/local/build/go"
 	"github.com/asim/go-micro/v3/runtime/local/git"
+	"github.com/asim/go-micro/v3/runtime/local/source"
 	"github.com/nxadm/tail"
 )
 
@@ -88,7 +92,10 @@ func (r *runtime) checkoutSourceIfNeeded(s *Service) error {
 	if err != nil {
 		return err
 	}
-	source.Ref = s.Version
+	// a ref in the source e.g github.com/org/service@v1.2.3 takes precedence
+	if !strings.Contains(s.Source, "@") && len(s.Version) > 0 {
+		source.Ref = s.Version
+	}
 
 	err = git.CheckoutSource(os.TempDir(), source)
 	if err != nil {
@@ -98,6 +105,35 @@ func (r *runtime) checkoutSourceIfNeeded(s *Service) error {
 	return nil
 }
 
+// build builds the service binary from its source, the build logs are written to output
+func (r *runtime) build(s *Service, output io.Writer) (string, error) {
+	version := s.Version
+	if len(version) == 0 {
+		version = "latest"
+	}
+	path := filepath.Join(os.TempDir(), "micro", "bin", strings.Replace(s.Name, "/", "-", -1), version)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(output, "Building %s %s from %s\n", s.Name, version, s.Source)
+
+	builder := golang.NewBuild(build.Path(path), build.Output(output))
+	pkg, err := builder.Build(&build.Source{
+		Language: "go",
+		Repository: &source.Repository{
+			Name: filepath.Base(s.Source),
+			Path: filepath.Dir(s.Source),
+		},
+	})
+	if err != nil {
+		fmt.Fprintf(output, "Build failed: %v\n", err)
+		return "", fmt.Errorf("failed to build %s: %v", s.Name, err)
+	}
+
+	return pkg.Path, nil
+}
+
 // modified version of: https://gist.github.com/mimoo/25fc9716e0f1353791f5908f94d6e726
 func uncompress(src string, dst string) error {
 	file, err := os.OpenFile(src, os.O_RDWR|os.O_CREATE, 0666)
@@ -334,8 +370,6 @@ func (r *runtime) Create(s *Service, opts ...CreateOption) error {
 	if err != nil {
 		return err
 	}
-	r.Lock()
-	defer r.Unlock()
 
 	var options CreateOptions
 	for _, o := range opts {
@@ -344,33 +378,58 @@ func (r *runtime) Create(s *Service, opts ...CreateOption) error {
 	if len(options.Namespace) == 0 {
 		options.Namespace = defaultNamespace
 	}
-	if len(options.Command) == 0 {
+
+	r.RLock()
+	_, ok := r.namespaces[options.Namespace][serviceKey(s)]
+	r.RUnlock()
+	if ok {
+		return errors.New("service already running")
+	}
+
+	f, err := os.OpenFile(logFile(s.Name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	var output io.Writer = newLogWriter(f)
+	if options.Output != nil {
+		output = io.MultiWriter(options.Output, output)
+	}
+
+	var built bool
+	if len(options.Command) == 0 && len(s.Source) > 0 {
+		// build the binary, the build logs are written to the service logs
+		bin, err := r.build(s, output)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		options.Command = []string{bin}
+		built = true
+	} else if len(options.Command) == 0 {
 		options.Command = []string{"go"}
 		options.Args = []string{"run", "."}
 	}
 
+	r.Lock()
+	defer r.Unlock()
+
 	if _, ok := r.namespaces[options.Namespace]; !ok {
 		r.namespaces[options.Namespace] = make(map[string]*service)
 	}
 	if _, ok := r.namespaces[options.Namespace][serviceKey(s)]; ok {
+		f.Close()
 		return errors.New("service already running")
 	}
 
 	// create new service
 	service := newService(s, options)
+	service.output = output
+	service.built = built
 
-	f, err := os.OpenFile(logFile(service.Name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	if service.output != nil {
-		service.output = io.MultiWriter(service.output, f)
-	} else {
-		service.output = f
-	}
 	// start the service
 	if err := service.Start(); err != nil {
+		f.Close()
 		return err
 	}
 	// save service
@@ -391,10 +450,8 @@ func exists(path string) (bool, error) {
 	return true, err
 }
 
-// @todo: Getting existing lines is not supported yet.
-// The reason for this is because it's hard to calculate line offset
-// as opposed to character offset.
-// This logger streams by default and only supports the `StreamCount` option.
+// Logs returns the existing lines of the service log file, filtered by the since
+// and count options, followed by the new lines if streaming is requested.
 func (r *runtime) Logs(s *Service, options ...LogsOption) (LogStream, error) {
 	lopts := LogsOptions{}
 	for _, o := range options {
@@ -413,49 +470,108 @@ func (r *runtime) Logs(s *Service, options ...LogsOption) (LogStream, error) {
 		return nil, fmt.Errorf("Log file %v does not exists", fpath)
 	}
 
-	// have to check file size to avoid too big of a seek
-	fi, err := os.Stat(fpath)
+	f, err := os.Open(fpath)
 	if err != nil {
 		return nil, err
 	}
-	size := fi.Size()
-
-	whence := 2
-	// Multiply by length of an average line of log in bytes
-	offset := lopts.Count * 200
-
-	if offset > size {
-		offset = size
-	}
-	offset *= -1
 
-	t, err := tail.TailFile(fpath, tail.Config{Follow: lopts.Stream, Location: &tail.SeekInfo{
-		Whence: whence,
-		Offset: int64(offset),
-	}, Logger: tail.DiscardingLogger})
+	// existing lines are read up to the current size and new lines tailed from there
+	fi, err := f.Stat()
 	if err != nil {
+		f.Close()
 		return nil, err
 	}
+	size := fi.Size()
+
+	if lopts.Stream {
+		t, err := tail.TailFile(fpath, tail.Config{Follow: true, Location: &tail.SeekInfo{
+			Whence: io.SeekStart,
+			Offset: size,
+		}, Logger: tail.DiscardingLogger})
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		ret.tail = t
+	}
 
-	ret.tail = t
 	go func() {
+		defer close(ret.stream)
+
+		err := ret.read(io.LimitReader(f, size), lopts)
+		f.Close()
+		if err != nil {
+			ret.setError(err)
+			return
+		}
+
+		if ret.tail == nil {
+			return
+		}
+
 		for {
 			select {
-			case line, ok := <-t.Lines:
+			case line, ok := <-ret.tail.Lines:
 				if !ok {
-					ret.Stop()
 					return
 				}
-				ret.stream <- LogRecord{Message: line.Text}
+				if !ret.send(newLogRecord(line.Text)) {
+					return
+				}
 			case <-ret.stop:
 				return
 			}
 		}
-
 	}()
+
 	return ret, nil
 }
 
+// newLogRecord parses a line of a log file
+func newLogRecord(line string) LogRecord {
+	t, msg := ParseLogLine(line)
+	return LogRecord{Timestamp: t, Message: msg}
+}
+
+// logWriter prefixes every line written to the log file with a timestamp
+type logWriter struct {
+	sync.Mutex
+	w io.Writer
+	// set at the start of a line
+	newline bool
+}
+
+func newLogWriter(w io.Writer) *logWriter {
+	return &logWriter{w: w, newline: true}
+}
+
+func (l *logWriter) Write(p []byte) (int, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	buf := make([]byte, 0, len(p)+64)
+	for b := p; len(b) > 0; {
+		if l.newline {
+			buf = append(buf, time.Now().Format(time.RFC3339Nano)...)
+			buf = append(buf, ' ')
+			l.newline = false
+		}
+		idx := bytes.IndexByte(b, '\n')
+		if idx < 0 {
+			buf = append(buf, b...)
+			break
+		}
+		buf = append(buf, b[:idx+1]...)
+		b = b[idx+1:]
+		l.newline = true
+	}
+
+	if _, err := l.w.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
 type logStream struct {
 	tail    *tail.Tail
 	service string
@@ -465,11 +581,70 @@ type logStream struct {
 	err  error
 }
 
+// read sends the existing lines, only the last count lines are sent unless
+// since is set and lines written before since are skipped. No existing
+// lines are sent if neither is set.
+func (l *logStream) read(r io.Reader, opts LogsOptions) error {
+	if opts.Count <= 0 && opts.Since.IsZero() {
+		return nil
+	}
+
+	var last []LogRecord
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), MaxLogLineSize)
+	for scanner.Scan() {
+		record := newLogRecord(scanner.Text())
+		if !opts.Since.IsZero() && record.Timestamp.Before(opts.Since) {
+			continue
+		}
+		if opts.Count <= 0 {
+			if !l.send(record) {
+				return nil
+			}
+			continue
+		}
+		last = append(last, record)
+		if int64(len(last)) > opts.Count {
+			last = last[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for _, record := range last {
+		if !l.send(record) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// send sends the record unless the stream is stopped
+func (l *logStream) send(record LogRecord) bool {
+	select {
+	case l.stream <- record:
+		return true
+	case <-l.stop:
+		return false
+	}
+}
+
+func (l *logStream) setError(err error) {
+	l.Lock()
+	l.err = err
+	l.Unlock()
+}
+
 func (l *logStream) Chan() chan LogRecord {
 	return l.stream
 }
 
 func (l *logStream) Error() error {
+	l.Lock()
+	defer l.Unlock()
 	return l.err
 }
 
@@ -482,7 +657,9 @@ func (l *logStream) Stop() error {
 		return nil
 	default:
 		close(l.stop)
-		close(l.stream)
+		if l.tail == nil {
+			return nil
+		}
 		err := l.tail.Stop()
 		if err != nil {
 			logger.Errorf("Error stopping tail: %v", err)
@@ -564,6 +741,18 @@ func (r *runtime) Update(s *Service, opts ...UpdateOption) error {
 		return errors.New("Service not found")
 	}
 
+	// rebuild the binary from the updated source
+	if service.built {
+		bin, err := r.build(s, service.output)
+		if err != nil {
+			return err
+		}
+		service.Lock()
+		service.Exec.Package.Path = bin
+		service.Exec.Dir = s.Source
+		service.Unlock()
+	}
+
 	if err := service.Stop(); err != nil && err.Error() != "no such process" {
 		logger.Errorf("Error stopping service %s: %s", service.Name, err)
 		return err
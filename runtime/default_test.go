@@ -0,0 +1,210 @@
+package runtime
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testOutput is a writer safe to read while the service writes to it
+type testOutput struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (o *testOutput) Write(b []byte) (int, error) {
+	o.Lock()
+	defer o.Unlock()
+	return o.buf.Write(b)
+}
+
+func (o *testOutput) String() string {
+	o.Lock()
+	defer o.Unlock()
+	return o.buf.String()
+}
+
+// testSource writes a go module with the main package to a temp dir
+func testSource(t *testing.T, main string) string {
+	dir, err := ioutil.TempDir("", "runtime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(dir, "service")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"go.mod":  "module example.com/service\n\ngo 1.16\n",
+		"main.go": main,
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(src, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return src
+}
+
+func TestCreateBuild(t *testing.T) {
+	src := testSource(t, "package main\n\nfunc main() {}\n")
+	defer os.RemoveAll(filepath.Dir(src))
+
+	r := NewRuntime()
+	s := &Service{Name: "test-create-build", Version: "v1", Source: src}
+	out := new(testOutput)
+
+	if err := r.Create(s, WithOutput(out)); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Delete(s)
+
+	if !strings.Contains(out.String(), "Building test-create-build v1") {
+		t.Fatalf("Expected the build to be logged, got %q", out.String())
+	}
+
+	services, err := r.Read(ReadService(s.Name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 {
+		t.Fatalf("Expected the service to be created, got %d services", len(services))
+	}
+}
+
+func TestCreateBuildFailure(t *testing.T) {
+	src := testSource(t, "package main\n\nfunc main() {\n")
+	defer os.RemoveAll(filepath.Dir(src))
+
+	r := NewRuntime()
+	s := &Service{Name: "test-create-build-failure", Version: "v1", Source: src}
+	out := new(testOutput)
+
+	if err := r.Create(s, WithOutput(out)); err == nil {
+		t.Fatal("Expected the build to fail")
+	}
+	if !strings.Contains(out.String(), "Build failed") {
+		t.Fatalf("Expected the failure to be logged, got %q", out.String())
+	}
+
+	services, err := r.Read(ReadService(s.Name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 0 {
+		t.Fatalf("Expected no service to be created, got %d services", len(services))
+	}
+}
+
+func TestParseLogLine(t *testing.T) {
+	ts := time.Date(2021, 3, 4, 5, 6, 7, 8, time.UTC)
+
+	testCases := []struct {
+		line string
+		time time.Time
+		msg  string
+	}{
+		{ts.Format(time.RFC3339Nano) + " hello world", ts, "hello world"},
+		{ts.Format(time.RFC3339) + " hello", ts.Truncate(time.Second), "hello"},
+		{"hello world", time.Time{}, "hello world"},
+		{"hello", time.Time{}, "hello"},
+		{"", time.Time{}, ""},
+	}
+
+	for _, test := range testCases {
+		tm, msg := ParseLogLine(test.line)
+		if !tm.Equal(test.time) || msg != test.msg {
+			t.Fatalf("Expected %v %q for %q got: %v %q", test.time, test.msg, test.line, tm, msg)
+		}
+	}
+}
+
+func TestLogWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := newLogWriter(&buf)
+
+	// lines are split across writes
+	for _, s := range []string{"foo", " bar\nbaz\n", "qux\n"} {
+		if n, err := w.Write([]byte(s)); err != nil || n != len(s) {
+			t.Fatalf("Expected %d bytes written got: %d %v", len(s), n, err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	expect := []string{"foo bar", "baz", "qux"}
+	if len(lines) != len(expect) {
+		t.Fatalf("Expected %d lines got: %q", len(expect), buf.String())
+	}
+	for i, line := range lines {
+		tm, msg := ParseLogLine(line)
+		if tm.IsZero() {
+			t.Fatalf("Expected a timestamp on %q", line)
+		}
+		if msg != expect[i] {
+			t.Fatalf("Expected message %q got: %q", expect[i], msg)
+		}
+	}
+}
+
+func TestLogStreamRead(t *testing.T) {
+	now := time.Now()
+
+	var lines []string
+	for i := 0; i < 5; i++ {
+		ts := now.Add(time.Duration(i) * time.Second)
+		lines = append(lines, fmt.Sprintf("%s line %d", ts.Format(time.RFC3339Nano), i))
+	}
+	long := strings.Repeat("x", bufio.MaxScanTokenSize*2)
+	lines = append(lines, now.Add(5*time.Second).Format(time.RFC3339Nano)+" "+long)
+	data := strings.Join(lines, "\n") + "\n"
+
+	testCases := []struct {
+		opts   LogsOptions
+		expect []string
+	}{
+		// no existing lines unless count or since is set
+		{LogsOptions{}, nil},
+		{LogsOptions{Count: 2}, []string{"line 4", long}},
+		{LogsOptions{Since: now.Add(3 * time.Second)}, []string{"line 3", "line 4", long}},
+		{LogsOptions{Count: 1, Since: now.Add(3 * time.Second)}, []string{long}},
+		{LogsOptions{Count: 10, Since: now.Add(time.Minute)}, nil},
+	}
+
+	for _, test := range testCases {
+		l := &logStream{
+			stream: make(chan LogRecord, len(lines)),
+			stop:   make(chan bool),
+		}
+		if err := l.read(strings.NewReader(data), test.opts); err != nil {
+			t.Fatalf("Unexpected error for %+v: %v", test.opts, err)
+		}
+		close(l.stream)
+
+		var msgs []string
+		for record := range l.stream {
+			msgs = append(msgs, record.Message)
+		}
+		if len(msgs) != len(test.expect) {
+			t.Fatalf("Expected %d lines for %+v got: %d", len(test.expect), test.opts, len(msgs))
+		}
+		for i, msg := range msgs {
+			if msg != test.expect[i] {
+				t.Fatalf("Expected line %d to be %.20q got: %.20q", i, test.expect[i], msg)
+			}
+		}
+	}
+
+	// lines over the max size fail the read
+	l := &logStream{stream: make(chan LogRecord, 1), stop: make(chan bool)}
+	tooLong := strings.Repeat("x", MaxLogLineSize+1) + "\n"
+	if err := l.read(strings.NewReader(tooLong), LogsOptions{Count: 1}); err == nil {
+		t.Fatal("Expected an error for a line over the max size")
+	}
+}
@@ -0,0 +1,50 @@
+package docker
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/asim/go-micro/v3/runtime"
+	"github.com/asim/go-micro/v3/runtime/local/git"
+	dc "github.com/fsouza/go-dockerclient"
+)
+
+// build checks out the service source and builds an image from its Dockerfile,
+// the build logs are written to output
+func (d *docker) build(s *runtime.Service, output io.Writer) (string, error) {
+	source, err := git.ParseSourceLocal("", s.Source)
+	if err != nil {
+		return "", err
+	}
+	// a ref in the source e.g github.com/org/service@v1.2.3 takes precedence
+	if !strings.Contains(s.Source, "@") && len(s.Version) > 0 {
+		source.Ref = s.Version
+	}
+	if err := git.CheckoutSource(os.TempDir(), source); err != nil {
+		return "", err
+	}
+
+	tag := source.Ref
+	if len(tag) == 0 || source.Local {
+		tag = "latest"
+	}
+	image := fmt.Sprintf("micro/%s:%s", strings.ToLower(nameRegex(s.Name)), nameRegex(tag))
+
+	fmt.Fprintf(output, "Building %s from %s\n", image, s.Source)
+
+	err = d.client.BuildImage(dc.BuildImageOptions{
+		Name:           image,
+		Dockerfile:     "Dockerfile",
+		ContextDir:     source.FullPath,
+		OutputStream:   output,
+		RmTmpContainer: true,
+	})
+	if err != nil {
+		fmt.Fprintf(output, "Build failed: %v\n", err)
+		return "", fmt.Errorf("failed to build %s: %v", s.Name, err)
+	}
+
+	return image, nil
+}
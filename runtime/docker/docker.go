@@ -0,0 +1,650 @@
+// Package docker implements a docker micro runtime
+//
+// Services are run as containers labelled with the service name, version
+// and namespace so they can be read back after a restart of the runtime.
+// Containers are restarted by the docker daemon according to the restart
+// policy which defaults to on-failure with the create retries. Services
+// created without an image are built from the Dockerfile in their source.
+package docker
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/runtime"
+	dc "github.com/fsouza/go-dockerclient"
+)
+
+const (
+	// defaultNamespace to use if not provided as an option
+	defaultNamespace = "default"
+	// stopTimeout is the seconds given to a container to stop
+	stopTimeout = 10
+)
+
+var (
+	// ErrNoImage is returned when no image is set for a service
+	ErrNoImage = errors.New("no image specified")
+	// ErrNotFound is returned when the service container doesn't exist
+	ErrNotFound = errors.New("service not found")
+)
+
+type docker struct {
+	sync.RWMutex
+	// options configure runtime
+	options runtime.Options
+	// client is the docker client
+	client *dc.Client
+	// err is set if the client couldn't be created
+	err error
+	// indicates if we're running
+	running bool
+}
+
+// containerName returns the name of the service container
+func containerName(s *runtime.Service, namespace string) string {
+	name := strings.Join([]string{"micro", namespace, s.Name, s.Version}, "-")
+	return strings.Trim(nameRegex(name), "-")
+}
+
+// nameRegex replaces characters not valid in container names
+func nameRegex(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+// labels returns the labels used to find the service containers
+func (d *docker) labels(name, version, namespace string) map[string]string {
+	labels := map[string]string{
+		"micro":     d.options.Type,
+		"namespace": namespace,
+	}
+	if len(name) > 0 {
+		labels["name"] = name
+	}
+	if len(version) > 0 {
+		labels["version"] = version
+	}
+	return labels
+}
+
+// filters returns docker label filters
+func filters(labels map[string]string) map[string][]string {
+	var f []string
+	for k, v := range labels {
+		f = append(f, k+"="+v)
+	}
+	return map[string][]string{"label": f}
+}
+
+// portBindings parses ports as either port, host:container or
+// ip:host:container
+func portBindings(ports []string) (map[dc.Port]struct{}, map[dc.Port][]dc.PortBinding, error) {
+	exposed := make(map[dc.Port]struct{})
+	bindings := make(map[dc.Port][]dc.PortBinding)
+
+	for _, p := range ports {
+		var ip string
+		host, container := p, p
+		if idx := strings.LastIndex(p, ":"); idx >= 0 {
+			host, container = p[:idx], p[idx+1:]
+		}
+		if idx := strings.LastIndex(host, ":"); idx >= 0 {
+			ip, host = host[:idx], host[idx+1:]
+		}
+		if _, err := strconv.Atoi(container); err != nil {
+			return nil, nil, fmt.Errorf("invalid port %s", p)
+		}
+		port := dc.Port(container + "/tcp")
+		exposed[port] = struct{}{}
+		bindings[port] = append(bindings[port], dc.PortBinding{HostIP: ip, HostPort: host})
+	}
+
+	return exposed, bindings, nil
+}
+
+// pull pulls the image if it doesn't exist locally
+func (d *docker) pull(ctx context.Context, image string) error {
+	if _, err := d.client.InspectImage(image); err == nil {
+		return nil
+	}
+
+	repo, tag := dc.ParseRepositoryTag(image)
+	if len(tag) == 0 {
+		tag = "latest"
+	}
+
+	if log.V(log.DebugLevel, log.DefaultLogger) {
+		log.Debugf("Runtime pulling image %s:%s", repo, tag)
+	}
+
+	return d.client.PullImage(dc.PullImageOptions{
+		Repository: repo,
+		Tag:        tag,
+		Context:    ctx,
+	}, dc.AuthConfiguration{})
+}
+
+// Init initializes runtime options
+func (d *docker) Init(opts ...runtime.Option) error {
+	d.Lock()
+	defer d.Unlock()
+
+	for _, o := range opts {
+		o(&d.options)
+	}
+
+	return nil
+}
+
+// Create pulls the image and starts the service container
+func (d *docker) Create(s *runtime.Service, opts ...runtime.CreateOption) error {
+	if d.err != nil {
+		return d.err
+	}
+
+	options := runtime.CreateOptions{
+		Type:    d.options.Type,
+		Context: context.Background(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = defaultNamespace
+	}
+	if len(options.Type) == 0 {
+		options.Type = d.options.Type
+	}
+	if s.Metadata == nil {
+		s.Metadata = make(map[string]string)
+	}
+
+	var output io.Writer = ioutil.Discard
+	if options.Output != nil {
+		output = options.Output
+	}
+
+	// build the image from the service source
+	var built bool
+	if len(options.Image) == 0 && len(s.Source) > 0 {
+		image, err := d.build(s, output)
+		if err != nil {
+			s.Metadata["status"] = "error"
+			s.Metadata["error"] = err.Error()
+			return err
+		}
+		options.Image = image
+		built = true
+	}
+
+	if len(options.Image) == 0 {
+		options.Image = d.options.Image
+	}
+	if len(options.Image) == 0 {
+		return ErrNoImage
+	}
+	if len(s.Source) == 0 {
+		s.Source = d.options.Source
+	}
+
+	ports, _ := options.Context.Value(portsKey{}).([]string)
+	exposed, bindings, err := portBindings(ports)
+	if err != nil {
+		return err
+	}
+
+	policy := dc.RestartOnFailure(options.Retries)
+	if v, ok := options.Context.Value(restartPolicyKey{}).(restartPolicy); ok {
+		policy = dc.RestartPolicy{Name: v.name, MaximumRetryCount: v.retries}
+	}
+
+	if !built {
+		if err := d.pull(options.Context, options.Image); err != nil {
+			s.Metadata["status"] = "error"
+			s.Metadata["error"] = err.Error()
+			return err
+		}
+	}
+
+	labels := d.labels(s.Name, s.Version, options.Namespace)
+	labels["micro"] = options.Type
+	labels["source"] = s.Source
+	if built {
+		labels["built"] = "true"
+	}
+	// store the service metadata so it's returned by Read
+	for k, v := range s.Metadata {
+		labels["metadata."+k] = v
+	}
+
+	config := &dc.Config{
+		Image:        options.Image,
+		Env:          options.Env,
+		Labels:       labels,
+		ExposedPorts: exposed,
+	}
+	if len(options.Command) > 0 {
+		config.Entrypoint = options.Command
+	}
+	if len(options.Args) > 0 {
+		config.Cmd = options.Args
+	}
+
+	container, err := d.client.CreateContainer(dc.CreateContainerOptions{
+		Name:   containerName(s, options.Namespace),
+		Config: config,
+		HostConfig: &dc.HostConfig{
+			PortBindings:  bindings,
+			RestartPolicy: policy,
+		},
+		Context: options.Context,
+	})
+	if err == dc.ErrContainerAlreadyExists {
+		return runtime.ErrAlreadyExists
+	} else if err != nil {
+		return err
+	}
+
+	if err := d.client.StartContainer(container.ID, nil); err != nil {
+		return err
+	}
+
+	s.Metadata["id"] = container.ID
+	s.Metadata["status"] = "running"
+	s.Metadata["started"] = time.Now().Format(time.RFC3339)
+
+	return nil
+}
+
+// list returns the containers matching the labels
+func (d *docker) list(labels map[string]string) ([]dc.APIContainers, error) {
+	return d.client.ListContainers(dc.ListContainersOptions{
+		All:     true,
+		Filters: filters(labels),
+	})
+}
+
+// Read returns the services from the container labels and state
+func (d *docker) Read(opts ...runtime.ReadOption) ([]*runtime.Service, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	var options runtime.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = defaultNamespace
+	}
+
+	labels := d.labels(options.Service, options.Version, options.Namespace)
+	if len(options.Type) > 0 {
+		labels["micro"] = options.Type
+	}
+
+	containers, err := d.list(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make([]*runtime.Service, 0, len(containers))
+
+	for _, c := range containers {
+		md := make(map[string]string)
+		for k, v := range c.Labels {
+			if strings.HasPrefix(k, "metadata.") {
+				md[strings.TrimPrefix(k, "metadata.")] = v
+			}
+		}
+
+		md["id"] = c.ID
+		md["image"] = c.Image
+		md["status"] = c.State
+		md["started"] = time.Unix(c.Created, 0).Format(time.RFC3339)
+
+		// get the exit code and error of stopped containers
+		if c.State == "exited" || c.State == "dead" {
+			if info, err := d.client.InspectContainerWithOptions(dc.InspectContainerOptions{ID: c.ID}); err == nil {
+				md["exitCode"] = strconv.Itoa(info.State.ExitCode)
+				if len(info.State.Error) > 0 {
+					md["error"] = info.State.Error
+				}
+			}
+		}
+
+		services = append(services, &runtime.Service{
+			Name:     c.Labels["name"],
+			Version:  c.Labels["version"],
+			Source:   c.Labels["source"],
+			Metadata: md,
+		})
+	}
+
+	return services, nil
+}
+
+// Update pulls the image and recreates the service containers
+func (d *docker) Update(s *runtime.Service, opts ...runtime.UpdateOption) error {
+	if d.err != nil {
+		return d.err
+	}
+
+	options := runtime.UpdateOptions{
+		Context: context.Background(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = defaultNamespace
+	}
+
+	containers, err := d.list(d.labels(s.Name, s.Version, options.Namespace))
+	if err != nil {
+		return err
+	}
+	if len(containers) == 0 {
+		return ErrNotFound
+	}
+
+	for _, c := range containers {
+		info, err := d.client.InspectContainerWithOptions(dc.InspectContainerOptions{ID: c.ID})
+		if err != nil {
+			return err
+		}
+
+		// update the metadata
+		for k, v := range s.Metadata {
+			info.Config.Labels["metadata."+k] = v
+		}
+
+		// rebuild the image from source or pull the latest image
+		if info.Config.Labels["built"] == "true" {
+			image, err := d.build(&runtime.Service{
+				Name:    s.Name,
+				Version: s.Version,
+				Source:  info.Config.Labels["source"],
+			}, ioutil.Discard)
+			if err != nil {
+				return err
+			}
+			info.Config.Image = image
+		} else if err := d.pull(options.Context, info.Config.Image); err != nil {
+			return err
+		}
+
+		if log.V(log.DebugLevel, log.DefaultLogger) {
+			log.Debugf("Runtime recreating container %s for service %s", info.Name, s.Name)
+		}
+
+		if err := d.client.RemoveContainer(dc.RemoveContainerOptions{ID: c.ID, Force: true}); err != nil {
+			return err
+		}
+
+		container, err := d.client.CreateContainer(dc.CreateContainerOptions{
+			Name:       strings.TrimPrefix(info.Name, "/"),
+			Config:     info.Config,
+			HostConfig: info.HostConfig,
+			Context:    options.Context,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := d.client.StartContainer(container.ID, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete stops and removes the service containers
+func (d *docker) Delete(s *runtime.Service, opts ...runtime.DeleteOption) error {
+	if d.err != nil {
+		return d.err
+	}
+
+	var options runtime.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = defaultNamespace
+	}
+
+	containers, err := d.list(d.labels(s.Name, s.Version, options.Namespace))
+	if err != nil {
+		return err
+	}
+
+	for _, c := range containers {
+		// give the service a chance to shutdown
+		if err := d.client.StopContainer(c.ID, stopTimeout); err != nil {
+			if _, ok := err.(*dc.ContainerNotRunning); !ok {
+				if log.V(log.DebugLevel, log.DefaultLogger) {
+					log.Debugf("Runtime failed to stop container %s: %v", c.ID, err)
+				}
+			}
+		}
+		if err := d.client.RemoveContainer(dc.RemoveContainerOptions{ID: c.ID, Force: true}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Logs streams the logs of the service containers
+func (d *docker) Logs(s *runtime.Service, opts ...runtime.LogsOption) (runtime.LogStream, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	var options runtime.LogsOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Namespace) == 0 {
+		options.Namespace = defaultNamespace
+	}
+
+	containers, err := d.list(d.labels(s.Name, s.Version, options.Namespace))
+	if err != nil {
+		return nil, err
+	}
+	if len(containers) == 0 {
+		return nil, ErrNotFound
+	}
+
+	tail := "all"
+	if options.Count > 0 {
+		tail = strconv.FormatInt(options.Count, 10)
+	}
+
+	var since int64
+	if !options.Since.IsZero() {
+		since = options.Since.Unix()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream := &logStream{
+		stream: make(chan runtime.LogRecord),
+		stop:   make(chan bool),
+		cancel: cancel,
+	}
+
+	var wg sync.WaitGroup
+
+	for _, c := range containers {
+		pr, pw := io.Pipe()
+
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			err := d.client.Logs(dc.LogsOptions{
+				Context:      ctx,
+				Container:    id,
+				OutputStream: pw,
+				ErrorStream:  pw,
+				Stdout:       true,
+				Stderr:       true,
+				Follow:       options.Stream,
+				Tail:         tail,
+				Since:        since,
+				Timestamps:   true,
+			})
+			if err != nil && ctx.Err() == nil {
+				stream.setError(err)
+			}
+			pw.Close()
+		}(c.ID)
+
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			defer pr.Close()
+			s := bufio.NewScanner(pr)
+			s.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), runtime.MaxLogLineSize)
+			for s.Scan() {
+				t, msg := runtime.ParseLogLine(s.Text())
+				// since is only precise to the second
+				if !options.Since.IsZero() && t.Before(options.Since) {
+					continue
+				}
+				select {
+				case stream.stream <- runtime.LogRecord{
+					Timestamp: t,
+					Message:   msg,
+					Metadata:  map[string]string{"container": id},
+				}:
+				case <-stream.stop:
+					return
+				}
+			}
+		}(c.ID)
+	}
+
+	// close the stream once all the logs are read
+	go func() {
+		wg.Wait()
+		stream.Stop()
+		close(stream.stream)
+	}()
+
+	return stream, nil
+}
+
+type logStream struct {
+	stream chan runtime.LogRecord
+	cancel context.CancelFunc
+	sync.Mutex
+	stop chan bool
+	err  error
+}
+
+func (l *logStream) setError(err error) {
+	l.Lock()
+	l.err = err
+	l.Unlock()
+}
+
+func (l *logStream) Error() error {
+	l.Lock()
+	defer l.Unlock()
+	return l.err
+}
+
+func (l *logStream) Chan() chan runtime.LogRecord {
+	return l.stream
+}
+
+func (l *logStream) Stop() error {
+	l.Lock()
+	defer l.Unlock()
+
+	select {
+	case <-l.stop:
+		return nil
+	default:
+		close(l.stop)
+		l.cancel()
+	}
+
+	return nil
+}
+
+// Start starts the runtime
+func (d *docker) Start() error {
+	d.Lock()
+	defer d.Unlock()
+	d.running = true
+	return nil
+}
+
+// Stop stops the runtime, containers are left running and managed by the docker daemon
+func (d *docker) Stop() error {
+	d.Lock()
+	defer d.Unlock()
+
+	if !d.running {
+		return nil
+	}
+	d.running = false
+
+	if d.options.Scheduler != nil {
+		return d.options.Scheduler.Close()
+	}
+
+	return nil
+}
+
+// String implements stringer interface
+func (d *docker) String() string {
+	return "docker"
+}
+
+// NewRuntime creates a new docker runtime using the docker environment
+// variables e.g DOCKER_HOST to connect to the daemon
+func NewRuntime(opts ...runtime.Option) runtime.Runtime {
+	// get default options
+	options := runtime.Options{
+		// Create labels with type "micro": "service"
+		Type: "service",
+	}
+
+	// apply requested options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	client, err := dc.NewClientFromEnv()
+	if err != nil {
+		if log.V(log.ErrorLevel, log.DefaultLogger) {
+			log.Errorf("Runtime failed to create docker client: %v", err)
+		}
+	}
+
+	return &docker{
+		options: options,
+		client:  client,
+		err:     err,
+	}
+}
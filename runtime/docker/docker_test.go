@@ -0,0 +1,94 @@
+package docker
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/asim/go-micro/v3/runtime"
+	dc "github.com/fsouza/go-dockerclient"
+)
+
+func TestContainerName(t *testing.T) {
+	testCases := []struct {
+		service   *runtime.Service
+		namespace string
+		expect    string
+	}{
+		{&runtime.Service{Name: "foo", Version: "latest"}, "default", "micro-default-foo-latest"},
+		{&runtime.Service{Name: "go.micro.foo"}, "default", "micro-default-go.micro.foo"},
+		{&runtime.Service{Name: "github.com/foo/bar", Version: "v1.0.0"}, "dev", "micro-dev-github.com-foo-bar-v1.0.0"},
+		{&runtime.Service{Name: "foo bar", Version: "v1+build"}, "default", "micro-default-foo-bar-v1-build"},
+	}
+
+	for _, test := range testCases {
+		if v := containerName(test.service, test.namespace); v != test.expect {
+			t.Fatalf("Expected name %s for %s got: %s", test.expect, test.service.Name, v)
+		}
+	}
+}
+
+func TestFilters(t *testing.T) {
+	d := &docker{options: runtime.Options{Type: "service"}}
+
+	f := filters(d.labels("foo", "latest", "default"))
+
+	labels := f["label"]
+	sort.Strings(labels)
+
+	expect := []string{"micro=service", "name=foo", "namespace=default", "version=latest"}
+	if len(labels) != len(expect) {
+		t.Fatalf("Expected filters %v got: %v", expect, labels)
+	}
+	for i, l := range expect {
+		if labels[i] != l {
+			t.Fatalf("Expected filters %v got: %v", expect, labels)
+		}
+	}
+
+	// the name and version are left out to list every service
+	if f := filters(d.labels("", "", "default")); len(f["label"]) != 2 {
+		t.Fatalf("Expected the micro and namespace filters got: %v", f["label"])
+	}
+}
+
+func TestPortBindings(t *testing.T) {
+	testCases := []struct {
+		ports   []string
+		host    map[dc.Port]dc.PortBinding
+		invalid bool
+	}{
+		{ports: []string{"8080"}, host: map[dc.Port]dc.PortBinding{"8080/tcp": {HostPort: "8080"}}},
+		{ports: []string{"8081:8080"}, host: map[dc.Port]dc.PortBinding{"8080/tcp": {HostPort: "8081"}}},
+		{ports: []string{"127.0.0.1:8081:8080", "9090"}, host: map[dc.Port]dc.PortBinding{
+			"8080/tcp": {HostIP: "127.0.0.1", HostPort: "8081"},
+			"9090/tcp": {HostPort: "9090"},
+		}},
+		{ports: []string{"8081:http"}, invalid: true},
+		{ports: []string{""}, invalid: true},
+	}
+
+	for _, test := range testCases {
+		exposed, bindings, err := portBindings(test.ports)
+		if test.invalid {
+			if err == nil {
+				t.Fatalf("Expected an error for %v", test.ports)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error for %v: %v", test.ports, err)
+		}
+
+		if len(exposed) != len(test.host) || len(bindings) != len(test.host) {
+			t.Fatalf("Expected %d ports for %v got: %v %v", len(test.host), test.ports, exposed, bindings)
+		}
+		for port, host := range test.host {
+			if _, ok := exposed[port]; !ok {
+				t.Fatalf("Expected %s to be exposed for %v", port, test.ports)
+			}
+			if b := bindings[port]; len(b) != 1 || b[0] != host {
+				t.Fatalf("Expected %s bound to %+v for %v got: %+v", port, host, test.ports, b)
+			}
+		}
+	}
+}
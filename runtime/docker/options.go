@@ -0,0 +1,37 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/asim/go-micro/v3/runtime"
+)
+
+type portsKey struct{}
+type restartPolicyKey struct{}
+
+type restartPolicy struct {
+	name    string
+	retries int
+}
+
+// Ports publishes container ports e.g 8080 or 8081:8080 to publish
+// container port 8080 on host port 8081
+func Ports(ports ...string) runtime.CreateOption {
+	return func(o *runtime.CreateOptions) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, portsKey{}, ports)
+	}
+}
+
+// RestartPolicy sets the docker restart policy; no, always, unless-stopped
+// or on-failure which restarts the container up to the max retries
+func RestartPolicy(name string, retries int) runtime.CreateOption {
+	return func(o *runtime.CreateOptions) {
+		if o.Context == nil {
+			o.Context = context.Background()
+		}
+		o.Context = context.WithValue(o.Context, restartPolicyKey{}, restartPolicy{name, retries})
+	}
+}
@@ -212,7 +212,16 @@ func (k *kubernetes) getService(labels map[string]string, opts ...client.GetOpti
 						status = v.Reason
 					}
 				}
-				// TODO: set from terminated
+
+				// set status and exit code from terminated
+				if v := state.Terminated; v != nil {
+					if len(v.Reason) > 0 {
+						status = v.Reason
+					}
+					svc.Metadata["exitCode"] = fmt.Sprintf("%d", v.ExitCode)
+				}
+
+				svc.Metadata["restarts"] = fmt.Sprintf("%d", item.Status.Containers[0].RestartCount)
 				svc.Status(status, nil)
 			}
 
@@ -331,28 +340,7 @@ func (k *kubernetes) Init(opts ...runtime.Option) error {
 }
 
 func (k *kubernetes) Logs(s *runtime.Service, options ...runtime.LogsOption) (runtime.LogStream, error) {
-	klo := newLog(k.client, s.Name, options...)
-	stream, err := klo.Stream()
-	if err != nil {
-		return nil, err
-	}
-	// If requested, also read existing records and stream those too
-	if klo.options.Count > 0 {
-		go func() {
-			records, err := klo.Read()
-			if err != nil {
-				log.Errorf("Failed to get logs for service '%v' from k8s: %v", err)
-				return
-			}
-			// @todo: this might actually not run before podLogStream starts
-			// and might cause out of order log retrieval at the receiving end.
-			// A better approach would probably to suppor this inside the `klog.Stream` method.
-			for _, record := range records {
-				stream.Chan() <- record
-			}
-		}()
-	}
-	return stream, nil
+	return newLog(k.client, s.Name, options...).Stream()
 }
 
 type kubeStream struct {
@@ -364,7 +352,15 @@ type kubeStream struct {
 	err  error
 }
 
+func (k *kubeStream) setError(err error) {
+	k.Lock()
+	k.err = err
+	k.Unlock()
+}
+
 func (k *kubeStream) Error() error {
+	k.Lock()
+	defer k.Unlock()
 	return k.err
 }
 
@@ -380,7 +376,6 @@ func (k *kubeStream) Stop() error {
 		return nil
 	default:
 		close(k.stop)
-		close(k.stream)
 	}
 	return nil
 }
@@ -494,13 +489,19 @@ func (k *kubernetes) Update(s *runtime.Service, opts ...runtime.UpdateOption) er
 	}
 
 	// get the existing service
-	services, err := k.getService(labels)
+	services, err := k.getService(labels, client.GetNamespace(options.Namespace))
 	if err != nil {
 		return err
 	}
 
-	// update the relevant services
+	// update the relevant services, changing the template annotations
+	// triggers a rolling update of the deployment pods
 	for _, service := range services {
+		// skip services without a deployment
+		if service.kdeploy == nil {
+			continue
+		}
+
 		// nil check
 		if service.kdeploy.Metadata == nil || service.kdeploy.Metadata.Annotations == nil {
 			md := new(client.Metadata)
@@ -514,6 +515,9 @@ func (k *kubernetes) Update(s *runtime.Service, opts ...runtime.UpdateOption) er
 		}
 
 		// update build time annotation
+		if service.kdeploy.Spec.Template.Metadata.Annotations == nil {
+			service.kdeploy.Spec.Template.Metadata.Annotations = make(map[string]string)
+		}
 		service.kdeploy.Spec.Template.Metadata.Annotations["updated"] = fmt.Sprintf("%d", time.Now().Unix())
 
 		// update the service
@@ -7,11 +7,12 @@ package kubernetes
 import (
 	"bufio"
 	"strconv"
+	"sync"
 	"time"
 
+	"github.com/asim/go-micro/v3/logger"
 	"github.com/asim/go-micro/v3/runtime"
 	"github.com/asim/go-micro/v3/util/kubernetes/client"
-	"github.com/asim/go-micro/v3/util/log"
 )
 
 type klog struct {
@@ -20,9 +21,23 @@ type klog struct {
 	options     runtime.LogsOptions
 }
 
+// podLogStream sends the logs of a pod to the stream until they're read or the stream is stopped
 func (k *klog) podLogStream(podName string, stream *kubeStream) error {
 	p := make(map[string]string)
-	p["follow"] = "true"
+	// prefix the lines with the time so they can be filtered by since
+	p["timestamps"] = "true"
+
+	if k.options.Count > 0 {
+		p["tailLines"] = strconv.FormatInt(k.options.Count, 10)
+	}
+
+	if !k.options.Since.IsZero() {
+		p["sinceTime"] = k.options.Since.UTC().Format(time.RFC3339)
+	}
+
+	if k.options.Stream {
+		p["follow"] = "true"
+	}
 
 	opts := []client.LogOption{
 		client.LogParams(p),
@@ -34,33 +49,51 @@ func (k *klog) podLogStream(podName string, stream *kubeStream) error {
 		Name: podName,
 		Kind: "pod",
 	}, opts...)
-
 	if err != nil {
-		stream.err = err
-		stream.Stop()
 		return err
 	}
 
+	// close the body to unblock the scanner when stopped
+	done := make(chan bool)
+	defer close(done)
+
+	go func() {
+		select {
+		case <-stream.stop:
+		case <-done:
+		}
+		body.Close()
+	}()
+
 	s := bufio.NewScanner(body)
-	defer body.Close()
+	s.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), runtime.MaxLogLineSize)
+
+	for s.Scan() {
+		t, msg := runtime.ParseLogLine(s.Text())
+		// sinceTime is only precise to the second
+		if !k.options.Since.IsZero() && t.Before(k.options.Since) {
+			continue
+		}
+
+		record := runtime.LogRecord{
+			Timestamp: t,
+			Message:   msg,
+			Metadata:  map[string]string{"pod": podName},
+		}
 
-	for {
 		select {
+		case stream.stream <- record:
 		case <-stream.stop:
-			return stream.Error()
-		default:
-			if s.Scan() {
-				record := runtime.LogRecord{
-					Message: s.Text(),
-				}
-				stream.stream <- record
-			} else {
-				// TODO: is there a blocking call
-				// rather than a sleep loop?
-				time.Sleep(time.Second)
-			}
+			return nil
 		}
 	}
+
+	select {
+	case <-stream.stop:
+		return nil
+	default:
+		return s.Err()
+	}
 }
 
 func (k *klog) getMatchingPods() ([]string, error) {
@@ -96,61 +129,7 @@ func (k *klog) getMatchingPods() ([]string, error) {
 	return matches, nil
 }
 
-func (k *klog) Read() ([]runtime.LogRecord, error) {
-	pods, err := k.getMatchingPods()
-	if err != nil {
-		return nil, err
-	}
-
-	var records []runtime.LogRecord
-
-	for _, pod := range pods {
-		logParams := make(map[string]string)
-
-		//if !opts.Since.Equal(time.Time{}) {
-		//	logParams["sinceSeconds"] = strconv.Itoa(int(time.Since(opts.Since).Seconds()))
-		//}
-
-		if k.options.Count != 0 {
-			logParams["tailLines"] = strconv.Itoa(int(k.options.Count))
-		}
-
-		if k.options.Stream {
-			logParams["follow"] = "true"
-		}
-
-		opts := []client.LogOption{
-			client.LogParams(logParams),
-			client.LogNamespace(k.options.Namespace),
-		}
-
-		logs, err := k.client.Log(&client.Resource{
-			Name: pod,
-			Kind: "pod",
-		}, opts...)
-
-		if err != nil {
-			return nil, err
-		}
-		defer logs.Close()
-
-		s := bufio.NewScanner(logs)
-
-		for s.Scan() {
-			record := runtime.LogRecord{
-				Message: s.Text(),
-			}
-			// record.Metadata["pod"] = pod
-			records = append(records, record)
-		}
-	}
-
-	// sort the records
-	// sort.Slice(records, func(i, j int) bool { return records[i].Timestamp.Before(records[j].Timestamp) })
-
-	return records, nil
-}
-
+// Stream returns the logs of the pods running the service
 func (k *klog) Stream() (runtime.LogStream, error) {
 	// find the matching pods
 	pods, err := k.getMatchingPods()
@@ -163,16 +142,27 @@ func (k *klog) Stream() (runtime.LogStream, error) {
 		stop:   make(chan bool),
 	}
 
+	var wg sync.WaitGroup
+
 	// stream from the individual pods
 	for _, pod := range pods {
+		wg.Add(1)
 		go func(podName string) {
-			err := k.podLogStream(podName, stream)
-			if err != nil {
-				log.Errorf("Error streaming from pod: %v", err)
+			defer wg.Done()
+			if err := k.podLogStream(podName, stream); err != nil {
+				logger.Errorf("Error streaming from pod: %v", err)
+				stream.setError(err)
 			}
 		}(pod)
 	}
 
+	// close the stream once the logs of all pods are read
+	go func() {
+		wg.Wait()
+		stream.Stop()
+		close(stream.stream)
+	}()
+
 	return stream, nil
 }
 
@@ -2,10 +2,7 @@
 package docker
 
 import (
-	"archive/tar"
-	"bytes"
 	"io/ioutil"
-	"os"
 	"path/filepath"
 
 	"github.com/asim/go-micro/v3/logger"
@@ -21,44 +18,21 @@ type Builder struct {
 func (d *Builder) Build(s *build.Source) (*build.Package, error) {
 	image := filepath.Join(s.Repository.Path, s.Repository.Name)
 
-	buf := new(bytes.Buffer)
-	tw := tar.NewWriter(buf)
-	defer tw.Close()
+	// the repository is the build context
+	context := filepath.Join(s.Repository.Path, s.Repository.Name)
 
-	dockerFile := "Dockerfile"
-
-	// open docker file
-	f, err := os.Open(filepath.Join(s.Repository.Path, s.Repository.Name, dockerFile))
-	if err != nil {
-		return nil, err
-	}
-	// read docker file
-	by, err := ioutil.ReadAll(f)
-	if err != nil {
-		return nil, err
-	}
-
-	tarHeader := &tar.Header{
-		Name: dockerFile,
-		Size: int64(len(by)),
-	}
-	err = tw.WriteHeader(tarHeader)
-	if err != nil {
-		return nil, err
-	}
-	_, err = tw.Write(by)
-	if err != nil {
-		return nil, err
+	output := d.Options.Output
+	if output == nil {
+		output = ioutil.Discard
 	}
-	tr := bytes.NewReader(buf.Bytes())
 
-	err = d.Client.BuildImage(docker.BuildImageOptions{
+	err := d.Client.BuildImage(docker.BuildImageOptions{
 		Name:           image,
-		Dockerfile:     dockerFile,
-		InputStream:    tr,
-		OutputStream:   ioutil.Discard,
+		Dockerfile:     "Dockerfile",
+		ContextDir:     context,
+		OutputStream:   output,
 		RmTmpContainer: true,
-		SuppressOutput: true,
+		SuppressOutput: d.Options.Output == nil,
 	})
 	if err != nil {
 		return nil, err
@@ -72,8 +46,7 @@ func (d *Builder) Build(s *build.Source) (*build.Package, error) {
 }
 
 func (d *Builder) Clean(b *build.Package) error {
-	image := filepath.Join(b.Path, b.Name)
-	return d.Client.RemoveImage(image)
+	return d.Client.RemoveImage(b.Path)
 }
 
 func NewBuilder(opts ...build.Option) build.Builder {
@@ -35,10 +35,17 @@ func whichGo() string {
 }
 
 func (g *Builder) Build(s *build.Source) (*build.Package, error) {
-	binary := filepath.Join(g.Path, s.Repository.Name)
+	binary, err := filepath.Abs(filepath.Join(g.Path, s.Repository.Name))
+	if err != nil {
+		return nil, err
+	}
 	source := filepath.Join(s.Repository.Path, s.Repository.Name)
 
-	cmd := exec.Command(g.Cmd, "build", "-o", binary, source)
+	// build in the source dir so its go.mod is used
+	cmd := exec.Command(g.Cmd, "build", "-o", binary, ".")
+	cmd.Dir = source
+	cmd.Stdout = g.Options.Output
+	cmd.Stderr = g.Options.Output
 	if err := cmd.Run(); err != nil {
 		return nil, err
 	}
@@ -51,8 +58,7 @@ func (g *Builder) Build(s *build.Source) (*build.Package, error) {
 }
 
 func (g *Builder) Clean(b *build.Package) error {
-	binary := filepath.Join(b.Path, b.Name)
-	return os.Remove(binary)
+	return os.Remove(b.Path)
 }
 
 func NewBuild(opts ...build.Option) build.Builder {
@@ -1,8 +1,14 @@
 package build
 
+import (
+	"io"
+)
+
 type Options struct {
 	// local path to download source
 	Path string
+	// Output the build logs are written to
+	Output io.Writer
 }
 
 type Option func(o *Options)
@@ -13,3 +19,10 @@ func Path(p string) Option {
 		o.Path = p
 	}
 }
+
+// Output sets where the build logs are written
+func Output(w io.Writer) Option {
+	return func(o *Options) {
+		o.Output = w
+	}
+}
@@ -82,10 +82,19 @@ func (g libGitter) Checkout(repo, branchOrCommit string) error {
 		})
 	}
 
-	return worktree.Checkout(&git.CheckoutOptions{
+	err = worktree.Checkout(&git.CheckoutOptions{
 		Branch: plumbing.NewBranchReferenceName(branchOrCommit),
 		Force:  true,
 	})
+	if err != plumbing.ErrReferenceNotFound {
+		return err
+	}
+
+	// try a tag e.g v1.2.3
+	return worktree.Checkout(&git.CheckoutOptions{
+		Branch: plumbing.NewTagReferenceName(branchOrCommit),
+		Force:  true,
+	})
 }
 
 func (g libGitter) RepoDir(repo string) string {
@@ -323,6 +332,10 @@ func CheckoutSource(folder string, source *Source) error {
 	if err != nil {
 		return err
 	}
+	// fetch refs pushed since the repo was cloned
+	if err := gitter.FetchAll(repo); err != nil {
+		return err
+	}
 	source.FullPath = filepath.Join(gitter.RepoDir(source.Repo), source.Folder)
 	return gitter.Checkout(repo, source.Ref)
 }
@@ -0,0 +1,79 @@
+package os
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"github.com/asim/go-micro/v3/runtime/local/process"
+)
+
+// start starts the process with its limits set. The process is traced so it
+// stops at exec and the limits are set before any of its code runs, they're
+// inherited by any processes it forks e.g the binary started by go run.
+func start(cmd *exec.Cmd, exe *process.Executable) error {
+	if exe.CPU <= 0 && exe.Mem <= 0 {
+		return cmd.Start()
+	}
+
+	// ptrace requests must come from the thread which started the process
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	cmd.SysProcAttr.Ptrace = true
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	pid := cmd.Process.Pid
+
+	// wait for the process to stop at exec
+	var ws syscall.WaitStatus
+	if _, err := syscall.Wait4(pid, &ws, 0, nil); err != nil {
+		syscall.Kill(-pid, syscall.SIGKILL)
+		cmd.Wait()
+		return err
+	}
+	if !ws.Stopped() {
+		return errors.New("process exited before its limits were set")
+	}
+
+	err := setLimits(pid, exe)
+	if derr := syscall.PtraceDetach(pid); err == nil {
+		err = derr
+	}
+	if err != nil {
+		syscall.Kill(-pid, syscall.SIGKILL)
+		cmd.Wait()
+		return err
+	}
+
+	return nil
+}
+
+func setLimits(pid int, exe *process.Executable) error {
+	if exe.CPU > 0 {
+		// the process is sent SIGXCPU once it has used the cpu time
+		if err := prlimit(pid, syscall.RLIMIT_CPU, uint64(exe.CPU)); err != nil {
+			return err
+		}
+	}
+	if exe.Mem > 0 {
+		// this limits virtual memory, allocations fail once the address
+		// space of the process reaches it
+		if err := prlimit(pid, syscall.RLIMIT_AS, uint64(exe.Mem)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func prlimit(pid, resource int, limit uint64) error {
+	rlim := syscall.Rlimit{Cur: limit, Max: limit}
+	_, _, errno := syscall.RawSyscall6(syscall.SYS_PRLIMIT64, uintptr(pid), uintptr(resource), uintptr(unsafe.Pointer(&rlim)), 0, 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
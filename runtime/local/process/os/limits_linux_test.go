@@ -0,0 +1,38 @@
+package os
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/asim/go-micro/v3/runtime/local/build"
+	"github.com/asim/go-micro/v3/runtime/local/process"
+)
+
+func TestForkLimits(t *testing.T) {
+	p := new(Process)
+
+	// the limits are set before the shell runs so it reports them
+	pid, err := p.Fork(&process.Executable{
+		Package: &build.Package{Name: "sh", Path: "/bin/sh"},
+		Args:    []string{"-c", "ulimit -t; ulimit -v"},
+		CPU:     7,
+		Mem:     1 << 30,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadAll(pid.Output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Wait(pid); err != nil {
+		t.Fatal(err)
+	}
+
+	// ulimit reports virtual memory in kilobytes
+	if v := strings.Fields(string(b)); len(v) != 2 || v[0] != "7" || v[1] != "1048576" {
+		t.Fatalf("Expected the cpu and memory limits got %q", b)
+	}
+}
@@ -0,0 +1,21 @@
+// +build !linux,!windows
+
+package os
+
+import (
+	"os/exec"
+
+	"github.com/asim/go-micro/v3/runtime/local/process"
+)
+
+// start starts the process, ErrLimitsNotSupported is returned once it's
+// started if it has limits
+func start(cmd *exec.Cmd, exe *process.Executable) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if exe.CPU > 0 || exe.Mem > 0 {
+		return process.ErrLimitsNotSupported
+	}
+	return nil
+}
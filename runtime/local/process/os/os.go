@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"syscall"
 
+	"github.com/asim/go-micro/v3/logger"
 	"github.com/asim/go-micro/v3/runtime/local/process"
 )
 
@@ -44,8 +45,12 @@ func (p *Process) Fork(exe *process.Executable) (*process.PID, error) {
 	if err != nil {
 		return nil, err
 	}
-	// start the process
-	if err := cmd.Start(); err != nil {
+	// start the process, limiting its resources where the OS supports it
+	if err := start(cmd, exe); err == process.ErrLimitsNotSupported {
+		if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			logger.Warnf("Resource limits not supported, running %s without limits", exe.Package.Name)
+		}
+	} else if err != nil {
 		return nil, err
 	}
 
@@ -91,5 +96,8 @@ func (p *Process) Wait(pid *process.PID) error {
 		return nil
 	}
 
-	return fmt.Errorf(ps.String())
+	return &process.ExitError{
+		Code:   ps.ExitCode(),
+		Status: ps.String(),
+	}
 }
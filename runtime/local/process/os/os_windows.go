@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"strconv"
 
+	"github.com/asim/go-micro/v3/logger"
 	"github.com/asim/go-micro/v3/runtime/local/process"
 )
 
@@ -39,6 +40,12 @@ func (p *Process) Fork(exe *process.Executable) (*process.PID, error) {
 		return nil, err
 	}
 
+	if exe.CPU > 0 || exe.Mem > 0 {
+		if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+			logger.Warnf("Resource limits not supported, running %s without limits", exe.Package.Name)
+		}
+	}
+
 	return &process.PID{
 		ID:     fmt.Sprintf("%d", cmd.Process.Pid),
 		Input:  in,
@@ -85,5 +92,8 @@ func (p *Process) Wait(pid *process.PID) error {
 		return nil
 	}
 
-	return fmt.Errorf(ps.String())
+	return &process.ExitError{
+		Code:   ps.ExitCode(),
+		Status: ps.String(),
+	}
 }
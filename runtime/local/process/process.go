@@ -2,6 +2,7 @@
 package process
 
 import (
+	"errors"
 	"io"
 
 	"github.com/asim/go-micro/v3/runtime/local/build"
@@ -28,6 +29,10 @@ type Executable struct {
 	Args []string
 	// Initial working directory
 	Dir string
+	// Max cpu time in seconds, 0 is unlimited
+	CPU int
+	// Max virtual memory in bytes, 0 is unlimited
+	Mem int64
 }
 
 // PID is the running process
@@ -41,3 +46,18 @@ type PID struct {
 	// Stderr
 	Error io.Reader
 }
+
+// ErrLimitsNotSupported is returned when resource limits can't be applied on the OS
+var ErrLimitsNotSupported = errors.New("resource limits not supported")
+
+// ExitError is returned by Wait when the process exits unsuccessfully
+type ExitError struct {
+	// Code is the exit code or -1 if the process was killed by a signal
+	Code int
+	// Status describes the exit e.g exit status 1
+	Status string
+}
+
+func (e *ExitError) Error() string {
+	return e.Status
+}
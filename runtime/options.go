@@ -3,6 +3,7 @@ package runtime
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/asim/go-micro/v3/client"
 )
@@ -60,6 +61,27 @@ func WithClient(c client.Client) Option {
 
 type CreateOption func(o *CreateOptions)
 
+// RestartPolicy decides when a service which exited is restarted
+type RestartPolicy string
+
+const (
+	// RestartAlways restarts the service whenever it exits
+	RestartAlways RestartPolicy = "always"
+	// RestartOnFailure restarts the service only when it exits with an error
+	RestartOnFailure RestartPolicy = "on-failure"
+	// RestartNever leaves the service stopped once it exits
+	RestartNever RestartPolicy = "never"
+)
+
+// Resources limit the resources a service can use, zero values are unlimited
+type Resources struct {
+	// CPU is the max cpu time in seconds
+	CPU int
+	// Mem is the max virtual memory in bytes, it limits the address space
+	// of the process rather than its resident memory so leave headroom
+	Mem int64
+}
+
 type ReadOption func(o *ReadOptions)
 
 // CreateOptions configure runtime services
@@ -76,6 +98,10 @@ type CreateOptions struct {
 	Type string
 	// Retries before failing deploy
 	Retries int
+	// RestartPolicy of the service, defaults to always
+	RestartPolicy RestartPolicy
+	// Resources the service is limited to
+	Resources *Resources
 	// Specify the image to use
 	Image string
 	// Namespace to create the service in
@@ -149,6 +175,21 @@ func WithRetries(retries int) CreateOption {
 	}
 }
 
+// WithRestartPolicy sets when the service is restarted after it exits. Crashed
+// services are restarted up to the max retries with an exponential backoff.
+func WithRestartPolicy(p RestartPolicy) CreateOption {
+	return func(o *CreateOptions) {
+		o.RestartPolicy = p
+	}
+}
+
+// WithResources limits the resources of the service
+func WithResources(r *Resources) CreateOption {
+	return func(o *CreateOptions) {
+		o.Resources = r
+	}
+}
+
 // WithEnv sets the created service environment
 func WithEnv(env []string) CreateOption {
 	return func(o *CreateOptions) {
@@ -253,6 +294,8 @@ type LogsOptions struct {
 	Count int64
 	// Stream new lines?
 	Stream bool
+	// Since shows lines written after the time
+	Since time.Time
 	// Namespace the service is running in
 	Namespace string
 	// Specify the context to use
@@ -273,6 +316,13 @@ func LogsStream(stream bool) LogsOption {
 	}
 }
 
+// LogsSince shows the lines written after the given time
+func LogsSince(t time.Time) LogsOption {
+	return func(l *LogsOptions) {
+		l.Since = t
+	}
+}
+
 // LogsNamespace sets the namespace
 func LogsNamespace(ns string) LogsOption {
 	return func(o *LogsOptions) {
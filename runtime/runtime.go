@@ -3,6 +3,7 @@ package runtime
 
 import (
 	"errors"
+	"strings"
 	"time"
 )
 
@@ -11,6 +12,8 @@ var (
 	DefaultRuntime Runtime = NewRuntime()
 	// DefaultName is default runtime service name
 	DefaultName = "go.micro.runtime"
+	// MaxLogLineSize is the longest log line read, longer lines fail the stream
+	MaxLogLineSize = 1024 * 1024
 
 	ErrAlreadyExists = errors.New("already exists")
 )
@@ -45,8 +48,25 @@ type LogStream interface {
 }
 
 type LogRecord struct {
-	Message  string
-	Metadata map[string]string
+	// Timestamp of the record, zero if unknown
+	Timestamp time.Time
+	Message   string
+	Metadata  map[string]string
+}
+
+// ParseLogLine parses a log line prefixed with a RFC3339 timestamp as written
+// by the local runtime, docker and kubernetes. Lines without a timestamp are
+// returned as the message with a zero timestamp.
+func ParseLogLine(line string) (time.Time, string) {
+	idx := strings.IndexByte(line, ' ')
+	if idx < 0 {
+		return time.Time{}, line
+	}
+	t, err := time.Parse(time.RFC3339Nano, line[:idx])
+	if err != nil {
+		return time.Time{}, line
+	}
+	return t, line[idx+1:]
 }
 
 // Scheduler is a runtime service scheduler
@@ -14,6 +14,16 @@ import (
 	proc "github.com/asim/go-micro/v3/runtime/local/process/os"
 )
 
+var (
+	// DefaultBackoff is the delay before restarting a service after its first crash,
+	// the delay is doubled for every crash after up to MaxBackoff
+	DefaultBackoff = time.Second
+	// MaxBackoff is the max delay before restarting a crashed service
+	MaxBackoff = 5 * time.Minute
+	// BackoffReset is how long a service runs before its crashes are forgotten
+	BackoffReset = 10 * time.Minute
+)
+
 type service struct {
 	sync.RWMutex
 
@@ -25,8 +35,19 @@ type service struct {
 	retries    int
 	maxRetries int
 
+	// restart policy of the service
+	policy RestartPolicy
+	// exited is set once the service exits and shouldn't be restarted
+	exited bool
+	// started is when the service was last started
+	started time.Time
+	// restartAt is when the crashed service can be restarted
+	restartAt time.Time
+
 	// output for logs
 	output io.Writer
+	// built is set if the binary was built from source
+	built bool
 
 	// service to manage
 	*Service
@@ -46,6 +67,18 @@ func newService(s *Service, c CreateOptions) *service {
 	exec = strings.Join(c.Command, " ")
 	args = c.Args
 
+	policy := c.RestartPolicy
+	if len(policy) == 0 {
+		policy = RestartAlways
+	}
+
+	var cpu int
+	var mem int64
+	if c.Resources != nil {
+		cpu = c.Resources.CPU
+		mem = c.Resources.Mem
+	}
+
 	return &service{
 		Service: s,
 		Process: new(proc.Process),
@@ -57,11 +90,14 @@ func newService(s *Service, c CreateOptions) *service {
 			Env:  c.Env,
 			Args: args,
 			Dir:  s.Source,
+			CPU:  cpu,
+			Mem:  mem,
 		},
 		closed:     make(chan bool),
 		output:     c.Output,
 		updated:    time.Now(),
 		maxRetries: c.Retries,
+		policy:     policy,
 	}
 }
 
@@ -71,12 +107,30 @@ func (s *service) streamOutput() {
 }
 
 func (s *service) shouldStart() bool {
-	if s.running {
+	if s.running || s.exited {
+		return false
+	}
+	if time.Now().Before(s.restartAt) {
 		return false
 	}
 	return s.retries <= s.maxRetries
 }
 
+// backoff returns the delay before restarting after the given number of crashes
+func backoff(retries int) time.Duration {
+	if retries <= 0 {
+		return 0
+	}
+	d := DefaultBackoff
+	for i := 1; i < retries; i++ {
+		d *= 2
+		if d >= MaxBackoff {
+			return MaxBackoff
+		}
+	}
+	return d
+}
+
 func (s *service) key() string {
 	return fmt.Sprintf("%v:%v", s.Name, s.Version)
 }
@@ -102,10 +156,9 @@ func (s *service) Start() error {
 		return nil
 	}
 
-	// reset
+	// reset, the retries are kept so crashes back off
 	s.err = nil
 	s.closed = make(chan bool)
-	s.retries = 0
 
 	if s.Metadata == nil {
 		s.Metadata = make(map[string]string)
@@ -129,7 +182,10 @@ func (s *service) Start() error {
 	// set status
 	s.Status("running", nil)
 	// set started
-	s.Metadata["started"] = time.Now().Format(time.RFC3339)
+	s.started = time.Now()
+	s.Metadata["started"] = s.started.Format(time.RFC3339)
+	delete(s.Metadata, "exitCode")
+	delete(s.Metadata, "restartAt")
 
 	if s.output != nil {
 		s.streamOutput()
@@ -165,6 +221,8 @@ func (s *service) Stop() error {
 		close(s.closed)
 		s.running = false
 		s.retries = 0
+		s.exited = false
+		s.restartAt = time.Time{}
 		if s.PID == nil {
 			return nil
 		}
@@ -211,6 +269,28 @@ func (s *service) Wait() {
 		return
 	}
 
+	// stopped by the runtime so leave the status as is
+	select {
+	case <-s.closed:
+		return
+	default:
+	}
+
+	// forget previous crashes if the service ran long enough
+	if time.Since(s.started) > BackoffReset {
+		s.retries = 0
+	}
+
+	// no longer running
+	s.running = false
+
+	// save the exit code
+	if ee, ok := err.(*process.ExitError); ok {
+		s.Metadata["exitCode"] = strconv.Itoa(ee.Code)
+	} else if err == nil {
+		s.Metadata["exitCode"] = "0"
+	}
+
 	// save the error
 	if err != nil {
 		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
@@ -225,6 +305,14 @@ func (s *service) Wait() {
 		s.Status("done", nil)
 	}
 
-	// no longer running
-	s.running = false
+	switch {
+	case s.policy == RestartNever, s.policy == RestartOnFailure && err == nil:
+		s.exited = true
+	case err != nil:
+		// back off before restarting a crashed service
+		s.restartAt = time.Now().Add(backoff(s.retries))
+		if s.retries <= s.maxRetries {
+			s.Metadata["restartAt"] = s.restartAt.Format(time.RFC3339)
+		}
+	}
 }
@@ -0,0 +1,26 @@
+package runtime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	testCases := []struct {
+		retries int
+		expect  time.Duration
+	}{
+		{0, 0},
+		{1, DefaultBackoff},
+		{2, DefaultBackoff * 2},
+		{3, DefaultBackoff * 4},
+		{10, MaxBackoff},
+		{100, MaxBackoff},
+	}
+
+	for _, test := range testCases {
+		if d := backoff(test.retries); d != test.expect {
+			t.Fatalf("Expected backoff %v for %d retries got: %v", test.expect, test.retries, d)
+		}
+	}
+}
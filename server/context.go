@@ -7,6 +7,8 @@ import (
 
 type serverKey struct{}
 
+type peerKey struct{}
+
 func wait(ctx context.Context) *sync.WaitGroup {
 	if ctx == nil {
 		return nil
@@ -26,3 +28,16 @@ func FromContext(ctx context.Context) (Server, bool) {
 func NewContext(ctx context.Context, s Server) context.Context {
 	return context.WithValue(ctx, serverKey{}, s)
 }
+
+// PeerFromContext returns the name of the peer which made the request, as
+// verified by the transport from its tls certificate
+func PeerFromContext(ctx context.Context) (string, bool) {
+	p, ok := ctx.Value(peerKey{}).(string)
+	return p, ok
+}
+
+// NewPeerContext sets the name of the verified peer, it's only to be set
+// by servers
+func NewPeerContext(ctx context.Context, peer string) context.Context {
+	return context.WithValue(ctx, peerKey{}, peer)
+}
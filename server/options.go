@@ -9,6 +9,7 @@ import (
 	"github.com/asim/go-micro/v3/broker"
 	"github.com/asim/go-micro/v3/codec"
 	"github.com/asim/go-micro/v3/debug/trace"
+	"github.com/asim/go-micro/v3/logger"
 	"github.com/asim/go-micro/v3/registry"
 	"github.com/asim/go-micro/v3/transport"
 )
@@ -41,6 +42,12 @@ type Options struct {
 	// TLSConfig specifies tls.Config for secure serving
 	TLSConfig *tls.Config
 
+	// ListenOptions such as the connection limit of the listener
+	ListenOptions []transport.ListenOption
+
+	// Logger of the server, the logger.DefaultLogger if nil
+	Logger logger.Logger
+
 	// Other options for implementations of the interface
 	// can be stored in a context
 	Context context.Context
@@ -165,6 +172,13 @@ func Tracer(t trace.Tracer) Option {
 	}
 }
 
+// Logger to log with
+func Logger(l logger.Logger) Option {
+	return func(o *Options) {
+		o.Logger = l
+	}
+}
+
 // Transport mechanism for communication e.g http, rabbitmq, etc
 func Transport(t transport.Transport) Option {
 	return func(o *Options) {
@@ -172,6 +186,15 @@ func Transport(t transport.Transport) Option {
 	}
 }
 
+// ListenOptions adds options of the transport listener e.g
+//
+//	server.ListenOptions(transport.MaxConnections(1024))
+func ListenOptions(opts ...transport.ListenOption) Option {
+	return func(o *Options) {
+		o.ListenOptions = append(o.ListenOptions, opts...)
+	}
+}
+
 // Metadata associated with the server
 func Metadata(md map[string]string) Option {
 	return func(o *Options) {
@@ -74,6 +74,8 @@ type router struct {
 	hdlrWrappers []HandlerWrapper
 	// subscriber wrappers
 	subWrappers []SubscriberWrapper
+	// logger of the server
+	logger logger.Logger
 
 	su          sync.RWMutex
 	subscribers map[string][]*subscriber
@@ -118,7 +120,7 @@ func isExportedOrBuiltinType(t reflect.Type) bool {
 
 // prepareMethod returns a methodType for the provided method or nil
 // in case if the method was unsuitable.
-func prepareMethod(method reflect.Method) *methodType {
+func prepareMethod(method reflect.Method, log *logger.Helper) *methodType {
 	mtype := method.Type
 	mname := method.Name
 	var replyType, argType, contextType reflect.Type
@@ -141,7 +143,7 @@ func prepareMethod(method reflect.Method) *methodType {
 		replyType = mtype.In(3)
 		contextType = mtype.In(1)
 	default:
-		logger.Errorf("method %v of %v has wrong number of ins: %v", mname, mtype, mtype.NumIn())
+		log.Errorf("method %v of %v has wrong number of ins: %v", mname, mtype, mtype.NumIn())
 		return nil
 	}
 
@@ -149,7 +151,7 @@ func prepareMethod(method reflect.Method) *methodType {
 		// check stream type
 		streamType := reflect.TypeOf((*Stream)(nil)).Elem()
 		if !argType.Implements(streamType) {
-			logger.Errorf("%v argument does not implement Stream interface: %v", mname, argType)
+			log.Errorf("%v argument does not implement Stream interface: %v", mname, argType)
 			return nil
 		}
 	} else {
@@ -157,30 +159,30 @@ func prepareMethod(method reflect.Method) *methodType {
 
 		// First arg need not be a pointer.
 		if !isExportedOrBuiltinType(argType) {
-			logger.Errorf("%v argument type not exported: %v", mname, argType)
+			log.Errorf("%v argument type not exported: %v", mname, argType)
 			return nil
 		}
 
 		if replyType.Kind() != reflect.Ptr {
-			logger.Errorf("method %v reply type not a pointer: %v", mname, replyType)
+			log.Errorf("method %v reply type not a pointer: %v", mname, replyType)
 			return nil
 		}
 
 		// Reply type must be exported.
 		if !isExportedOrBuiltinType(replyType) {
-			logger.Errorf("method %v reply type not exported: %v", mname, replyType)
+			log.Errorf("method %v reply type not exported: %v", mname, replyType)
 			return nil
 		}
 	}
 
 	// Method needs one out.
 	if mtype.NumOut() != 1 {
-		logger.Errorf("method %v has wrong number of outs: %v", mname, mtype.NumOut())
+		log.Errorf("method %v has wrong number of outs: %v", mname, mtype.NumOut())
 		return nil
 	}
 	// The return type of the method must be error.
 	if returnType := mtype.Out(0); returnType != typeOfError {
-		logger.Errorf("method %v returns %v not error", mname, returnType.String())
+		log.Errorf("method %v returns %v not error", mname, returnType.String())
 		return nil
 	}
 	return &methodType{method: method, ArgType: argType, ReplyType: replyType, ContextType: contextType, stream: stream}
@@ -446,7 +448,7 @@ func (router *router) Handle(h Handler) error {
 	// Install the methods
 	for m := 0; m < s.typ.NumMethod(); m++ {
 		method := s.typ.Method(m)
-		if mt := prepareMethod(method); mt != nil {
+		if mt := prepareMethod(method, logger.NewHelper(router.logger)); mt != nil {
 			s.method[method.Name] = mt
 		}
 	}
@@ -509,8 +511,9 @@ func (router *router) ProcessMessage(ctx context.Context, msg Message) (err erro
 	defer func() {
 		// recover any panics
 		if r := recover(); r != nil {
-			logger.Errorf("panic recovered: %v", r)
-			logger.Error(string(debug.Stack()))
+			log := logger.NewHelper(router.logger).WithContext(ctx)
+			log.Errorf("panic recovered: %v", r)
+			log.Error(string(debug.Stack()))
 			err = merrors.InternalServerError("go.micro.server", "panic recovered: %v", r)
 		}
 	}()
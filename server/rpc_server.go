@@ -2,19 +2,20 @@ package server
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"net"
 	"runtime/debug"
 	"sort"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/asim/go-micro/v3/broker"
 	"github.com/asim/go-micro/v3/codec"
 	raw "github.com/asim/go-micro/v3/codec/bytes"
+	"github.com/asim/go-micro/v3/errors"
 	"github.com/asim/go-micro/v3/logger"
 	"github.com/asim/go-micro/v3/metadata"
 	"github.com/asim/go-micro/v3/registry"
@@ -50,6 +51,7 @@ func newRpcServer(opts ...Option) Server {
 	router := newRpcRouter()
 	router.hdlrWrappers = options.HdlrWrappers
 	router.subWrappers = options.SubWrappers
+	router.logger = options.Logger
 
 	return &rpcServer{
 		opts:        options,
@@ -96,6 +98,10 @@ func (s *rpcServer) HandleEvent(e broker.Event) error {
 	// create context
 	ctx := metadata.NewContext(context.Background(), hdr)
 
+	// apply the deadline if the publisher set one
+	ctx, cancel := metadata.WithDeadline(ctx)
+	defer cancel()
+
 	// TODO: inspect message header
 	// Micro-Service means a request
 	// Micro-Topic means a message
@@ -129,6 +135,25 @@ func (s *rpcServer) HandleEvent(e broker.Event) error {
 	return r.ProcessMessage(ctx, rpcMsg)
 }
 
+// tooLarge replies with an error to a request whose body was too large, the
+// headers of the request are still received
+func (s *rpcServer) tooLarge(sock transport.Socket, msg *transport.Message, err error) {
+	if msg.Header == nil {
+		return
+	}
+
+	hdr := map[string]string{
+		"Micro-Error": errors.RequestEntityTooLarge(s.opts.Name, err.Error()).Error(),
+	}
+	for _, k := range []string{"Content-Type", "Micro-Id", "Micro-Service", "Micro-Endpoint", "Micro-Stream"} {
+		if v, ok := msg.Header[k]; ok {
+			hdr[k] = v
+		}
+	}
+
+	sock.Send(&transport.Message{Header: hdr})
+}
+
 // ServeConn serves a single connection
 func (s *rpcServer) ServeConn(sock transport.Socket) {
 	// global error tracking
@@ -139,6 +164,7 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 	// get global waitgroup
 	s.Lock()
 	gg := s.wg
+	log := logger.NewHelper(s.opts.Logger)
 	s.Unlock()
 
 	// waitgroup to wait for processing to finish
@@ -161,9 +187,9 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 
 		// recover any panics
 		if r := recover(); r != nil {
-			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-				logger.Error("panic recovered: ", r)
-				logger.Error(string(debug.Stack()))
+			if logger.V(logger.ErrorLevel, log) {
+				log.Error("panic recovered: ", r)
+				log.Error(string(debug.Stack()))
 			}
 		}
 	}()
@@ -172,6 +198,10 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 		var msg transport.Message
 		// process inbound messages one at a time
 		if err := sock.Recv(&msg); err != nil {
+			// let the client know the request was too large
+			if stderrors.Is(err, transport.ErrMessageTooLarge) {
+				s.tooLarge(sock, &msg, err)
+			}
 			// set a global error and return
 			// we're saying we essentially can't
 			// use the socket anymore
@@ -279,6 +309,11 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 		// create new context with the metadata
 		ctx := metadata.NewContext(context.Background(), hdr)
 
+		// set the peer verified by the transport
+		if p, ok := sock.(transport.PeerSocket); ok && len(p.Peer()) > 0 {
+			ctx = NewPeerContext(ctx, p.Peer())
+		}
+
 		// set the timeout from the header if we have it
 		if len(to) > 0 {
 			if n, err := strconv.ParseUint(to, 10, 64); err == nil {
@@ -383,9 +418,9 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 
 				// recover any panics for outbound process
 				if r := recover(); r != nil {
-					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-						logger.Error("panic recovered: ", r)
-						logger.Error(string(debug.Stack()))
+					if logger.V(logger.ErrorLevel, log) {
+						log.Error("panic recovered: ", r)
+						log.Error(string(debug.Stack()))
 					}
 				}
 			}()
@@ -406,6 +441,9 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 
 		// serve the request in a go routine as this may be a stream
 		go func(id string, psock *socket.Socket) {
+			// log with the ids of the request
+			log := log.WithContext(ctx)
+
 			defer func() {
 				// release the socket
 				pool.Release(psock)
@@ -414,8 +452,8 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 
 				// recover any panics for call handler
 				if r := recover(); r != nil {
-					logger.Error("panic recovered: ", r)
-					logger.Error(string(debug.Stack()))
+					log.Error("panic recovered: ", r)
+					log.Error(string(debug.Stack()))
 				}
 			}()
 
@@ -434,7 +472,7 @@ func (s *rpcServer) ServeConn(sock transport.Socket) {
 
 				// could not write error response
 				if writeError != nil && !alreadyClosed {
-					logger.Debugf("rpc: unable to write error response: %v", writeError)
+					log.Debugf("rpc: unable to write error response: %v", writeError)
 				}
 			}
 		}(id, psock)
@@ -471,6 +509,7 @@ func (s *rpcServer) Init(opts ...Option) error {
 		r.hdlrWrappers = s.opts.HdlrWrappers
 		r.serviceMap = s.router.serviceMap
 		r.subWrappers = s.opts.SubWrappers
+		r.logger = s.opts.Logger
 		s.router = r
 	}
 
@@ -518,6 +557,8 @@ func (s *rpcServer) Register() error {
 	config := s.Options()
 	s.RUnlock()
 
+	log := logger.NewHelper(config.Logger)
+
 	regFunc := func(service *registry.Service) error {
 		// create registry options
 		rOpts := []registry.RegisterOption{registry.RegisterTTL(config.RegisterTTL)}
@@ -562,14 +603,10 @@ func (s *rpcServer) Register() error {
 		advt = config.Address
 	}
 
-	if cnt := strings.Count(advt, ":"); cnt >= 1 {
-		// ipv6 address in format [host]:port or ipv4 host:port
-		host, port, err = net.SplitHostPort(advt)
-		if err != nil {
-			return err
-		}
-	} else {
-		host = advt
+	// ipv6 address in format [host]:port, a bare ipv6 address or ipv4 host:port
+	host, port, err = mnet.SplitHostPort(advt)
+	if err != nil {
+		return err
 	}
 
 	if ip := net.ParseIP(host); ip != nil {
@@ -650,8 +687,8 @@ func (s *rpcServer) Register() error {
 	s.RUnlock()
 
 	if !registered {
-		if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-			logger.Infof("Registry [%s] Registering node: %s", config.Registry.String(), node.Id)
+		if logger.V(logger.InfoLevel, log) {
+			log.Infof("Registry [%s] Registering node: %s", config.Registry.String(), node.Id)
 		}
 	}
 
@@ -702,8 +739,8 @@ func (s *rpcServer) Register() error {
 		if err != nil {
 			return err
 		}
-		if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-			logger.Infof("Subscribing to topic: %s", sub.Topic())
+		if logger.V(logger.InfoLevel, log) {
+			log.Infof("Subscribing to topic: %s", sub.Topic())
 		}
 		s.subscribers[sb] = []broker.Subscriber{sub}
 	}
@@ -723,6 +760,8 @@ func (s *rpcServer) Deregister() error {
 	config := s.Options()
 	s.RUnlock()
 
+	log := logger.NewHelper(config.Logger)
+
 	// check the advertise address first
 	// if it exists then use it, otherwise
 	// use the address
@@ -732,14 +771,10 @@ func (s *rpcServer) Deregister() error {
 		advt = config.Address
 	}
 
-	if cnt := strings.Count(advt, ":"); cnt >= 1 {
-		// ipv6 address in format [host]:port or ipv4 host:port
-		host, port, err = net.SplitHostPort(advt)
-		if err != nil {
-			return err
-		}
-	} else {
-		host = advt
+	// ipv6 address in format [host]:port, a bare ipv6 address or ipv4 host:port
+	host, port, err = mnet.SplitHostPort(advt)
+	if err != nil {
+		return err
 	}
 
 	addr, err := addr.Extract(host)
@@ -763,8 +798,8 @@ func (s *rpcServer) Deregister() error {
 		Nodes:   []*registry.Node{node},
 	}
 
-	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-		logger.Infof("Registry [%s] Deregistering node: %s", config.Registry.String(), node.Id)
+	if logger.V(logger.InfoLevel, log) {
+		log.Infof("Registry [%s] Deregistering node: %s", config.Registry.String(), node.Id)
 	}
 	if err := config.Registry.Deregister(service); err != nil {
 		return err
@@ -788,8 +823,8 @@ func (s *rpcServer) Deregister() error {
 
 	for sb, subs := range s.subscribers {
 		for _, sub := range subs {
-			if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-				logger.Infof("Unsubscribing %s from topic: %s", node.Id, sub.Topic())
+			if logger.V(logger.InfoLevel, log) {
+				log.Infof("Unsubscribing %s from topic: %s", node.Id, sub.Topic())
 			}
 			sub.Unsubscribe()
 		}
@@ -809,15 +844,16 @@ func (s *rpcServer) Start() error {
 	s.RUnlock()
 
 	config := s.Options()
+	log := logger.NewHelper(config.Logger)
 
 	// start listening on the transport
-	ts, err := config.Transport.Listen(config.Address)
+	ts, err := config.Transport.Listen(config.Address, config.ListenOptions...)
 	if err != nil {
 		return err
 	}
 
-	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-		logger.Infof("Transport [%s] Listening on %s", config.Transport.String(), ts.Addr())
+	if logger.V(logger.InfoLevel, log) {
+		log.Infof("Transport [%s] Listening on %s", config.Transport.String(), ts.Addr())
 	}
 
 	// swap address
@@ -830,26 +866,26 @@ func (s *rpcServer) Start() error {
 
 	// connect to the broker
 	if err := config.Broker.Connect(); err != nil {
-		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-			logger.Errorf("Broker [%s] connect error: %v", bname, err)
+		if logger.V(logger.ErrorLevel, log) {
+			log.Errorf("Broker [%s] connect error: %v", bname, err)
 		}
 		return err
 	}
 
-	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-		logger.Infof("Broker [%s] Connected to %s", bname, config.Broker.Address())
+	if logger.V(logger.InfoLevel, log) {
+		log.Infof("Broker [%s] Connected to %s", bname, config.Broker.Address())
 	}
 
 	// use RegisterCheck func before register
 	if err = s.opts.RegisterCheck(s.opts.Context); err != nil {
-		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-			logger.Errorf("Server %s-%s register check error: %s", config.Name, config.Id, err)
+		if logger.V(logger.ErrorLevel, log) {
+			log.Errorf("Server %s-%s register check error: %s", config.Name, config.Id, err)
 		}
 	} else {
 		// announce self to the world
 		if err = s.Register(); err != nil {
-			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-				logger.Errorf("Server %s-%s register error: %s", config.Name, config.Id, err)
+			if logger.V(logger.ErrorLevel, log) {
+				log.Errorf("Server %s-%s register error: %s", config.Name, config.Id, err)
 			}
 		}
 	}
@@ -871,8 +907,8 @@ func (s *rpcServer) Start() error {
 			// check the error and backoff
 			default:
 				if err != nil {
-					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-						logger.Errorf("Accept error: %v", err)
+					if logger.V(logger.ErrorLevel, log) {
+						log.Errorf("Accept error: %v", err)
 					}
 					time.Sleep(time.Second)
 					continue
@@ -906,24 +942,24 @@ func (s *rpcServer) Start() error {
 				s.RUnlock()
 				rerr := s.opts.RegisterCheck(s.opts.Context)
 				if rerr != nil && registered {
-					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-						logger.Errorf("Server %s-%s register check error: %s, deregister it", config.Name, config.Id, err)
+					if logger.V(logger.ErrorLevel, log) {
+						log.Errorf("Server %s-%s register check error: %s, deregister it", config.Name, config.Id, err)
 					}
 					// deregister self in case of error
 					if err := s.Deregister(); err != nil {
-						if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-							logger.Errorf("Server %s-%s deregister error: %s", config.Name, config.Id, err)
+						if logger.V(logger.ErrorLevel, log) {
+							log.Errorf("Server %s-%s deregister error: %s", config.Name, config.Id, err)
 						}
 					}
 				} else if rerr != nil && !registered {
-					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-						logger.Errorf("Server %s-%s register check error: %s", config.Name, config.Id, err)
+					if logger.V(logger.ErrorLevel, log) {
+						log.Errorf("Server %s-%s register check error: %s", config.Name, config.Id, err)
 					}
 					continue
 				}
 				if err := s.Register(); err != nil {
-					if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-						logger.Errorf("Server %s-%s register error: %s", config.Name, config.Id, err)
+					if logger.V(logger.ErrorLevel, log) {
+						log.Errorf("Server %s-%s register error: %s", config.Name, config.Id, err)
 					}
 				}
 			// wait for exit
@@ -940,8 +976,8 @@ func (s *rpcServer) Start() error {
 		if registered {
 			// deregister self
 			if err := s.Deregister(); err != nil {
-				if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-					logger.Errorf("Server %s-%s deregister error: %s", config.Name, config.Id, err)
+				if logger.V(logger.ErrorLevel, log) {
+					log.Errorf("Server %s-%s deregister error: %s", config.Name, config.Id, err)
 				}
 			}
 		}
@@ -958,13 +994,13 @@ func (s *rpcServer) Start() error {
 		// close transport listener
 		ch <- ts.Close()
 
-		if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-			logger.Infof("Broker [%s] Disconnected from %s", bname, config.Broker.Address())
+		if logger.V(logger.InfoLevel, log) {
+			log.Infof("Broker [%s] Disconnected from %s", bname, config.Broker.Address())
 		}
 		// disconnect the broker
 		if err := config.Broker.Disconnect(); err != nil {
-			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
-				logger.Errorf("Broker [%s] Disconnect error: %v", bname, err)
+			if logger.V(logger.ErrorLevel, log) {
+				log.Errorf("Broker [%s] Disconnect error: %v", bname, err)
 			}
 		}
 
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/metadata"
+	"github.com/asim/go-micro/v3/transport"
+)
+
+type PeerRequest struct{}
+
+type PeerResponse struct {
+	Peer string
+}
+
+type Peers struct{}
+
+func (h *Peers) Peer(ctx context.Context, req *PeerRequest, rsp *PeerResponse) error {
+	rsp.Peer, _ = PeerFromContext(ctx)
+	return nil
+}
+
+func TestRpcServerPeerHeader(t *testing.T) {
+	tr := transport.NewMemoryTransport()
+
+	srv := newRpcServer(Name("test"), Transport(tr))
+	if err := srv.Handle(srv.NewHandler(&Peers{})); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := tr.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go l.Accept(srv.(*rpcServer).ServeConn)
+
+	c := client.NewClient(client.Transport(tr))
+
+	// a peer can't claim to be verified with a header
+	ctx := metadata.NewContext(context.Background(), map[string]string{"Micro-Peer": "forged"})
+
+	req := c.NewRequest("test", "Peers.Peer", &PeerRequest{}, client.WithContentType("application/json"))
+	rsp := new(PeerResponse)
+	if err := c.Call(ctx, req, rsp, client.WithAddress(l.Addr())); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rsp.Peer) > 0 {
+		t.Fatalf("Expected no peer got %s", rsp.Peer)
+	}
+}
@@ -211,8 +211,9 @@ func Run() error {
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, signalutil.Shutdown()...)
 
-	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-		logger.Infof("Received signal %s", <-ch)
+	log := logger.NewHelper(DefaultServer.Options().Logger)
+	if logger.V(logger.InfoLevel, log) {
+		log.Infof("Received signal %s", <-ch)
 	}
 	return Stop()
 }
@@ -220,16 +221,18 @@ func Run() error {
 // Start starts the default server
 func Start() error {
 	config := DefaultServer.Options()
-	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-		logger.Infof("Starting server %s id %s", config.Name, config.Id)
+	log := logger.NewHelper(config.Logger)
+	if logger.V(logger.InfoLevel, log) {
+		log.Infof("Starting server %s id %s", config.Name, config.Id)
 	}
 	return DefaultServer.Start()
 }
 
 // Stop stops the default server
 func Stop() error {
-	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-		logger.Infof("Stopping server")
+	log := logger.NewHelper(DefaultServer.Options().Logger)
+	if logger.V(logger.InfoLevel, log) {
+		log.Infof("Stopping server")
 	}
 	return DefaultServer.Stop()
 }
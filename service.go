@@ -7,6 +7,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/asim/go-micro/v3/auth"
 	"github.com/asim/go-micro/v3/client"
 	"github.com/asim/go-micro/v3/cmd"
 	"github.com/asim/go-micro/v3/debug/handler"
@@ -16,6 +17,7 @@ import (
 	"github.com/asim/go-micro/v3/plugins"
 	"github.com/asim/go-micro/v3/server"
 	"github.com/asim/go-micro/v3/store"
+	authutil "github.com/asim/go-micro/v3/util/auth"
 	signalutil "github.com/asim/go-micro/v3/util/signal"
 	"github.com/asim/go-micro/v3/util/wrapper"
 )
@@ -33,14 +35,22 @@ func newService(opts ...Option) Service {
 	// service name
 	serviceName := options.Server.Options().Name
 
-	// wrap client to inject From-Service header on any calls
-	options.Client = wrapper.FromService(serviceName, options.Client)
+	// auth is set on init so the wrappers get it when called
+	authFn := func() auth.Auth { return service.opts.Auth }
+
+	// wrap client to pass on the caller, deadline and metadata on any calls
+	options.Client = wrapper.Propagate(serviceName, options.Client)
 	options.Client = wrapper.TraceCall(serviceName, trace.DefaultTracer, options.Client)
+	options.Client = wrapper.AuthClient(authFn, options.Client)
 
 	// wrap the server to provide handler stats
 	err := options.Server.Init(
 		server.WrapHandler(wrapper.HandlerStats(stats.DefaultStats)),
 		server.WrapHandler(wrapper.TraceHandler(trace.DefaultTracer)),
+		server.WrapHandler(wrapper.AuthHandler(authFn)),
+		server.WrapHandler(wrapper.PropagateHandler()),
+		server.WrapSubscriber(wrapper.AuthSubscriber(authFn)),
+		server.WrapSubscriber(wrapper.PropagateSubscriber()),
 	)
 	if err != nil {
 		logger.Fatal(err)
@@ -137,6 +147,15 @@ func (s *service) Start() error {
 		}
 	}
 
+	// generate the account used to authenticate the service, without it
+	// requests are made without a service token
+	if err := authutil.Generate(s.opts.Server.Options().Id, s.Name(), s.opts.Auth); err != nil {
+		log := logger.NewHelper(s.opts.Server.Options().Logger)
+		if logger.V(logger.WarnLevel, log) {
+			log.Warnf("Unable to generate auth account for %s: %v", s.Name(), err)
+		}
+	}
+
 	if err := s.opts.Server.Start(); err != nil {
 		return err
 	}
@@ -177,6 +196,8 @@ func (s *service) Run() (err error) {
 		),
 	)
 
+	log := logger.NewHelper(s.opts.Server.Options().Logger)
+
 	// start the profiler
 	if s.opts.Profile != nil {
 		// to view mutex contention
@@ -190,13 +211,13 @@ func (s *service) Run() (err error) {
 		defer func() {
 			err = s.opts.Profile.Stop()
 			if err != nil {
-				logger.Error(err)
+				log.Error(err)
 			}
 		}()
 	}
 
-	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
-		logger.Infof("Starting [service] %s", s.Name())
+	if logger.V(logger.InfoLevel, log) {
+		log.Infof("Starting [service] %s", s.Name())
 	}
 
 	if err = s.Start(); err != nil {
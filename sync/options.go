@@ -31,3 +31,10 @@ func LockWait(t time.Duration) LockOption {
 		o.Wait = t
 	}
 }
+
+// BarrierWait sets the time to wait for all participants to arrive
+func BarrierWait(t time.Duration) BarrierOption {
+	return func(o *BarrierOptions) {
+		o.Wait = t
+	}
+}
@@ -7,7 +7,11 @@ import (
 )
 
 var (
-	ErrLockTimeout = errors.New("lock timeout")
+	ErrLockTimeout    = errors.New("lock timeout")
+	ErrBarrierTimeout = errors.New("barrier timeout")
+	// ErrBarrierMismatch is returned when participants of a barrier
+	// disagree on the number of participants
+	ErrBarrierMismatch = errors.New("barrier participant mismatch")
 )
 
 // Sync is an interface for distributed synchronization
@@ -22,6 +26,8 @@ type Sync interface {
 	Lock(id string, opts ...LockOption) error
 	// Unlock releases a lock
 	Unlock(id string) error
+	// Barrier creates a barrier for n participants
+	Barrier(id string, n int, opts ...BarrierOption) (Barrier, error)
 	// Sync implementation
 	String() string
 }
@@ -34,6 +40,12 @@ type Leader interface {
 	Status() chan bool
 }
 
+// Barrier blocks participants until all of them have arrived
+type Barrier interface {
+	// Wait blocks until n participants are waiting
+	Wait() error
+}
+
 type Options struct {
 	Nodes  []string
 	Prefix string
@@ -51,3 +63,9 @@ type LockOptions struct {
 }
 
 type LockOption func(o *LockOptions)
+
+type BarrierOptions struct {
+	Wait time.Duration
+}
+
+type BarrierOption func(o *BarrierOptions)
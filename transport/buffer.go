@@ -0,0 +1,49 @@
+package transport
+
+import (
+	"bufio"
+	"io"
+	"sync"
+)
+
+var (
+	// writers buffer the requests and responses written to connections
+	writers = sync.Pool{
+		New: func() interface{} {
+			return bufio.NewWriter(nil)
+		},
+	}
+
+	// chunks are the buffers streamed bodies are read into
+	chunks sync.Pool
+)
+
+// writeBuffered writes to w through a pooled buffer which is flushed
+// once fn returns
+func writeBuffered(w io.Writer, fn func(io.Writer) error) error {
+	bw := writers.Get().(*bufio.Writer)
+	bw.Reset(w)
+	defer func() {
+		bw.Reset(nil)
+		writers.Put(bw)
+	}()
+
+	if err := fn(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// getChunk returns a pooled buffer of at least the size
+func getChunk(size int) *[]byte {
+	if b, ok := chunks.Get().(*[]byte); ok && cap(*b) >= size {
+		*b = (*b)[:size]
+		return b
+	}
+	b := make([]byte, size)
+	return &b
+}
+
+func putChunk(b *[]byte) {
+	chunks.Put(b)
+}
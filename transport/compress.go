@@ -0,0 +1,259 @@
+package transport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+const (
+	// AcceptEncodingHeader lists the compressions a client accepts in
+	// order of preference
+	AcceptEncodingHeader = "Micro-Accept-Encoding"
+	// ContentEncodingHeader is the compression of a message body
+	ContentEncodingHeader = "Micro-Content-Encoding"
+
+	// identity is the encoding of uncompressed bodies
+	identity = "identity"
+	// compressMinSize is the size of the smallest body worth compressing
+	compressMinSize = 1024
+)
+
+var (
+	// DefaultCompressors are the compressions which may be negotiated,
+	// plugins add further compressors e.g snappy
+	DefaultCompressors = map[string]Compressor{
+		"gzip": gzipCompressor{},
+	}
+
+	// MaxDecompressSize limits decompressed bodies when no max recv
+	// size is set so a small message can't expand without bound
+	MaxDecompressSize = 1024 * 1024 * 64 // 64Mb
+)
+
+// Compressor compresses message bodies
+type Compressor interface {
+	// Writer compresses what's written to the writer
+	Writer(w io.Writer) io.WriteCloser
+	// Reader decompresses what's read from the reader
+	Reader(r io.Reader) (io.Reader, error)
+}
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) Writer(w io.Writer) io.WriteCloser {
+	return gzip.NewWriter(w)
+}
+
+func (gzipCompressor) Reader(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
+// compressClient compresses the messages of a client once the server
+// accepted a compression
+type compressClient struct {
+	Client
+	opts Options
+
+	sync.RWMutex
+	// the compression chosen by the server
+	enc string
+}
+
+// compressSocket compresses the messages of a socket with the first
+// compression accepted by the client
+type compressSocket struct {
+	Socket
+	opts Options
+
+	sync.RWMutex
+	// the compression chosen by the socket
+	enc string
+}
+
+// newCompressClient compresses messages of the client if compressions are
+// enabled, they're negotiated with the server on the first message
+func newCompressClient(c Client, opts Options) Client {
+	if len(opts.Compression) == 0 {
+		return c
+	}
+	return &compressClient{Client: c, opts: opts}
+}
+
+// newCompressSocket compresses messages of the socket if compressions are
+// enabled and the client accepts one of them
+func newCompressSocket(s Socket, opts Options) Socket {
+	if len(opts.Compression) == 0 {
+		return s
+	}
+	return &compressSocket{Socket: s, opts: opts}
+}
+
+func (c *compressClient) Send(m *Message) error {
+	c.RLock()
+	enc := c.enc
+	c.RUnlock()
+
+	msg, err := compress(m, enc)
+	if err != nil {
+		return err
+	}
+	if len(enc) == 0 {
+		msg.Header[AcceptEncodingHeader] = strings.Join(c.opts.Compression, ",")
+	}
+	return c.Client.Send(msg)
+}
+
+func (c *compressClient) Recv(m *Message) error {
+	if err := c.Client.Recv(m); err != nil {
+		return err
+	}
+
+	// the server only responds with a compression the client accepts
+	if enc := m.Header[ContentEncodingHeader]; len(enc) > 0 && enc != identity && accepts(c.opts.Compression, enc) {
+		c.Lock()
+		c.enc = enc
+		c.Unlock()
+	}
+
+	return decompress(m, c.opts.MaxRecvSize)
+}
+
+// Probe probes the connection of the client, if it can be probed
+func (c *compressClient) Probe() error {
+	if p, ok := c.Client.(ProbeClient); ok {
+		return p.Probe()
+	}
+	return nil
+}
+
+func (s *compressSocket) Recv(m *Message) error {
+	if err := s.Socket.Recv(m); err != nil {
+		return err
+	}
+
+	// choose the first compression accepted by the client
+	if accept, ok := m.Header[AcceptEncodingHeader]; ok {
+		s.Lock()
+		if len(s.enc) == 0 {
+			s.enc = identity
+			for _, enc := range strings.Split(accept, ",") {
+				if enc = strings.TrimSpace(enc); accepts(s.opts.Compression, enc) {
+					s.enc = enc
+					break
+				}
+			}
+		}
+		s.Unlock()
+		delete(m.Header, AcceptEncodingHeader)
+	}
+
+	return decompress(m, s.opts.MaxRecvSize)
+}
+
+func (s *compressSocket) Send(m *Message) error {
+	s.RLock()
+	enc := s.enc
+	s.RUnlock()
+
+	// clients not accepting a compression aren't sent the header
+	if len(enc) == 0 {
+		return s.Socket.Send(m)
+	}
+
+	msg, err := compress(m, enc)
+	if err != nil {
+		return err
+	}
+	return s.Socket.Send(msg)
+}
+
+// Peer returns the verified peer of the socket, if it verifies peers
+func (s *compressSocket) Peer() string {
+	if p, ok := s.Socket.(PeerSocket); ok {
+		return p.Peer()
+	}
+	return ""
+}
+
+// accepts returns true if the compression is in the list and is known
+func accepts(list []string, enc string) bool {
+	if _, ok := DefaultCompressors[enc]; !ok {
+		return false
+	}
+	for _, e := range list {
+		if e == enc {
+			return true
+		}
+	}
+	return false
+}
+
+// compress returns a copy of the message compressed with the encoding, the
+// encoding is always set so it overrides any echoed by the transport
+func compress(m *Message, enc string) (*Message, error) {
+	msg := &Message{
+		Header: make(map[string]string, len(m.Header)+1),
+		Body:   m.Body,
+	}
+	for k, v := range m.Header {
+		msg.Header[k] = v
+	}
+	if len(enc) == 0 {
+		return msg, nil
+	}
+
+	c, ok := DefaultCompressors[enc]
+	if !ok || enc == identity || len(m.Body) < compressMinSize {
+		msg.Header[ContentEncodingHeader] = identity
+		return msg, nil
+	}
+
+	var b bytes.Buffer
+	w := c.Writer(&b)
+	if _, err := w.Write(m.Body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	msg.Header[ContentEncodingHeader] = enc
+	msg.Body = b.Bytes()
+	return msg, nil
+}
+
+// decompress decompresses the body of the message in place, the
+// decompressed body is limited to the maximum size or MaxDecompressSize
+func decompress(m *Message, max int) error {
+	enc, ok := m.Header[ContentEncodingHeader]
+	if !ok {
+		return nil
+	}
+	delete(m.Header, ContentEncodingHeader)
+	if enc == identity {
+		return nil
+	}
+
+	c, ok := DefaultCompressors[enc]
+	if !ok {
+		return fmt.Errorf("unknown compression %s", enc)
+	}
+
+	r, err := c.Reader(bytes.NewReader(m.Body))
+	if err != nil {
+		return err
+	}
+	if max <= 0 {
+		max = MaxDecompressSize
+	}
+	b, err := readBody(r, max)
+	if err != nil {
+		return err
+	}
+	m.Body = b
+	return nil
+}
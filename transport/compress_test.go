@@ -0,0 +1,127 @@
+package transport
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+)
+
+// testCompressor counts the bodies it compresses
+type testCompressor struct {
+	gzipCompressor
+	count int32
+}
+
+func (c *testCompressor) Writer(w io.Writer) io.WriteCloser {
+	atomic.AddInt32(&c.count, 1)
+	return c.gzipCompressor.Writer(w)
+}
+
+func TestCompression(t *testing.T) {
+	c := new(testCompressor)
+	DefaultCompressors["test"] = c
+	defer delete(DefaultCompressors, "test")
+
+	testData := []struct {
+		name   string
+		server []string
+		client []string
+		// compressions of the request and response after negotiating
+		compressed int32
+	}{
+		{name: "negotiated", server: []string{"gzip", "test"}, client: []string{"test", "gzip"}, compressed: 3},
+		{name: "server only", server: []string{"test"}},
+		{name: "client only", client: []string{"test"}},
+		{name: "no match", server: []string{"gzip"}, client: []string{"test"}},
+	}
+
+	body := bytes.Repeat([]byte(`ping`), compressMinSize)
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			atomic.StoreInt32(&c.count, 0)
+
+			tr := NewMemoryTransport(Compression(d.server...))
+			l, err := tr.Listen("127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer l.Close()
+
+			go l.Accept(func(sock Socket) {
+				for {
+					var m Message
+					if err := sock.Recv(&m); err != nil {
+						return
+					}
+					if !bytes.Equal(m.Body, body) {
+						t.Errorf("Server received unexpected body of %d bytes", len(m.Body))
+					}
+					if err := sock.Send(&Message{Header: map[string]string{}, Body: body}); err != nil {
+						return
+					}
+				}
+			})
+
+			// the listener keeps the options of the server
+			tr.Init(Compression(d.client...))
+			cl, err := tr.Dial(l.Addr())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cl.Close()
+
+			// the first request is sent before a compression is negotiated
+			for i := 0; i < 2; i++ {
+				if err := cl.Send(&Message{Header: map[string]string{}, Body: body}); err != nil {
+					t.Fatal(err)
+				}
+				var m Message
+				if err := cl.Recv(&m); err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(m.Body, body) {
+					t.Fatalf("Client received unexpected body of %d bytes", len(m.Body))
+				}
+				if _, ok := m.Header[ContentEncodingHeader]; ok {
+					t.Fatal("Expected the encoding header to be removed")
+				}
+			}
+
+			if n := atomic.LoadInt32(&c.count); n != d.compressed {
+				t.Fatalf("Expected %d compressed bodies got %d", d.compressed, n)
+			}
+		})
+	}
+}
+
+func TestCompressionMaxSize(t *testing.T) {
+	m, err := compress(&Message{Header: map[string]string{}, Body: make([]byte, compressMinSize*4)}, "gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Body) >= compressMinSize {
+		t.Fatalf("Expected the body to be compressed, got %d bytes", len(m.Body))
+	}
+	if err := decompress(m, compressMinSize); !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("Expected %v got %v", ErrMessageTooLarge, err)
+	}
+}
+
+func TestCompressionDefaultMaxSize(t *testing.T) {
+	max := MaxDecompressSize
+	MaxDecompressSize = compressMinSize
+	defer func() { MaxDecompressSize = max }()
+
+	m, err := compress(&Message{Header: map[string]string{}, Body: make([]byte, compressMinSize*4)}, "gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without a max recv size the body is limited to MaxDecompressSize
+	if err := decompress(m, 0); !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("Expected %v got %v", ErrMessageTooLarge, err)
+	}
+}
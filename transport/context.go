@@ -0,0 +1,84 @@
+package transport
+
+import (
+	"context"
+	"time"
+)
+
+// ContextDialer is implemented by transports which can cancel a dial
+type ContextDialer interface {
+	DialContext(ctx context.Context, addr string, opts ...DialOption) (Client, error)
+}
+
+// ContextAcceptor is implemented by listeners which stop accepting when
+// the context is done
+type ContextAcceptor interface {
+	AcceptContext(ctx context.Context, fn func(Socket)) error
+}
+
+// DialContext dials the address with the transport, returning when the
+// context is done. The dial timeout is bounded by the context deadline.
+func DialContext(ctx context.Context, t Transport, addr string, opts ...DialOption) (Client, error) {
+	if d, ok := t.(ContextDialer); ok {
+		return d.DialContext(ctx, addr, opts...)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	options := DialOptions{
+		Timeout: DefaultDialTimeout,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if d, ok := ctx.Deadline(); ok && time.Until(d) < options.Timeout {
+		opts = append(opts, WithTimeout(time.Until(d)))
+	}
+
+	type result struct {
+		c   Client
+		err error
+	}
+
+	ch := make(chan result, 1)
+	go func() {
+		c, err := t.Dial(addr, opts...)
+		ch <- result{c, err}
+	}()
+
+	select {
+	case r := <-ch:
+		return r.c, r.err
+	case <-ctx.Done():
+		// close the connection if the dial succeeds later
+		go func() {
+			if r := <-ch; r.c != nil {
+				r.c.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// AcceptContext accepts connections on the listener until the context is
+// done, the listener is then closed and the context error returned
+func AcceptContext(ctx context.Context, l Listener, fn func(Socket)) error {
+	if a, ok := l.(ContextAcceptor); ok {
+		return a.AcceptContext(ctx, fn)
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- l.Accept(fn)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		l.Close()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,250 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"golang.org/x/net/http2"
+)
+
+// httpMuxClient sends each message as a request of its own over a shared
+// http2 connection, responses are received in the order messages are sent
+type httpMuxClient struct {
+	ht       *httpTransport
+	conn     *http2.ClientConn
+	addr     string
+	dialOpts DialOptions
+
+	sync.Mutex
+	// responses in the order of requests
+	rsp    []chan *httpMuxResponse
+	closed bool
+}
+
+type httpMuxResponse struct {
+	rsp    *http.Response
+	err    error
+	cancel context.CancelFunc
+}
+
+// muxConn returns the connection to the address shared by the clients. If
+// there's none which can take a request one is dialed bound to the context.
+func (h *httpTransport) muxConn(ctx context.Context, addr string) (*http2.ClientConn, error) {
+	h.Lock()
+	if cc, ok := h.conns[addr]; ok && cc.CanTakeNewRequest() {
+		h.Unlock()
+		return cc, nil
+	}
+	h.Unlock()
+
+	conn, err := h.dial(ctx, addr, http2.NextProtoTLS)
+	if err != nil {
+		return nil, err
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	// another client may have dialed it meanwhile
+	if cc, ok := h.conns[addr]; ok && cc.CanTakeNewRequest() {
+		conn.Close()
+		return cc, nil
+	}
+
+	if h.mux == nil {
+		h.mux = new(http2.Transport)
+	}
+
+	// plaintext connections use h2c
+	cc, err := h.mux.NewClientConn(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if h.conns == nil {
+		h.conns = make(map[string]*http2.ClientConn)
+	}
+	h.conns[addr] = cc
+
+	return cc, nil
+}
+
+// closeMux shuts down the shared connections once their requests complete
+func (h *httpTransport) closeMux() {
+	h.Lock()
+	defer h.Unlock()
+
+	for _, cc := range h.conns {
+		go cc.Shutdown(context.Background())
+	}
+
+	h.conns = nil
+	h.mux = nil
+}
+
+func (h *httpTransport) muxClient(conn *http2.ClientConn, addr string, dopts DialOptions) *httpMuxClient {
+	return &httpMuxClient{
+		ht:       h,
+		conn:     conn,
+		addr:     addr,
+		dialOpts: dopts,
+	}
+}
+
+func (h *httpMuxClient) Local() string {
+	return ""
+}
+
+func (h *httpMuxClient) Remote() string {
+	return h.addr
+}
+
+func (h *httpMuxClient) Send(m *Message) error {
+	if err := CheckSize(len(m.Body), h.ht.opts.MaxSendSize); err != nil {
+		return err
+	}
+
+	header := make(http.Header, len(m.Header))
+	for k, v := range m.Header {
+		header.Set(k, v)
+	}
+
+	// the request is cancelled once the response is received
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if h.ht.opts.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), h.ht.opts.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	scheme := "http"
+	if h.ht.opts.Secure || h.ht.opts.TLSConfig != nil {
+		scheme = "https"
+	}
+
+	// requests of a known length carry a single message
+	var body io.ReadCloser = http.NoBody
+	if len(m.Body) > 0 {
+		body = ioutil.NopCloser(bytes.NewReader(m.Body))
+	}
+
+	req := (&http.Request{
+		Method: "POST",
+		URL: &url.URL{
+			Scheme: scheme,
+			Host:   h.addr,
+			Path:   "/",
+		},
+		Header:        header,
+		Body:          body,
+		ContentLength: int64(len(m.Body)),
+		Host:          h.addr,
+	}).WithContext(ctx)
+
+	h.Lock()
+	if h.closed {
+		h.Unlock()
+		cancel()
+		return io.EOF
+	}
+	ch := make(chan *httpMuxResponse, 1)
+	h.rsp = append(h.rsp, ch)
+	h.Unlock()
+
+	go func() {
+		rsp, err := h.conn.RoundTrip(req)
+		ch <- &httpMuxResponse{rsp: rsp, err: err, cancel: cancel}
+	}()
+
+	return nil
+}
+
+func (h *httpMuxClient) Recv(m *Message) error {
+	if m == nil {
+		return errors.New("message passed in is nil")
+	}
+
+	h.Lock()
+	if h.closed {
+		h.Unlock()
+		return io.EOF
+	}
+	if len(h.rsp) == 0 {
+		h.Unlock()
+		return errors.New("no request sent")
+	}
+	ch := h.rsp[0]
+	h.rsp = h.rsp[1:]
+	h.Unlock()
+
+	r := <-ch
+	defer r.cancel()
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rsp.Body.Close()
+
+	b, err := readBody(r.rsp.Body, h.ht.opts.MaxRecvSize)
+	if err != nil {
+		return err
+	}
+
+	// the listener rejected the request before it reached the socket
+	if r.rsp.StatusCode == http.StatusRequestEntityTooLarge {
+		return fmt.Errorf("%w: %s", ErrMessageTooLarge, r.rsp.Status)
+	}
+
+	if r.rsp.StatusCode != 200 {
+		return errors.New(r.rsp.Status + ": " + string(b))
+	}
+
+	m.Body = b
+
+	if m.Header == nil {
+		m.Header = make(map[string]string, len(r.rsp.Header))
+	}
+
+	for k, v := range r.rsp.Header {
+		if len(v) > 0 {
+			m.Header[k] = v[0]
+		} else {
+			m.Header[k] = ""
+		}
+	}
+
+	return nil
+}
+
+// Close cancels the requests of which responses weren't received, the
+// shared connection is kept open
+func (h *httpMuxClient) Close() error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+
+	for _, ch := range h.rsp {
+		go func(ch chan *httpMuxResponse) {
+			r := <-ch
+			r.cancel()
+			if r.err == nil {
+				r.rsp.Body.Close()
+			}
+		}(ch)
+	}
+	h.rsp = nil
+
+	return nil
+}
@@ -2,6 +2,8 @@ package transport
 
 import (
 	"bufio"
+	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -9,13 +11,26 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/proxy"
 )
 
 const (
 	proxyAuthHeader = "Proxy-Authorization"
 )
 
-func getURL(addr string) (*url.URL, error) {
+// getURL returns the url of the proxy to dial the address through, the proxy
+// of the environment e.g HTTPS_PROXY is used if no proxy is set
+func getURL(proxyAddr, addr string) (*url.URL, error) {
+	if len(proxyAddr) > 0 {
+		if !strings.Contains(proxyAddr, "://") {
+			proxyAddr = "http://" + proxyAddr
+		}
+		return url.Parse(proxyAddr)
+	}
+
 	r := &http.Request{
 		URL: &url.URL{
 			Scheme: "https",
@@ -76,34 +91,64 @@ func proxyDial(conn net.Conn, addr string, proxyURL *url.URL) (_ net.Conn, err e
 	return &pbuffer{Conn: conn, r: br}, nil
 }
 
-// Creates a new connection
-func newConn(dial func(string) (net.Conn, error)) func(string) (net.Conn, error) {
-	return func(addr string) (net.Conn, error) {
-		// get the proxy url
-		proxyURL, err := getURL(addr)
-		if err != nil {
-			return nil, err
-		}
+// socksDial dials the address through a socks5 proxy
+func socksDial(ctx context.Context, d *net.Dialer, addr string, proxyURL *url.URL) (net.Conn, error) {
+	var auth *proxy.Auth
+	if user := proxyURL.User; user != nil {
+		p, _ := user.Password()
+		auth = &proxy.Auth{User: user.Username(), Password: p}
+	}
 
-		// set to addr
-		callAddr := addr
+	pd, err := proxy.SOCKS5("tcp", proxyURL.Host, auth, d)
+	if err != nil {
+		return nil, err
+	}
+	return pd.(proxy.ContextDialer).DialContext(ctx, "tcp", addr)
+}
 
-		// got proxy
-		if proxyURL != nil {
-			callAddr = proxyURL.Host
-		}
+// newConn dials the address, through the proxy if there is one. HTTP CONNECT
+// and SOCKS5 proxies are supported
+func newConn(ctx context.Context, d *net.Dialer, proxyAddr, addr string) (net.Conn, error) {
+	// get the proxy url
+	proxyURL, err := getURL(proxyAddr, addr)
+	if err != nil {
+		return nil, err
+	}
 
-		// dial the addr
-		c, err := dial(callAddr)
-		if err != nil {
-			return nil, err
-		}
+	// no proxy
+	if proxyURL == nil {
+		return d.DialContext(ctx, "tcp", addr)
+	}
 
-		// do proxy connect if we have proxy url
-		if proxyURL != nil {
-			c, err = proxyDial(c, addr, proxyURL)
-		}
+	switch proxyURL.Scheme {
+	case "socks5", "socks5h":
+		return socksDial(ctx, d, addr, proxyURL)
+	case "http", "https":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %s", proxyURL.Scheme)
+	}
 
-		return c, err
+	// dial the proxy
+	c, err := d.DialContext(ctx, "tcp", proxyURL.Host)
+	if err != nil {
+		return nil, err
 	}
+
+	// bound the connect handshake by the context
+	if deadline, ok := ctx.Deadline(); ok {
+		c.SetDeadline(deadline)
+		defer c.SetDeadline(time.Time{})
+	}
+
+	// secure the connection to the proxy
+	if proxyURL.Scheme == "https" {
+		tc := tls.Client(c, &tls.Config{ServerName: proxyURL.Hostname()})
+		if err := tc.Handshake(); err != nil {
+			c.Close()
+			return nil, err
+		}
+		c = tc
+	}
+
+	return proxyDial(c, addr, proxyURL)
 }
@@ -0,0 +1,156 @@
+package transport
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+// pipe copies between the connections until either is closed
+func pipe(a, b net.Conn) {
+	defer a.Close()
+	defer b.Close()
+	go io.Copy(a, b)
+	io.Copy(b, a)
+}
+
+// connectProxy is a HTTP CONNECT proxy
+func connectProxy(t *testing.T, dialed chan<- string) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				r, err := http.ReadRequest(bufio.NewReader(c))
+				if err != nil || r.Method != http.MethodConnect {
+					c.Close()
+					return
+				}
+				dialed <- r.Host
+				rc, err := net.Dial("tcp", r.Host)
+				if err != nil {
+					c.Write([]byte("HTTP/1.1 502 Bad Gateway\r\n\r\n"))
+					c.Close()
+					return
+				}
+				c.Write([]byte("HTTP/1.1 200 OK\r\n\r\n"))
+				pipe(c, rc)
+			}()
+		}
+	}()
+	return l
+}
+
+// socksProxy is a SOCKS5 proxy of ipv4 addresses without authentication
+func socksProxy(t *testing.T, dialed chan<- string) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				// version and methods
+				b := make([]byte, 2)
+				if _, err := io.ReadFull(c, b); err != nil {
+					c.Close()
+					return
+				}
+				if _, err := io.ReadFull(c, make([]byte, b[1])); err != nil {
+					c.Close()
+					return
+				}
+				c.Write([]byte{5, 0})
+
+				// connect to an ipv4 address
+				b = make([]byte, 10)
+				if _, err := io.ReadFull(c, b); err != nil || b[3] != 1 {
+					c.Close()
+					return
+				}
+				addr := net.JoinHostPort(net.IP(b[4:8]).String(), strconv.Itoa(int(binary.BigEndian.Uint16(b[8:]))))
+				dialed <- addr
+				rc, err := net.Dial("tcp", addr)
+				if err != nil {
+					c.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+					c.Close()
+					return
+				}
+				c.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+				pipe(c, rc)
+			}()
+		}
+	}()
+	return l
+}
+
+func TestHTTPTransportProxy(t *testing.T) {
+	testData := []struct {
+		name   string
+		scheme string
+		proxy  func(*testing.T, chan<- string) net.Listener
+	}{
+		{name: "connect", scheme: "http", proxy: connectProxy},
+		{name: "socks5", scheme: "socks5", proxy: socksProxy},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			dialed := make(chan string, 1)
+			p := d.proxy(t, dialed)
+			defer p.Close()
+
+			tr := NewHTTPTransport(Proxy(d.scheme + "://" + p.Addr().String()))
+
+			l, err := tr.Listen("127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("Unexpected listen err: %v", err)
+			}
+			defer l.Close()
+
+			go l.Accept(func(sock Socket) {
+				defer sock.Close()
+				var m Message
+				if err := sock.Recv(&m); err != nil {
+					return
+				}
+				sock.Send(&m)
+			})
+
+			c, err := tr.Dial(l.Addr())
+			if err != nil {
+				t.Fatalf("Unexpected dial err: %v", err)
+			}
+			defer c.Close()
+
+			if addr := <-dialed; addr != l.Addr() {
+				t.Fatalf("Expected the proxy to dial %s got %s", l.Addr(), addr)
+			}
+
+			m := Message{Header: map[string]string{"Content-Type": "application/json"}, Body: []byte(`{}`)}
+			if err := c.Send(&m); err != nil {
+				t.Fatalf("Unexpected send err: %v", err)
+			}
+			if err := c.Recv(&m); err != nil {
+				t.Fatalf("Unexpected recv err: %v", err)
+			}
+			if string(m.Body) != `{}` {
+				t.Fatalf("Expected {} got %s", m.Body)
+			}
+		})
+	}
+}
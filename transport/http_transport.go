@@ -3,8 +3,10 @@ package transport
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net"
@@ -23,6 +25,12 @@ import (
 
 type httpTransport struct {
 	opts Options
+
+	// the http2 transport multiplexing requests and its
+	// connections by address
+	sync.Mutex
+	mux   *http2.Transport
+	conns map[string]*http2.ClientConn
 }
 
 type httpTransportClient struct {
@@ -61,10 +69,18 @@ type httpTransportSocket struct {
 	buf *bufio.Reader
 	// indicate if socket is closed
 	closed chan bool
+	// a h2 request of a known length carries a single message, the
+	// socket is done once it's received and responded to
+	unary    bool
+	received bool
+	sent     chan bool
+	sendOnce sync.Once
 
 	// local/remote ip
 	local  string
 	remote string
+	// name of the verified peer
+	peer string
 }
 
 type httpTransportListener struct {
@@ -81,6 +97,10 @@ func (h *httpTransportClient) Remote() string {
 }
 
 func (h *httpTransportClient) Send(m *Message) error {
+	if err := CheckSize(len(m.Body), h.ht.opts.MaxSendSize); err != nil {
+		return err
+	}
+
 	header := make(http.Header)
 
 	for k, v := range m.Header {
@@ -115,8 +135,9 @@ func (h *httpTransportClient) Send(m *Message) error {
 	if h.ht.opts.Timeout > time.Duration(0) {
 		h.conn.SetDeadline(time.Now().Add(h.ht.opts.Timeout))
 	}
+	setWriteDeadline(h.conn, h.ht.opts.WriteTimeout)
 
-	return req.Write(h.conn)
+	return writeBuffered(h.conn, req.Write)
 }
 
 func (h *httpTransportClient) Recv(m *Message) error {
@@ -144,11 +165,16 @@ func (h *httpTransportClient) Recv(m *Message) error {
 	}
 	defer rsp.Body.Close()
 
-	b, err := ioutil.ReadAll(rsp.Body)
+	b, err := readBody(rsp.Body, h.ht.opts.MaxRecvSize)
 	if err != nil {
 		return err
 	}
 
+	// the listener rejected the request before it reached the socket
+	if rsp.StatusCode == http.StatusRequestEntityTooLarge {
+		return fmt.Errorf("%w: %s", ErrMessageTooLarge, rsp.Status)
+	}
+
 	if rsp.StatusCode != 200 {
 		return errors.New(rsp.Status + ": " + string(b))
 	}
@@ -170,6 +196,50 @@ func (h *httpTransportClient) Recv(m *Message) error {
 	return nil
 }
 
+// readBody reads the body up to the maximum size, there's no maximum if
+// it's zero. It's read into a pooled buffer and copied out at its size
+func readBody(r io.Reader, max int) ([]byte, error) {
+	b := buf.Get()
+	defer buf.Put(b)
+
+	if max > 0 {
+		r = io.LimitReader(r, int64(max)+1)
+	}
+	if _, err := b.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	if err := CheckSize(b.Len(), max); err != nil {
+		return nil, err
+	}
+
+	body := make([]byte, b.Len())
+	copy(body, b.Bytes())
+	return body, nil
+}
+
+// setWriteDeadline bounds the next write to the connection, there's no
+// bound if the timeout is zero
+func setWriteDeadline(conn net.Conn, timeout time.Duration) {
+	if timeout > time.Duration(0) {
+		conn.SetWriteDeadline(time.Now().Add(timeout))
+	}
+}
+
+// Probe checks the server hasn't closed the idle connection, nothing is
+// sent by the server so the read times out if the connection is open
+func (h *httpTransportClient) Probe() error {
+	h.conn.SetReadDeadline(time.Now().Add(time.Millisecond))
+	defer h.conn.SetReadDeadline(time.Time{})
+
+	_, err := h.buff.Peek(1)
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return errors.New("unexpected data on idle connection")
+}
+
 func (h *httpTransportClient) Close() error {
 	h.once.Do(func() {
 		h.Lock()
@@ -210,6 +280,10 @@ func (h *httpTransportSocket) Recv(m *Message) error {
 		case r = <-h.ch:
 		// read next request
 		default:
+			// close the connection if the next request takes too long
+			if t := h.ht.opts.IdleTimeout; t > time.Duration(0) {
+				h.conn.SetReadDeadline(time.Now().Add(t))
+			}
 			rr, err := http.ReadRequest(h.rw.Reader)
 			if err != nil {
 				return err
@@ -217,8 +291,17 @@ func (h *httpTransportSocket) Recv(m *Message) error {
 			r = rr
 		}
 
+		// set headers, they're returned if the body is too large
+		for k, v := range r.Header {
+			if len(v) > 0 {
+				m.Header[k] = v[0]
+			} else {
+				m.Header[k] = ""
+			}
+		}
+
 		// read body
-		b, err := ioutil.ReadAll(r.Body)
+		b, err := readBody(r.Body, h.ht.opts.MaxRecvSize)
 		if err != nil {
 			return err
 		}
@@ -227,15 +310,6 @@ func (h *httpTransportSocket) Recv(m *Message) error {
 		r.Body.Close()
 		m.Body = b
 
-		// set headers
-		for k, v := range r.Header {
-			if len(v) > 0 {
-				m.Header[k] = v[0]
-			} else {
-				m.Header[k] = ""
-			}
-		}
-
 		// return early early
 		return nil
 	}
@@ -249,22 +323,14 @@ func (h *httpTransportSocket) Recv(m *Message) error {
 	}
 
 	// processing http2 request
-	// read streaming body
-
-	// set max buffer size
-	// TODO: adjustable buffer size
-	buf := make([]byte, 4*1024*1024)
-
-	// read the request body
-	n, err := h.buf.Read(buf)
-	// not an eof error
-	if err != nil {
-		return err
-	}
-
-	// check if we have data
-	if n > 0 {
-		m.Body = buf[:n]
+	if h.unary && h.received {
+		// wait for the response to be sent
+		select {
+		case <-h.sent:
+		case <-h.closed:
+		case <-h.r.Context().Done():
+		}
+		return io.EOF
 	}
 
 	// set headers
@@ -279,10 +345,56 @@ func (h *httpTransportSocket) Recv(m *Message) error {
 	// set path
 	m.Header[":path"] = h.r.URL.Path
 
+	// read the whole body of a single message
+	if h.unary {
+		h.received = true
+		b, err := readBody(h.buf, h.ht.opts.MaxRecvSize)
+		if err != nil {
+			return err
+		}
+		m.Body = b
+		return nil
+	}
+
+	// read streaming body
+
+	// set max buffer size, one more than the maximum to detect larger bodies
+	size := 4 * 1024 * 1024
+	if max := h.ht.opts.MaxRecvSize; max > 0 {
+		size = max + 1
+	}
+	chunk := getChunk(size)
+	defer putChunk(chunk)
+
+	// read the request body
+	n, err := h.buf.Read(*chunk)
+	// not an eof error
+	if err != nil {
+		return err
+	}
+	if err := CheckSize(n, h.ht.opts.MaxRecvSize); err != nil {
+		return err
+	}
+
+	// check if we have data, it's copied out of the pooled chunk
+	if n > 0 {
+		m.Body = make([]byte, n)
+		copy(m.Body, (*chunk)[:n])
+	}
+
 	return nil
 }
 
+// Peer returns the name of the peer verified from its certificate
+func (h *httpTransportSocket) Peer() string {
+	return h.peer
+}
+
 func (h *httpTransportSocket) Send(m *Message) error {
+	if err := CheckSize(len(m.Body), h.ht.opts.MaxSendSize); err != nil {
+		return err
+	}
+
 	if h.r.ProtoMajor == 1 {
 		// make copy of header
 		hdr := make(http.Header)
@@ -309,8 +421,9 @@ func (h *httpTransportSocket) Send(m *Message) error {
 		if h.ht.opts.Timeout > time.Duration(0) {
 			h.conn.SetDeadline(time.Now().Add(h.ht.opts.Timeout))
 		}
+		setWriteDeadline(h.conn, h.ht.opts.WriteTimeout)
 
-		return rsp.Write(h.conn)
+		return writeBuffered(h.conn, rsp.Write)
 	}
 
 	// only process if the socket is open
@@ -336,6 +449,13 @@ func (h *httpTransportSocket) Send(m *Message) error {
 	// flush the trailers
 	h.w.(http.Flusher).Flush()
 
+	// the response of a single message is complete
+	if h.unary {
+		h.sendOnce.Do(func() {
+			close(h.sent)
+		})
+	}
+
 	return err
 }
 
@@ -356,7 +476,9 @@ func (h *httpTransportSocket) error(m *Message) error {
 			rsp.Header.Set(k, v)
 		}
 
-		return rsp.Write(h.conn)
+		setWriteDeadline(h.conn, h.ht.opts.WriteTimeout)
+
+		return writeBuffered(h.conn, rsp.Write)
 	}
 
 	return nil
@@ -393,6 +515,12 @@ func (h *httpTransportListener) Close() error {
 }
 
 func (h *httpTransportListener) Accept(fn func(Socket)) error {
+	return h.AcceptContext(context.Background(), fn)
+}
+
+// AcceptContext accepts connections until the context is done, the
+// listener is then closed
+func (h *httpTransportListener) AcceptContext(ctx context.Context, fn func(Socket)) error {
 	// create handler mux
 	mux := http.NewServeMux()
 
@@ -403,8 +531,11 @@ func (h *httpTransportListener) Accept(fn func(Socket)) error {
 
 		// read a regular request
 		if r.ProtoMajor == 1 {
-			b, err := ioutil.ReadAll(r.Body)
-			if err != nil {
+			b, err := readBody(r.Body, h.ht.opts.MaxRecvSize)
+			if errors.Is(err, ErrMessageTooLarge) {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+				return
+			} else if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
@@ -425,6 +556,12 @@ func (h *httpTransportListener) Accept(fn func(Socket)) error {
 			defer conn.Close()
 			buf = bufrw
 			con = conn
+		} else if r.ContentLength > 0 {
+			// reject a multiplexed request of a known size up front
+			if err := CheckSize(int(r.ContentLength), h.ht.opts.MaxRecvSize); err != nil {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
 		}
 
 		// buffered reader
@@ -446,10 +583,17 @@ func (h *httpTransportListener) Accept(fn func(Socket)) error {
 			local:  h.Addr(),
 			remote: r.RemoteAddr,
 			closed: make(chan bool),
+			unary:  r.ProtoMajor == 2 && r.ContentLength >= 0,
+			sent:   make(chan bool),
+		}
+
+		// the peer certificate is only trusted if it was verified
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 && mls.VerifiesPeers(h.ht.opts.TLSConfig) {
+			sock.peer = mls.PeerName(r.TLS.PeerCertificates[0])
 		}
 
 		// execute the socket
-		fn(sock)
+		fn(newCompressSocket(sock, h.ht.opts))
 	})
 
 	// get optional handlers
@@ -464,19 +608,42 @@ func (h *httpTransportListener) Accept(fn func(Socket)) error {
 
 	// default http2 server
 	srv := &http.Server{
-		Handler: mux,
+		Handler:     mux,
+		IdleTimeout: h.ht.opts.IdleTimeout,
 	}
 
 	// insecure connection use h2c
 	if !(h.ht.opts.Secure || h.ht.opts.TLSConfig != nil) {
-		srv.Handler = h2c.NewHandler(mux, &http2.Server{})
+		srv.Handler = h2c.NewHandler(mux, &http2.Server{IdleTimeout: h.ht.opts.IdleTimeout})
 	}
 
+	// stop serving when the context is done
+	done := make(chan bool)
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			srv.Close()
+		case <-done:
+		}
+	}()
+
 	// begin serving
-	return srv.Serve(h.listener)
+	err := srv.Serve(h.listener)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
 }
 
 func (h *httpTransport) Dial(addr string, opts ...DialOption) (Client, error) {
+	return h.DialContext(context.Background(), addr, opts...)
+}
+
+// DialContext dials the address, the dial is cancelled when the context is
+// done and is bounded by the dial timeout
+func (h *httpTransport) DialContext(ctx context.Context, addr string, opts ...DialOption) (Client, error) {
 	dopts := DialOptions{
 		Timeout: DefaultDialTimeout,
 	}
@@ -485,32 +652,30 @@ func (h *httpTransport) Dial(addr string, opts ...DialOption) (Client, error) {
 		opt(&dopts)
 	}
 
-	var conn net.Conn
-	var err error
+	// only the dial is bound to the context
+	dctx := ctx
+	if dopts.Timeout > 0 {
+		var cancel context.CancelFunc
+		dctx, cancel = context.WithTimeout(ctx, dopts.Timeout)
+		defer cancel()
+	}
 
-	// TODO: support dial option here rather than using internal config
-	if h.opts.Secure || h.opts.TLSConfig != nil {
-		config := h.opts.TLSConfig
-		if config == nil {
-			config = &tls.Config{
-				InsecureSkipVerify: true,
-			}
+	// multiplex requests over a shared connection, streams keep a
+	// connection of their own
+	if h.opts.Multiplex && !dopts.Stream {
+		conn, err := h.muxConn(dctx, addr)
+		if err != nil {
+			return nil, err
 		}
-		config.NextProtos = []string{"http/1.1"}
-		conn, err = newConn(func(addr string) (net.Conn, error) {
-			return tls.DialWithDialer(&net.Dialer{Timeout: dopts.Timeout}, "tcp", addr, config)
-		})(addr)
-	} else {
-		conn, err = newConn(func(addr string) (net.Conn, error) {
-			return net.DialTimeout("tcp", addr, dopts.Timeout)
-		})(addr)
+		return newCompressClient(h.muxClient(conn, addr, dopts), h.opts), nil
 	}
 
+	conn, err := h.dial(dctx, addr, "http/1.1")
 	if err != nil {
 		return nil, err
 	}
 
-	return &httpTransportClient{
+	return newCompressClient(&httpTransportClient{
 		ht:       h,
 		addr:     addr,
 		conn:     conn,
@@ -519,7 +684,90 @@ func (h *httpTransport) Dial(addr string, opts ...DialOption) (Client, error) {
 		r:        make(chan *http.Request, 1),
 		local:    conn.LocalAddr().String(),
 		remote:   conn.RemoteAddr().String(),
-	}, nil
+	}, h.opts), nil
+}
+
+// dial connects to the address through the proxy if there is one, the
+// connection is secured negotiating the protocol if tls is enabled
+func (h *httpTransport) dial(ctx context.Context, addr, proto string) (net.Conn, error) {
+	// hosts resolving to both families are dialed happy eyeballs style
+	d := &net.Dialer{KeepAlive: h.opts.KeepAlive, FallbackDelay: h.opts.FallbackDelay}
+	o := h.opts.Observer
+	start := time.Now()
+
+	// dial the address, through the proxy if there is one
+	conn, err := newConn(ctx, d, h.opts.Proxy, addr)
+	if err != nil {
+		if o != nil {
+			o.Dialed(addr, time.Since(start), err)
+		}
+		return nil, err
+	}
+	conn = ObserveConn(conn, o)
+
+	// TODO: support dial option here rather than using internal config
+	if !(h.opts.Secure || h.opts.TLSConfig != nil) {
+		if o != nil {
+			o.Dialed(addr, time.Since(start), nil)
+		}
+		return conn, nil
+	}
+
+	config := h.opts.TLSConfig
+	if config == nil {
+		config = &tls.Config{
+			InsecureSkipVerify: true,
+		}
+	}
+	config = config.Clone()
+	config.NextProtos = []string{proto}
+
+	// verify the name of the address rather than that of a proxy
+	if len(config.ServerName) == 0 {
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			config.ServerName = host
+		} else {
+			config.ServerName = addr
+		}
+	}
+
+	// the handshake is bound to the dial
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := make(chan bool)
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		select {
+		case <-ctx.Done():
+			// fail the handshake
+			conn.SetDeadline(time.Unix(1, 0))
+		case <-stop:
+		}
+	}()
+	tc := tls.Client(conn, config)
+	err = tc.Handshake()
+	close(stop)
+	<-done
+	if err != nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		conn.Close()
+		if o != nil {
+			o.HandshakeFailed(addr, err)
+			o.Dialed(addr, time.Since(start), err)
+		}
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+
+	if o != nil {
+		o.Dialed(addr, time.Since(start), nil)
+	}
+
+	return tc, nil
 }
 
 func (h *httpTransport) Listen(addr string, opts ...ListenOption) (Listener, error) {
@@ -531,6 +779,9 @@ func (h *httpTransport) Listen(addr string, opts ...ListenOption) (Listener, err
 	var l net.Listener
 	var err error
 
+	// keep alives of accepted connections
+	lc := net.ListenConfig{KeepAlive: h.opts.KeepAlive}
+
 	// TODO: support use of listen options
 	if h.opts.Secure || h.opts.TLSConfig != nil {
 		config := h.opts.TLSConfig
@@ -555,13 +806,30 @@ func (h *httpTransport) Listen(addr string, opts ...ListenOption) (Listener, err
 				}
 				config = &tls.Config{Certificates: []tls.Certificate{cert}}
 			}
-			return tls.Listen("tcp", addr, config)
+			l, err := lc.Listen(context.Background(), "tcp", addr)
+			if err != nil {
+				return nil, err
+			}
+			l = ObserveListener(l, h.opts.Observer)
+			// limit before the handshake so the tls state is kept
+			l = LimitListener(l, options.MaxConnections, options.Reject)
+			// multiplexing clients negotiate http2
+			if len(config.NextProtos) == 0 {
+				config = config.Clone()
+				config.NextProtos = []string{"h2", "http/1.1"}
+			}
+			return TLSListener(l, config, h.opts.Observer), nil
 		}
 
 		l, err = mnet.Listen(addr, fn)
 	} else {
 		fn := func(addr string) (net.Listener, error) {
-			return net.Listen("tcp", addr)
+			l, err := lc.Listen(context.Background(), "tcp", addr)
+			if err != nil {
+				return nil, err
+			}
+			l = ObserveListener(l, h.opts.Observer)
+			return LimitListener(l, options.MaxConnections, options.Reject), nil
 		}
 
 		l, err = mnet.Listen(addr, fn)
@@ -581,6 +849,10 @@ func (h *httpTransport) Init(opts ...Option) error {
 	for _, o := range opts {
 		o(&h.opts)
 	}
+
+	// connections are multiplexed using the new options
+	h.closeMux()
+
 	return nil
 }
 
@@ -1,8 +1,15 @@
 package transport
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"io"
 	"net"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
@@ -244,3 +251,515 @@ func TestHTTPTransportTimeout(t *testing.T) {
 
 	<-done
 }
+
+func TestHTTPTransportContext(t *testing.T) {
+	tr := NewHTTPTransport()
+
+	l, err := tr.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected listen err: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		errc <- AcceptContext(ctx, l, func(sock Socket) {
+			sock.Close()
+		})
+	}()
+
+	// a done context fails the dial
+	dctx, dcancel := context.WithCancel(context.Background())
+	dcancel()
+	if _, err := DialContext(dctx, tr, l.Addr()); err == nil {
+		t.Fatal("Expected the dial to be cancelled")
+	}
+
+	c, err := DialContext(context.Background(), tr, l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected dial err: %v", err)
+	}
+	c.Close()
+
+	cancel()
+	select {
+	case err := <-errc:
+		if err != context.Canceled {
+			t.Fatalf("Expected the accept to be cancelled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the accept to return")
+	}
+}
+
+func TestHTTPTransportIdleTimeout(t *testing.T) {
+	tr := NewHTTPTransport(IdleTimeout(time.Millisecond*50), KeepAlive(time.Second))
+
+	l, err := tr.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected listen err: %v", err)
+	}
+	defer l.Close()
+
+	errc := make(chan error, 1)
+	go l.Accept(func(sock Socket) {
+		defer sock.Close()
+		for {
+			var m Message
+			if err := sock.Recv(&m); err != nil {
+				errc <- err
+				return
+			}
+			if err := sock.Send(&m); err != nil {
+				errc <- err
+				return
+			}
+		}
+	})
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected dial err: %v", err)
+	}
+	defer c.Close()
+
+	m := Message{Header: map[string]string{"Content-Type": "application/json"}, Body: []byte(`{}`)}
+	if err := c.Send(&m); err != nil {
+		t.Fatalf("Unexpected send err: %v", err)
+	}
+	if err := c.Recv(&m); err != nil {
+		t.Fatalf("Unexpected recv err: %v", err)
+	}
+
+	// the idle connection is closed by the server
+	select {
+	case err := <-errc:
+		if nerr, ok := err.(net.Error); !ok || !nerr.Timeout() {
+			t.Fatalf("Expected a timeout, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the idle connection to be closed")
+	}
+}
+
+func TestHTTPTransportMultiplex(t *testing.T) {
+	tr := NewHTTPTransport(Multiplex(true))
+
+	l, err := tr.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected listen err: %v", err)
+	}
+	defer l.Close()
+
+	remotes := make(chan string, 10)
+	go l.Accept(func(sock Socket) {
+		defer sock.Close()
+		remotes <- sock.Remote()
+		for {
+			var m Message
+			if err := sock.Recv(&m); err != nil {
+				return
+			}
+			if err := sock.Send(&m); err != nil {
+				return
+			}
+		}
+	})
+
+	errc := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			c, err := tr.Dial(l.Addr())
+			if err != nil {
+				errc <- err
+				return
+			}
+			defer c.Close()
+
+			body := []byte(`{"i": ` + strconv.Itoa(i) + `}`)
+			m := Message{Header: map[string]string{"Content-Type": "application/json"}, Body: body}
+			if err := c.Send(&m); err != nil {
+				errc <- err
+				return
+			}
+			var rsp Message
+			if err := c.Recv(&rsp); err != nil {
+				errc <- err
+				return
+			}
+			if string(rsp.Body) != string(body) {
+				errc <- fmt.Errorf("Expected %s got %s", body, rsp.Body)
+				return
+			}
+			errc <- nil
+		}(i)
+	}
+
+	for i := 0; i < 10; i++ {
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the requests shared a connection
+	remote := <-remotes
+	for i := 1; i < 10; i++ {
+		if r := <-remotes; r != remote {
+			t.Fatalf("Expected requests from %s got %s", remote, r)
+		}
+	}
+}
+
+func TestHTTPTransportMultiplexDialContext(t *testing.T) {
+	// accepts connections but never completes a handshake
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+
+	tr := NewHTTPTransport(Multiplex(true), Secure(true))
+
+	// cancelling the context fails the dial
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond*50, cancel)
+
+	start := time.Now()
+	if _, err := DialContext(ctx, tr, l.Addr().String(), WithTimeout(time.Minute)); err != context.Canceled {
+		t.Fatalf("Expected %v got %v", context.Canceled, err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Expected the dial to be cancelled got %v", d)
+	}
+
+	// the dial timeout bounds the dial
+	start = time.Now()
+	if _, err := tr.Dial(l.Addr().String(), WithTimeout(time.Millisecond*50)); err == nil {
+		t.Fatal("Expected the dial to time out")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Expected the dial to time out got %v", d)
+	}
+}
+
+// testObserver counts the events of connections
+type testObserver struct {
+	sync.Mutex
+	dialed, failed, opened, closed, sent, received int
+}
+
+func (o *testObserver) Dialed(addr string, latency time.Duration, err error) {
+	o.Lock()
+	defer o.Unlock()
+	if err == nil {
+		o.dialed++
+	}
+}
+
+func (o *testObserver) HandshakeFailed(remote string, err error) {
+	o.Lock()
+	defer o.Unlock()
+	o.failed++
+}
+
+func (o *testObserver) Opened(local, remote string) {
+	o.Lock()
+	defer o.Unlock()
+	o.opened++
+}
+
+func (o *testObserver) Closed(local, remote string) {
+	o.Lock()
+	defer o.Unlock()
+	o.closed++
+}
+
+func (o *testObserver) Sent(remote string, n int) {
+	o.Lock()
+	defer o.Unlock()
+	o.sent += n
+}
+
+func (o *testObserver) Received(remote string, n int) {
+	o.Lock()
+	defer o.Unlock()
+	o.received += n
+}
+
+func TestHTTPTransportObserve(t *testing.T) {
+	so, co := new(testObserver), new(testObserver)
+
+	l, err := NewHTTPTransport(Observe(so)).Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected listen err: %v", err)
+	}
+	defer l.Close()
+
+	done := make(chan bool)
+	go l.Accept(func(sock Socket) {
+		defer close(done)
+		defer sock.Close()
+		var m Message
+		if err := sock.Recv(&m); err != nil {
+			return
+		}
+		sock.Send(&m)
+	})
+
+	c, err := NewHTTPTransport(Observe(co)).Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected dial err: %v", err)
+	}
+
+	m := Message{Header: map[string]string{"Content-Type": "application/json"}, Body: []byte(`{}`)}
+	if err := c.Send(&m); err != nil {
+		t.Fatalf("Unexpected send err: %v", err)
+	}
+	if err := c.Recv(&m); err != nil {
+		t.Fatalf("Unexpected recv err: %v", err)
+	}
+	c.Close()
+	<-done
+
+	co.Lock()
+	defer co.Unlock()
+	so.Lock()
+	defer so.Unlock()
+
+	if co.dialed != 1 || co.opened != 1 || co.closed != 1 {
+		t.Fatalf("Expected a dialed, opened and closed connection got %+v", co)
+	}
+	if so.opened != 1 || so.closed != 1 {
+		t.Fatalf("Expected an opened and closed connection got %+v", so)
+	}
+	if co.sent == 0 || co.sent != so.received || so.sent == 0 || so.sent != co.received {
+		t.Fatalf("Expected the bytes sent to be received got %+v and %+v", co, so)
+	}
+}
+
+func TestHTTPTransportObserveTLS(t *testing.T) {
+	so := new(testObserver)
+
+	l, err := NewHTTPTransport(Secure(true), Observe(so)).Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected listen err: %v", err)
+	}
+	defer l.Close()
+
+	// the connection handshaken by the listener is still tls
+	secure := make(chan bool, 1)
+	go l.Accept(func(sock Socket) {
+		defer sock.Close()
+		secure <- sock.(*httpTransportSocket).r.TLS != nil
+		var m Message
+		if err := sock.Recv(&m); err != nil {
+			return
+		}
+		sock.Send(&m)
+	})
+
+	c, err := NewHTTPTransport(Secure(true)).Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected dial err: %v", err)
+	}
+	defer c.Close()
+
+	m := Message{Header: map[string]string{"Content-Type": "application/json"}, Body: []byte(`{}`)}
+	if err := c.Send(&m); err != nil {
+		t.Fatalf("Unexpected send err: %v", err)
+	}
+	if err := c.Recv(&m); err != nil {
+		t.Fatalf("Unexpected recv err: %v", err)
+	}
+	if !<-secure {
+		t.Fatal("Expected a tls connection")
+	}
+
+	so.Lock()
+	defer so.Unlock()
+	if so.failed != 0 || so.opened != 1 {
+		t.Fatalf("Expected an opened connection got %+v", so)
+	}
+}
+
+func TestHTTPTransportObserveHandshake(t *testing.T) {
+	so, co := new(testObserver), new(testObserver)
+
+	l, err := NewHTTPTransport(Secure(true), Observe(so)).Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected listen err: %v", err)
+	}
+	defer l.Close()
+	go l.Accept(func(sock Socket) {
+		sock.Close()
+	})
+
+	// the self signed certificate of the server isn't trusted
+	if _, err := NewHTTPTransport(TLSConfig(&tls.Config{}), Observe(co)).Dial(l.Addr()); err == nil {
+		t.Fatal("Expected the handshake to fail")
+	}
+
+	for i := 0; i < 100; i++ {
+		so.Lock()
+		failed := so.failed
+		so.Unlock()
+		if failed > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	co.Lock()
+	defer co.Unlock()
+	so.Lock()
+	defer so.Unlock()
+
+	if co.failed != 1 || co.dialed != 0 {
+		t.Fatalf("Expected a failed handshake got %+v", co)
+	}
+	if so.failed != 1 {
+		t.Fatalf("Expected the server to observe the failed handshake got %+v", so)
+	}
+}
+
+func TestHTTPTransportIPv6(t *testing.T) {
+	if l, err := net.Listen("tcp", "[::1]:0"); err != nil {
+		t.Skip("ipv6 isn't supported")
+	} else {
+		l.Close()
+	}
+
+	tr := NewHTTPTransport()
+
+	l, err := tr.Listen("[::1]:0")
+	if err != nil {
+		t.Fatalf("Unexpected listen err: %v", err)
+	}
+	defer l.Close()
+
+	go l.Accept(func(sock Socket) {
+		defer sock.Close()
+		var m Message
+		if err := sock.Recv(&m); err != nil {
+			return
+		}
+		sock.Send(&m)
+	})
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		t.Fatalf("Unexpected dial err: %v", err)
+	}
+	defer c.Close()
+
+	m := Message{Header: map[string]string{"Content-Type": "application/json"}, Body: []byte(`{}`)}
+	if err := c.Send(&m); err != nil {
+		t.Fatalf("Unexpected send err: %v", err)
+	}
+	if err := c.Recv(&m); err != nil {
+		t.Fatalf("Unexpected recv err: %v", err)
+	}
+}
+
+func benchmarkHTTPTransport(b *testing.B, size int, opts ...Option) {
+	tr := NewHTTPTransport(opts...)
+
+	l, err := tr.Listen("127.0.0.1:0")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer l.Close()
+
+	go l.Accept(func(sock Socket) {
+		defer sock.Close()
+
+		for {
+			var m Message
+			if err := sock.Recv(&m); err != nil {
+				return
+			}
+			if err := sock.Send(&m); err != nil {
+				return
+			}
+		}
+	})
+
+	c, err := tr.Dial(l.Addr())
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer c.Close()
+
+	m := Message{
+		Header: map[string]string{
+			"Content-Type": "application/octet-stream",
+		},
+		Body: bytes.Repeat([]byte("a"), size),
+	}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := c.Send(&m); err != nil {
+			b.Fatal(err)
+		}
+		var rm Message
+		if err := c.Recv(&rm); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHTTPTransport1K(b *testing.B) {
+	benchmarkHTTPTransport(b, 1024)
+}
+
+func BenchmarkHTTPTransport64K(b *testing.B) {
+	benchmarkHTTPTransport(b, 64*1024)
+}
+
+func BenchmarkHTTPTransportMultiplex1K(b *testing.B) {
+	benchmarkHTTPTransport(b, 1024, Multiplex(true))
+}
+
+func TestHTTPTransportMaxRecvSize(t *testing.T) {
+	l, err := NewHTTPTransport(MaxRecvSize(4)).Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected listen err: %v", err)
+	}
+	defer l.Close()
+
+	// the request is rejected before it reaches a socket
+	go l.Accept(func(sock Socket) {
+		sock.Close()
+	})
+
+	for _, tr := range []Transport{NewHTTPTransport(), NewHTTPTransport(Multiplex(true))} {
+		c, err := tr.Dial(l.Addr())
+		if err != nil {
+			t.Fatalf("Unexpected dial err: %v", err)
+		}
+		defer c.Close()
+
+		if err := c.Send(&Message{Header: map[string]string{}, Body: []byte(`ping!`)}); err != nil {
+			t.Fatalf("Unexpected send err: %v", err)
+		}
+
+		var m Message
+		if err := c.Recv(&m); !errors.Is(err, ErrMessageTooLarge) {
+			t.Fatalf("Expected %v got %v", ErrMessageTooLarge, err)
+		}
+	}
+}
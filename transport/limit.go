@@ -0,0 +1,89 @@
+package transport
+
+import (
+	"net"
+	"sync"
+)
+
+// limitListener limits the number of concurrent connections of a listener
+type limitListener struct {
+	net.Listener
+	sem    chan struct{}
+	reject bool
+
+	once sync.Once
+	done chan struct{}
+}
+
+type limitConn struct {
+	net.Conn
+	release func()
+	once    sync.Once
+}
+
+// LimitListener limits the number of concurrent connections accepted by the
+// listener. Connections beyond the maximum are queued until others close or
+// closed on being accepted if reject is true, there's no limit if max is zero
+func LimitListener(l net.Listener, max int, reject bool) net.Listener {
+	if max <= 0 {
+		return l
+	}
+	return &limitListener{
+		Listener: l,
+		sem:      make(chan struct{}, max),
+		reject:   reject,
+		done:     make(chan struct{}),
+	}
+}
+
+func (l *limitListener) Accept() (net.Conn, error) {
+	for {
+		// wait for a connection to close
+		if !l.reject {
+			select {
+			case l.sem <- struct{}{}:
+			case <-l.done:
+				return nil, net.ErrClosed
+			}
+		}
+
+		c, err := l.Listener.Accept()
+		if err != nil {
+			if !l.reject {
+				<-l.sem
+			}
+			return nil, err
+		}
+
+		// queued connections already hold a slot
+		if !l.reject {
+			return &limitConn{Conn: c, release: l.release}, nil
+		}
+
+		select {
+		case l.sem <- struct{}{}:
+			return &limitConn{Conn: c, release: l.release}, nil
+		default:
+			// too many connections
+			c.Close()
+		}
+	}
+}
+
+func (l *limitListener) Close() error {
+	err := l.Listener.Close()
+	l.once.Do(func() {
+		close(l.done)
+	})
+	return err
+}
+
+func (l *limitListener) release() {
+	<-l.sem
+}
+
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
@@ -0,0 +1,155 @@
+package transport
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLimitListener(t *testing.T) {
+	testData := []struct {
+		name   string
+		reject bool
+	}{
+		{name: "queue"},
+		{name: "reject", reject: true},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			l := LimitListener(ln, 1, d.reject)
+			defer l.Close()
+
+			accepted := make(chan net.Conn, 2)
+			go func() {
+				for {
+					c, err := l.Accept()
+					if err != nil {
+						return
+					}
+					accepted <- c
+				}
+			}()
+
+			c1, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c1.Close()
+
+			var s1 net.Conn
+			select {
+			case s1 = <-accepted:
+			case <-time.After(time.Second):
+				t.Fatal("Expected the first connection to be accepted")
+			}
+
+			c2, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c2.Close()
+
+			if d.reject {
+				// the connection beyond the limit is closed
+				c2.SetReadDeadline(time.Now().Add(time.Second))
+				if _, err := c2.Read(make([]byte, 1)); err != io.EOF {
+					t.Fatalf("Expected the connection to be closed got %v", err)
+				}
+				s1.Close()
+				return
+			}
+
+			// the connection beyond the limit is queued
+			select {
+			case <-accepted:
+				t.Fatal("Expected the second connection to be queued")
+			case <-time.After(time.Millisecond * 100):
+			}
+
+			s1.Close()
+
+			select {
+			case s2 := <-accepted:
+				s2.Close()
+			case <-time.After(time.Second):
+				t.Fatal("Expected the second connection to be accepted once the first closed")
+			}
+		})
+	}
+}
+
+func TestHTTPTransportMaxConnections(t *testing.T) {
+	testData := []struct {
+		name   string
+		secure bool
+	}{
+		{name: "plain"},
+		{name: "tls", secure: true},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			tr := NewHTTPTransport(Secure(d.secure))
+
+			l, err := tr.Listen("127.0.0.1:0", MaxConnections(1), RejectConnections())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer l.Close()
+
+			req := make(chan *http.Request, 1)
+			go l.Accept(func(sock Socket) {
+				req <- sock.(*httpTransportSocket).r
+				for {
+					var m Message
+					if err := sock.Recv(&m); err != nil {
+						return
+					}
+					if err := sock.Send(&m); err != nil {
+						return
+					}
+				}
+			})
+
+			c1, err := tr.Dial(l.Addr())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c1.Close()
+
+			m := Message{Header: map[string]string{"Content-Type": "application/json"}, Body: []byte(`{}`)}
+			if err := c1.Send(&m); err != nil {
+				t.Fatal(err)
+			}
+			if err := c1.Recv(&Message{}); err != nil {
+				t.Fatal(err)
+			}
+
+			// the tls state of the connection is kept
+			if r := <-req; d.secure && r.TLS == nil {
+				t.Fatal("Expected the tls state of the connection")
+			}
+
+			// the second connection is rejected while the first is open
+			c2, err := tr.Dial(l.Addr())
+			if err != nil {
+				// the tls handshake failed
+				return
+			}
+			defer c2.Close()
+
+			if err := c2.Send(&m); err == nil {
+				if err := c2.Recv(&Message{}); err == nil {
+					t.Fatal("Expected the second connection to be rejected")
+				}
+			}
+		})
+	}
+}
@@ -27,6 +27,9 @@ type memorySocket struct {
 	// for send/recv Timeout
 	timeout time.Duration
 	ctx     context.Context
+	// maximum body sizes
+	maxSend int
+	maxRecv int
 	sync.RWMutex
 }
 
@@ -72,7 +75,7 @@ func (ms *memorySocket) Recv(m *Message) error {
 	case cm := <-ms.recv:
 		*m = *cm
 	}
-	return nil
+	return CheckSize(len(m.Body), ms.maxRecv)
 }
 
 func (ms *memorySocket) Local() string {
@@ -84,6 +87,10 @@ func (ms *memorySocket) Remote() string {
 }
 
 func (ms *memorySocket) Send(m *Message) error {
+	if err := CheckSize(len(m.Body), ms.maxSend); err != nil {
+		return err
+	}
+
 	ms.RLock()
 	defer ms.RUnlock()
 
@@ -135,12 +142,21 @@ func (m *memoryListener) Close() error {
 }
 
 func (m *memoryListener) Accept(fn func(Socket)) error {
+	return m.AcceptContext(context.Background(), fn)
+}
+
+// AcceptContext accepts connections until the listener is closed or the
+// context is done
+func (m *memoryListener) AcceptContext(ctx context.Context, fn func(Socket)) error {
 	for {
 		select {
+		case <-ctx.Done():
+			m.Close()
+			return ctx.Err()
 		case <-m.exit:
 			return nil
 		case c := <-m.conn:
-			go fn(&memorySocket{
+			go fn(newCompressSocket(&memorySocket{
 				lexit:   c.lexit,
 				exit:    c.exit,
 				send:    c.recv,
@@ -149,12 +165,19 @@ func (m *memoryListener) Accept(fn func(Socket)) error {
 				remote:  c.Local(),
 				timeout: m.topts.Timeout,
 				ctx:     m.topts.Context,
-			})
+				maxSend: m.topts.MaxSendSize,
+				maxRecv: m.topts.MaxRecvSize,
+			}, m.topts))
 		}
 	}
 }
 
 func (m *memoryTransport) Dial(addr string, opts ...DialOption) (Client, error) {
+	return m.DialContext(context.Background(), addr, opts...)
+}
+
+// DialContext connects to the listener, returning when the context is done
+func (m *memoryTransport) DialContext(ctx context.Context, addr string, opts ...DialOption) (Client, error) {
 	m.RLock()
 	defer m.RUnlock()
 
@@ -178,18 +201,22 @@ func (m *memoryTransport) Dial(addr string, opts ...DialOption) (Client, error)
 			remote:  addr,
 			timeout: m.opts.Timeout,
 			ctx:     m.opts.Context,
+			maxSend: m.opts.MaxSendSize,
+			maxRecv: m.opts.MaxRecvSize,
 		},
 		options,
 	}
 
 	// pseudo connect
 	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	case <-listener.exit:
 		return nil, errors.New("connection error")
 	case listener.conn <- client.memorySocket:
 	}
 
-	return client, nil
+	return newCompressClient(client, m.opts), nil
 }
 
 func (m *memoryTransport) Listen(addr string, opts ...ListenOption) (Listener, error) {
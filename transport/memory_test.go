@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"errors"
 	"os"
 	"testing"
 )
@@ -90,3 +91,47 @@ func TestListener(t *testing.T) {
 		t.Fatal("Expected error binding to :8080 got nil")
 	}
 }
+
+func TestMemoryTransportMaxSize(t *testing.T) {
+	tr := NewMemoryTransport(MaxSendSize(8), MaxRecvSize(4))
+
+	l, err := tr.Listen("127.0.0.1:8081")
+	if err != nil {
+		t.Fatalf("Unexpected error listening %v", err)
+	}
+	defer l.Close()
+
+	errc := make(chan error, 1)
+	go l.Accept(func(sock Socket) {
+		for i := 0; i < 2; i++ {
+			var m Message
+			errc <- sock.Recv(&m)
+		}
+	})
+
+	c, err := tr.Dial("127.0.0.1:8081")
+	if err != nil {
+		t.Fatalf("Unexpected error dialing %v", err)
+	}
+	defer c.Close()
+
+	// larger than the maximum send size
+	if err := c.Send(&Message{Body: []byte(`ping ping`)}); !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("Expected %v got %v", ErrMessageTooLarge, err)
+	}
+
+	// within the send size but larger than the receive size
+	if err := c.Send(&Message{Body: []byte(`ping`)}); err != nil {
+		t.Fatalf("Unexpected error sending %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("Unexpected error receiving %v", err)
+	}
+
+	if err := c.Send(&Message{Body: []byte(`ping!`)}); err != nil {
+		t.Fatalf("Unexpected error sending %v", err)
+	}
+	if err := <-errc; !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("Expected %v got %v", ErrMessageTooLarge, err)
+	}
+}
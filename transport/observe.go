@@ -0,0 +1,177 @@
+package transport
+
+import (
+	"crypto/tls"
+	"net"
+	"sync"
+	"time"
+)
+
+// handshakeTimeout bounds the tls handshake of accepted connections
+const handshakeTimeout = time.Second * 10
+
+// Observer is notified of the connections of a transport e.g to export
+// metrics. It's called synchronously so it must not block
+type Observer interface {
+	// Dialed is called once a dial completes with its latency
+	Dialed(addr string, latency time.Duration, err error)
+	// HandshakeFailed is called if the tls handshake of a connection fails
+	HandshakeFailed(remote string, err error)
+	// Opened is called when a connection is dialed or accepted
+	Opened(local, remote string)
+	// Closed is called when a connection is closed
+	Closed(local, remote string)
+	// Sent is called with the number of bytes written to a connection
+	Sent(remote string, n int)
+	// Received is called with the number of bytes read from a connection
+	Received(remote string, n int)
+}
+
+type observedConn struct {
+	net.Conn
+	o    Observer
+	once sync.Once
+}
+
+type observedListener struct {
+	net.Listener
+	o Observer
+}
+
+// handshakeListener completes the tls handshake of accepted connections
+// before returning them so failed handshakes can be observed
+type handshakeListener struct {
+	net.Listener
+	config *tls.Config
+	o      Observer
+
+	conns chan net.Conn
+	errs  chan error
+	once  sync.Once
+	done  chan struct{}
+}
+
+// ObserveConn notifies the observer of the reads, writes and close of the
+// connection, it's notified the connection was opened
+func ObserveConn(c net.Conn, o Observer) net.Conn {
+	if o == nil {
+		return c
+	}
+	o.Opened(c.LocalAddr().String(), c.RemoteAddr().String())
+	return &observedConn{Conn: c, o: o}
+}
+
+// ObserveListener observes the connections accepted by the listener
+func ObserveListener(l net.Listener, o Observer) net.Listener {
+	if o == nil {
+		return l
+	}
+	return &observedListener{Listener: l, o: o}
+}
+
+func (c *observedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.o.Received(c.RemoteAddr().String(), n)
+	}
+	return n, err
+}
+
+func (c *observedConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.o.Sent(c.RemoteAddr().String(), n)
+	}
+	return n, err
+}
+
+func (c *observedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() {
+		c.o.Closed(c.LocalAddr().String(), c.RemoteAddr().String())
+	})
+	return err
+}
+
+func (l *observedListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return ObserveConn(c, l.o), nil
+}
+
+// TLSListener returns a listener of tls connections, the observer is notified
+// of connections which fail the handshake
+func TLSListener(l net.Listener, config *tls.Config, o Observer) net.Listener {
+	if o == nil {
+		return tls.NewListener(l, config)
+	}
+
+	h := &handshakeListener{
+		Listener: l,
+		config:   config,
+		o:        o,
+		conns:    make(chan net.Conn),
+		errs:     make(chan error),
+		done:     make(chan struct{}),
+	}
+	go h.accept()
+	return h
+}
+
+func (h *handshakeListener) accept() {
+	for {
+		c, err := h.Listener.Accept()
+		if err != nil {
+			select {
+			case h.errs <- err:
+			case <-h.done:
+				return
+			}
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				continue
+			}
+			return
+		}
+
+		// handshakes don't hold up accepting other connections
+		go h.handshake(c)
+	}
+}
+
+func (h *handshakeListener) handshake(c net.Conn) {
+	tc := tls.Server(c, h.config)
+
+	c.SetDeadline(time.Now().Add(handshakeTimeout))
+	if err := tc.Handshake(); err != nil {
+		h.o.HandshakeFailed(c.RemoteAddr().String(), err)
+		c.Close()
+		return
+	}
+	c.SetDeadline(time.Time{})
+
+	select {
+	case h.conns <- tc:
+	case <-h.done:
+		tc.Close()
+	}
+}
+
+func (h *handshakeListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-h.conns:
+		return c, nil
+	case err := <-h.errs:
+		return nil, err
+	case <-h.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (h *handshakeListener) Close() error {
+	h.once.Do(func() {
+		close(h.done)
+	})
+	return h.Listener.Close()
+}
@@ -2,6 +2,8 @@
 package transport
 
 import (
+	"errors"
+	"fmt"
 	"time"
 )
 
@@ -16,6 +18,21 @@ type Transport interface {
 	String() string
 }
 
+// PeerSocket is implemented by sockets of transports which verify the
+// peer from its tls certificate
+type PeerSocket interface {
+	// Peer returns the name of the verified peer, it's empty if the peer
+	// wasn't verified
+	Peer() string
+}
+
+// ProbeClient is implemented by clients of transports which can check the
+// connection is still open without sending a message
+type ProbeClient interface {
+	// Probe returns an error if the connection was closed
+	Probe() error
+}
+
 type Message struct {
 	Header map[string]string
 	Body   []byte
@@ -45,6 +62,21 @@ type DialOption func(*DialOptions)
 
 type ListenOption func(*ListenOptions)
 
+var (
+	// ErrMessageTooLarge is returned when the body of a message exceeds
+	// the maximum size sent or received by the transport
+	ErrMessageTooLarge = errors.New("message too large")
+)
+
+// CheckSize returns an error wrapping ErrMessageTooLarge if the size exceeds
+// the maximum, there's no maximum if it's zero
+func CheckSize(size, max int) error {
+	if max > 0 && size > max {
+		return fmt.Errorf("%w: %d bytes exceeds the maximum of %d", ErrMessageTooLarge, size, max)
+	}
+	return nil
+}
+
 var (
 	DefaultTransport Transport = NewHTTPTransport()
 
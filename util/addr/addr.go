@@ -3,6 +3,7 @@ package addr
 import (
 	"fmt"
 	"net"
+	"strings"
 )
 
 var (
@@ -59,10 +60,15 @@ func IsLocal(addr string) bool {
 	return false
 }
 
-// Extract returns a real ip
+// Extract returns a real ip. An address of an interface is returned if the
+// address is unspecified e.g 0.0.0.0 or ::, private addresses are preferred
+// over public ones and those of the family of the address over the other
 func Extract(addr string) (string, error) {
+	// strip the brackets of an ipv6 address
+	addr = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+
 	// if addr specified then its returned
-	if len(addr) > 0 && (addr != "0.0.0.0" && addr != "[::]" && addr != "::") {
+	if len(addr) > 0 && (addr != "0.0.0.0" && addr != "::") {
 		return addr, nil
 	}
 
@@ -88,48 +94,56 @@ func Extract(addr string) (string, error) {
 	}
 	addrs = append(addrs, loAddrs...)
 
-	var ipAddr string
-	var publicIP string
+	var ip net.IP
+	var rank int
 
 	for _, rawAddr := range addrs {
-		var ip net.IP
+		var a net.IP
 		switch addr := rawAddr.(type) {
 		case *net.IPAddr:
-			ip = addr.IP
+			a = addr.IP
 		case *net.IPNet:
-			ip = addr.IP
+			a = addr.IP
 		default:
 			continue
 		}
 
-		if !isPrivateIP(ip.String()) {
-			publicIP = ip.String()
+		// link local addresses can't be dialed without a zone
+		if a == nil || a.IsUnspecified() || a.IsLinkLocalUnicast() {
 			continue
 		}
 
-		ipAddr = ip.String()
-		break
+		// the first address of the best rank is returned
+		if r := rankIP(a, addr); ip == nil || r < rank {
+			ip = a
+			rank = r
+		}
 	}
 
-	// return private ip
-	if len(ipAddr) > 0 {
-		a := net.ParseIP(ipAddr)
-		if a == nil {
-			return "", fmt.Errorf("ip addr %s is invalid", ipAddr)
-		}
-		return a.String(), nil
+	if ip == nil {
+		return "", fmt.Errorf("No IP address found, and explicit IP not provided")
 	}
 
-	// return public or virtual ip
-	if len(publicIP) > 0 {
-		a := net.ParseIP(publicIP)
-		if a == nil {
-			return "", fmt.Errorf("ip addr %s is invalid", publicIP)
-		}
-		return a.String(), nil
+	return ip.String(), nil
+}
+
+// rankIP ranks private, public and then loopback addresses, those of the
+// family of the unspecified address rank before those of the other
+func rankIP(ip net.IP, unspecified string) int {
+	var rank int
+	switch {
+	case ip.IsLoopback():
+		rank = 4
+	case !isPrivateIP(ip.String()):
+		rank = 1
+	}
+
+	v4 := ip.To4() != nil
+	if (unspecified == "0.0.0.0" && !v4) || (unspecified == "::" && v4) {
+		rank += 2
 	}
 
-	return "", fmt.Errorf("No IP address found, and explicit IP not provided")
+	return rank
 }
 
 // IPs returns all known ips
@@ -36,6 +36,9 @@ func TestExtractor(t *testing.T) {
 		{"", "", true},
 		{"0.0.0.0", "", true},
 		{"[::]", "", true},
+		{"::", "", true},
+		{"::1", "::1", false},
+		{"[::1]", "::1", false},
 	}
 
 	for _, d := range testData {
@@ -57,6 +60,28 @@ func TestExtractor(t *testing.T) {
 
 }
 
+func TestRankIP(t *testing.T) {
+	testData := []struct {
+		unspecified string
+		// addresses in order of preference
+		ips []string
+	}{
+		{"", []string{"10.0.0.1", "8.8.8.8", "127.0.0.1"}},
+		{"", []string{"fd00::1", "2001:db8::1", "::1"}},
+		{"0.0.0.0", []string{"10.0.0.1", "8.8.8.8", "fd00::1", "2001:db8::1", "127.0.0.1"}},
+		{"::", []string{"fd00::1", "2001:db8::1", "10.0.0.1", "8.8.8.8", "::1"}},
+	}
+
+	for _, d := range testData {
+		for i := 1; i < len(d.ips); i++ {
+			a, b := net.ParseIP(d.ips[i-1]), net.ParseIP(d.ips[i])
+			if rankIP(a, d.unspecified) >= rankIP(b, d.unspecified) {
+				t.Errorf("Expected %s to rank before %s for %q", a, b, d.unspecified)
+			}
+		}
+	}
+}
+
 func TestAppendPrivateBlocks(t *testing.T) {
 	tests := []struct {
 		addr   string
@@ -0,0 +1,34 @@
+// Package auth provides helpers for service authentication
+package auth
+
+import (
+	"github.com/asim/go-micro/v3/auth"
+	"github.com/asim/go-micro/v3/logger"
+)
+
+// Generate generates the service account if credentials weren't provided, the
+// account credentials are exchanged for tokens by the auth client wrapper.
+func Generate(id string, name string, a auth.Auth) error {
+	// the account credentials were provided
+	if len(a.Options().ID) > 0 || len(a.Options().Secret) > 0 {
+		return nil
+	}
+
+	acc, err := a.Generate(id,
+		auth.WithType("service"),
+		auth.WithScopes("service"),
+		auth.WithMetadata(map[string]string{"name": name}),
+	)
+	if err != nil {
+		return err
+	}
+
+	if logger.V(logger.DebugLevel, logger.DefaultLogger) {
+		logger.Debugf("Auth [%v] generated an account for %s", a.String(), name)
+	}
+
+	// set the credentials the service uses to get tokens
+	a.Init(auth.Credentials(acc.ID, acc.Secret))
+
+	return nil
+}
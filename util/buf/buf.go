@@ -2,8 +2,19 @@ package buf
 
 import (
 	"bytes"
+	"sync"
 )
 
+// maxSize is the capacity beyond which buffers aren't returned to the pool
+// so a single large message doesn't keep its memory alive
+const maxSize = 1024 * 1024
+
+var pool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
 type buffer struct {
 	*bytes.Buffer
 }
@@ -19,3 +30,18 @@ func New(b *bytes.Buffer) *buffer {
 	}
 	return &buffer{b}
 }
+
+// Get returns an empty buffer from the pool
+func Get() *bytes.Buffer {
+	return pool.Get().(*bytes.Buffer)
+}
+
+// Put returns the buffer to the pool, its contents must no longer be
+// referenced once it's put back
+func Put(b *bytes.Buffer) {
+	if b == nil || b.Cap() > maxSize {
+		return
+	}
+	b.Reset()
+	pool.Put(b)
+}
@@ -0,0 +1,29 @@
+package buf
+
+import (
+	"testing"
+)
+
+func TestPool(t *testing.T) {
+	b := Get()
+	b.WriteString("hello")
+	Put(b)
+
+	if b.Len() != 0 {
+		t.Fatalf("Expected the buffer to be reset, got %d bytes", b.Len())
+	}
+
+	// large buffers aren't reset as they're not pooled
+	l := Get()
+	l.Grow(maxSize + 1)
+	l.WriteString("hello")
+	Put(l)
+
+	if l.Len() == 0 {
+		t.Fatal("Expected the large buffer not to be pooled")
+	}
+
+	if n := Get().Len(); n != 0 {
+		t.Fatalf("Expected an empty buffer, got %d bytes", n)
+	}
+}
@@ -310,6 +310,14 @@ func NewDeployment(name, version, typ, namespace string) *Deployment {
 		Selector: &LabelSelector{
 			MatchLabels: Labels,
 		},
+		// start the new pod before stopping the old one on update
+		Strategy: &DeploymentStrategy{
+			Type: "RollingUpdate",
+			RollingUpdate: &RollingUpdate{
+				MaxSurge:       1,
+				MaxUnavailable: 0,
+			},
+		},
 		Template: &Template{
 			Metadata: Metadata,
 			PodSpec: &PodSpec{
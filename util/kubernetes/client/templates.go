@@ -37,6 +37,15 @@ spec:
       {{ $key }}: "{{ $value }}"
       {{- end }}
       {{- end }}
+  {{- with .Spec.Strategy }}
+  strategy:
+    type: {{ .Type }}
+    {{- with .RollingUpdate }}
+    rollingUpdate:
+      maxSurge: {{ .MaxSurge }}
+      maxUnavailable: {{ .MaxUnavailable }}
+    {{- end }}
+  {{- end }}
   template:
     metadata:
       labels:
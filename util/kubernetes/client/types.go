@@ -15,9 +15,10 @@ type EnvVar struct {
 }
 
 type Condition struct {
-	Started string `json:"startedAt,omitempty"`
-	Reason  string `json:"reason,omitempty"`
-	Message string `json:"message,omitempty"`
+	Started  string `json:"startedAt,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Message  string `json:"message,omitempty"`
+	ExitCode int    `json:"exitCode,omitempty"`
 }
 
 // Container defined container runtime values
@@ -32,9 +33,22 @@ type Container struct {
 
 // DeploymentSpec defines micro deployment spec
 type DeploymentSpec struct {
-	Replicas int            `json:"replicas,omitempty"`
-	Selector *LabelSelector `json:"selector"`
-	Template *Template      `json:"template,omitempty"`
+	Replicas int                 `json:"replicas,omitempty"`
+	Selector *LabelSelector      `json:"selector"`
+	Strategy *DeploymentStrategy `json:"strategy,omitempty"`
+	Template *Template           `json:"template,omitempty"`
+}
+
+// DeploymentStrategy describes how pods are replaced on update
+type DeploymentStrategy struct {
+	Type          string         `json:"type"`
+	RollingUpdate *RollingUpdate `json:"rollingUpdate,omitempty"`
+}
+
+// RollingUpdate limits the pods added and removed during a rolling update
+type RollingUpdate struct {
+	MaxSurge       int `json:"maxSurge"`
+	MaxUnavailable int `json:"maxUnavailable"`
 }
 
 // DeploymentCondition describes the state of deployment
@@ -126,7 +140,8 @@ type PodCondition struct {
 }
 
 type ContainerStatus struct {
-	State ContainerState `json:"state"`
+	State        ContainerState `json:"state"`
+	RestartCount int            `json:"restartCount"`
 }
 
 type ContainerState struct {
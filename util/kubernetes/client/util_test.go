@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 )
 
@@ -24,6 +25,15 @@ func TestTemplates(t *testing.T) {
 	if err := renderTemplate(templates["deployment"], bd, d); err != nil {
 		t.Errorf("Failed to render kubernetes deployment: %v", err)
 	}
+
+	// Render the rolling update strategy
+	bd.Reset()
+	if err := renderTemplate("deployment", bd, d); err != nil {
+		t.Fatalf("Failed to render kubernetes deployment: %v", err)
+	}
+	if !strings.Contains(bd.String(), "type: RollingUpdate") || !strings.Contains(bd.String(), "maxUnavailable: 0") {
+		t.Errorf("Expected rolling update strategy in deployment: %s", bd.String())
+	}
 }
 
 func TestFormatName(t *testing.T) {
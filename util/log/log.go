@@ -1,5 +1,7 @@
 // Package log is a global internal logger
-// DEPRECATED: this is frozen package, use github.com/asim/go-micro/v3/logger
+//
+// Deprecated: this is a frozen package, the functions log with the
+// logger.DefaultLogger, use github.com/asim/go-micro/v3/logger instead
 package log
 
 import (
@@ -104,36 +106,44 @@ func (el *elog) Stream() (dlog.Stream, error) {
 	return el.dlog.Stream()
 }
 
-// Log makes use of github.com/micro/debug/log
+// Log logs with the current level
 func Log(v ...interface{}) {
-	if len(prefix) > 0 {
-		v = append([]interface{}{prefix, " "}, v...)
-	}
-	nlog.DefaultLogger.Log(levelToLevel(level), v)
+	log(GetLevel(), v...)
 }
 
-// Logf makes use of github.com/micro/debug/log
+// Logf logs with the current level
 func Logf(format string, v ...interface{}) {
-	if len(prefix) > 0 {
-		format = prefix + " " + format
-	}
-	nlog.DefaultLogger.Logf(levelToLevel(level), format, v)
+	logf(GetLevel(), format, v...)
 }
 
 // WithLevel logs with the level specified
 func WithLevel(l Level, v ...interface{}) {
-	if l > level {
+	if l > GetLevel() {
 		return
 	}
-	Log(v...)
+	log(l, v...)
 }
 
 // WithLevel logs with the level specified
 func WithLevelf(l Level, format string, v ...interface{}) {
-	if l > level {
+	if l > GetLevel() {
 		return
 	}
-	Logf(format, v...)
+	logf(l, format, v...)
+}
+
+func log(l Level, v ...interface{}) {
+	if len(prefix) > 0 {
+		v = append([]interface{}{prefix, " "}, v...)
+	}
+	nlog.DefaultLogger.Log(levelToLevel(l), v...)
+}
+
+func logf(l Level, format string, v ...interface{}) {
+	if len(prefix) > 0 {
+		format = prefix + " " + format
+	}
+	nlog.DefaultLogger.Logf(levelToLevel(l), format, v...)
 }
 
 // Trace provides trace level logging
@@ -189,11 +199,13 @@ func Errorf(format string, v ...interface{}) {
 // Fatal logs with Log and then exits with os.Exit(1)
 func Fatal(v ...interface{}) {
 	WithLevel(LevelFatal, v...)
+	os.Exit(1)
 }
 
 // Fatalf logs with Logf and then exits with os.Exit(1)
 func Fatalf(format string, v ...interface{}) {
 	WithLevelf(LevelFatal, format, v...)
+	os.Exit(1)
 }
 
 // SetLogger sets the local logger
@@ -213,7 +225,7 @@ func SetLevel(l Level) {
 
 // GetLevel returns the current level
 func GetLevel() Level {
-	return level
+	return Level(atomic.LoadInt32((*int32)(&level)))
 }
 
 // Set a prefix for the logger
@@ -0,0 +1,145 @@
+package mtls
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/asim/go-micro/v3/auth"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/util/pki"
+)
+
+// SignRequest asks the CA to certify a service
+type SignRequest struct {
+	Service string `json:"service"`
+	// CSR in PEM format, the common name must be the service
+	CSR []byte `json:"csr"`
+}
+
+// SignResponse is the certificate issued by the CA
+type SignResponse struct {
+	// Certificate in PEM format
+	Certificate []byte `json:"certificate"`
+	// CA certificate in PEM format
+	CA []byte `json:"ca"`
+}
+
+// CA is the handler of the CA service. It signs short lived certificates
+// bound to the name of the service requesting them e.g
+//
+//	micro.RegisterHandler(service.Server(), ca)
+type CA struct {
+	opts Options
+	cert []byte
+	key  []byte
+}
+
+// GenerateCA generates a self signed CA certificate and key in PEM format
+func GenerateCA(name string, ttl time.Duration) ([]byte, []byte, error) {
+	pub, priv, err := pki.GenerateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	return pki.CA(
+		pki.KeyPair(pub, priv),
+		pki.Subject(pkix.Name{CommonName: name}),
+		pki.SerialNumber(serial),
+		pki.NotBefore(time.Now().Add(-time.Minute)),
+		pki.NotAfter(time.Now().Add(ttl)),
+		pki.ExtKeyUsage(x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth),
+	)
+}
+
+// NewCA returns the CA handler signing with the certificate and key in PEM format
+func NewCA(cert, key []byte, opts ...Option) (*CA, error) {
+	if _, err := tls.X509KeyPair(cert, key); err != nil {
+		return nil, err
+	}
+	return &CA{
+		opts: NewOptions(opts...),
+		cert: cert,
+		key:  key,
+	}, nil
+}
+
+// Sign certifies the service, the caller must be authenticated with the
+// account of the service i.e. one with the name of the service in its metadata
+func (c *CA) Sign(ctx context.Context, req *SignRequest, rsp *SignResponse) error {
+	id := c.opts.Service
+
+	if len(req.Service) == 0 {
+		return errors.BadRequest(id, "service required")
+	}
+
+	// only the account of the service can be certified as the service
+	acc, ok := auth.AccountFromContext(ctx)
+	if !ok {
+		return errors.Unauthorized(id, "account required")
+	}
+	if acc.Metadata["name"] != req.Service {
+		return errors.Forbidden(id, "account %s can not be certified as %s", acc.ID, req.Service)
+	}
+
+	block, _ := pem.Decode(req.CSR)
+	if block == nil {
+		return errors.BadRequest(id, "invalid csr")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return errors.BadRequest(id, "invalid csr: %v", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return errors.BadRequest(id, "invalid csr signature: %v", err)
+	}
+
+	// the certificate can only identify the service
+	if csr.Subject.CommonName != req.Service {
+		return errors.BadRequest(id, "csr common name %s does not match service %s", csr.Subject.CommonName, req.Service)
+	}
+	for _, name := range csr.DNSNames {
+		if name != req.Service {
+			return errors.BadRequest(id, "csr dns name %s does not match service %s", name, req.Service)
+		}
+	}
+	if len(csr.IPAddresses) > 0 || len(csr.URIs) > 0 || len(csr.EmailAddresses) > 0 {
+		return errors.BadRequest(id, "csr can only contain the service name")
+	}
+
+	cert, err := sign(c.cert, c.key, req.CSR, c.opts.TTL)
+	if err != nil {
+		return errors.InternalServerError(id, "%v", err)
+	}
+
+	rsp.Certificate = cert
+	rsp.CA = c.cert
+
+	return nil
+}
+
+// sign issues a certificate for the csr valid for the ttl
+func sign(caCert, caKey, csr []byte, ttl time.Duration) ([]byte, error) {
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+	return pki.Sign(caCert, caKey, csr,
+		pki.SerialNumber(serial),
+		pki.NotBefore(time.Now().Add(-time.Minute)),
+		pki.NotAfter(time.Now().Add(ttl)),
+		pki.ExtKeyUsage(x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth),
+	)
+}
+
+func serialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
@@ -0,0 +1,310 @@
+// Package mtls provides mutual tls between services using short lived
+// certificates issued by a CA service, or signed locally with a CA shared by
+// the services. A service obtains a certificate bound to its name at startup
+// and verifies the name of its peers from their certificate e.g
+//
+//	id := mtls.NewIdentity("go.micro.service.foo",
+//		mtls.RootCA(caCert),
+//		mtls.Allow("go.micro.service.bar"),
+//	)
+//	if err := id.Start(); err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	service := micro.NewService(
+//		micro.Transport(transport.NewHTTPTransport(
+//			transport.Secure(true),
+//			transport.TLSConfig(id.TLSConfig()),
+//		)),
+//	)
+//
+// Handlers get the name of the calling service with PeerFromContext.
+package mtls
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/logger"
+	"github.com/asim/go-micro/v3/server"
+	"github.com/asim/go-micro/v3/util/backoff"
+	"github.com/asim/go-micro/v3/util/pki"
+	mls "github.com/asim/go-micro/v3/util/tls"
+)
+
+// Identity is the certificate of a service issued by the CA, it's renewed
+// before it expires
+type Identity struct {
+	name string
+	opts Options
+
+	sync.RWMutex
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	exit    chan bool
+	running bool
+}
+
+// NewIdentity returns the identity of the service, Start obtains the certificate
+func NewIdentity(name string, opts ...Option) *Identity {
+	return &Identity{
+		name: name,
+		opts: NewOptions(opts...),
+	}
+}
+
+// Start obtains the certificate from the CA and renews it in the background
+func (i *Identity) Start() error {
+	i.RLock()
+	running := i.running
+	i.RUnlock()
+
+	if running {
+		return nil
+	}
+
+	leaf, err := i.renew()
+	if err != nil {
+		return err
+	}
+
+	i.Lock()
+	defer i.Unlock()
+
+	if i.running {
+		return nil
+	}
+
+	i.exit = make(chan bool)
+	i.running = true
+
+	go i.run(leaf, i.exit)
+
+	return nil
+}
+
+// Stop stops renewing the certificate
+func (i *Identity) Stop() error {
+	i.Lock()
+	defer i.Unlock()
+
+	if !i.running {
+		return nil
+	}
+
+	close(i.exit)
+	i.running = false
+
+	return nil
+}
+
+// TLSConfig returns the config for mutual tls. The certificate is renewed
+// without having to reconfigure the transport.
+func (i *Identity) TLSConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return i.certificate()
+		},
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return i.certificate()
+		},
+		ClientAuth: tls.RequireAnyClientCert,
+		// peers are verified by service name rather than host
+		InsecureSkipVerify:    true,
+		VerifyPeerCertificate: i.verify,
+		MinVersion:            tls.VersionTLS12,
+	}
+}
+
+func (i *Identity) certificate() (*tls.Certificate, error) {
+	i.RLock()
+	defer i.RUnlock()
+
+	if i.cert == nil {
+		return nil, errors.New("no certificate, identity not started")
+	}
+
+	return i.cert, nil
+}
+
+// verify checks the peer was certified by the CA as an allowed service
+func (i *Identity) verify(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return errors.New("no peer certificate")
+	}
+
+	i.RLock()
+	pool := i.pool
+	i.RUnlock()
+
+	if pool == nil {
+		return errors.New("no CA certificate, identity not started")
+	}
+
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for n, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		certs[n] = cert
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(i.opts.Allow) == 0 {
+		return nil
+	}
+
+	service := mls.PeerName(certs[0])
+	for _, name := range i.opts.Allow {
+		if name == service {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("service %s not allowed", service)
+}
+
+// PeerFromContext returns the service which made the request, as verified
+// by the transport from its certificate
+func PeerFromContext(ctx context.Context) (string, bool) {
+	return server.PeerFromContext(ctx)
+}
+
+// renew requests a certificate for a new key from the CA
+func (i *Identity) renew() (*x509.Certificate, error) {
+	pub, priv, err := pki.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	csr, err := pki.CSR(
+		pki.KeyPair(pub, priv),
+		pki.Subject(pkix.Name{CommonName: i.name}),
+		pki.DNSNames(i.name),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// sign locally with the issuer
+	if len(i.opts.IssuerCert) > 0 {
+		cert, err := sign(i.opts.IssuerCert, i.opts.IssuerKey, csr, i.opts.TTL)
+		if err != nil {
+			return nil, err
+		}
+		return i.load(cert, priv, i.opts.IssuerCert)
+	}
+
+	// the CA certificate isn't trusted from the response
+	if len(i.opts.RootCA) == 0 {
+		return nil, errors.New("root CA required to verify certificates of the CA service")
+	}
+
+	c := i.opts.Client
+	req := c.NewRequest(i.opts.Service, "CA.Sign", &SignRequest{
+		Service: i.name,
+		CSR:     csr,
+	}, client.WithContentType("application/json"))
+	rsp := new(SignResponse)
+
+	if err := c.Call(context.Background(), req, rsp); err != nil {
+		return nil, err
+	}
+
+	return i.load(rsp.Certificate, priv, i.opts.RootCA)
+}
+
+// load sets the certificate after checking it was issued to the service by the CA
+func (i *Identity) load(cert []byte, key ed25519.PrivateKey, ca []byte) (*x509.Certificate, error) {
+	if len(i.opts.RootCA) > 0 {
+		ca = i.opts.RootCA
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("invalid CA certificate")
+	}
+
+	block, _ := pem.Decode(cert)
+	if block == nil {
+		return nil, errors.New("invalid certificate")
+	}
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:     pool,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		return nil, err
+	}
+	if leaf.Subject.CommonName != i.name {
+		return nil, fmt.Errorf("certificate issued to %s not %s", leaf.Subject.CommonName, i.name)
+	}
+
+	i.Lock()
+	i.cert = &tls.Certificate{
+		Certificate: [][]byte{block.Bytes},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+	i.pool = pool
+	i.Unlock()
+
+	return leaf, nil
+}
+
+// run renews the certificate when two thirds of its lifetime has passed
+func (i *Identity) run(leaf *x509.Certificate, exit chan bool) {
+	var attempts int
+
+	for {
+		wait := time.Until(leaf.NotBefore.Add(leaf.NotAfter.Sub(leaf.NotBefore) * 2 / 3))
+		if attempts > 0 {
+			wait = backoff.Do(attempts)
+		}
+
+		select {
+		case <-exit:
+			return
+		case <-time.After(wait):
+		}
+
+		l, err := i.renew()
+		if err != nil {
+			attempts++
+			if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+				logger.Errorf("error renewing certificate for %s: %v", i.name, err)
+			}
+			continue
+		}
+
+		attempts = 0
+		leaf = l
+	}
+}
@@ -0,0 +1,248 @@
+package mtls
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/asim/go-micro/v3/auth"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/transport"
+	"github.com/asim/go-micro/v3/util/pki"
+)
+
+func testCA(t *testing.T) *CA {
+	cert, key, err := GenerateCA("test", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := NewCA(cert, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ca
+}
+
+func testCSR(t *testing.T, name string, dns ...string) ([]byte, ed25519.PrivateKey) {
+	pub, priv, err := pki.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := pki.CSR(pki.KeyPair(pub, priv), pki.Subject(pkix.Name{CommonName: name}), pki.DNSNames(dns...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return csr, priv
+}
+
+// testAccount returns a context with the account of the service
+func testAccount(name string) context.Context {
+	return auth.ContextWithAccount(context.TODO(), &auth.Account{ID: name, Metadata: map[string]string{"name": name}})
+}
+
+// testIdentity certifies the service with the CA without a client
+func testIdentity(t *testing.T, ca *CA, name string, opts ...Option) *Identity {
+	csr, priv := testCSR(t, name, name)
+	rsp := new(SignResponse)
+	if err := ca.Sign(testAccount(name), &SignRequest{Service: name, CSR: csr}, rsp); err != nil {
+		t.Fatal(err)
+	}
+	id := NewIdentity(name, opts...)
+	if _, err := id.load(rsp.Certificate, priv, rsp.CA); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestSign(t *testing.T) {
+	ca := testCA(t)
+	foo := &auth.Account{ID: "1", Metadata: map[string]string{"name": "foo"}}
+
+	testData := []struct {
+		name    string
+		service string
+		csr     string
+		dns     []string
+		account *auth.Account
+		code    int32
+	}{
+		{name: "valid", service: "foo", csr: "foo", dns: []string{"foo"}, account: foo},
+		{name: "no service", service: "", csr: "foo", account: foo, code: 400},
+		{name: "common name mismatch", service: "foo", csr: "bar", account: foo, code: 400},
+		{name: "dns name mismatch", service: "foo", csr: "foo", dns: []string{"bar"}, account: foo, code: 400},
+		{name: "no account", service: "foo", csr: "foo", code: 401},
+		{name: "account without name", service: "foo", csr: "foo", account: &auth.Account{ID: "1"}, code: 403},
+		{name: "account mismatch", service: "foo", csr: "foo", account: &auth.Account{ID: "1", Metadata: map[string]string{"name": "bar"}}, code: 403},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			csr, _ := testCSR(t, d.csr, d.dns...)
+
+			ctx := context.TODO()
+			if d.account != nil {
+				ctx = auth.ContextWithAccount(ctx, d.account)
+			}
+
+			rsp := new(SignResponse)
+			err := ca.Sign(ctx, &SignRequest{Service: d.service, CSR: csr}, rsp)
+			if d.code > 0 {
+				if err == nil || errors.FromError(err).Code != d.code {
+					t.Fatalf("Expected error code %d got %v", d.code, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rsp.Certificate) == 0 || len(rsp.CA) == 0 {
+				t.Fatal("Expected certificate and CA")
+			}
+		})
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	ca := testCA(t)
+	other := testCA(t)
+
+	testData := []struct {
+		name   string
+		server *Identity
+		client *Identity
+		err    bool
+	}{
+		{
+			name:   "same CA",
+			server: testIdentity(t, ca, "foo"),
+			client: testIdentity(t, ca, "bar"),
+		},
+		{
+			name:   "allowed",
+			server: testIdentity(t, ca, "foo", Allow("bar")),
+			client: testIdentity(t, ca, "bar", Allow("foo")),
+		},
+		{
+			name:   "not allowed",
+			server: testIdentity(t, ca, "foo", Allow("baz")),
+			client: testIdentity(t, ca, "bar"),
+			err:    true,
+		},
+		{
+			name:   "other CA",
+			server: testIdentity(t, ca, "foo"),
+			client: testIdentity(t, other, "bar"),
+			err:    true,
+		},
+	}
+
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer l.Close()
+
+			errc := make(chan error, 1)
+			go func() {
+				s, err := l.Accept()
+				if err != nil {
+					errc <- err
+					return
+				}
+				defer s.Close()
+				errc <- tls.Server(s, d.server.TLSConfig()).Handshake()
+			}()
+
+			c, err := net.Dial("tcp", l.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			cli := tls.Client(c, d.client.TLSConfig())
+			err = cli.Handshake()
+			if serr := <-errc; err == nil {
+				err = serr
+			}
+
+			if d.err && err == nil {
+				t.Fatal("Expected handshake error")
+			}
+			if !d.err && err != nil {
+				t.Fatalf("Unexpected handshake error: %v", err)
+			}
+		})
+	}
+}
+
+func TestIssuer(t *testing.T) {
+	cert, key, err := GenerateCA("test", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// services sharing the CA identify each other over the transport
+	server := NewIdentity("foo", Issuer(cert, key), Allow("bar"))
+	client := NewIdentity("bar", Issuer(cert, key))
+	for _, id := range []*Identity{server, client} {
+		if err := id.Start(); err != nil {
+			t.Fatal(err)
+		}
+		defer id.Stop()
+	}
+
+	l, err := transport.NewHTTPTransport(transport.TLSConfig(server.TLSConfig())).Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	peer := make(chan string, 1)
+	go l.Accept(func(sock transport.Socket) {
+		defer sock.Close()
+		var m transport.Message
+		if err := sock.Recv(&m); err != nil {
+			peer <- err.Error()
+			return
+		}
+		p, _ := sock.(transport.PeerSocket)
+		if p == nil {
+			peer <- "socket without peer"
+			return
+		}
+		peer <- p.Peer()
+		sock.Send(&m)
+	})
+
+	c, err := transport.NewHTTPTransport(transport.TLSConfig(client.TLSConfig())).Dial(l.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	m := &transport.Message{Header: map[string]string{}, Body: []byte(`{}`)}
+	if err := c.Send(m); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Recv(m); err != nil {
+		t.Fatal(err)
+	}
+	if p := <-peer; p != "bar" {
+		t.Fatalf("Expected peer bar got %v", p)
+	}
+}
+
+func TestIdentityRootCA(t *testing.T) {
+	// certificates of the CA service can't be verified without the root CA
+	id := NewIdentity("foo")
+	if err := id.Start(); err == nil {
+		id.Stop()
+		t.Fatal("Expected an error without a root CA")
+	}
+}
@@ -0,0 +1,94 @@
+package mtls
+
+import (
+	"time"
+
+	"github.com/asim/go-micro/v3/client"
+)
+
+var (
+	// DefaultCAService is the name of the service acting as CA
+	DefaultCAService = "go.micro.ca"
+	// DefaultTTL is how long certificates issued by the CA are valid for
+	DefaultTTL = time.Hour
+)
+
+type Options struct {
+	// Service the CA is registered as
+	Service string
+	// Client used to request certificates from the CA, it must not
+	// require mutual tls itself
+	Client client.Client
+	// TTL of the certificates issued
+	TTL time.Duration
+	// Allow restricts the peers to the given services, any service
+	// certified by the CA is allowed if empty
+	Allow []string
+	// RootCA is the CA certificate in PEM format, it's required to
+	// verify the certificates issued by the CA service
+	RootCA []byte
+	// IssuerCert and IssuerKey in PEM format sign the certificate of the
+	// service locally instead of requesting it from the CA service
+	IssuerCert []byte
+	IssuerKey  []byte
+}
+
+type Option func(o *Options)
+
+func NewOptions(opts ...Option) Options {
+	options := Options{
+		Service: DefaultCAService,
+		Client:  client.DefaultClient,
+		TTL:     DefaultTTL,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return options
+}
+
+// Service sets the name of the CA service
+func Service(name string) Option {
+	return func(o *Options) {
+		o.Service = name
+	}
+}
+
+// Client sets the client used to request certificates
+func Client(c client.Client) Option {
+	return func(o *Options) {
+		o.Client = c
+	}
+}
+
+// TTL sets how long issued certificates are valid for
+func TTL(d time.Duration) Option {
+	return func(o *Options) {
+		o.TTL = d
+	}
+}
+
+// Allow restricts the peers to the given services
+func Allow(services ...string) Option {
+	return func(o *Options) {
+		o.Allow = services
+	}
+}
+
+// RootCA sets the CA certificate the certificates are verified with
+func RootCA(cert []byte) Option {
+	return func(o *Options) {
+		o.RootCA = cert
+	}
+}
+
+// Issuer signs the certificate of the service with the CA certificate and
+// key in PEM format, so services sharing them don't need a CA service
+func Issuer(cert, key []byte) Option {
+	return func(o *Options) {
+		o.IssuerCert = cert
+		o.IssuerKey = key
+	}
+}
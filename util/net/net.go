@@ -25,6 +25,19 @@ func HostPort(addr string, port interface{}) string {
 	return fmt.Sprintf("%s:%v", host, port)
 }
 
+// SplitHostPort splits the address into its host and port, the port is empty
+// if the address is a host alone e.g localhost, ::1 or [::1]
+func SplitHostPort(addr string) (host, port string, err error) {
+	// a bare ipv4 or ipv6 address
+	if h := strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]"); net.ParseIP(h) != nil {
+		return h, "", nil
+	}
+	if !strings.Contains(addr, ":") {
+		return addr, "", nil
+	}
+	return net.SplitHostPort(addr)
+}
+
 // Listen takes addr:portmin-portmax and binds to the first available port
 // Example: Listen("localhost:5000-6000", fn)
 func Listen(addr string, fn func(string) (net.Listener, error)) (net.Listener, error) {
@@ -26,6 +26,32 @@ func TestListen(t *testing.T) {
 
 }
 
+func TestSplitHostPort(t *testing.T) {
+	testData := []struct {
+		addr string
+		host string
+		port string
+	}{
+		{"localhost", "localhost", ""},
+		{"localhost:8080", "localhost", "8080"},
+		{"10.0.0.1:8080", "10.0.0.1", "8080"},
+		{"::1", "::1", ""},
+		{"[::1]", "::1", ""},
+		{"[::1]:8080", "::1", "8080"},
+		{":8080", "", "8080"},
+	}
+
+	for _, d := range testData {
+		host, port, err := SplitHostPort(d.addr)
+		if err != nil {
+			t.Fatalf("Unexpected error splitting %s: %v", d.addr, err)
+		}
+		if host != d.host || port != d.port {
+			t.Fatalf("Expected %s and %s got %s and %s", d.host, d.port, host, port)
+		}
+	}
+}
+
 // TestProxyEnv checks whether we have proxy/network settings in env
 func TestProxyEnv(t *testing.T) {
 	service := "foo"
@@ -18,6 +18,7 @@ type CertOptions struct {
 	SerialNumber *big.Int
 	NotBefore    time.Time
 	NotAfter     time.Time
+	ExtKeyUsage  []x509.ExtKeyUsage
 
 	Parent *x509.Certificate
 	Pub    ed25519.PublicKey
@@ -83,3 +84,11 @@ func NotAfter(time time.Time) CertOption {
 		c.NotAfter = time
 	}
 }
+
+// ExtKeyUsage sets what the certificate can be used for e.g client auth, it
+// defaults to server auth
+func ExtKeyUsage(usage ...x509.ExtKeyUsage) CertOption {
+	return func(c *CertOptions) {
+		c.ExtKeyUsage = usage
+	}
+}
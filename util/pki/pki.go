@@ -30,7 +30,7 @@ func CA(opts ...CertOption) ([]byte, []byte, error) {
 		DNSNames:              options.DNSNames,
 		IPAddresses:           options.IPAddresses,
 		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		ExtKeyUsage:           extKeyUsage(options),
 		NotBefore:             options.NotBefore,
 		NotAfter:              options.NotAfter,
 		SerialNumber:          options.SerialNumber,
@@ -128,14 +128,14 @@ func Sign(CACrt, CAKey, CSR []byte, opts ...CertOption) ([]byte, error) {
 		DNSNames:              csr.DNSNames,
 		IPAddresses:           csr.IPAddresses,
 		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		ExtKeyUsage:           extKeyUsage(options),
 		NotBefore:             options.NotBefore,
 		NotAfter:              options.NotAfter,
 		SerialNumber:          options.SerialNumber,
 		BasicConstraintsValid: true,
 	}
 
-	x509Cert, err := x509.CreateCertificate(rand.Reader, template, caCrt, caCrt.PublicKey, caKey)
+	x509Cert, err := x509.CreateCertificate(rand.Reader, template, caCrt, csr.PublicKey, caKey)
 	if err != nil {
 		return nil, errors.Wrap(err, "Couldn't sign certificate")
 	}
@@ -146,6 +146,13 @@ func Sign(CACrt, CAKey, CSR []byte, opts ...CertOption) ([]byte, error) {
 	return out.Bytes(), nil
 }
 
+func extKeyUsage(options CertOptions) []x509.ExtKeyUsage {
+	if len(options.ExtKeyUsage) > 0 {
+		return options.ExtKeyUsage
+	}
+	return []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+}
+
 func decodePEM(PEM []byte) ([]*pem.Block, error) {
 	var blocks []*pem.Block
 	var asn1 *pem.Block
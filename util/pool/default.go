@@ -1,42 +1,82 @@
 package pool
 
 import (
+	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/asim/go-micro/v3/transport"
 	"github.com/google/uuid"
 )
 
+var errProbeTimeout = errors.New("probe timeout")
+
 type pool struct {
-	size int
-	ttl  time.Duration
-	tr   transport.Transport
+	size    int
+	ttl     time.Duration
+	idleTTL time.Duration
+	probe   func(transport.Client) error
+	timeout time.Duration
+	tr      transport.Transport
 
 	sync.Mutex
 	conns map[string][]*poolConn
+
+	// stats updated atomically
+	open       int64
+	dials      uint64
+	dialErrors uint64
+	reuses     uint64
+	evictions  uint64
+
+	once sync.Once
+	exit chan bool
 }
 
 type poolConn struct {
 	transport.Client
 	id      string
 	created time.Time
+	// when the conn was put back in the pool
+	released time.Time
 }
 
 func newPool(options Options) *pool {
-	return &pool{
-		size:  options.Size,
-		tr:    options.Transport,
-		ttl:   options.TTL,
-		conns: make(map[string][]*poolConn),
+	p := &pool{
+		size:    options.Size,
+		tr:      options.Transport,
+		ttl:     options.TTL,
+		idleTTL: options.IdleTTL,
+		probe:   options.Probe,
+		timeout: options.ProbeTimeout,
+		conns:   make(map[string][]*poolConn),
+		exit:    make(chan bool),
+	}
+
+	if p.probe == nil {
+		p.probe = DefaultProbe
+	}
+	if p.timeout <= 0 {
+		p.timeout = DefaultProbeTimeout
+	}
+
+	if options.Interval > 0 {
+		go p.run(options.Interval)
 	}
+
+	return p
 }
 
 func (p *pool) Close() error {
+	p.once.Do(func() {
+		close(p.exit)
+	})
+
 	p.Lock()
 	for k, c := range p.conns {
 		for _, conn := range c {
-			conn.Client.Close()
+			p.close(conn)
 		}
 		delete(p.conns, k)
 	}
@@ -57,6 +97,26 @@ func (p *poolConn) Created() time.Time {
 	return p.created
 }
 
+// close closes the underlying connection
+func (p *pool) close(conn *poolConn) error {
+	atomic.AddInt64(&p.open, -1)
+	return conn.Client.Close()
+}
+
+// evict closes the connection which is no longer usable
+func (p *pool) evict(conn *poolConn) {
+	atomic.AddUint64(&p.evictions, 1)
+	p.close(conn)
+}
+
+// expired returns true if the connection is too old or has been idle too long
+func (p *pool) expired(conn *poolConn) bool {
+	if time.Since(conn.created) > p.ttl {
+		return true
+	}
+	return p.idleTTL > 0 && time.Since(conn.released) > p.idleTTL
+}
+
 func (p *pool) Get(addr string, opts ...transport.DialOption) (Conn, error) {
 	p.Lock()
 	conns := p.conns[addr]
@@ -69,24 +129,29 @@ func (p *pool) Get(addr string, opts ...transport.DialOption) (Conn, error) {
 		p.conns[addr] = conns
 
 		// if conn is old kill it and move on
-		if d := time.Since(conn.Created()); d > p.ttl {
-			conn.Client.Close()
+		if p.expired(conn) {
+			p.evict(conn)
 			continue
 		}
 
 		// we got a good conn, lets unlock and return it
 		p.Unlock()
 
+		atomic.AddUint64(&p.reuses, 1)
 		return conn, nil
 	}
 
 	p.Unlock()
 
 	// create new conn
+	atomic.AddUint64(&p.dials, 1)
 	c, err := p.tr.Dial(addr, opts...)
 	if err != nil {
+		atomic.AddUint64(&p.dialErrors, 1)
 		return nil, err
 	}
+	atomic.AddInt64(&p.open, 1)
+
 	return &poolConn{
 		Client:  c,
 		id:      uuid.New().String(),
@@ -95,9 +160,11 @@ func (p *pool) Get(addr string, opts ...transport.DialOption) (Conn, error) {
 }
 
 func (p *pool) Release(conn Conn, err error) error {
+	pc := conn.(*poolConn)
+
 	// don't store the conn if it has errored
 	if err != nil {
-		return conn.(*poolConn).Client.Close()
+		return p.close(pc)
 	}
 
 	// otherwise put it back for reuse
@@ -105,10 +172,117 @@ func (p *pool) Release(conn Conn, err error) error {
 	conns := p.conns[conn.Remote()]
 	if len(conns) >= p.size {
 		p.Unlock()
-		return conn.(*poolConn).Client.Close()
+		return p.close(pc)
 	}
-	p.conns[conn.Remote()] = append(conns, conn.(*poolConn))
+	pc.released = time.Now()
+	p.conns[conn.Remote()] = append(conns, pc)
 	p.Unlock()
 
 	return nil
 }
+
+func (p *pool) Stats() Stats {
+	p.Lock()
+	var idle int64
+	for _, conns := range p.conns {
+		idle += int64(len(conns))
+	}
+	p.Unlock()
+
+	return Stats{
+		Open:       atomic.LoadInt64(&p.open),
+		Idle:       idle,
+		Dials:      atomic.LoadUint64(&p.dials),
+		DialErrors: atomic.LoadUint64(&p.dialErrors),
+		Reuses:     atomic.LoadUint64(&p.reuses),
+		Evictions:  atomic.LoadUint64(&p.evictions),
+	}
+}
+
+// check evicts the idle connections which expired or fail the probe, each
+// is taken out of the pool while probed so it isn't used at the same time
+func (p *pool) check() {
+	p.Lock()
+	idle := make(map[string][]*poolConn, len(p.conns))
+	for addr, conns := range p.conns {
+		idle[addr] = append([]*poolConn(nil), conns...)
+	}
+	p.Unlock()
+
+	for addr, conns := range idle {
+		for _, conn := range conns {
+			// skip the conn if it was taken in the meantime
+			if !p.take(addr, conn) {
+				continue
+			}
+
+			if p.expired(conn) || p.probeConn(conn) != nil {
+				p.evict(conn)
+				continue
+			}
+
+			// put it back unless the pool filled up or was closed
+			p.Lock()
+			if len(p.conns[addr]) >= p.size || p.closed() {
+				p.close(conn)
+			} else {
+				p.conns[addr] = append([]*poolConn{conn}, p.conns[addr]...)
+			}
+			p.Unlock()
+		}
+	}
+}
+
+// take removes the idle conn from the pool, false if it's no longer there
+func (p *pool) take(addr string, conn *poolConn) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	conns := p.conns[addr]
+	for i, c := range conns {
+		if c == conn {
+			p.conns[addr] = append(conns[:i:i], conns[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// probeConn probes the conn, failing if the probe takes longer than the
+// probe timeout
+func (p *pool) probeConn(conn *poolConn) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- p.probe(conn.Client)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(p.timeout):
+		return errProbeTimeout
+	}
+}
+
+func (p *pool) closed() bool {
+	select {
+	case <-p.exit:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *pool) run(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-p.exit:
+			return
+		case <-t.C:
+			p.check()
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package pool
 
 import (
+	"errors"
+	"net"
 	"testing"
 	"time"
 
@@ -86,3 +88,180 @@ func TestClientPool(t *testing.T) {
 	testPool(t, 0, time.Minute)
 	testPool(t, 2, time.Minute)
 }
+
+func TestPoolEviction(t *testing.T) {
+	tr := transport.NewMemoryTransport()
+
+	l, err := tr.Listen(":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go l.Accept(func(s transport.Socket) {
+		var msg transport.Message
+		s.Recv(&msg)
+	})
+
+	var healthy = true
+	p := newPool(Options{
+		Size:      2,
+		TTL:       time.Minute,
+		IdleTTL:   time.Minute,
+		Transport: tr,
+		Probe: func(transport.Client) error {
+			if !healthy {
+				return errors.New("unhealthy")
+			}
+			return nil
+		},
+	})
+	defer p.Close()
+
+	c, err := p.Get(l.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Release(c, nil)
+
+	// healthy connections are kept and reused
+	p.check()
+	if c, err = p.Get(l.Addr()); err != nil {
+		t.Fatal(err)
+	}
+	p.Release(c, nil)
+
+	// unhealthy ones are evicted
+	healthy = false
+	p.check()
+
+	stats := p.Stats()
+	if stats.Dials != 1 || stats.Reuses != 1 || stats.Evictions != 1 {
+		t.Fatalf("Unexpected stats %+v", stats)
+	}
+	if stats.Open != 0 || stats.Idle != 0 {
+		t.Fatalf("Expected no open connections, got %+v", stats)
+	}
+
+	// idle connections expire
+	healthy = true
+	if c, err = p.Get(l.Addr()); err != nil {
+		t.Fatal(err)
+	}
+	p.Release(c, nil)
+	p.idleTTL = time.Nanosecond
+	time.Sleep(time.Millisecond)
+
+	if c, err = p.Get(l.Addr()); err != nil {
+		t.Fatal(err)
+	}
+	if stats := p.Stats(); stats.Evictions != 2 || stats.Dials != 3 {
+		t.Fatalf("Expected the idle connection to be evicted, got %+v", stats)
+	}
+	p.Release(c, errors.New("error"))
+}
+
+func TestPoolCheck(t *testing.T) {
+	tr := transport.NewMemoryTransport()
+
+	l, err := tr.Listen(":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go l.Accept(func(s transport.Socket) {
+		var msg transport.Message
+		s.Recv(&msg)
+	})
+
+	// the first probe hangs until the check is done
+	hang := make(chan bool)
+	defer close(hang)
+
+	var p *pool
+	var idle []int
+	p = newPool(Options{
+		Size:         2,
+		TTL:          time.Minute,
+		Transport:    tr,
+		ProbeTimeout: time.Millisecond * 250,
+		Probe: func(c transport.Client) error {
+			// the other conns stay in the pool while one is probed
+			p.Lock()
+			idle = append(idle, len(p.conns[l.Addr()]))
+			first := len(idle) == 1
+			p.Unlock()
+
+			if first {
+				<-hang
+			}
+			return nil
+		},
+	})
+	defer p.Close()
+
+	c1, err := p.Get(l.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := p.Get(l.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Release(c1, nil)
+	p.Release(c2, nil)
+
+	p.check()
+
+	// the second conn is probed once the first was evicted
+	p.Lock()
+	probed := append([]int(nil), idle...)
+	p.Unlock()
+
+	if len(probed) != 2 || probed[0] != 1 || probed[1] != 0 {
+		t.Fatalf("Expected conns to be probed one at a time, got %v idle", probed)
+	}
+
+	// the conn whose probe timed out is evicted
+	if stats := p.Stats(); stats.Evictions != 1 || stats.Idle != 1 {
+		t.Fatalf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestDefaultProbe(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	conns := make(chan net.Conn, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		conns <- c
+	}()
+
+	c, err := transport.NewHTTPTransport().Dial(l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	sc := <-conns
+
+	if err := DefaultProbe(c); err != nil {
+		t.Fatalf("Expected the open conn to pass the probe, got %v", err)
+	}
+
+	// the server closed the connection
+	sc.Close()
+	time.Sleep(time.Millisecond * 10)
+
+	if err := DefaultProbe(c); err == nil {
+		t.Fatal("Expected the closed conn to fail the probe")
+	}
+}
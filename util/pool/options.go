@@ -8,8 +8,21 @@ import (
 
 type Options struct {
 	Transport transport.Transport
-	TTL       time.Duration
-	Size      int
+	// TTL is the maximum age of a connection
+	TTL time.Duration
+	// Size is the maximum number of idle connections per address
+	Size int
+	// IdleTTL is how long a connection can be idle, no limit if zero
+	IdleTTL time.Duration
+	// Interval at which idle connections are checked, they're only
+	// checked when taken from the pool if zero
+	Interval time.Duration
+	// Probe checks an idle connection is still usable, connections
+	// failing it are evicted. DefaultProbe is used if not set
+	Probe func(transport.Client) error
+	// ProbeTimeout is how long a probe may take before the connection
+	// is evicted, DefaultProbeTimeout is used if zero
+	ProbeTimeout time.Duration
 }
 
 type Option func(*Options)
@@ -31,3 +44,31 @@ func TTL(t time.Duration) Option {
 		o.TTL = t
 	}
 }
+
+// IdleTTL sets how long a connection can be idle in the pool
+func IdleTTL(t time.Duration) Option {
+	return func(o *Options) {
+		o.IdleTTL = t
+	}
+}
+
+// Interval sets how often idle connections are checked in the background
+func Interval(t time.Duration) Option {
+	return func(o *Options) {
+		o.Interval = t
+	}
+}
+
+// Probe sets the health check of idle connections
+func Probe(fn func(transport.Client) error) Option {
+	return func(o *Options) {
+		o.Probe = fn
+	}
+}
+
+// ProbeTimeout sets how long the probe of a connection may take
+func ProbeTimeout(t time.Duration) Option {
+	return func(o *Options) {
+		o.ProbeTimeout = t
+	}
+}
@@ -17,6 +17,28 @@ type Pool interface {
 	Release(c Conn, status error) error
 }
 
+// StatsPool is a pool which counts its connections
+type StatsPool interface {
+	Pool
+	// Stats of the connections
+	Stats() Stats
+}
+
+// Stats are the counts of the pool connections
+type Stats struct {
+	// Open connections, idle and in use
+	Open int64
+	// Idle connections in the pool
+	Idle int64
+	// Dials made and failed
+	Dials      uint64
+	DialErrors uint64
+	// Reuses of idle connections
+	Reuses uint64
+	// Evictions of connections which expired, were idle or failed the probe
+	Evictions uint64
+}
+
 type Conn interface {
 	// unique id of connection
 	Id() string
@@ -26,6 +48,20 @@ type Conn interface {
 	transport.Client
 }
 
+var (
+	// DefaultProbeTimeout is how long a probe of an idle connection may take
+	DefaultProbeTimeout = time.Second
+)
+
+// DefaultProbe probes clients which implement transport.ProbeClient, other
+// clients are assumed to be usable
+func DefaultProbe(c transport.Client) error {
+	if p, ok := c.(transport.ProbeClient); ok {
+		return p.Probe()
+	}
+	return nil
+}
+
 func NewPool(opts ...Option) Pool {
 	var options Options
 	for _, o := range opts {
@@ -0,0 +1,172 @@
+package tls
+
+import (
+	"crypto/tls"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/logger"
+)
+
+// Reloader loads a certificate and key from files and reloads them when they
+// change e.g when rotated by cert-manager or vault. Configs using it pick up
+// the new certificate on the next handshake without dropping connections.
+//
+//	r, err := tls.NewReloader("/etc/micro/cert.pem", "/etc/micro/key.pem", time.Minute)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	t := transport.NewHTTPTransport(transport.TLSConfig(r.Config(nil)))
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	sync.RWMutex
+	cert *tls.Certificate
+	// modification times of the files loaded
+	certMod time.Time
+	keyMod  time.Time
+
+	once sync.Once
+	exit chan bool
+}
+
+// NewReloader loads the certificate and checks the files for changes every
+// interval, the files are only reloaded by calling Reload if it's zero
+func NewReloader(certFile, keyFile string, interval time.Duration) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		exit:     make(chan bool),
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	if interval > 0 {
+		go r.run(interval)
+	}
+
+	return r, nil
+}
+
+// Reload loads the certificate from the files, the current certificate is
+// kept if they're invalid
+func (r *Reloader) Reload() error {
+	certMod, keyMod, err := r.modTimes()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.Lock()
+	r.cert = &cert
+	r.certMod = certMod
+	r.keyMod = keyMod
+	r.Unlock()
+
+	return nil
+}
+
+// Certificate returns the current certificate
+func (r *Reloader) Certificate() (*tls.Certificate, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	if r.cert == nil {
+		return nil, errors.New("no certificate loaded")
+	}
+
+	return r.cert, nil
+}
+
+// Config returns a copy of the config which uses the current certificate
+// for servers and clients
+func (r *Reloader) Config(config *tls.Config) *tls.Config {
+	if config == nil {
+		config = &tls.Config{}
+	} else {
+		config = config.Clone()
+	}
+
+	config.Certificates = nil
+	config.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return r.Certificate()
+	}
+	config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return r.Certificate()
+	}
+
+	return config
+}
+
+// Stop stops checking the files for changes
+func (r *Reloader) Stop() {
+	r.once.Do(func() {
+		close(r.exit)
+	})
+}
+
+// modTimes returns the modification times of the files, symlinks are
+// followed as mounted secrets are updated by swapping them
+func (r *Reloader) modTimes() (time.Time, time.Time, error) {
+	cert, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	key, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return cert.ModTime(), key.ModTime(), nil
+}
+
+// changed returns true if either file was modified since it was loaded
+func (r *Reloader) changed() bool {
+	certMod, keyMod, err := r.modTimes()
+	if err != nil {
+		return false
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+
+	return !certMod.Equal(r.certMod) || !keyMod.Equal(r.keyMod)
+}
+
+func (r *Reloader) run(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-r.exit:
+			return
+		case <-t.C:
+		}
+
+		if !r.changed() {
+			continue
+		}
+
+		// the files may be written one after the other so a failed
+		// reload is retried on the next tick
+		if err := r.Reload(); err != nil {
+			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+				logger.Warnf("Error reloading certificate %s: %v", r.certFile, err)
+			}
+			continue
+		}
+
+		if logger.V(logger.InfoLevel, logger.DefaultLogger) {
+			logger.Infof("Reloaded certificate %s", r.certFile)
+		}
+	}
+}
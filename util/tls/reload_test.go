@@ -0,0 +1,103 @@
+package tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCertificate writes a self signed certificate for the name
+func writeCertificate(t *testing.T, certFile, keyFile, name string) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: key}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func commonName(t *testing.T, r *Reloader) string {
+	cert, err := r.Config(nil).GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeCertificate(t, certFile, keyFile, "foo")
+
+	r, err := NewReloader(certFile, keyFile, time.Millisecond*10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Stop()
+
+	if name := commonName(t, r); name != "foo" {
+		t.Fatalf("Expected foo got %v", name)
+	}
+
+	// an invalid key keeps the current certificate
+	if err := ioutil.WriteFile(keyFile, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reload(); err == nil {
+		t.Fatal("Expected an error reloading an invalid key")
+	}
+	if name := commonName(t, r); name != "foo" {
+		t.Fatalf("Expected foo got %v", name)
+	}
+
+	// the new certificate is picked up by the watcher
+	writeCertificate(t, certFile, keyFile, "bar")
+	// modification times may not change within the resolution of the fs
+	future := time.Now().Add(time.Second)
+	os.Chtimes(certFile, future, future)
+
+	for i := 0; i < 100; i++ {
+		if commonName(t, r) == "bar" {
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	t.Fatal("Expected the certificate to be reloaded")
+}
@@ -72,3 +72,25 @@ func Certificate(host ...string) (tls.Certificate, error) {
 
 	return tls.X509KeyPair(certOut.Bytes(), keyOut.Bytes())
 }
+
+// PeerName returns the name a certificate identifies, the first DNS name of
+// its subject alternative names or the common name of its subject
+func PeerName(cert *x509.Certificate) string {
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0]
+	}
+	return cert.Subject.CommonName
+}
+
+// VerifiesPeers returns true if servers using the config verify the
+// certificates of their clients
+func VerifiesPeers(config *tls.Config) bool {
+	if config == nil {
+		return false
+	}
+	switch config.ClientAuth {
+	case tls.VerifyClientCertIfGiven, tls.RequireAndVerifyClientCert:
+		return true
+	}
+	return config.VerifyPeerCertificate != nil && config.ClientAuth != tls.NoClientCert
+}
@@ -3,11 +3,14 @@ package wrapper
 import (
 	"context"
 	"strings"
+	"sync"
 
 	"github.com/asim/go-micro/v3/auth"
 	"github.com/asim/go-micro/v3/client"
 	"github.com/asim/go-micro/v3/debug/stats"
 	"github.com/asim/go-micro/v3/debug/trace"
+	"github.com/asim/go-micro/v3/errors"
+	"github.com/asim/go-micro/v3/logger"
 	"github.com/asim/go-micro/v3/metadata"
 	"github.com/asim/go-micro/v3/server"
 )
@@ -53,6 +56,62 @@ func FromService(name string, c client.Client) client.Client {
 	}
 }
 
+type propagateWrapper struct {
+	client.Client
+
+	// name of the service
+	name string
+}
+
+// endpointKey is the context key of the endpoint or topic being handled
+type endpointKey struct{}
+
+// setCaller sets this service and the endpoint being handled as the caller,
+// replacing the caller of the request being handled
+func (p *propagateWrapper) setCaller(ctx context.Context) context.Context {
+	endpoint, _ := ctx.Value(endpointKey{}).(string)
+	return metadata.SetCaller(ctx, p.name, endpoint)
+}
+
+func (p *propagateWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	return p.Client.Call(p.setCaller(ctx), req, rsp, opts...)
+}
+
+func (p *propagateWrapper) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	return p.Client.Stream(p.setCaller(ctx), req, opts...)
+}
+
+func (p *propagateWrapper) Publish(ctx context.Context, msg client.Message, opts ...client.PublishOption) error {
+	// the deadline is only passed on if the publisher sets it
+	return p.Client.Publish(p.setCaller(ctx), msg, opts...)
+}
+
+// Propagate wraps a client to pass on the request context. The metadata is
+// already passed on, this sets the caller.
+func Propagate(name string, c client.Client) client.Client {
+	return &propagateWrapper{Client: c, name: name}
+}
+
+// PropagateHandler wraps a server handler to set the endpoint being handled
+// as the caller of the requests made by the handler
+func PropagateHandler() server.HandlerWrapper {
+	return func(h server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			return h(context.WithValue(ctx, endpointKey{}, req.Endpoint()), req, rsp)
+		}
+	}
+}
+
+// PropagateSubscriber wraps a subscriber to set the topic being handled as
+// the caller of the requests made by the subscriber
+func PropagateSubscriber() server.SubscriberWrapper {
+	return func(fn server.SubscriberFunc) server.SubscriberFunc {
+		return func(ctx context.Context, msg server.Message) error {
+			return fn(context.WithValue(ctx, endpointKey{}, msg.Topic()), msg)
+		}
+	}
+}
+
 // HandlerStats wraps a server handler to generate request/error stats
 func HandlerStats(stats stats.Stats) server.HandlerWrapper {
 	// return a handler wrapper
@@ -131,9 +190,44 @@ func TraceHandler(t trace.Tracer) server.HandlerWrapper {
 type authWrapper struct {
 	client.Client
 	auth func() auth.Auth
+
+	sync.Mutex
+	// token obtained using the auth credentials
+	token *auth.Token
 }
 
-func (a *authWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+// serviceToken returns the token of the service, a new token is requested
+// using the auth credentials once the current one expires
+func (a *authWrapper) serviceToken(aa auth.Auth) *auth.Token {
+	opts := aa.Options()
+	if opts.Token != nil && !opts.Token.Expired() {
+		return opts.Token
+	}
+	if len(opts.ID) == 0 || len(opts.Secret) == 0 {
+		return nil
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	if a.token != nil && !a.token.Expired() {
+		return a.token
+	}
+
+	tok, err := aa.Token(auth.WithCredentials(opts.ID, opts.Secret))
+	if err != nil {
+		if logger.V(logger.ErrorLevel, logger.DefaultLogger) {
+			logger.Errorf("Error getting auth token for %s: %v", opts.ID, err)
+		}
+		return nil
+	}
+	a.token = tok
+
+	return tok
+}
+
+// setToken sets the auth header and namespace of the request
+func (a *authWrapper) setToken(ctx context.Context, opts []client.CallOption) context.Context {
 	// parse the options
 	var options client.CallOptions
 	for _, o := range opts {
@@ -144,14 +238,14 @@ func (a *authWrapper) Call(ctx context.Context, req client.Request, rsp interfac
 	// We dont't override the header unless the ServiceToken option has
 	// been specified or the header wasn't provided
 	if _, ok := metadata.Get(ctx, "Authorization"); ok && !options.ServiceToken {
-		return a.Client.Call(ctx, req, rsp, opts...)
+		return ctx
 	}
 
 	// if auth is nil we won't be able to get an access token, so we execute
 	// the request without one.
 	aa := a.auth()
 	if aa == nil {
-		return a.Client.Call(ctx, req, rsp, opts...)
+		return ctx
 	}
 
 	// set the namespace header if it has not been set (e.g. on a service to service request)
@@ -160,12 +254,118 @@ func (a *authWrapper) Call(ctx context.Context, req client.Request, rsp interfac
 	}
 
 	// check to see if we have a valid access token
-	aaOpts := aa.Options()
-	if aaOpts.Token != nil && !aaOpts.Token.Expired() {
-		ctx = metadata.Set(ctx, "Authorization", auth.BearerScheme+aaOpts.Token.AccessToken)
-		return a.Client.Call(ctx, req, rsp, opts...)
+	if tok := a.serviceToken(aa); tok != nil && len(tok.AccessToken) > 0 {
+		ctx = metadata.Set(ctx, "Authorization", auth.BearerScheme+tok.AccessToken)
+	}
+
+	return ctx
+}
+
+func (a *authWrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+	return a.Client.Call(a.setToken(ctx, opts), req, rsp, opts...)
+}
+
+func (a *authWrapper) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	return a.Client.Stream(a.setToken(ctx, opts), req, opts...)
+}
+
+func (a *authWrapper) Publish(ctx context.Context, msg client.Message, opts ...client.PublishOption) error {
+	return a.Client.Publish(a.setToken(ctx, nil), msg, opts...)
+}
+
+// AuthClient wraps requests with the auth header containing the service token
+func AuthClient(auth func() auth.Auth, c client.Client) client.Client {
+	return &authWrapper{Client: c, auth: auth}
+}
+
+// AuthHandler wraps a server handler to authenticate the caller using the token
+// in the Authorization header. The token must have been issued by the namespace
+// of the service, for the service if it has an audience and not have expired.
+// The account is set in the context and requests without a token are passed on.
+func AuthHandler(fn func() auth.Auth) server.HandlerWrapper {
+	return func(h server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			// get the auth, if nil we can't authenticate the request
+			a := fn()
+			if a == nil {
+				return h(ctx, req, rsp)
+			}
+
+			// no token, the request is anonymous
+			header, ok := metadata.Get(ctx, "Authorization")
+			if !ok || !strings.HasPrefix(header, auth.BearerScheme) {
+				return h(ctx, req, rsp)
+			}
+
+			// inspect the token, this checks the signature and expiry
+			acc, err := a.Inspect(strings.TrimPrefix(header, auth.BearerScheme))
+			if err != nil {
+				return errors.Unauthorized(req.Service(), err.Error())
+			}
+
+			if ns := a.Options().Namespace; len(ns) > 0 && acc.Issuer != ns {
+				return errors.Unauthorized(req.Service(), auth.ErrInvalidIssuer.Error())
+			}
+			if len(acc.Audience) > 0 && acc.Audience != req.Service() {
+				return errors.Unauthorized(req.Service(), auth.ErrInvalidAudience.Error())
+			}
+
+			return h(auth.ContextWithAccount(ctx, acc), req, rsp)
+		}
 	}
+}
 
-	// call without an auth token
-	return a.Client.Call(ctx, req, rsp, opts...)
+// AuthSubscriber wraps a subscriber to authenticate the publisher using the
+// token in the Authorization header, as the AuthHandler does for requests.
+// The account is set in the context and messages without a token are passed on.
+func AuthSubscriber(fn func() auth.Auth) server.SubscriberWrapper {
+	return func(sub server.SubscriberFunc) server.SubscriberFunc {
+		return func(ctx context.Context, msg server.Message) error {
+			a := fn()
+			if a == nil {
+				return sub(ctx, msg)
+			}
+
+			header, ok := metadata.Get(ctx, "Authorization")
+			if !ok || !strings.HasPrefix(header, auth.BearerScheme) {
+				return sub(ctx, msg)
+			}
+
+			acc, err := a.Inspect(strings.TrimPrefix(header, auth.BearerScheme))
+			if err != nil {
+				return errors.Unauthorized(msg.Topic(), err.Error())
+			}
+
+			if ns := a.Options().Namespace; len(ns) > 0 && acc.Issuer != ns {
+				return errors.Unauthorized(msg.Topic(), auth.ErrInvalidIssuer.Error())
+			}
+
+			return sub(auth.ContextWithAccount(ctx, acc), msg)
+		}
+	}
+}
+
+// RulesHandler wraps a server handler to verify the caller has access to the
+// endpoint using the rules. The account is set in the context by the AuthHandler.
+func RulesHandler(r auth.Rules) server.HandlerWrapper {
+	return func(h server.HandlerFunc) server.HandlerFunc {
+		return func(ctx context.Context, req server.Request, rsp interface{}) error {
+			acc, _ := auth.AccountFromContext(ctx)
+
+			res := &auth.Resource{
+				Type:     "service",
+				Name:     req.Service(),
+				Endpoint: req.Endpoint(),
+			}
+
+			err := r.Verify(acc, res, auth.VerifyContext(ctx))
+			if err != nil && acc == nil {
+				return errors.Unauthorized(req.Service(), "Unauthorized call made to %v:%v", req.Service(), req.Endpoint())
+			} else if err != nil {
+				return errors.Forbidden(req.Service(), "Forbidden call made to %v:%v by %v", req.Service(), req.Endpoint(), acc.ID)
+			}
+
+			return h(ctx, req, rsp)
+		}
+	}
 }
@@ -4,9 +4,11 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/asim/go-micro/v3/auth"
 	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/errors"
 	"github.com/asim/go-micro/v3/metadata"
 	"github.com/asim/go-micro/v3/server"
 )
@@ -61,8 +63,10 @@ type testAuth struct {
 	namespace      string
 	inspectAccount *auth.Account
 	verifyError    error
+	token          *auth.Token
 
 	auth.Auth
+	auth.Rules
 }
 
 func (a *testAuth) Verify(acc *auth.Account, res *auth.Resource, opts ...auth.VerifyOption) error {
@@ -76,7 +80,7 @@ func (a *testAuth) Inspect(token string) (*auth.Account, error) {
 }
 
 func (a *testAuth) Options() auth.Options {
-	return auth.Options{Namespace: a.namespace}
+	return auth.Options{Namespace: a.namespace, Token: a.token}
 }
 
 type testRequest struct {
@@ -97,11 +101,13 @@ func (r testRequest) Endpoint() string {
 type testClient struct {
 	callCount int
 	callRsp   interface{}
+	callCtx   context.Context
 	client.Client
 }
 
 func (c *testClient) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
 	c.callCount++
+	c.callCtx = ctx
 
 	if c.callRsp != nil {
 		val := reflect.ValueOf(rsp).Elem()
@@ -111,6 +117,222 @@ func (c *testClient) Call(ctx context.Context, req client.Request, rsp interface
 	return nil
 }
 
+func (c *testClient) Publish(ctx context.Context, msg client.Message, opts ...client.PublishOption) error {
+	c.callCount++
+	c.callCtx = ctx
+	return nil
+}
+
 type testRsp struct {
 	value string
 }
+
+func TestPropagate(t *testing.T) {
+	c := new(testClient)
+	w := Propagate("bar", c)
+
+	// the handler of the foo service calls the bar service
+	ctx := metadata.SetCaller(context.TODO(), "foo", "Foo.Call")
+	ctx = metadata.SetBaggage(ctx, "user", "1")
+
+	h := func(ctx context.Context, req server.Request, rsp interface{}) error {
+		return w.Call(ctx, nil, nil)
+	}
+	if err := PropagateHandler()(h)(ctx, testRequest{service: "bar", endpoint: "Bar.Call"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if service, endpoint := metadata.Caller(c.callCtx); service != "bar" || endpoint != "Bar.Call" {
+		t.Errorf("Expected the caller bar Bar.Call, got %v %v", service, endpoint)
+	}
+	if v, _ := metadata.GetBaggage(c.callCtx, "user"); v != "1" {
+		t.Errorf("Expected the baggage to be passed on, got %v", v)
+	}
+
+	// messages only carry the deadline if the publisher sets it
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Minute)
+	defer cancel()
+	if err := w.Publish(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := metadata.Get(c.callCtx, metadata.DeadlineKey); ok {
+		t.Errorf("Expected the deadline not to be set")
+	}
+	if err := w.Publish(metadata.SetDeadline(ctx), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := metadata.Get(c.callCtx, metadata.DeadlineKey); !ok {
+		t.Errorf("Expected the deadline to be set")
+	}
+}
+
+func TestAuthClient(t *testing.T) {
+	token := &auth.Token{AccessToken: "secret", Expiry: time.Now().Add(time.Minute)}
+
+	t.Run("SetsToken", func(t *testing.T) {
+		c := new(testClient)
+		a := &testAuth{namespace: "foo", token: token}
+		w := AuthClient(func() auth.Auth { return a }, c)
+
+		if err := w.Call(context.TODO(), nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if hdr, _ := metadata.Get(c.callCtx, "Authorization"); hdr != auth.BearerScheme+"secret" {
+			t.Errorf("Expected the service token to be set, got %v", hdr)
+		}
+		if ns, _ := metadata.Get(c.callCtx, "Micro-Namespace"); ns != "foo" {
+			t.Errorf("Expected the namespace foo to be set, got %v", ns)
+		}
+	})
+
+	t.Run("KeepsHeader", func(t *testing.T) {
+		c := new(testClient)
+		a := &testAuth{namespace: "foo", token: token}
+		w := AuthClient(func() auth.Auth { return a }, c)
+
+		ctx := metadata.Set(context.TODO(), "Authorization", auth.BearerScheme+"user")
+		if err := w.Call(ctx, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if hdr, _ := metadata.Get(c.callCtx, "Authorization"); hdr != auth.BearerScheme+"user" {
+			t.Errorf("Expected the header to be kept, got %v", hdr)
+		}
+	})
+
+	t.Run("ExpiredToken", func(t *testing.T) {
+		c := new(testClient)
+		a := &testAuth{token: &auth.Token{AccessToken: "secret", Expiry: time.Now().Add(-time.Minute)}}
+		w := AuthClient(func() auth.Auth { return a }, c)
+
+		if err := w.Call(context.TODO(), nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := metadata.Get(c.callCtx, "Authorization"); ok {
+			t.Errorf("Expected no token to be set")
+		}
+	})
+}
+
+func TestAuthHandler(t *testing.T) {
+	tt := []struct {
+		name    string
+		header  string
+		account *auth.Account
+		err     bool
+	}{
+		{name: "NoToken"},
+		{
+			name:    "ValidToken",
+			header:  auth.BearerScheme + "token",
+			account: &auth.Account{ID: "bar", Issuer: "foo"},
+		},
+		{
+			name:    "ValidAudience",
+			header:  auth.BearerScheme + "token",
+			account: &auth.Account{ID: "bar", Issuer: "foo", Audience: "go.micro.service.foo"},
+		},
+		{
+			name:    "InvalidIssuer",
+			header:  auth.BearerScheme + "token",
+			account: &auth.Account{ID: "bar", Issuer: "baz"},
+			err:     true,
+		},
+		{
+			name:    "InvalidAudience",
+			header:  auth.BearerScheme + "token",
+			account: &auth.Account{ID: "bar", Issuer: "foo", Audience: "go.micro.service.bar"},
+			err:     true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &testAuth{namespace: "foo", inspectAccount: tc.account}
+			handler := AuthHandler(func() auth.Auth { return a })
+
+			var acc *auth.Account
+			var called bool
+			h := func(ctx context.Context, req server.Request, rsp interface{}) error {
+				called = true
+				acc, _ = auth.AccountFromContext(ctx)
+				return nil
+			}
+
+			ctx := context.TODO()
+			if len(tc.header) > 0 {
+				ctx = metadata.Set(ctx, "Authorization", tc.header)
+			}
+
+			err := handler(h)(ctx, testRequest{service: "go.micro.service.foo"}, nil)
+			if tc.err {
+				if verr, ok := err.(*errors.Error); !ok || verr.Code != 401 {
+					t.Fatalf("Expected unauthorized error, got %v", err)
+				}
+				if called {
+					t.Errorf("Expected the handler not to be called")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected nil error, got %v", err)
+			}
+			if !called {
+				t.Fatalf("Expected the handler to be called")
+			}
+			if tc.account != nil && (acc == nil || acc.ID != tc.account.ID) {
+				t.Errorf("Expected account %v in the context, got %v", tc.account, acc)
+			}
+			if tc.account == nil && acc != nil {
+				t.Errorf("Expected no account in the context, got %v", acc)
+			}
+		})
+	}
+}
+
+func TestRulesHandler(t *testing.T) {
+	tt := []struct {
+		name    string
+		account *auth.Account
+		err     error
+		code    int32
+	}{
+		{name: "Granted", account: &auth.Account{ID: "foo"}},
+		{name: "Public"},
+		{name: "Unauthorized", err: auth.ErrForbidden, code: 401},
+		{name: "Forbidden", account: &auth.Account{ID: "foo"}, err: auth.ErrForbidden, code: 403},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &testAuth{verifyError: tc.err}
+
+			var called bool
+			h := func(ctx context.Context, req server.Request, rsp interface{}) error {
+				called = true
+				return nil
+			}
+
+			ctx := context.TODO()
+			if tc.account != nil {
+				ctx = auth.ContextWithAccount(ctx, tc.account)
+			}
+
+			err := RulesHandler(r)(h)(ctx, testRequest{service: "go.micro.service.foo", endpoint: "Foo.Bar"}, nil)
+			if r.verifyCount != 1 {
+				t.Errorf("Expected verify to be called once, got %v", r.verifyCount)
+			}
+			if tc.code == 0 {
+				if err != nil || !called {
+					t.Fatalf("Expected the handler to be called, got %v", err)
+				}
+				return
+			}
+			if verr, ok := err.(*errors.Error); !ok || verr.Code != tc.code {
+				t.Fatalf("Expected error code %v, got %v", tc.code, err)
+			}
+			if called {
+				t.Errorf("Expected the handler not to be called")
+			}
+		})
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/asim/go-micro/v3"
+	"github.com/asim/go-micro/v3/api/server/acme"
+	"github.com/asim/go-micro/v3/api/server/cors"
 	"github.com/asim/go-micro/v3/registry"
 	"github.com/micro/cli/v2"
 )
@@ -38,8 +40,16 @@ type Options struct {
 	Registry registry.Registry
 	Service  micro.Service
 
-	Secure      bool
-	TLSConfig   *tls.Config
+	Secure    bool
+	TLSConfig *tls.Config
+
+	// ACMEProvider issues certificates for the ACMEHosts e.g Let's Encrypt
+	ACMEProvider acme.Provider
+	ACMEHosts    []string
+
+	// ShutdownTimeout is how long in flight requests are given to complete
+	ShutdownTimeout time.Duration
+
 	BeforeStart []func() error
 	BeforeStop  []func() error
 	AfterStart  []func() error
@@ -47,6 +57,11 @@ type Options struct {
 
 	// Static directory
 	StaticDir string
+	// SPA serves the static index for unknown paths
+	SPA bool
+
+	// CORS policy applied to the handler
+	CORS *cors.Config
 
 	Signal bool
 }
@@ -60,6 +75,7 @@ func newOptions(opts ...Option) Options {
 		RegisterTTL:      DefaultRegisterTTL,
 		RegisterInterval: DefaultRegisterInterval,
 		StaticDir:        DefaultStaticDir,
+		ShutdownTimeout:  DefaultShutdownTimeout,
 		Service:          micro.NewService(),
 		Context:          context.TODO(),
 		Signal:           true,
@@ -235,6 +251,23 @@ func TLSConfig(t *tls.Config) Option {
 	}
 }
 
+// ACME serves TLS using certificates issued by the provider for the hosts,
+// e.g autocert.NewProvider() for Let's Encrypt
+func ACME(p acme.Provider, hosts ...string) Option {
+	return func(o *Options) {
+		o.ACMEProvider = p
+		o.ACMEHosts = hosts
+	}
+}
+
+// ShutdownTimeout sets how long in flight requests are given to complete
+// on shutdown before the server is forcefully closed
+func ShutdownTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.ShutdownTimeout = d
+	}
+}
+
 // StaticDir sets the static file directory. This defaults to ./html
 func StaticDir(d string) Option {
 	return func(o *Options) {
@@ -242,6 +275,21 @@ func StaticDir(d string) Option {
 	}
 }
 
+// SPA serves index.html from the static directory for unknown paths
+// so single page apps using client side routing work
+func SPA(b bool) Option {
+	return func(o *Options) {
+		o.SPA = b
+	}
+}
+
+// CORS applies the CORS policy to all requests served
+func CORS(c cors.Config) Option {
+	return func(o *Options) {
+		o.CORS = &c
+	}
+}
+
 // RegisterCheck run func before registry service
 func RegisterCheck(fn func(context.Context) error) Option {
 	return func(o *Options) {
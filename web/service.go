@@ -1,6 +1,7 @@
 package web
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"net"
@@ -14,6 +15,8 @@ import (
 	"time"
 
 	"github.com/asim/go-micro/v3"
+	"github.com/asim/go-micro/v3/api/server/cors"
+	staticfs "github.com/asim/go-micro/v3/api/server/static"
 	"github.com/asim/go-micro/v3/logger"
 	"github.com/asim/go-micro/v3/registry"
 	maddr "github.com/asim/go-micro/v3/util/addr"
@@ -223,12 +226,16 @@ func (s *service) start() error {
 					if logger.V(logger.InfoLevel, logger.DefaultLogger) {
 						logger.Infof("Enabling static file serving from %s", static)
 					}
-					s.mux.Handle("/", http.FileServer(http.Dir(static)))
+					s.mux.Handle("/", staticfs.NewHandler(static, staticfs.SPA(s.opts.SPA)))
 				}
 			}
 		})
 	}
 
+	if s.opts.CORS != nil {
+		h = cors.NewHandler(h, *s.opts.CORS)
+	}
+
 	var httpSrv *http.Server
 	if s.opts.Server != nil {
 		httpSrv = s.opts.Server
@@ -251,7 +258,20 @@ func (s *service) start() error {
 
 	go func() {
 		ch := <-s.exit
-		ch <- l.Close()
+
+		// stop accepting connections and drain in flight requests
+		ctx, cancel := context.WithTimeout(context.Background(), s.opts.ShutdownTimeout)
+		defer cancel()
+
+		if err := httpSrv.Shutdown(ctx); err != nil {
+			if logger.V(logger.WarnLevel, logger.DefaultLogger) {
+				logger.Warnf("Failed to drain requests: %v", err)
+			}
+			ch <- httpSrv.Close()
+			return
+		}
+
+		ch <- nil
 	}()
 
 	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
@@ -509,7 +529,18 @@ func (s *service) listen(network, addr string) (net.Listener, error) {
 	var err error
 
 	// TODO: support use of listen options
-	if s.opts.Secure || s.opts.TLSConfig != nil {
+	if s.opts.ACMEProvider != nil {
+		config, cerr := s.opts.ACMEProvider.TLSConfig(s.opts.ACMEHosts...)
+		if cerr != nil {
+			return nil, cerr
+		}
+
+		fn := func(addr string) (net.Listener, error) {
+			return tls.Listen(network, addr, config)
+		}
+
+		l, err = mnet.Listen(addr, fn)
+	} else if s.opts.Secure || s.opts.TLSConfig != nil {
 		config := s.opts.TLSConfig
 
 		fn := func(addr string) (net.Listener, error) {
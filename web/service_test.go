@@ -1,9 +1,11 @@
 package web
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,6 +14,7 @@ import (
 	"time"
 
 	"github.com/asim/go-micro/v3/registry"
+	mls "github.com/asim/go-micro/v3/util/tls"
 )
 
 func TestService(t *testing.T) {
@@ -297,3 +300,163 @@ func TestTLS(t *testing.T) {
 	}
 
 }
+
+func TestShutdown(t *testing.T) {
+	var (
+		reg      = registry.NewMemoryRegistry()
+		started  = make(chan bool)
+		release  = make(chan bool)
+		ctx, ccl = context.WithCancel(context.Background())
+	)
+
+	service := NewService(
+		Name("go.micro.web.test"),
+		Address("127.0.0.1:0"),
+		Registry(reg),
+		Context(ctx),
+		ShutdownTimeout(time.Second*5),
+	)
+
+	service.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		fmt.Fprint(w, "done")
+	})
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- service.Run()
+	}()
+
+	var s []*registry.Service
+
+	eventually(func() bool {
+		var err error
+		s, err = reg.GetService("go.micro.web.test")
+		return err == nil
+	}, t.Fatal)
+
+	addr := s[0].Nodes[0].Address
+
+	type result struct {
+		body string
+		err  error
+	}
+
+	// hold a request in flight
+	results := make(chan result, 1)
+	go func() {
+		rsp, err := http.Get(fmt.Sprintf("http://%s", addr))
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+		defer rsp.Body.Close()
+		b, err := ioutil.ReadAll(rsp.Body)
+		results <- result{string(b), err}
+	}()
+	<-started
+
+	// stop the service
+	ccl()
+
+	// new connections are refused while the request is in flight
+	eventually(func() bool {
+		c, err := net.Dial("tcp", addr)
+		if err != nil {
+			return true
+		}
+		c.Close()
+		return false
+	}, t.Fatal)
+
+	select {
+	case err := <-errCh:
+		t.Fatalf("Expected the service to wait for the request, it returned %v", err)
+	default:
+	}
+
+	close(release)
+
+	res := <-results
+	if res.err != nil {
+		t.Fatalf("Expected the request to complete got %v", res.err)
+	}
+	if res.body != "done" {
+		t.Fatalf("Expected done got %s", res.body)
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("service.Run():%v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the service to stop")
+	}
+}
+
+// testProvider issues a self signed certificate for the hosts
+type testProvider struct {
+	hosts []string
+}
+
+func (p *testProvider) Listen(hosts ...string) (net.Listener, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (p *testProvider) TLSConfig(hosts ...string) (*tls.Config, error) {
+	p.hosts = hosts
+	cert, err := mls.Certificate(hosts...)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+func TestACME(t *testing.T) {
+	var (
+		reg      = registry.NewMemoryRegistry()
+		provider = new(testProvider)
+		ctx, ccl = context.WithCancel(context.Background())
+	)
+	defer ccl()
+
+	service := NewService(
+		Name("go.micro.web.test"),
+		Address("127.0.0.1:0"),
+		Registry(reg),
+		Context(ctx),
+		ACME(provider, "example.com"),
+	)
+
+	service.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "hello")
+	})
+
+	go service.Run()
+
+	var s []*registry.Service
+
+	eventually(func() bool {
+		var err error
+		s, err = reg.GetService("go.micro.web.test")
+		return err == nil
+	}, t.Fatal)
+
+	if len(provider.hosts) != 1 || provider.hosts[0] != "example.com" {
+		t.Fatalf("Expected the provider to be asked for example.com got %v", provider.hosts)
+	}
+
+	// served with the certificate of the provider
+	conn, err := tls.Dial("tcp", s[0].Nodes[0].Address, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 || certs[0].VerifyHostname("example.com") != nil {
+		t.Fatal("Expected the certificate issued by the provider")
+	}
+}
@@ -37,6 +37,9 @@ var (
 	// static directory
 	DefaultStaticDir     = "html"
 	DefaultRegisterCheck = func(context.Context) error { return nil }
+
+	// how long in flight requests are given to complete on shutdown
+	DefaultShutdownTimeout = time.Second * 30
 )
 
 // NewService returns a new web.Service